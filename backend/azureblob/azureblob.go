@@ -3,6 +3,7 @@ package azureblob
 
 import (
 	"bytes"
+	"context"
 	"crypto/md5"
 	"encoding/base64"
 	"encoding/binary"
@@ -21,8 +22,8 @@ import (
 	"github.com/Azure/azure-sdk-for-go/storage"
 	"github.com/ncw/rclone/fs"
 	"github.com/ncw/rclone/fs/accounting"
-	"github.com/ncw/rclone/fs/config"
-	"github.com/ncw/rclone/fs/config/flags"
+	"github.com/ncw/rclone/fs/config/configmap"
+	"github.com/ncw/rclone/fs/config/configstruct"
 	"github.com/ncw/rclone/fs/fserrors"
 	"github.com/ncw/rclone/fs/fshttp"
 	"github.com/ncw/rclone/fs/hash"
@@ -47,9 +48,11 @@ const (
 // Globals
 var (
 	maxChunkSize    = fs.SizeSuffix(100 * 1024 * 1024)
-	chunkSize       = fs.SizeSuffix(4 * 1024 * 1024)
-	uploadCutoff    = fs.SizeSuffix(256 * 1024 * 1024)
+	defaultChunk    = fs.SizeSuffix(4 * 1024 * 1024)
 	maxUploadCutoff = fs.SizeSuffix(256 * 1024 * 1024)
+
+	// metadata names must be valid C# identifiers
+	validMetadataName = regexp.MustCompile(`^[a-z_][a-z0-9_]*$`)
 )
 
 // Register with Fs
@@ -67,11 +70,27 @@ func init() {
 		}, {
 			Name: "endpoint",
 			Help: "Endpoint for the service - leave blank normally.",
-		},
-		},
+		}, {
+			Name:     "upload_cutoff",
+			Help:     "Cutoff for switching to chunked upload",
+			Default:  maxUploadCutoff,
+			Advanced: true,
+		}, {
+			Name:     "chunk_size",
+			Help:     "Upload chunk size. Must fit in memory.",
+			Default:  defaultChunk,
+			Advanced: true,
+		}},
 	})
-	flags.VarP(&uploadCutoff, "azureblob-upload-cutoff", "", "Cutoff for switching to chunked upload")
-	flags.VarP(&chunkSize, "azureblob-chunk-size", "", "Upload chunk size. Must fit in memory.")
+}
+
+// Options defines the configuration for this backend
+type Options struct {
+	Account      string        `config:"account"`
+	Key          string        `config:"key"`
+	Endpoint     string        `config:"endpoint"`
+	UploadCutoff fs.SizeSuffix `config:"upload_cutoff"`
+	ChunkSize    fs.SizeSuffix `config:"chunk_size"`
 }
 
 // Fs represents a remote azure server
@@ -79,9 +98,8 @@ type Fs struct {
 	name             string       // name of this remote
 	root             string       // the path we are working on if any
 	features         *fs.Features // optional features
-	account          string       // account name
+	opt              Options      // parsed config options
 	key              []byte       // auth key
-	endpoint         string       // name of the starting api endpoint
 	bc               *storage.BlobStorageClient
 	cc               *storage.Container
 	container        string                // the container we are working on
@@ -94,14 +112,18 @@ type Fs struct {
 
 // Object describes a azure object
 type Object struct {
-	fs       *Fs               // what this object is part of
-	remote   string            // The remote path
-	id       string            // azure id of the file
-	modTime  time.Time         // The modified time of the object if known
-	md5      string            // MD5 hash if known
-	size     int64             // Size of the object
-	mimeType string            // Content-Type of the object
-	meta     map[string]string // blob metadata
+	fs                 *Fs               // what this object is part of
+	remote             string            // The remote path
+	id                 string            // azure id of the file
+	modTime            time.Time         // The modified time of the object if known
+	md5                string            // MD5 hash if known
+	size               int64             // Size of the object
+	mimeType           string            // Content-Type of the object
+	cacheControl       string            // Cache-Control of the object
+	contentDisposition string            // Content-Disposition of the object
+	contentEncoding    string            // Content-Encoding of the object
+	contentLanguage    string            // Content-Language of the object
+	meta               map[string]string // blob metadata
 }
 
 // ------------------------------------------------------------
@@ -173,33 +195,41 @@ func (f *Fs) shouldRetry(err error) (bool, error) {
 }
 
 // NewFs contstructs an Fs from the path, container:path
-func NewFs(name, root string) (fs.Fs, error) {
-	if uploadCutoff > maxUploadCutoff {
-		return nil, errors.Errorf("azure: upload cutoff (%v) must be less than or equal to %v", uploadCutoff, maxUploadCutoff)
+func NewFs(name, root string, m configmap.Mapper) (fs.Fs, error) {
+	ctx := context.Background()
+	// Parse config into Options struct
+	opt := new(Options)
+	err := configstruct.Set(m, opt)
+	if err != nil {
+		return nil, err
 	}
-	if chunkSize > maxChunkSize {
-		return nil, errors.Errorf("azure: chunk size can't be greater than %v - was %v", maxChunkSize, chunkSize)
+
+	if opt.UploadCutoff > maxUploadCutoff {
+		return nil, errors.Errorf("azure: upload cutoff (%v) must be less than or equal to %v", opt.UploadCutoff, maxUploadCutoff)
+	}
+	if opt.ChunkSize > maxChunkSize {
+		return nil, errors.Errorf("azure: chunk size can't be greater than %v - was %v", maxChunkSize, opt.ChunkSize)
 	}
 	container, directory, err := parsePath(root)
 	if err != nil {
 		return nil, err
 	}
-	account := config.FileGet(name, "account")
-	if account == "" {
+	if opt.Account == "" {
 		return nil, errors.New("account not found")
 	}
-	key := config.FileGet(name, "key")
-	if key == "" {
+	if opt.Key == "" {
 		return nil, errors.New("key not found")
 	}
-	keyBytes, err := base64.StdEncoding.DecodeString(key)
+	keyBytes, err := base64.StdEncoding.DecodeString(opt.Key)
 	if err != nil {
 		return nil, errors.Errorf("malformed storage account key: %v", err)
 	}
 
-	endpoint := config.FileGet(name, "endpoint", storage.DefaultBaseURL)
+	if opt.Endpoint == "" {
+		opt.Endpoint = storage.DefaultBaseURL
+	}
 
-	client, err := storage.NewClient(account, key, endpoint, apiVersion, true)
+	client, err := storage.NewClient(opt.Account, opt.Key, opt.Endpoint, apiVersion, true)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to make azure storage client")
 	}
@@ -210,17 +240,18 @@ func NewFs(name, root string) (fs.Fs, error) {
 		name:        name,
 		container:   container,
 		root:        directory,
-		account:     account,
+		opt:         *opt,
 		key:         keyBytes,
-		endpoint:    endpoint,
 		bc:          &bc,
 		cc:          bc.GetContainerReference(container),
-		pacer:       pacer.New().SetMinSleep(minSleep).SetMaxSleep(maxSleep).SetDecayConstant(decayConstant),
+		pacer:       pacer.New().SetMinSleep(minSleep).SetMaxSleep(maxSleep).SetDecayConstant(decayConstant).SetName(name),
 		uploadToken: pacer.NewTokenDispenser(fs.Config.Transfers),
 	}
 	f.features = (&fs.Features{
 		ReadMimeType:  true,
 		WriteMimeType: true,
+		ReadMetadata:  true,
+		WriteMetadata: true,
 		BucketBased:   true,
 	}).Fill(f)
 	if f.root != "" {
@@ -234,7 +265,7 @@ func NewFs(name, root string) (fs.Fs, error) {
 		} else {
 			f.root += "/"
 		}
-		_, err := f.NewObject(remote)
+		_, err := f.NewObject(ctx, remote)
 		if err != nil {
 			if err == fs.ErrorObjectNotFound {
 				// File doesn't exist so return old f
@@ -252,7 +283,7 @@ func NewFs(name, root string) (fs.Fs, error) {
 // Return an Object from a path
 //
 // If it can't be found it returns the error fs.ErrorObjectNotFound.
-func (f *Fs) newObjectWithInfo(remote string, info *storage.Blob) (fs.Object, error) {
+func (f *Fs) newObjectWithInfo(ctx context.Context, remote string, info *storage.Blob) (fs.Object, error) {
 	o := &Object{
 		fs:     f,
 		remote: remote,
@@ -263,7 +294,7 @@ func (f *Fs) newObjectWithInfo(remote string, info *storage.Blob) (fs.Object, er
 			return nil, err
 		}
 	} else {
-		err := o.readMetaData() // reads info and headers, returning an error
+		err := o.readMetaData(ctx) // reads info and headers, returning an error
 		if err != nil {
 			return nil, err
 		}
@@ -273,8 +304,8 @@ func (f *Fs) newObjectWithInfo(remote string, info *storage.Blob) (fs.Object, er
 
 // NewObject finds the Object at remote.  If it can't be found
 // it returns the error fs.ErrorObjectNotFound.
-func (f *Fs) NewObject(remote string) (fs.Object, error) {
-	return f.newObjectWithInfo(remote, nil)
+func (f *Fs) NewObject(ctx context.Context, remote string) (fs.Object, error) {
+	return f.newObjectWithInfo(ctx, remote, nil)
 }
 
 // getBlobReference creates an empty blob reference with no metadata
@@ -305,7 +336,7 @@ type listFn func(remote string, object *storage.Blob, isDirectory bool) error
 // the container and root supplied
 //
 // dir is the starting directory, "" for root
-func (f *Fs) list(dir string, recurse bool, maxResults uint, fn listFn) error {
+func (f *Fs) list(ctx context.Context, dir string, recurse bool, maxResults uint, fn listFn) error {
 	f.containerOKMu.Lock()
 	deleted := f.containerDeleted
 	f.containerOKMu.Unlock()
@@ -333,7 +364,7 @@ func (f *Fs) list(dir string, recurse bool, maxResults uint, fn listFn) error {
 	}
 	for {
 		var response storage.BlobListResponse
-		err := f.pacer.Call(func() (bool, error) {
+		err := f.pacer.CallContext(ctx, func() (bool, error) {
 			var err error
 			response, err = f.cc.ListBlobs(params)
 			return f.shouldRetry(err)
@@ -390,12 +421,12 @@ func (f *Fs) list(dir string, recurse bool, maxResults uint, fn listFn) error {
 }
 
 // Convert a list item into a DirEntry
-func (f *Fs) itemToDirEntry(remote string, object *storage.Blob, isDirectory bool) (fs.DirEntry, error) {
+func (f *Fs) itemToDirEntry(ctx context.Context, remote string, object *storage.Blob, isDirectory bool) (fs.DirEntry, error) {
 	if isDirectory {
 		d := fs.NewDir(remote, time.Time{})
 		return d, nil
 	}
-	o, err := f.newObjectWithInfo(remote, object)
+	o, err := f.newObjectWithInfo(ctx, remote, object)
 	if err != nil {
 		return nil, err
 	}
@@ -413,9 +444,9 @@ func (f *Fs) markContainerOK() {
 }
 
 // listDir lists a single directory
-func (f *Fs) listDir(dir string) (entries fs.DirEntries, err error) {
-	err = f.list(dir, false, listChunkSize, func(remote string, object *storage.Blob, isDirectory bool) error {
-		entry, err := f.itemToDirEntry(remote, object, isDirectory)
+func (f *Fs) listDir(ctx context.Context, dir string) (entries fs.DirEntries, err error) {
+	err = f.list(ctx, dir, false, listChunkSize, func(remote string, object *storage.Blob, isDirectory bool) error {
+		entry, err := f.itemToDirEntry(ctx, remote, object, isDirectory)
 		if err != nil {
 			return err
 		}
@@ -433,11 +464,11 @@ func (f *Fs) listDir(dir string) (entries fs.DirEntries, err error) {
 }
 
 // listContainers returns all the containers to out
-func (f *Fs) listContainers(dir string) (entries fs.DirEntries, err error) {
+func (f *Fs) listContainers(ctx context.Context, dir string) (entries fs.DirEntries, err error) {
 	if dir != "" {
 		return nil, fs.ErrorListBucketRequired
 	}
-	err = f.listContainersToFn(func(container *storage.Container) error {
+	err = f.listContainersToFn(ctx, func(container *storage.Container) error {
 		t, err := time.Parse(time.RFC1123, container.Properties.LastModified)
 		if err != nil {
 			fs.Debugf(f, "Failed to parse LastModified %q: %v", container.Properties.LastModified, err)
@@ -462,11 +493,11 @@ func (f *Fs) listContainers(dir string) (entries fs.DirEntries, err error) {
 //
 // This should return ErrDirNotFound if the directory isn't
 // found.
-func (f *Fs) List(dir string) (entries fs.DirEntries, err error) {
+func (f *Fs) List(ctx context.Context, dir string) (entries fs.DirEntries, err error) {
 	if f.container == "" {
-		return f.listContainers(dir)
+		return f.listContainers(ctx, dir)
 	}
-	return f.listDir(dir)
+	return f.listDir(ctx, dir)
 }
 
 // ListR lists the objects and directories of the Fs starting
@@ -485,13 +516,13 @@ func (f *Fs) List(dir string) (entries fs.DirEntries, err error) {
 //
 // Don't implement this unless you have a more efficient way
 // of listing recursively that doing a directory traversal.
-func (f *Fs) ListR(dir string, callback fs.ListRCallback) (err error) {
+func (f *Fs) ListR(ctx context.Context, dir string, callback fs.ListRCallback) (err error) {
 	if f.container == "" {
 		return fs.ErrorListBucketRequired
 	}
 	list := walk.NewListRHelper(callback)
-	err = f.list(dir, true, listChunkSize, func(remote string, object *storage.Blob, isDirectory bool) error {
-		entry, err := f.itemToDirEntry(remote, object, isDirectory)
+	err = f.list(ctx, dir, true, listChunkSize, func(remote string, object *storage.Blob, isDirectory bool) error {
+		entry, err := f.itemToDirEntry(ctx, remote, object, isDirectory)
 		if err != nil {
 			return err
 		}
@@ -509,11 +540,11 @@ func (f *Fs) ListR(dir string, callback fs.ListRCallback) (err error) {
 type listContainerFn func(*storage.Container) error
 
 // listContainersToFn lists the containers to the function supplied
-func (f *Fs) listContainersToFn(fn listContainerFn) error {
+func (f *Fs) listContainersToFn(ctx context.Context, fn listContainerFn) error {
 	// FIXME page the containers if necessary?
 	params := storage.ListContainersParameters{}
 	var response *storage.ContainerListResponse
-	err := f.pacer.Call(func() (bool, error) {
+	err := f.pacer.CallContext(ctx, func() (bool, error) {
 		var err error
 		response, err = f.bc.ListContainers(params)
 		return f.shouldRetry(err)
@@ -535,17 +566,17 @@ func (f *Fs) listContainersToFn(fn listContainerFn) error {
 // Copy the reader in to the new object which is returned
 //
 // The new object may have been created if an error is returned
-func (f *Fs) Put(in io.Reader, src fs.ObjectInfo, options ...fs.OpenOption) (fs.Object, error) {
+func (f *Fs) Put(ctx context.Context, in io.Reader, src fs.ObjectInfo, options ...fs.OpenOption) (fs.Object, error) {
 	// Temporary Object under construction
 	fs := &Object{
 		fs:     f,
 		remote: src.Remote(),
 	}
-	return fs, fs.Update(in, src, options...)
+	return fs, fs.Update(ctx, in, src, options...)
 }
 
 // Mkdir creates the container if it doesn't exist
-func (f *Fs) Mkdir(dir string) error {
+func (f *Fs) Mkdir(ctx context.Context, dir string) error {
 	f.containerOKMu.Lock()
 	defer f.containerOKMu.Unlock()
 	if f.containerOK {
@@ -554,7 +585,7 @@ func (f *Fs) Mkdir(dir string) error {
 	options := storage.CreateContainerOptions{
 		Access: storage.ContainerAccessTypePrivate,
 	}
-	err := f.pacer.Call(func() (bool, error) {
+	err := f.pacer.CallContext(ctx, func() (bool, error) {
 		err := f.cc.Create(&options)
 		if err != nil {
 			if storageErr, ok := err.(storage.AzureStorageServiceError); ok {
@@ -581,9 +612,9 @@ func (f *Fs) Mkdir(dir string) error {
 }
 
 // isEmpty checks to see if a given directory is empty and returns an error if not
-func (f *Fs) isEmpty(dir string) (err error) {
+func (f *Fs) isEmpty(ctx context.Context, dir string) (err error) {
 	empty := true
-	err = f.list("", true, 1, func(remote string, object *storage.Blob, isDirectory bool) error {
+	err = f.list(ctx, "", true, 1, func(remote string, object *storage.Blob, isDirectory bool) error {
 		empty = false
 		return nil
 	})
@@ -598,11 +629,11 @@ func (f *Fs) isEmpty(dir string) (err error) {
 
 // deleteContainer deletes the container.  It can delete a full
 // container so use isEmpty if you don't want that.
-func (f *Fs) deleteContainer() error {
+func (f *Fs) deleteContainer(ctx context.Context) error {
 	f.containerOKMu.Lock()
 	defer f.containerOKMu.Unlock()
 	options := storage.DeleteContainerOptions{}
-	err := f.pacer.Call(func() (bool, error) {
+	err := f.pacer.CallContext(ctx, func() (bool, error) {
 		exists, err := f.cc.Exists()
 		if err != nil {
 			return f.shouldRetry(err)
@@ -623,15 +654,15 @@ func (f *Fs) deleteContainer() error {
 // Rmdir deletes the container if the fs is at the root
 //
 // Returns an error if it isn't empty
-func (f *Fs) Rmdir(dir string) error {
-	err := f.isEmpty(dir)
+func (f *Fs) Rmdir(ctx context.Context, dir string) error {
+	err := f.isEmpty(ctx, dir)
 	if err != nil {
 		return err
 	}
 	if f.root != "" || dir != "" {
 		return nil
 	}
-	return f.deleteContainer()
+	return f.deleteContainer(ctx)
 }
 
 // Precision of the remote
@@ -644,14 +675,24 @@ func (f *Fs) Hashes() hash.Set {
 	return hash.Set(hash.MD5)
 }
 
+// ServerSideCompatible returns true if src uses the same storage
+// account as f so server side copies between them will work
+func (f *Fs) ServerSideCompatible(src fs.Info) bool {
+	srcFs, ok := src.(*Fs)
+	if !ok {
+		return false
+	}
+	return f.opt.Account == srcFs.opt.Account && f.opt.Endpoint == srcFs.opt.Endpoint
+}
+
 // Purge deletes all the files and directories including the old versions.
-func (f *Fs) Purge() error {
+func (f *Fs) Purge(ctx context.Context) error {
 	dir := "" // forward compat!
 	if f.root != "" || dir != "" {
 		// Delegate to caller if not root container
 		return fs.ErrorCantPurge
 	}
-	return f.deleteContainer()
+	return f.deleteContainer(ctx)
 }
 
 // Copy src to this remote using server side copy operations.
@@ -663,8 +704,8 @@ func (f *Fs) Purge() error {
 // Will only be called if src.Fs().Name() == f.Name()
 //
 // If it isn't possible then return fs.ErrorCantCopy
-func (f *Fs) Copy(src fs.Object, remote string) (fs.Object, error) {
-	err := f.Mkdir("")
+func (f *Fs) Copy(ctx context.Context, src fs.Object, remote string) (fs.Object, error) {
+	err := f.Mkdir(ctx, "")
 	if err != nil {
 		return nil, err
 	}
@@ -677,14 +718,14 @@ func (f *Fs) Copy(src fs.Object, remote string) (fs.Object, error) {
 	srcBlob := srcObj.getBlobReference()
 	options := storage.CopyOptions{}
 	sourceBlobURL := srcBlob.GetURL()
-	err = f.pacer.Call(func() (bool, error) {
+	err = f.pacer.CallContext(ctx, func() (bool, error) {
 		err = dstBlob.Copy(sourceBlobURL, &options)
 		return f.shouldRetry(err)
 	})
 	if err != nil {
 		return nil, err
 	}
-	return f.NewObject(remote)
+	return f.NewObject(ctx, remote)
 }
 
 // ------------------------------------------------------------
@@ -739,6 +780,10 @@ func (o *Object) Size() int64 {
 func (o *Object) decodeMetaData(info *storage.Blob) (err error) {
 	o.md5 = info.Properties.ContentMD5
 	o.mimeType = info.Properties.ContentType
+	o.cacheControl = info.Properties.CacheControl
+	o.contentDisposition = info.Properties.ContentDisposition
+	o.contentEncoding = info.Properties.ContentEncoding
+	o.contentLanguage = info.Properties.ContentLanguage
 	o.size = info.Properties.ContentLength
 	o.modTime = time.Time(info.Properties.LastModified)
 	if len(info.Metadata) > 0 {
@@ -773,7 +818,7 @@ func (o *Object) clearMetaData() {
 //  o.modTime
 //  o.size
 //  o.md5
-func (o *Object) readMetaData() (err error) {
+func (o *Object) readMetaData(ctx context.Context) (err error) {
 	if !o.modTime.IsZero() {
 		return nil
 	}
@@ -781,7 +826,7 @@ func (o *Object) readMetaData() (err error) {
 
 	// Read metadata (this includes metadata)
 	getPropertiesOptions := storage.GetBlobPropertiesOptions{}
-	err = o.fs.pacer.Call(func() (bool, error) {
+	err = o.fs.pacer.CallContext(ctx, func() (bool, error) {
 		err = blob.GetProperties(&getPropertiesOptions)
 		return o.fs.shouldRetry(err)
 	})
@@ -823,15 +868,15 @@ func (o *Object) parseTimeString(timeString string) (err error) {
 // LastModified returned in the http headers
 func (o *Object) ModTime() (result time.Time) {
 	// The error is logged in readMetaData
-	_ = o.readMetaData()
+	_ = o.readMetaData(context.TODO())
 	return o.modTime
 }
 
 // SetModTime sets the modification time of the local fs object
-func (o *Object) SetModTime(modTime time.Time) error {
+func (o *Object) SetModTime(ctx context.Context, modTime time.Time) error {
 	blob := o.getBlobWithModTime(modTime)
 	options := storage.SetBlobMetadataOptions{}
-	err := o.fs.pacer.Call(func() (bool, error) {
+	err := o.fs.pacer.CallContext(ctx, func() (bool, error) {
 		err := blob.SetMetadata(&options)
 		return o.fs.shouldRetry(err)
 	})
@@ -858,7 +903,7 @@ type openFile struct {
 }
 
 // Open an object for read
-func (o *Object) Open(options ...fs.OpenOption) (in io.ReadCloser, err error) {
+func (o *Object) Open(ctx context.Context, options ...fs.OpenOption) (in io.ReadCloser, err error) {
 	getBlobOptions := storage.GetBlobOptions{}
 	getBlobRangeOptions := storage.GetBlobRangeOptions{
 		GetBlobOptions: &getBlobOptions,
@@ -889,7 +934,7 @@ func (o *Object) Open(options ...fs.OpenOption) (in io.ReadCloser, err error) {
 		}
 	}
 	blob := o.getBlobReference()
-	err = o.fs.pacer.Call(func() (bool, error) {
+	err = o.fs.pacer.CallContext(ctx, func() (bool, error) {
 		if getBlobRangeOptions.Range == nil {
 			in, err = blob.Get(&getBlobOptions)
 		} else {
@@ -941,9 +986,9 @@ func urlEncode(in string) string {
 // uploadMultipart uploads a file using multipart upload
 //
 // Write a larger blob, using CreateBlockBlob, PutBlock, and PutBlockList.
-func (o *Object) uploadMultipart(in io.Reader, size int64, blob *storage.Blob, putBlobOptions *storage.PutBlobOptions) (err error) {
+func (o *Object) uploadMultipart(ctx context.Context, in io.Reader, size int64, blob *storage.Blob, putBlobOptions *storage.PutBlobOptions) (err error) {
 	// Calculate correct chunkSize
-	chunkSize := int64(chunkSize)
+	chunkSize := int64(o.fs.opt.ChunkSize)
 	var totalParts int64
 	for {
 		// Calculate number of parts
@@ -964,7 +1009,7 @@ func (o *Object) uploadMultipart(in io.Reader, size int64, blob *storage.Blob, p
 	fs.Debugf(o, "Multipart upload session started for %d parts of size %v", totalParts, fs.SizeSuffix(chunkSize))
 
 	// Create an empty blob
-	err = o.fs.pacer.Call(func() (bool, error) {
+	err = o.fs.pacer.CallContext(ctx, func() (bool, error) {
 		err := blob.CreateBlockBlob(putBlobOptions)
 		return o.fs.shouldRetry(err)
 	})
@@ -1035,7 +1080,7 @@ outer:
 			putBlockOptions := storage.PutBlockOptions{
 				ContentMD5: base64.StdEncoding.EncodeToString(md5sum[:]),
 			}
-			err = o.fs.pacer.Call(func() (bool, error) {
+			err = o.fs.pacer.CallContext(ctx, func() (bool, error) {
 				err = blob.PutBlockWithLength(blockID, uint64(len(buf)), wrap(bytes.NewBuffer(buf)), &putBlockOptions)
 				return o.fs.shouldRetry(err)
 			})
@@ -1067,7 +1112,7 @@ outer:
 
 	// Finalise the upload session
 	putBlockListOptions := storage.PutBlockListOptions{}
-	err = o.fs.pacer.Call(func() (bool, error) {
+	err = o.fs.pacer.CallContext(ctx, func() (bool, error) {
 		err := blob.PutBlockList(blocks, &putBlockListOptions)
 		return o.fs.shouldRetry(err)
 	})
@@ -1080,8 +1125,8 @@ outer:
 // Update the object with the contents of the io.Reader, modTime and size
 //
 // The new object may have been created if an error is returned
-func (o *Object) Update(in io.Reader, src fs.ObjectInfo, options ...fs.OpenOption) (err error) {
-	err = o.fs.Mkdir("")
+func (o *Object) Update(ctx context.Context, in io.Reader, src fs.ObjectInfo, options ...fs.OpenOption) (err error) {
+	err = o.fs.Mkdir(ctx, "")
 	if err != nil {
 		return err
 	}
@@ -1096,13 +1141,17 @@ func (o *Object) Update(in io.Reader, src fs.ObjectInfo, options ...fs.OpenOptio
 			fs.Debugf(o, "Failed to decode %q as MD5: %v", sourceMD5, err)
 		}
 	}
+	err = o.setMetadata(ctx, blob, src, options)
+	if err != nil {
+		return err
+	}
 	putBlobOptions := storage.PutBlobOptions{}
 
 	// Don't retry, return a retry error instead
-	err = o.fs.pacer.CallNoRetry(func() (bool, error) {
-		if size >= int64(uploadCutoff) {
+	err = o.fs.pacer.CallNoRetryContext(ctx, func() (bool, error) {
+		if size >= int64(o.fs.opt.UploadCutoff) {
 			// If a large file upload in chunks
-			err = o.uploadMultipart(in, size, blob, &putBlobOptions)
+			err = o.uploadMultipart(ctx, in, size, blob, &putBlobOptions)
 		} else {
 			// Write a small blob in one transaction
 			if size == 0 {
@@ -1116,14 +1165,14 @@ func (o *Object) Update(in io.Reader, src fs.ObjectInfo, options ...fs.OpenOptio
 		return err
 	}
 	o.clearMetaData()
-	return o.readMetaData()
+	return o.readMetaData(ctx)
 }
 
 // Remove an object
-func (o *Object) Remove() error {
+func (o *Object) Remove(ctx context.Context) error {
 	blob := o.getBlobReference()
 	options := storage.DeleteBlobOptions{}
-	return o.fs.pacer.Call(func() (bool, error) {
+	return o.fs.pacer.CallContext(ctx, func() (bool, error) {
 		err := blob.Delete(&options)
 		return o.fs.shouldRetry(err)
 	})
@@ -1134,12 +1183,77 @@ func (o *Object) MimeType() string {
 	return o.mimeType
 }
 
+// Metadata returns the blob metadata and the Content-* properties of
+// the object
+func (o *Object) Metadata(ctx context.Context) (metadata fs.Metadata, err error) {
+	err = o.readMetaData(ctx)
+	if err != nil {
+		return nil, err
+	}
+	metadata = make(fs.Metadata, len(o.meta)+5)
+	for k, v := range o.meta {
+		if k == modTimeKey {
+			continue
+		}
+		metadata[strings.ToLower(k)] = v
+	}
+	for k, v := range map[string]string{
+		"content-type":        o.mimeType,
+		"cache-control":       o.cacheControl,
+		"content-disposition": o.contentDisposition,
+		"content-encoding":    o.contentEncoding,
+		"content-language":    o.contentLanguage,
+	} {
+		if v != "" {
+			metadata[k] = v
+		}
+	}
+	return metadata, nil
+}
+
+// setMetadata sets the metadata read from src and options on blob
+// ready for upload if --metadata is in use
+//
+// Azure only allows metadata names which are valid C# identifiers so
+// any which aren't are skipped.
+func (o *Object) setMetadata(ctx context.Context, blob *storage.Blob, src fs.ObjectInfo, options []fs.OpenOption) error {
+	metadata, err := fs.GetMetadataOptions(ctx, src, options)
+	if err != nil {
+		return errors.Wrap(err, "failed to read metadata from source object")
+	}
+	for k, v := range metadata {
+		switch k {
+		case "content-type":
+			blob.Properties.ContentType = v
+		case "cache-control":
+			blob.Properties.CacheControl = v
+		case "content-disposition":
+			blob.Properties.ContentDisposition = v
+		case "content-encoding":
+			blob.Properties.ContentEncoding = v
+		case "content-language":
+			blob.Properties.ContentLanguage = v
+		case modTimeKey:
+			// set by rclone so ignore
+		default:
+			if !validMetadataName.MatchString(k) {
+				fs.Debugf(o, "Ignoring metadata %q as it isn't a valid Azure metadata name", k)
+				continue
+			}
+			blob.Metadata[k] = v
+		}
+	}
+	return nil
+}
+
 // Check the interfaces are satisfied
 var (
-	_ fs.Fs        = &Fs{}
-	_ fs.Copier    = &Fs{}
-	_ fs.Purger    = &Fs{}
-	_ fs.ListRer   = &Fs{}
-	_ fs.Object    = &Object{}
-	_ fs.MimeTyper = &Object{}
+	_ fs.Fs                    = &Fs{}
+	_ fs.Copier                = &Fs{}
+	_ fs.ServerSideCompatibler = &Fs{}
+	_ fs.Purger                = &Fs{}
+	_ fs.ListRer               = &Fs{}
+	_ fs.Object                = &Object{}
+	_ fs.MimeTyper             = &Object{}
+	_ fs.Metadataer            = &Object{}
 )
@@ -190,3 +190,23 @@ type CommitUpload struct {
 		ContentModifiedAt Time `json:"content_modified_at"`
 	} `json:"attributes"`
 }
+
+// User is returned from /users/me
+type User struct {
+	Type          string `json:"type"`
+	ID            string `json:"id"`
+	Name          string `json:"name"`
+	Login         string `json:"login"`
+	CreatedAt     Time   `json:"created_at"`
+	ModifiedAt    Time   `json:"modified_at"`
+	Language      string `json:"language"`
+	Timezone      string `json:"timezone"`
+	SpaceAmount   int64  `json:"space_amount"`
+	SpaceUsed     int64  `json:"space_used"`
+	MaxUploadSize int64  `json:"max_upload_size"`
+	Status        string `json:"status"`
+	JobTitle      string `json:"job_title"`
+	Phone         string `json:"phone"`
+	Address       string `json:"address"`
+	AvatarURL     string `json:"avatar_url"`
+}
@@ -1,14 +1,19 @@
 package local
 
 import (
+	"context"
 	"os"
 	"path"
+	"runtime"
+	"sort"
 	"testing"
 	"time"
 
+	"github.com/ncw/rclone/fs"
 	"github.com/ncw/rclone/fs/hash"
 	"github.com/ncw/rclone/fstest"
 	"github.com/ncw/rclone/lib/readers"
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -44,7 +49,7 @@ func TestUpdatingCheck(t *testing.T) {
 	}
 
 	fi, err := fd.Stat()
-	o := &Object{size: fi.Size(), modTime: fi.ModTime()}
+	o := &Object{size: fi.Size(), modTime: fi.ModTime(), fs: &Fs{}}
 	wrappedFd := readers.NewLimitedReadCloser(fd, -1)
 	hash, err := hash.NewMultiHasherTypes(hash.Supported)
 	in := localOpenFile{
@@ -64,13 +69,114 @@ func TestUpdatingCheck(t *testing.T) {
 
 	// turn the checking off and try again
 
-	*noCheckUpdated = true
-	defer func() {
-		*noCheckUpdated = false
-	}()
+	o.fs.opt.NoCheckUpdated = true
 
 	r.WriteFile(filePath, "content updated", time.Now())
 	_, err = in.Read(buf)
 	require.NoError(t, err)
 
 }
+
+// Test reading and writing metadata
+func TestMetadata(t *testing.T) {
+	r := fstest.NewRun(t)
+	defer r.Finalise()
+	const filePath = "metafile.txt"
+	when := time.Date(2018, 6, 1, 12, 0, 0, 0, time.UTC)
+	r.WriteFile(filePath, "metadata", when)
+	f := r.Flocal.(*Fs)
+	obj, err := f.NewObject(context.Background(), filePath)
+	require.NoError(t, err)
+	o := obj.(*Object)
+
+	atime := time.Date(2018, 6, 2, 12, 0, 0, 0, time.UTC)
+	err = o.writeMetadata(fs.Metadata{
+		"mode":  "600",
+		"atime": atime.Format(time.RFC3339Nano),
+	})
+	require.NoError(t, err)
+
+	m, err := o.Metadata(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, when.Format(time.RFC3339Nano), m["mtime"])
+	if runtime.GOOS != "windows" {
+		assert.Equal(t, "600", m["mode"])
+	}
+	if runtime.GOOS == "linux" {
+		assert.Equal(t, atime.Format(time.RFC3339Nano), m["atime"])
+		assert.NotEqual(t, "", m["uid"])
+		assert.NotEqual(t, "", m["gid"])
+	}
+}
+
+func TestListP(t *testing.T) {
+	ctx := context.Background()
+	r := fstest.NewRun(t)
+	defer r.Finalise()
+	r.WriteFile("dir/file1", "one", time.Now())
+	r.WriteFile("dir/file2", "two", time.Now())
+	r.WriteFile("dir/sub/file3", "three", time.Now())
+	f := r.Flocal.(*Fs)
+
+	var remotes []string
+	err := f.ListP(ctx, "dir", func(entries fs.DirEntries) error {
+		for _, entry := range entries {
+			remotes = append(remotes, entry.Remote())
+		}
+		return nil
+	})
+	require.NoError(t, err)
+	sort.Strings(remotes)
+	assert.Equal(t, []string{"dir/file1", "dir/file2", "dir/sub"}, remotes)
+
+	// Errors from the callback stop the listing
+	errPotato := errors.New("potato")
+	err = f.ListP(ctx, "dir", func(entries fs.DirEntries) error {
+		return errPotato
+	})
+	assert.Equal(t, errPotato, err)
+
+	err = f.ListP(ctx, "notfound", func(entries fs.DirEntries) error {
+		return nil
+	})
+	assert.Equal(t, fs.ErrorDirNotFound, err)
+}
+
+// Test only the hashes asked for are calculated
+func TestHashSet(t *testing.T) {
+	r := fstest.NewRun(t)
+	defer r.Finalise()
+	const filePath = "hashes.txt"
+	r.WriteFile(filePath, "hello", time.Now())
+	f := r.Flocal.(*Fs)
+	obj, err := f.NewObject(context.Background(), filePath)
+	require.NoError(t, err)
+	o := obj.(*Object)
+
+	// Reading calculates the default hashes only
+	in, err := o.Open(context.Background())
+	require.NoError(t, err)
+	buf := make([]byte, 16)
+	_, err = in.Read(buf)
+	require.NoError(t, err)
+	require.NoError(t, in.Close())
+	assert.Equal(t, defaultHashes, hashSetOf(o.hashes))
+
+	// Asking for more calculates only the missing ones
+	want := hash.NewHashSet(hash.MD5, hash.SHA256, hash.XXH64)
+	hashes, err := o.HashSet(want)
+	require.NoError(t, err)
+	assert.Equal(t, want, hashSetOf(hashes))
+	assert.Equal(t, "5d41402abc4b2a76b9719d911017c592", hashes[hash.MD5])
+	assert.Equal(t, "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824", hashes[hash.SHA256])
+	assert.Equal(t, defaultHashes|want, hashSetOf(o.hashes))
+}
+
+// hashSetOf returns the types of the hashes
+func hashSetOf(hashes map[hash.Type]string) hash.Set {
+	set := hash.Set(hash.None)
+	for hashType := range hashes {
+		set.Add(hashType)
+	}
+	return set
+}
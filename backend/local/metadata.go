@@ -0,0 +1,91 @@
+// Metadata reading and writing functions common to all OSes
+
+package local
+
+import (
+	"context"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/ncw/rclone/fs"
+	"github.com/pkg/errors"
+)
+
+// Keys used for metadata which isn't stored in extended attributes
+var systemMetadata = map[string]struct{}{
+	"mode":  {},
+	"uid":   {},
+	"gid":   {},
+	"atime": {},
+	"mtime": {},
+}
+
+// Metadata returns metadata for an object
+//
+// It should return nil if there is no Metadata
+func (o *Object) Metadata(ctx context.Context) (fs.Metadata, error) {
+	info, err := o.fs.lstat(o.path)
+	if err != nil {
+		return nil, err
+	}
+	m := make(fs.Metadata, len(systemMetadata))
+	m.Set("mtime", info.ModTime().Format(time.RFC3339Nano))
+	readStat(info, m)
+	err = o.readXattrs(m)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read extended attributes")
+	}
+	return m, nil
+}
+
+// writeMetadata sets the metadata passed in on the object, ignoring
+// any keys which can't be parsed.
+//
+// This should be called after the modification time has been set.
+func (o *Object) writeMetadata(m fs.Metadata) (err error) {
+	if len(m) == 0 {
+		return nil
+	}
+	if value, ok := m["mode"]; ok {
+		mode, err := strconv.ParseUint(value, 8, 32)
+		if err != nil {
+			fs.Debugf(o, "Ignoring invalid mode %q in metadata: %v", value, err)
+		} else if err = setMode(o.path, uint32(mode)); err != nil {
+			return errors.Wrap(err, "failed to set mode")
+		}
+	}
+	uid, gid := -1, -1
+	if value, ok := m["uid"]; ok {
+		if uid, err = strconv.Atoi(value); err != nil {
+			fs.Debugf(o, "Ignoring invalid uid %q in metadata: %v", value, err)
+			uid = -1
+		}
+	}
+	if value, ok := m["gid"]; ok {
+		if gid, err = strconv.Atoi(value); err != nil {
+			fs.Debugf(o, "Ignoring invalid gid %q in metadata: %v", value, err)
+			gid = -1
+		}
+	}
+	if uid >= 0 || gid >= 0 {
+		// Only root can usually change the owner so don't fail
+		// the transfer if it can't be done
+		if err = setOwner(o.path, uid, gid); err != nil {
+			fs.Debugf(o, "Failed to set owner: %v", err)
+		}
+	}
+	if value, ok := m["atime"]; ok {
+		atime, err := time.Parse(time.RFC3339Nano, value)
+		if err != nil {
+			fs.Debugf(o, "Ignoring invalid atime %q in metadata: %v", value, err)
+		} else if err = os.Chtimes(o.path, atime, o.modTime); err != nil {
+			return errors.Wrap(err, "failed to set atime")
+		}
+	}
+	err = o.writeXattrs(m)
+	if err != nil {
+		return errors.Wrap(err, "failed to write extended attributes")
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+// Metadata reading and writing functions for Linux
+
+// +build linux
+
+package local
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/ncw/rclone/fs"
+	"golang.org/x/sys/unix"
+)
+
+// Prefix of the extended attributes rclone reads and writes
+const xattrUserPrefix = "user."
+
+// readStat reads the mode, owner and access time from info into m
+func readStat(info os.FileInfo, m fs.Metadata) {
+	statT, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		m.Set("mode", strconv.FormatUint(uint64(info.Mode().Perm()), 8))
+		return
+	}
+	m.Set("mode", strconv.FormatUint(uint64(statT.Mode&07777), 8))
+	m.Set("uid", strconv.FormatUint(uint64(statT.Uid), 10))
+	m.Set("gid", strconv.FormatUint(uint64(statT.Gid), 10))
+	m.Set("atime", time.Unix(statT.Atim.Unix()).Format(time.RFC3339Nano))
+}
+
+// setMode sets the permission bits of the file at path
+func setMode(path string, mode uint32) error {
+	return syscall.Chmod(path, mode&07777)
+}
+
+// setOwner sets the owner of the file at path - -1 means don't change
+func setOwner(path string, uid, gid int) error {
+	return os.Lchown(path, uid, gid)
+}
+
+// xattrUnsupported returns whether err means that the filesystem
+// doesn't support extended attributes
+func xattrUnsupported(err error) bool {
+	return err == unix.ENOTSUP || err == unix.EOPNOTSUPP
+}
+
+// readXattrs reads the user extended attributes into m without
+// their "user." prefix
+func (o *Object) readXattrs(m fs.Metadata) error {
+	size, err := unix.Listxattr(o.path, nil)
+	if err != nil {
+		if xattrUnsupported(err) {
+			return nil
+		}
+		return err
+	}
+	if size == 0 {
+		return nil
+	}
+	buf := make([]byte, size)
+	size, err = unix.Listxattr(o.path, buf)
+	if err != nil {
+		return err
+	}
+	for _, name := range strings.Split(string(buf[:size]), "\x00") {
+		if !strings.HasPrefix(name, xattrUserPrefix) {
+			continue
+		}
+		size, err := unix.Getxattr(o.path, name, nil)
+		if err != nil {
+			return err
+		}
+		value := make([]byte, size)
+		if size > 0 {
+			size, err = unix.Getxattr(o.path, name, value)
+			if err != nil {
+				return err
+			}
+		}
+		m.Set(strings.ToLower(name[len(xattrUserPrefix):]), string(value[:size]))
+	}
+	return nil
+}
+
+// writeXattrs writes any keys in m which aren't system metadata as
+// user extended attributes
+func (o *Object) writeXattrs(m fs.Metadata) error {
+	for k, v := range m {
+		if _, isSystem := systemMetadata[k]; isSystem {
+			continue
+		}
+		err := unix.Setxattr(o.path, xattrUserPrefix+k, []byte(v), 0)
+		if err != nil {
+			if xattrUnsupported(err) {
+				fs.Debugf(o, "Extended attributes not supported - ignoring metadata")
+				return nil
+			}
+			return err
+		}
+	}
+	return nil
+}
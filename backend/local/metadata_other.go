@@ -0,0 +1,40 @@
+// Metadata reading and writing functions for OSes other than Linux
+
+// +build !linux
+
+package local
+
+import (
+	"os"
+	"strconv"
+
+	"github.com/ncw/rclone/fs"
+)
+
+// readStat reads the mode from info into m
+func readStat(info os.FileInfo, m fs.Metadata) {
+	m.Set("mode", strconv.FormatUint(uint64(info.Mode().Perm()), 8))
+}
+
+// setMode sets the permission bits of the file at path
+func setMode(path string, mode uint32) error {
+	return os.Chmod(path, os.FileMode(mode).Perm())
+}
+
+// setOwner sets the owner of the file at path - this isn't
+// supported on this OS
+func setOwner(path string, uid, gid int) error {
+	return nil
+}
+
+// readXattrs reads the extended attributes into m - this isn't
+// supported on this OS
+func (o *Object) readXattrs(m fs.Metadata) error {
+	return nil
+}
+
+// writeXattrs writes the extended attributes from m - this isn't
+// supported on this OS
+func (o *Object) writeXattrs(m fs.Metadata) error {
+	return nil
+}
@@ -0,0 +1,104 @@
+// Package quickxorhash implements the QuickXorHash used by OneDrive
+// for Business as described in
+//
+// https://docs.microsoft.com/en-us/onedrive/developer/code-snippets/quickxorhash
+//
+// The hash is a 160 bit circular bit field.  Each byte of input is
+// XORed into the field at a position which advances by 11 bits for
+// every byte, then the length of the input is XORed into the last 64
+// bits of the result.
+package quickxorhash
+
+import (
+	"encoding/binary"
+	"hash"
+)
+
+const (
+	// BlockSize of the checksum in bytes.
+	BlockSize = 64
+	// Size of the checksum in bytes.
+	Size        = 20
+	widthInBits = 8 * Size
+	shift       = 11
+)
+
+type digest struct {
+	data   [Size]byte // the 160 bit circular field
+	offset int        // bit offset into data of the next byte
+	length uint64     // total bytes written
+}
+
+// New returns a new hash.Hash computing the QuickXorHash checksum.
+func New() hash.Hash {
+	return &digest{}
+}
+
+// Write writes len(p) bytes from p to the underlying data stream. It returns
+// the number of bytes written from p (0 <= n <= len(p)) and any error
+// encountered that caused the write to stop early. Write must return a non-nil
+// error if it returns n < len(p). Write must not modify the slice data, even
+// temporarily.
+//
+// Implementations must not retain p.
+func (d *digest) Write(p []byte) (n int, err error) {
+	offset := d.offset
+	for _, b := range p {
+		// XOR the byte in at the bit offset - as the width is a
+		// multiple of 8 bits this spans at most two bytes, the
+		// second of which may wrap around to the start
+		i := offset >> 3
+		v := uint16(b) << uint(offset&7)
+		d.data[i] ^= byte(v)
+		d.data[(i+1)%Size] ^= byte(v >> 8)
+		offset += shift
+		if offset >= widthInBits {
+			offset -= widthInBits
+		}
+	}
+	d.offset = offset
+	d.length += uint64(len(p))
+	return len(p), nil
+}
+
+// Sum appends the current hash to b and returns the resulting slice.
+// It does not change the underlying hash state.
+func (d *digest) Sum(b []byte) []byte {
+	out := d.data
+	var length [8]byte
+	binary.LittleEndian.PutUint64(length[:], d.length)
+	for i, x := range length {
+		out[Size-len(length)+i] ^= x
+	}
+	return append(b, out[:]...)
+}
+
+// Reset resets the Hash to its initial state.
+func (d *digest) Reset() {
+	*d = digest{}
+}
+
+// Size returns the number of bytes Sum will return.
+func (d *digest) Size() int {
+	return Size
+}
+
+// BlockSize returns the hash's underlying block size.
+// The Write method must be able to accept any amount
+// of data, but it may operate more efficiently if all writes
+// are a multiple of the block size.
+func (d *digest) BlockSize() int {
+	return BlockSize
+}
+
+// Sum returns the QuickXorHash checksum of the data.
+func Sum(data []byte) [Size]byte {
+	var d digest
+	_, _ = d.Write(data)
+	var out [Size]byte
+	d.Sum(out[:0])
+	return out
+}
+
+// must implement this interface
+var _ hash.Hash = (*digest)(nil)
@@ -0,0 +1,54 @@
+package quickxorhash_test
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/ncw/rclone/backend/onedrive/quickxorhash"
+	"github.com/stretchr/testify/assert"
+)
+
+var testVectors = []struct {
+	in   string
+	want string
+}{
+	{"", "AAAAAAAAAAAAAAAAAAAAAAAAAAA="},
+	{"J", "SgAAAAAAAAAAAAAAAQAAAAAAAAA="},
+	{"The quick brown fox jumps over the lazy dog", "bMSlbysmxJL6S75XwfMcQZOpcr4="},
+}
+
+func TestSum(t *testing.T) {
+	for _, test := range testVectors {
+		got := quickxorhash.Sum([]byte(test.in))
+		assert.Equal(t, test.want, base64.StdEncoding.EncodeToString(got[:]), fmt.Sprintf("input %q", test.in))
+	}
+}
+
+// Check that splitting the input into chunks doesn't change the result
+func TestChunks(t *testing.T) {
+	data := []byte(strings.Repeat("Hello, world! ", 1000))
+	want := quickxorhash.Sum(data)
+	for _, chunk := range []int{1, 3, 19, 20, 64, 159, 160, 161, 4096} {
+		d := quickxorhash.New()
+		for p := data; len(p) > 0; {
+			n := chunk
+			if n > len(p) {
+				n = len(p)
+			}
+			written, err := d.Write(p[:n])
+			assert.NoError(t, err)
+			assert.Equal(t, n, written)
+			p = p[n:]
+		}
+		assert.Equal(t, want[:], d.Sum(nil), fmt.Sprintf("chunk size %d", chunk))
+	}
+}
+
+func TestReset(t *testing.T) {
+	d := quickxorhash.New()
+	_, _ = d.Write([]byte("potato"))
+	d.Reset()
+	assert.Equal(t, make([]byte, quickxorhash.Size), d.Sum(nil))
+}
@@ -151,3 +151,16 @@ type ChecksumFileResult struct {
 	Hashes
 	Metadata Item `json:"metadata"`
 }
+
+// UserInfo is returned from /userinfo
+type UserInfo struct {
+	Error
+	Email      string `json:"email"`
+	UserID     int    `json:"userid"`
+	Quota      int64  `json:"quota"`
+	UsedQuota  int64  `json:"usedquota"`
+	Premium    bool   `json:"premium"`
+	Business   bool   `json:"business"`
+	Language   string `json:"language"`
+	Registered Time   `json:"registered"`
+}
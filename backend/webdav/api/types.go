@@ -94,6 +94,28 @@ type PropValue struct {
 	Value   string   `xml:",chardata"`
 }
 
+// Quota is used to read the bytes used and available
+//
+// <d:multistatus xmlns:d="DAV:" xmlns:s="http://sabredav.org/ns">
+//   <d:response>
+//     <d:href>/remote.php/webdav/</d:href>
+//     <d:propstat>
+//       <d:prop>
+//         <d:quota-available-bytes>-3</d:quota-available-bytes>
+//         <d:quota-used-bytes>376461895</d:quota-used-bytes>
+//       </d:prop>
+//       <d:status>HTTP/1.1 200 OK</d:status>
+//     </d:propstat>
+//   </d:response>
+// </d:multistatus>
+//
+// A negative quota-available-bytes means the value isn't known or
+// there is no limit.
+type Quota struct {
+	Available int64 `xml:"DAV: response>propstat>prop>quota-available-bytes"`
+	Used      int64 `xml:"DAV: response>propstat>prop>quota-used-bytes"`
+}
+
 // Error is used to desribe webdav errors
 //
 // <d:error xmlns:d="DAV:" xmlns:s="http://sabredav.org/ns">
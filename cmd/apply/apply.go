@@ -0,0 +1,67 @@
+package apply
+
+import (
+	"context"
+
+	"github.com/ncw/rclone/cmd"
+	"github.com/ncw/rclone/fs"
+	"github.com/ncw/rclone/fs/sync"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	cmd.Root.AddCommand(commandDefintion)
+}
+
+var commandDefintion = &cobra.Command{
+	Use:   "apply plan.json",
+	Short: `Do the actions in a plan made with --plan-out.`,
+	Long: `
+Do the actions in a plan written by ` + "`rclone sync`" + `, ` + "`copy`" + ` or
+` + "`move`" + ` with the ` + "`--plan-out`" + ` flag.
+
+This lets you review exactly what a sync will do, for instance before
+running a sync which deletes files from a production bucket.
+
+    rclone sync --plan-out plan.json /path/to/src remote:dst
+    # review plan.json
+    rclone apply plan.json
+
+The plan records the source and destination and, for every file it
+acts on, the size, modification time and hash (if the source and
+destination have one in common) the file had when the plan was made.
+
+Before doing anything ` + "`apply`" + ` checks every file is still as
+recorded and that files to be created don't exist yet.  If any aren't
+then it does nothing and exits with an error, so make a new plan.
+
+The actions are done in this order: make directories, renames,
+transfers, deletes then remove empty directories.  If there are errors
+in the transfers then the deletes aren't done unless
+` + "`--ignore-errors`" + ` is set.  The actions are not retried.
+
+Use ` + "`--dry-run`" + ` to check the plan is still valid without
+doing anything.
+`,
+	Run: func(command *cobra.Command, args []string) {
+		cmd.CheckArgs(1, 1, command, args)
+		cmd.Run(false, true, command, func() error {
+			plan, err := sync.LoadPlan(args[0])
+			if err != nil {
+				return err
+			}
+			fsrc, err := fs.NewFs(plan.Src)
+			if err != nil {
+				return errors.Wrapf(err, "failed to make source %q", plan.Src)
+			}
+			fdst, err := fs.NewFs(plan.Dst)
+			if err != nil {
+				return errors.Wrapf(err, "failed to make destination %q", plan.Dst)
+			}
+			fs.CalculateModifyWindow(fdst, fsrc)
+			fs.Infof(fdst, "Applying %s plan from %v made at %v with %d actions", plan.Mode, fsrc, plan.Created.Format("2006/01/02 15:04:05"), len(plan.Actions))
+			return sync.ApplyPlan(context.Background(), fdst, fsrc, plan)
+		})
+	},
+}
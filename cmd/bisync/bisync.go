@@ -0,0 +1,79 @@
+package bisync
+
+import (
+	"context"
+
+	"github.com/ncw/rclone/cmd"
+	"github.com/ncw/rclone/fs/bisync"
+	"github.com/spf13/cobra"
+)
+
+var (
+	opt = bisync.DefaultOptions()
+)
+
+func init() {
+	cmd.Root.AddCommand(commandDefintion)
+	flags := commandDefintion.Flags()
+	flags.BoolVarP(&opt.Resync, "resync", "", opt.Resync, "Ignore the previous run and make both paths contain all the files, path1 winning.")
+	flags.VarP(&opt.ConflictResolve, "conflict-resolve", "", "How to resolve files changed on both paths rename|newer|path1.")
+	flags.IntVarP(&opt.MaxDeletePercent, "max-delete-percent", "", opt.MaxDeletePercent, "Refuse to run if more than this percentage of files are deleted on either path.")
+	flags.BoolVarP(&opt.Force, "force", "", opt.Force, "Run even if --max-delete-percent is exceeded.")
+	flags.StringVarP(&opt.Workdir, "workdir", "", opt.Workdir, "Directory to keep the listings in (default cache-dir/bisync).")
+}
+
+var commandDefintion = &cobra.Command{
+	Use:   "bisync path1:path path2:path",
+	Short: `Bidirectional synchronisation between two paths.`,
+	Long: `
+Bisync makes two paths contain the same files by copying new and
+changed files and deleting deleted files in both directions.
+
+Bisync keeps a listing of each path from the last successful run in
+the cache directory (see ` + "`--workdir`" + `).  Each path is compared
+with its listing to see which files are new, changed (by size or
+modification time) or deleted since the last run and those changes
+are made on the other path.
+
+The first time bisync is run on a pair of paths there are no listings
+so you must use the ` + "`--resync`" + ` flag.  This copies files found
+on only one path to the other and, where a file differs between the
+paths, copies the path1 version over the path2 one.  Nothing is
+deleted.  Use ` + "`--resync`" + ` again if the listings are lost or
+get out of step with the paths.
+
+    rclone bisync --resync /path/to/local remote:path
+    rclone bisync /path/to/local remote:path
+
+If a file is changed on one path and deleted on the other, the changed
+file is kept and copied back.
+
+If a file is new or changed on both paths and the two copies differ
+then this is a conflict which is resolved according to
+` + "`--conflict-resolve`" + `
+
+  * ` + "`rename`" + ` - (default) keep both copies, renaming them with
+    the suffixes ` + "`..path1`" + ` and ` + "`..path2`" + ` on both paths
+  * ` + "`newer`" + ` - the copy with the newest modification time wins
+  * ` + "`path1`" + ` - the copy on path1 wins
+
+The number of conflicts found is reported at the end of the run.
+
+To guard against an accidentally emptied path bisync refuses to run if
+more than ` + "`--max-delete-percent`" + ` (default 50) of the files on
+either path have been deleted since the last run.  Use ` + "`--force`" + `
+to run anyway.
+
+Bisync only synchronises files, not empty directories.  Use
+` + "`--dry-run`" + ` to see what would be done - the listings aren't
+updated on a dry run.  The listings aren't updated either if there
+were any errors so the changes will be found again on the next run.
+`,
+	Run: func(command *cobra.Command, args []string) {
+		cmd.CheckArgs(2, 2, command, args)
+		fs1, fs2 := cmd.NewFsSrcDst(args)
+		cmd.Run(false, true, command, func() error {
+			return bisync.Bisync(context.Background(), fs1, fs2, opt)
+		})
+	},
+}
@@ -1,19 +1,136 @@
 package check
 
 import (
+	"context"
+	"io"
+	"os"
+
 	"github.com/ncw/rclone/cmd"
+	"github.com/ncw/rclone/fs"
 	"github.com/ncw/rclone/fs/operations"
+	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 )
 
 // Globals
 var (
-	download = false
+	download     = false
+	combined     = ""
+	missingOnSrc = ""
+	missingOnDst = ""
+	match        = ""
+	differ       = ""
+	errFile      = ""
+	jsonFile     = ""
 )
 
 func init() {
 	cmd.Root.AddCommand(commandDefintion)
 	commandDefintion.Flags().BoolVarP(&download, "download", "", download, "Check by downloading rather than with hash.")
+	AddFlags(commandDefintion.Flags())
+}
+
+// AddFlags adds the flags for the reports to the flag set
+func AddFlags(flags *pflag.FlagSet) {
+	flags.StringVarP(&combined, "combined", "", combined, "Make a combined report of changes to this file")
+	flags.StringVarP(&missingOnSrc, "missing-on-src", "", missingOnSrc, "Report all files missing from the source to this file")
+	flags.StringVarP(&missingOnDst, "missing-on-dst", "", missingOnDst, "Report all files missing from the destination to this file")
+	flags.StringVarP(&match, "match", "", match, "Report all matching files to this file")
+	flags.StringVarP(&differ, "differ", "", differ, "Report all non-matching files to this file")
+	flags.StringVarP(&errFile, "error", "", errFile, "Report all files with errors (hashing or reading) to this file")
+	flags.StringVarP(&jsonFile, "json", "", jsonFile, "Report all files and their status as JSON to this file")
+}
+
+// ReportHelp describes the reports for the commands using AddFlags
+var ReportHelp = `
+The following flags write reports of the files checked to the file
+named, which can be "-" to write to standard output.  The reports list
+one path per line and are written as the check progresses.
+
+  * ` + "`--match`" + ` - the identical files
+  * ` + "`--differ`" + ` - the files which differ
+  * ` + "`--missing-on-src`" + ` - the files only in the destination
+  * ` + "`--missing-on-dst`" + ` - the files only in the source
+  * ` + "`--error`" + ` - the files which couldn't be checked because of an error
+  * ` + "`--combined`" + ` - all the files, each with a leading status character and a space
+
+The status characters in the combined report are
+
+  * ` + "`=`" + ` - the file is identical in the source and destination
+  * ` + "`-`" + ` - the file is missing from the source (only in the destination)
+  * ` + "`+`" + ` - the file is missing from the destination (only in the source)
+  * ` + "`*`" + ` - the file is in both the source and destination but differs
+  * ` + "`!`" + ` - there was an error reading or hashing the file
+
+` + "`--json`" + ` writes all the files as a JSON array with one object
+per line like this
+
+    [
+    {"Path":"file.txt","Status":"match"},
+    {"Path":"dir/other.txt","Status":"error","Error":"failed to open..."}
+    ]
+
+where Status is one of "match", "differ", "missing-on-src",
+"missing-on-dst" or "error".  Error is only present for errors.
+`
+
+// GetCheckOpt makes a CheckOpt for fsrc and fdst opening the report
+// files given on the command line.
+//
+// The returned close function must be called when the check is
+// finished to close the reports.
+func GetCheckOpt(fsrc, fdst fs.Fs) (opt *operations.CheckOpt, close func(), err error) {
+	opt = &operations.CheckOpt{
+		Fdst: fdst,
+		Fsrc: fsrc,
+	}
+	var closers []io.Closer
+	close = func() {
+		for _, closer := range closers {
+			err := closer.Close()
+			if err != nil {
+				fs.Errorf(nil, "Failed to close report: %v", err)
+			}
+		}
+	}
+
+	open := func(name string, pout *io.Writer) error {
+		if name == "" {
+			return nil
+		}
+		if name == "-" {
+			*pout = os.Stdout
+			return nil
+		}
+		out, err := os.Create(name)
+		if err != nil {
+			return errors.Wrapf(err, "failed to open report %q", name)
+		}
+		*pout = out
+		closers = append(closers, out)
+		return nil
+	}
+
+	for _, report := range []struct {
+		name string
+		pout *io.Writer
+	}{
+		{combined, &opt.Combined},
+		{missingOnSrc, &opt.MissingOnSrc},
+		{missingOnDst, &opt.MissingOnDst},
+		{match, &opt.Match},
+		{differ, &opt.Differ},
+		{errFile, &opt.Error},
+		{jsonFile, &opt.JSON},
+	} {
+		err = open(report.name, report.pout)
+		if err != nil {
+			close()
+			return nil, nil, err
+		}
+	}
+	return opt, close, nil
 }
 
 var commandDefintion = &cobra.Command{
@@ -31,15 +148,20 @@ If you supply the --download flag, it will download the data from
 both remotes and check them against each other on the fly.  This can
 be useful for remotes that don't support hashes or if you really want
 to check all the data.
-`,
+` + ReportHelp,
 	Run: func(command *cobra.Command, args []string) {
 		cmd.CheckArgs(2, 2, command, args)
 		fsrc, fdst := cmd.NewFsSrcDst(args)
 		cmd.Run(false, false, command, func() error {
+			opt, close, err := GetCheckOpt(fsrc, fdst)
+			if err != nil {
+				return err
+			}
+			defer close()
 			if download {
-				return operations.CheckDownload(fdst, fsrc)
+				return operations.CheckDownload(context.Background(), opt)
 			}
-			return operations.Check(fdst, fsrc)
+			return operations.Check(context.Background(), opt)
 		})
 	},
 }
@@ -7,6 +7,7 @@ package cmd
 // would probably mean bringing all the flags in to here? Or define some flagsets in fs...
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"os"
@@ -16,6 +17,7 @@ import (
 	"runtime"
 	"runtime/pprof"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/pkg/errors"
@@ -33,6 +35,7 @@ import (
 	fslog "github.com/ncw/rclone/fs/log"
 	"github.com/ncw/rclone/fs/rc"
 	"github.com/ncw/rclone/fs/rc/rcflags"
+	"github.com/ncw/rclone/fs/sync"
 	"github.com/ncw/rclone/lib/atexit"
 )
 
@@ -43,6 +46,8 @@ var (
 	memProfile    = flags.StringP("memprofile", "", "", "Write memory profile to file")
 	statsInterval = flags.DurationP("stats", "", time.Minute*1, "Interval between printing stats, e.g 500ms, 60s, 5m. (0 to disable)")
 	dataRateUnit  = flags.StringP("stats-unit", "", "bytes", "Show data rate in stats as either 'bits' or 'bytes'/s")
+	statsJSON     = flags.StringP("stats-json", "", "", "Append stats as a line of JSON to this file every --stats interval, - for stdout")
+	progress      = flags.BoolP("progress", "P", false, "Show progress during transfer.")
 	version       bool
 	retries       = flags.IntP("retries", "", 3, "Retry operations this many times if they fail")
 	// Errors
@@ -62,6 +67,7 @@ const (
 	exitCodeRetryError
 	exitCodeNoRetryError
 	exitCodeFatalError
+	exitCodeTransferExceeded
 )
 
 // Root is the main rclone command
@@ -147,12 +153,12 @@ func ShowVersion() {
 //
 // It returns a string with the file name if points to a file
 func NewFsFile(remote string) (fs.Fs, string) {
-	fsInfo, configName, fsPath, err := fs.ParseRemote(remote)
+	fsInfo, configName, fsPath, config, err := fs.ConfigFs(remote)
 	if err != nil {
 		fs.CountError(err)
 		log.Fatalf("Failed to create file system for %q: %v", remote, err)
 	}
-	f, err := fsInfo.NewFs(configName, fsPath)
+	f, err := fsInfo.NewFs(configName, fsPath, config)
 	switch err {
 	case fs.ErrorIsFile:
 		return f, path.Base(fsPath)
@@ -208,6 +214,24 @@ func NewFsSrcDst(args []string) (fs.Fs, fs.Fs) {
 	return fsrc, fdst
 }
 
+// NewFsSrcDstPlan creates a new src and dst fs from the arguments as
+// NewFsSrcDst does and returns a context which records the arguments
+// in any plan made with --plan-out
+func NewFsSrcDstPlan(args []string) (context.Context, fs.Fs, fs.Fs) {
+	fsrc, srcFileName := newFsSrc(args[0])
+	fdst := newFsDst(args[1])
+	fs.CalculateModifyWindow(fdst, fsrc)
+	srcRemote := args[0]
+	if srcFileName != "" {
+		// Record the directory the file is in
+		srcRemote = strings.TrimSuffix(strings.TrimSuffix(srcRemote, "/"), srcFileName)
+		if srcRemote == "" {
+			srcRemote = "."
+		}
+	}
+	return sync.WithPlanRemotes(context.Background(), srcRemote, args[1]), fsrc, fdst
+}
+
 // NewFsSrcDstFiles creates a new src and dst fs from the arguments
 // If src is a file then srcFileName and dstFileName will be non-empty
 func NewFsSrcDstFiles(args []string) (fsrc fs.Fs, srcFileName string, fdst fs.Fs, dstFileName string) {
@@ -269,24 +293,30 @@ func NewFsDstFile(args []string) (fdst fs.Fs, dstFileName string) {
 	return
 }
 
-// ShowStats returns true if the user added a `--stats` flag to the command line.
+// ShowStats returns true if the user added a `--stats` or
+// `--stats-json` flag to the command line.
 //
 // This is called by Run to override the default value of the
 // showStats passed in.
 func ShowStats() bool {
 	statsIntervalFlag := pflag.Lookup("stats")
-	return statsIntervalFlag != nil && statsIntervalFlag.Changed
+	return (statsIntervalFlag != nil && statsIntervalFlag.Changed) || *statsJSON != ""
 }
 
 // Run the function with stats and retries if required
 func Run(Retry bool, showStats bool, cmd *cobra.Command, f func() error) {
 	var err error
 	var stopStats chan struct{}
+	var stopProgress func()
 	if !showStats && ShowStats() {
 		showStats = true
 	}
+	if *progress && progressSupported() {
+		showStats = true
+		stopProgress = startProgress(progressInterval())
+	}
 	if showStats {
-		stopStats = StartStats()
+		stopStats = startStats(stopProgress == nil)
 	}
 	for try := 1; try <= *retries; try++ {
 		err = f()
@@ -315,12 +345,16 @@ func Run(Retry bool, showStats bool, cmd *cobra.Command, f func() error) {
 	}
 	if showStats {
 		close(stopStats)
+		writeStatsJSON()
+	}
+	if stopProgress != nil {
+		stopProgress()
 	}
 	if err != nil {
 		log.Printf("Failed to %s: %v", cmd.Name(), err)
 		resolveExitCode(err)
 	}
-	if showStats && (accounting.Stats.Errored() || *statsInterval > 0) {
+	if showStats && stopProgress == nil && (accounting.Stats.Errored() || *statsInterval > 0) {
 		accounting.Stats.Log()
 	}
 	fs.Debugf(nil, "%d go routines active\n", runtime.NumGoroutine())
@@ -368,6 +402,14 @@ func CheckArgs(MinArgs, MaxArgs int, cmd *cobra.Command, args []string) {
 //
 // It returns a channel which should be closed to stop the stats.
 func StartStats() chan struct{} {
+	return startStats(true)
+}
+
+// startStats writes the --stats-json file every statsInterval and
+// logs the stats too if logStats is set
+//
+// It returns a channel which should be closed to stop the stats.
+func startStats(logStats bool) chan struct{} {
 	stopStats := make(chan struct{})
 	if *statsInterval > 0 {
 		go func() {
@@ -375,7 +417,10 @@ func StartStats() chan struct{} {
 			for {
 				select {
 				case <-ticker.C:
-					accounting.Stats.Log()
+					if logStats {
+						accounting.Stats.Log()
+					}
+					writeStatsJSON()
 				case <-stopStats:
 					ticker.Stop()
 					return
@@ -386,6 +431,44 @@ func StartStats() chan struct{} {
 	return stopStats
 }
 
+// progressInterval returns how often --progress should redraw, which
+// is the --stats interval if set
+func progressInterval() time.Duration {
+	statsIntervalFlag := pflag.Lookup("stats")
+	if statsIntervalFlag != nil && statsIntervalFlag.Changed {
+		return *statsInterval
+	}
+	return defaultProgressInterval
+}
+
+// writeStatsJSON appends the stats as a line of JSON to the
+// --stats-json file if set
+func writeStatsJSON() {
+	if *statsJSON == "" {
+		return
+	}
+	if *statsJSON == "-" {
+		err := accounting.Stats.WriteJSON(os.Stdout)
+		if err != nil {
+			fs.Errorf(nil, "Failed to write stats: %v", err)
+		}
+		return
+	}
+	out, err := os.OpenFile(*statsJSON, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0666)
+	if err != nil {
+		fs.Errorf(nil, "Failed to open --stats-json file: %v", err)
+		return
+	}
+	err = accounting.Stats.WriteJSON(out)
+	closeErr := out.Close()
+	if err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		fs.Errorf(nil, "Failed to write stats to %q: %v", *statsJSON, err)
+	}
+}
+
 // initConfig is run by cobra after initialising the flags
 func initConfig() {
 	// Start the logger
@@ -460,6 +543,10 @@ func resolveExitCode(err error) {
 		os.Exit(exitCodeSuccess)
 	}
 
+	if accounting.IsCutoffError(err) {
+		os.Exit(exitCodeTransferExceeded)
+	}
+
 	err = errors.Cause(err)
 
 	switch {
@@ -479,3 +566,42 @@ func resolveExitCode(err error) {
 		os.Exit(exitCodeUsageError)
 	}
 }
+
+// AddBackendFlags creates flags for all the backend options
+func AddBackendFlags() {
+	for _, fsInfo := range fs.Registry {
+		done := map[string]struct{}{}
+		for i := range fsInfo.Options {
+			opt := &fsInfo.Options[i]
+			// Skip if done already (eg with Provider options)
+			if _, doneAlready := done[opt.Name]; doneAlready {
+				continue
+			}
+			done[opt.Name] = struct{}{}
+			// Make a flag from each option
+			name := opt.FlagName(fsInfo.Prefix)
+			if pflag.CommandLine.Lookup(name) != nil {
+				fs.Errorf(nil, "Not adding duplicate flag --%s", name)
+				continue
+			}
+			// Take first line of help only
+			help := strings.TrimSpace(opt.Help)
+			if nl := strings.IndexRune(help, '\n'); nl >= 0 {
+				help = help[:nl]
+			}
+			help = strings.TrimSpace(help)
+			flag := pflag.CommandLine.VarPF(opt, name, opt.ShortOpt, help)
+			if _, isBool := opt.Default.(bool); isBool {
+				flag.NoOptDefVal = "true"
+			}
+		}
+	}
+}
+
+// Main runs rclone interpreting flags and commands out of os.Args
+func Main() {
+	AddBackendFlags()
+	if err := Root.Execute(); err != nil {
+		log.Fatalf("Fatal error: %v", err)
+	}
+}
@@ -52,9 +52,9 @@ source or destination.
 `,
 	Run: func(command *cobra.Command, args []string) {
 		cmd.CheckArgs(2, 2, command, args)
-		fsrc, fdst := cmd.NewFsSrcDst(args)
+		ctx, fsrc, fdst := cmd.NewFsSrcDstPlan(args)
 		cmd.Run(true, true, command, func() error {
-			return sync.CopyDir(fdst, fsrc)
+			return sync.CopyDir(ctx, fdst, fsrc)
 		})
 	},
 }
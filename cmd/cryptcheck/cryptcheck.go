@@ -1,8 +1,10 @@
 package cryptcheck
 
 import (
+	"context"
 	"github.com/ncw/rclone/backend/crypt"
 	"github.com/ncw/rclone/cmd"
+	"github.com/ncw/rclone/cmd/check"
 	"github.com/ncw/rclone/fs"
 	"github.com/ncw/rclone/fs/hash"
 	"github.com/ncw/rclone/fs/operations"
@@ -12,6 +14,7 @@ import (
 
 func init() {
 	cmd.Root.AddCommand(commandDefintion)
+	check.AddFlags(commandDefintion.Flags())
 }
 
 var commandDefintion = &cobra.Command{
@@ -40,18 +43,24 @@ the files in remote:path.
     rclone cryptcheck remote:path encryptedremote:path
 
 After it has run it will log the status of the encryptedremote:.
-`,
+` + check.ReportHelp,
 	Run: func(command *cobra.Command, args []string) {
 		cmd.CheckArgs(2, 2, command, args)
 		fsrc, fdst := cmd.NewFsSrcDst(args)
 		cmd.Run(false, true, command, func() error {
-			return cryptCheck(fdst, fsrc)
+			opt, close, err := check.GetCheckOpt(fsrc, fdst)
+			if err != nil {
+				return err
+			}
+			defer close()
+			return cryptCheck(context.Background(), opt)
 		})
 	},
 }
 
 // cryptCheck checks the integrity of a crypted remote
-func cryptCheck(fdst, fsrc fs.Fs) error {
+func cryptCheck(ctx context.Context, opt *operations.CheckOpt) error {
+	fdst, fsrc := opt.Fdst, opt.Fsrc
 	// Check to see fcrypt is a crypt
 	fcrypt, ok := fdst.(*crypt.Fs)
 	if !ok {
@@ -69,36 +78,38 @@ func cryptCheck(fdst, fsrc fs.Fs) error {
 	//
 	// it returns true if differences were found
 	// it also returns whether it couldn't be hashed
-	checkIdentical := func(dst, src fs.Object) (differ bool, noHash bool) {
+	checkIdentical := func(ctx context.Context, dst, src fs.Object) (differ bool, noHash bool, err error) {
 		cryptDst := dst.(*crypt.Object)
 		underlyingDst := cryptDst.UnWrap()
 		underlyingHash, err := underlyingDst.Hash(hashType)
 		if err != nil {
 			fs.CountError(err)
 			fs.Errorf(dst, "Error reading hash from underlying %v: %v", underlyingDst, err)
-			return true, false
+			return true, false, err
 		}
 		if underlyingHash == "" {
-			return false, true
+			return false, true, nil
 		}
-		cryptHash, err := fcrypt.ComputeHash(cryptDst, src, hashType)
+		cryptHash, err := fcrypt.ComputeHash(ctx, cryptDst, src, hashType)
 		if err != nil {
 			fs.CountError(err)
 			fs.Errorf(dst, "Error computing hash: %v", err)
-			return true, false
+			return true, false, err
 		}
 		if cryptHash == "" {
-			return false, true
+			return false, true, nil
 		}
 		if cryptHash != underlyingHash {
 			err = errors.Errorf("hashes differ (%s:%s) %q vs (%s:%s) %q", fdst.Name(), fdst.Root(), cryptHash, fsrc.Name(), fsrc.Root(), underlyingHash)
 			fs.CountError(err)
 			fs.Errorf(src, err.Error())
-			return true, false
+			return true, false, nil
 		}
 		fs.Debugf(src, "OK")
-		return false, false
+		return false, false, nil
 	}
 
-	return operations.CheckFn(fcrypt, fsrc, checkIdentical)
+	optCopy := *opt
+	optCopy.Check = checkIdentical
+	return operations.CheckFn(ctx, &optCopy)
 }
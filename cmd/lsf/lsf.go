@@ -1,9 +1,11 @@
 package lsf
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"os"
+	"strings"
 
 	"github.com/ncw/rclone/cmd"
 	"github.com/ncw/rclone/cmd/ls/lshelp"
@@ -31,7 +33,7 @@ func init() {
 	flags.StringVarP(&format, "format", "F", "p", "Output format - see  help for details")
 	flags.StringVarP(&separator, "separator", "s", ";", "Separator for the items in the format.")
 	flags.BoolVarP(&dirSlash, "dir-slash", "d", true, "Append a slash to directory names.")
-	flags.VarP(&hashType, "hash", "", "Use this hash when `h` is used in the format MD5|SHA-1|DropboxHash")
+	flags.VarP(&hashType, "hash", "", "Use this hash when `h` is used in the format "+strings.Join(hash.Supported.Names(), "|"))
 	flags.BoolVarP(&filesOnly, "files-only", "", false, "Only list files.")
 	flags.BoolVarP(&dirsOnly, "dirs-only", "", false, "Only list directories.")
 	commandDefintion.Flags().BoolVarP(&recurse, "recursive", "R", false, "Recurse into the listing.")
@@ -106,7 +108,7 @@ func Lsf(fsrc fs.Fs, out io.Writer) error {
 		}
 	}
 
-	return walk.Walk(fsrc, "", false, operations.ConfigMaxDepth(recurse), func(path string, entries fs.DirEntries, err error) error {
+	return walk.Walk(context.Background(), fsrc, "", false, operations.ConfigMaxDepth(recurse), func(path string, entries fs.DirEntries, err error) error {
 		if err != nil {
 			fs.CountError(err)
 			fs.Errorf(path, "error listing: %v", err)
@@ -41,10 +41,10 @@ If you want to delete empty source directories after move, use the --delete-empt
 `,
 	Run: func(command *cobra.Command, args []string) {
 		cmd.CheckArgs(2, 2, command, args)
-		fsrc, fdst := cmd.NewFsSrcDst(args)
+		ctx, fsrc, fdst := cmd.NewFsSrcDstPlan(args)
 		cmd.Run(true, true, command, func() error {
 
-			return sync.MoveDir(fdst, fsrc, deleteEmptySrcDirs)
+			return sync.MoveDir(ctx, fdst, fsrc, deleteEmptySrcDirs)
 		})
 	},
 }
@@ -0,0 +1,166 @@
+// Show the dynamic progress bar
+
+package cmd
+
+import (
+	"bytes"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ncw/rclone/fs"
+	"github.com/ncw/rclone/fs/accounting"
+	fslog "github.com/ncw/rclone/fs/log"
+	"golang.org/x/crypto/ssh/terminal"
+)
+
+const (
+	// interval between progress prints
+	defaultProgressInterval = 500 * time.Millisecond
+	// terminal control sequences
+	eraseLine         = "\x1b[2K"
+	moveToStartOfLine = "\r"
+	moveUp            = "\x1b[1A"
+)
+
+// progressBar draws a block of text at the bottom of the terminal which
+// is redrawn in place, printing any log messages above it
+type progressBar struct {
+	mu    sync.Mutex
+	out   io.Writer
+	text  func() string              // returns the text for the block
+	size  func() (width, height int) // size of the terminal, 0 if unknown
+	lines int                        // number of lines in the block on screen
+}
+
+// terminalSize returns the size of the terminal on stderr or 0, 0 if
+// it can't be read
+func terminalSize() (width, height int) {
+	width, height, err := terminal.GetSize(int(os.Stderr.Fd()))
+	if err != nil {
+		return 0, 0
+	}
+	return width, height
+}
+
+// draw erases the block, writes logMessage if set, then draws the
+// block again underneath it
+func (p *progressBar) draw(logMessage string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	width, height := p.size()
+	var buf bytes.Buffer
+
+	// Move to the start of the block erasing all its lines
+	if p.lines > 0 {
+		buf.WriteString(moveToStartOfLine + eraseLine)
+		for i := 1; i < p.lines; i++ {
+			buf.WriteString(moveUp + eraseLine)
+		}
+	}
+
+	// Write the log message where the block was
+	if logMessage != "" {
+		buf.WriteString(logMessage)
+		if !strings.HasSuffix(logMessage, "\n") {
+			buf.WriteString("\n")
+		}
+	}
+
+	// Draw the block leaving the cursor at the end of it,
+	// truncating it to fit on the terminal otherwise the lines
+	// can't be erased
+	lines := strings.Split(strings.TrimSpace(p.text()), "\n")
+	if height > 1 && len(lines) > height-1 {
+		lines = lines[:height-1]
+	}
+	for i, line := range lines {
+		if width > 0 {
+			if runes := []rune(line); len(runes) > width {
+				line = string(runes[:width])
+			}
+		}
+		buf.WriteString(line)
+		if i != len(lines)-1 {
+			buf.WriteString("\n")
+		}
+	}
+	p.lines = len(lines)
+
+	_, _ = p.out.Write(buf.Bytes())
+}
+
+// Write writes log output above the block so it satisfies io.Writer
+func (p *progressBar) Write(b []byte) (n int, err error) {
+	p.draw(string(b))
+	return len(b), nil
+}
+
+// finish draws the block for the last time and leaves it on the
+// screen so following output goes underneath it
+func (p *progressBar) finish() {
+	p.draw("")
+	p.mu.Lock()
+	_, _ = io.WriteString(p.out, "\n")
+	p.lines = 0
+	p.mu.Unlock()
+}
+
+// startProgress starts showing the stats as a block at the bottom of
+// the terminal which is redrawn every interval with the log output
+// going above it.
+//
+// It returns a func which should be called to stop the progress.
+func startProgress(interval time.Duration) func() {
+	p := &progressBar{
+		out:  os.Stderr,
+		text: accounting.Stats.String,
+		size: terminalSize,
+	}
+	// Send the log output above the block unless it is going
+	// to a file or syslog
+	if !fslog.Redirected() {
+		log.SetOutput(p)
+	}
+	if interval <= 0 {
+		interval = defaultProgressInterval
+	}
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				p.draw("")
+			case <-stop:
+				return
+			}
+		}
+	}()
+	p.draw("")
+	return func() {
+		close(stop)
+		wg.Wait()
+		p.finish()
+		if !fslog.Redirected() {
+			log.SetOutput(os.Stderr)
+		}
+	}
+}
+
+// progressSupported returns true if --progress can be used, that is
+// if stderr is a terminal
+func progressSupported() bool {
+	if !terminal.IsTerminal(int(os.Stderr.Fd())) {
+		fs.Logf(nil, "Ignoring --progress as stderr is not a terminal")
+		return false
+	}
+	return true
+}
@@ -0,0 +1,43 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProgressBar(t *testing.T) {
+	var out bytes.Buffer
+	text := "\nline one\nline two is long\n"
+	p := &progressBar{
+		out:  &out,
+		text: func() string { return text },
+		size: func() (int, int) { return 10, 24 },
+	}
+
+	// first draw just writes the block truncated to the width
+	p.draw("")
+	assert.Equal(t, "line one\nline two i", out.String())
+	assert.Equal(t, 2, p.lines)
+
+	// log messages are written where the block was and the block
+	// is redrawn underneath
+	out.Reset()
+	_, err := p.Write([]byte("log message\n"))
+	assert.NoError(t, err)
+	assert.Equal(t, "\r"+eraseLine+moveUp+eraseLine+"log message\nline one\nline two i", out.String())
+
+	// the block is truncated to fit on the terminal
+	out.Reset()
+	text = "1\n2\n3\n4"
+	p.size = func() (int, int) { return 0, 3 }
+	p.draw("no newline")
+	assert.Equal(t, "\r"+eraseLine+moveUp+eraseLine+"no newline\n1\n2", out.String())
+
+	// finish leaves the block on the screen
+	out.Reset()
+	p.finish()
+	assert.Equal(t, "\r"+eraseLine+moveUp+eraseLine+"1\n2\n", out.String())
+	assert.Equal(t, 0, p.lines)
+}
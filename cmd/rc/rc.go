@@ -19,12 +19,16 @@ import (
 var (
 	noOutput = false
 	url      = "http://localhost:5572/"
+	authUser = ""
+	authPass = ""
 )
 
 func init() {
 	cmd.Root.AddCommand(commandDefintion)
 	commandDefintion.Flags().BoolVarP(&noOutput, "no-output", "", noOutput, "If set don't output the JSON result.")
 	commandDefintion.Flags().StringVarP(&url, "url", "", url, "URL to connect to rclone remote control.")
+	commandDefintion.Flags().StringVarP(&authUser, "user", "", "", "Username to use to rclone remote control.")
+	commandDefintion.Flags().StringVarP(&authPass, "pass", "", "", "Password to use to connect to rclone remote control.")
 }
 
 var commandDefintion = &cobra.Command{
@@ -34,6 +38,9 @@ var commandDefintion = &cobra.Command{
 This runs a command against a running rclone.  By default it will use
 that specified in the --rc-addr command.
 
+If the remote control has authentication set up then use --user and
+--pass to supply the credentials.
+
 Arguments should be passed in as parameter=value.
 
 The result will be returned as a JSON object by default.
@@ -65,7 +72,15 @@ func doCall(path string, in rc.Params) (out rc.Params, err error) {
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to encode JSON")
 	}
-	resp, err := client.Post(url, "application/json", bytes.NewBuffer(data))
+	req, err := http.NewRequest("POST", url, bytes.NewBuffer(data))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to make request")
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if authUser != "" || authPass != "" {
+		req.SetBasicAuth(authUser, authPass)
+	}
+	resp, err := client.Do(req)
 	if err != nil {
 		return nil, errors.Wrap(err, "connection failed")
 	}
@@ -132,6 +147,9 @@ func list() error {
 		}
 		fmt.Printf("### %s: %s\n\n", info["Path"], info["Title"])
 		fmt.Printf("%s\n\n", info["Help"])
+		if authRequired, _ := info["AuthRequired"].(bool); authRequired {
+			fmt.Printf("Authentication is required for this call.\n\n")
+		}
 	}
 	return nil
 }
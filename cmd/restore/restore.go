@@ -0,0 +1,89 @@
+package restore
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/ncw/rclone/cmd"
+	"github.com/ncw/rclone/fs"
+	"github.com/ncw/rclone/fs/fserrors"
+	"github.com/ncw/rclone/fs/operations"
+	"github.com/ncw/rclone/lib/version"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	cmd.Root.AddCommand(commandDefintion)
+}
+
+var commandDefintion = &cobra.Command{
+	Use:   "restore remote:backup-dir path version dest:path",
+	Short: `Restore a version of a file from a --backup-dir.`,
+	Long: `
+Copies a version of the file at path in a directory used as the
+` + "`--backup-dir`" + ` of a sync with ` + "`--backup-versions`" + `
+to dest:path.
+
+The version can be
+
+  * a version as listed by ` + "`rclone versions`" + `, eg 2019-01-02-093012-005
+  * a time in RFC3339 format, eg 2019-01-03T12:00:00Z, to get the version
+    of the file which was current at that time
+  * ` + "`latest`" + ` to get the newest version
+
+For example to restore the newest version of dir/file.txt back to
+where it was synced to
+
+    rclone restore remote:old dir/file.txt latest remote:current/dir/file.txt
+
+Each version is stamped with the time it was moved into the backup
+dir, which is when it stopped being current, so the version current at
+a given time is the oldest version stamped after it.  If there isn't
+one then the live file was current at that time and there is nothing
+to restore.
+
+The version is copied so it stays in the backup dir.
+`,
+	Run: func(command *cobra.Command, args []string) {
+		cmd.CheckArgs(4, 4, command, args)
+		backupDir := cmd.NewFsSrc(args[0:1])
+		fdst, dstFileName := cmd.NewFsDstFile(args[3:4])
+		remote := args[1]
+		when, err := parseVersion(args[2])
+		if err != nil {
+			log.Fatal(err)
+		}
+		cmd.Run(true, true, command, func() error {
+			ctx := context.Background()
+			v, err := operations.FindBackupVersion(ctx, backupDir, remote, when)
+			if err == fs.ErrorObjectNotFound {
+				return fserrors.FatalError(errors.Errorf("no version of %q found in %v", remote, backupDir))
+			} else if err != nil {
+				return err
+			}
+			if v == nil {
+				return fserrors.FatalError(errors.Errorf("the live version of %q was current at %v so there is nothing to restore", remote, when))
+			}
+			fs.Infof(v.Object, "Restoring version %s of %q", version.Format(v.Version), remote)
+			return operations.CopyFile(ctx, fdst, backupDir, dstFileName, v.Object.Remote())
+		})
+	},
+}
+
+// parseVersion parses the version argument returning a zero time
+// for the latest version
+func parseVersion(s string) (time.Time, error) {
+	if s == "latest" {
+		return time.Time{}, nil
+	}
+	if t, err := version.Parse(s); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return t, errors.Errorf("can't parse version %q - use a version from rclone versions, an RFC3339 time or latest", s)
+	}
+	return t, nil
+}
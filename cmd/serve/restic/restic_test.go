@@ -3,6 +3,7 @@
 package restic
 
 import (
+	"context"
 	"os"
 	"os/exec"
 	"testing"
@@ -36,7 +37,7 @@ func TestRestic(t *testing.T) {
 	assert.NoError(t, err)
 	defer clean()
 
-	err = fremote.Mkdir("")
+	err = fremote.Mkdir(context.Background(), "")
 	assert.NoError(t, err)
 
 	// Start the server
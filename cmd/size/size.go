@@ -1,6 +1,7 @@
 package size
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/ncw/rclone/cmd"
@@ -20,7 +21,7 @@ var commandDefintion = &cobra.Command{
 		cmd.CheckArgs(1, 1, command, args)
 		fsrc := cmd.NewFsSrc(args)
 		cmd.Run(false, false, command, func() error {
-			objects, size, err := operations.Count(fsrc)
+			objects, size, err := operations.Count(context.Background(), fsrc)
 			if err != nil {
 				return err
 			}
@@ -35,9 +35,9 @@ go there.
 `,
 	Run: func(command *cobra.Command, args []string) {
 		cmd.CheckArgs(2, 2, command, args)
-		fsrc, fdst := cmd.NewFsSrcDst(args)
+		ctx, fsrc, fdst := cmd.NewFsSrcDstPlan(args)
 		cmd.Run(true, true, command, func() error {
-			return sync.Sync(fdst, fsrc)
+			return sync.Sync(ctx, fdst, fsrc)
 		})
 	},
 }
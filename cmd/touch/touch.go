@@ -2,6 +2,7 @@ package touch
 
 import (
 	"bytes"
+	"context"
 	"time"
 
 	"github.com/ncw/rclone/cmd"
@@ -52,19 +53,19 @@ func Touch(fsrc fs.Fs, srcFileName string) error {
 		}
 		timeAtr = timeAtrFromFlags
 	}
-	file, err := fsrc.NewObject(srcFileName)
+	file, err := fsrc.NewObject(context.Background(), srcFileName)
 	if err != nil {
 		if !notCreateNewFile {
 			var buffer []byte
 			src := object.NewStaticObjectInfo(srcFileName, timeAtr, int64(len(buffer)), true, nil, fsrc)
-			_, err = fsrc.Put(bytes.NewBuffer(buffer), src)
+			_, err = fsrc.Put(context.Background(), bytes.NewBuffer(buffer), src)
 			if err != nil {
 				return err
 			}
 		}
 		return nil
 	}
-	err = file.SetModTime(timeAtr)
+	err = file.SetModTime(context.Background(), timeAtr)
 	if err != nil {
 		return errors.Wrap(err, "touch: couldn't set mod time")
 	}
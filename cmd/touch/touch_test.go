@@ -1,6 +1,7 @@
 package touch
 
 import (
+	"context"
 	"testing"
 	"time"
 
@@ -37,7 +38,7 @@ func TestTouchOneFile(t *testing.T) {
 
 	err := Touch(r.Fremote, "newFile")
 	require.NoError(t, err)
-	_, err = r.Fremote.NewObject("newFile")
+	_, err = r.Fremote.NewObject(context.Background(), "newFile")
 	require.NoError(t, err)
 }
 
@@ -48,7 +49,7 @@ func TestTouchWithNoCreateFlag(t *testing.T) {
 	notCreateNewFile = true
 	err := Touch(r.Fremote, "newFile")
 	require.NoError(t, err)
-	_, err = r.Fremote.NewObject("newFile")
+	_, err = r.Fremote.NewObject(context.Background(), "newFile")
 	require.Error(t, err)
 	notCreateNewFile = false
 }
@@ -0,0 +1,56 @@
+package versions
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ncw/rclone/cmd"
+	"github.com/ncw/rclone/fs/operations"
+	"github.com/ncw/rclone/lib/version"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	cmd.Root.AddCommand(commandDefintion)
+}
+
+var commandDefintion = &cobra.Command{
+	Use:   "versions remote:backup-dir [path]",
+	Short: `List the versions of files kept in a --backup-dir.`,
+	Long: `
+Lists the versions of files kept in a directory used as the
+` + "`--backup-dir`" + ` of a sync with ` + "`--backup-versions`" + `.
+
+If path is given then only the versions of that file are listed,
+otherwise the versions of all the files are listed.  The versions of
+each file are listed newest first with the version, the size and the
+path of the file, eg
+
+    $ rclone versions remote:old
+    2019-01-04-170418-221        5 dir/file.txt
+    2019-01-02-093012-005        4 dir/file.txt
+    2019-01-04-170418-224       12 other.txt
+
+The version is the time (in UTC) the file was moved into the backup
+dir.  Use it with ` + "`rclone restore`" + ` to get that version of
+the file back.
+`,
+	Run: func(command *cobra.Command, args []string) {
+		cmd.CheckArgs(1, 2, command, args)
+		backupDir := cmd.NewFsSrc(args)
+		remote := ""
+		if len(args) > 1 {
+			remote = args[1]
+		}
+		cmd.Run(false, false, command, func() error {
+			versions, err := operations.ListBackupVersions(context.Background(), backupDir, remote)
+			if err != nil {
+				return err
+			}
+			for _, v := range versions {
+				fmt.Printf("%s %9d %s\n", version.Format(v.Version), v.Object.Size(), v.Remote)
+			}
+			return nil
+		})
+	},
+}
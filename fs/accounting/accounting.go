@@ -10,8 +10,36 @@ import (
 	"github.com/VividCortex/ewma"
 	"github.com/ncw/rclone/fs"
 	"github.com/ncw/rclone/fs/asyncreader"
+	"github.com/ncw/rclone/fs/fserrors"
+	"github.com/pkg/errors"
 )
 
+// Errors returned when --max-transfer or --max-duration are reached
+var (
+	ErrorMaxTransferLimitReached = errors.New("Max transfer limit reached as set by --max-transfer")
+	ErrorMaxDurationReached      = errors.New("Max duration reached as set by --max-duration")
+
+	// The Fatal versions stop the sync immediately and the Graceful
+	// versions let the transfers in progress finish
+	ErrorMaxTransferLimitReachedFatal    = fserrors.FatalError(ErrorMaxTransferLimitReached)
+	ErrorMaxTransferLimitReachedGraceful = fserrors.NoRetryError(ErrorMaxTransferLimitReached)
+	ErrorMaxDurationReachedFatal         = fserrors.FatalError(ErrorMaxDurationReached)
+	ErrorMaxDurationReachedGraceful      = fserrors.NoRetryError(ErrorMaxDurationReached)
+)
+
+// IsCutoffError returns true if err shows that the transfers were
+// stopped early because of --max-transfer or --max-duration
+func IsCutoffError(err error) bool {
+	_, err = fserrors.Cause(err)
+	switch err {
+	case ErrorMaxTransferLimitReached, ErrorMaxDurationReached,
+		ErrorMaxTransferLimitReachedFatal, ErrorMaxTransferLimitReachedGraceful,
+		ErrorMaxDurationReachedFatal, ErrorMaxDurationReachedGraceful:
+		return true
+	}
+	return false
+}
+
 // Account limits and accounts for one transfer
 type Account struct {
 	// The mutex is to make sure Read() and Close() aren't called
@@ -34,6 +62,17 @@ type Account struct {
 	closed  bool               // set if the file is closed
 	exit    chan struct{}      // channel that will be closed when transfer is finished
 	withBuf bool               // is using a buffered in
+
+	// the names of the remotes the data is read from and written
+	// to, if known, for the per remote stats
+	srcName string
+	dstName string
+
+	// for the bandwidth limits
+	download bool         // set if the data is being downloaded from a remote
+	upload   bool         // set if the data is being uploaded to a remote
+	srcLimit *remoteLimit // limit for the remote the data is downloaded from or nil
+	dstLimit *remoteLimit // limit for the remote the data is uploaded to or nil
 }
 
 // NewAccountSizeName makes a Account reader for an io.ReadCloser of
@@ -51,12 +90,47 @@ func NewAccountSizeName(in io.ReadCloser, size int64, name string) *Account {
 	}
 	go acc.averageLoop()
 	Stats.inProgress.set(acc.name, acc)
+	Stats.addAccount(acc)
 	return acc
 }
 
 // NewAccount makes a Account reader for an object
 func NewAccount(in io.ReadCloser, obj fs.Object) *Account {
-	return NewAccountSizeName(in, obj.Size(), obj.Remote())
+	acc := NewAccountSizeName(in, obj.Size(), obj.Remote())
+	if src := obj.Fs(); src != nil {
+		acc.srcName = src.Name()
+		if !isLocal(src) {
+			acc.download = true
+			acc.srcLimit = getRemoteLimit(src)
+		}
+	}
+	return acc
+}
+
+// WithDestination sets the remote the data is being sent to so the
+// upload bandwidth limits can be applied and the data counted
+// against it
+func (acc *Account) WithDestination(dst fs.Info) *Account {
+	if dst == nil {
+		return acc
+	}
+	acc.dstName = dst.Name()
+	if !isLocal(dst) {
+		acc.upload = true
+		acc.dstLimit = getRemoteLimit(dst)
+	}
+	return acc
+}
+
+// isLocal returns true if f is on the local disk, in which case
+// reading and writing it doesn't count as a download or an upload
+func isLocal(f fs.Info) bool {
+	name := f.Name()
+	if name == "local" {
+		return true
+	}
+	fsType, _ := fs.ConfigFileGet(name, "type")
+	return fsType == "local"
 }
 
 // WithBuffer - If the file is above a certain size it adds an Async reader
@@ -129,29 +203,56 @@ func (acc *Account) averageLoop() {
 	}
 }
 
-// read bytes from the io.Reader passed in and account them
-func (acc *Account) read(in io.Reader, p []byte) (n int, err error) {
-	// Set start time.
+// checkStart sets the start time if it hasn't been set already
+func (acc *Account) checkStart() {
 	acc.statmu.Lock()
 	if acc.start.IsZero() {
 		acc.start = time.Now()
 	}
 	acc.statmu.Unlock()
+}
 
-	n, err = in.Read(p)
-
+// accountRead updates the stats for n bytes read and applies the
+// bandwidth limit
+func (acc *Account) accountRead(n int) {
 	// Update Stats
 	acc.statmu.Lock()
 	acc.lpBytes += n
 	acc.bytes += int64(n)
 	acc.statmu.Unlock()
 
-	Stats.Bytes(int64(n))
+	Stats.accountBytes(acc, int64(n))
 
-	limitBandwidth(n)
+	limitBandwidth(n, acc.upload, acc.download)
+	if acc.download {
+		acc.srcLimit.wait(n, false)
+	}
+	if acc.upload {
+		acc.dstLimit.wait(n, true)
+	}
+}
+
+// read bytes from the io.Reader passed in and account them
+func (acc *Account) read(in io.Reader, p []byte) (n int, err error) {
+	acc.checkStart()
+	if fs.Config.MaxTransfer >= 0 && fs.Config.CutoffMode == fs.CutoffModeHard && Stats.GetBytes() >= int64(fs.Config.MaxTransfer) {
+		return 0, ErrorMaxTransferLimitReachedFatal
+	}
+	n, err = in.Read(p)
+	acc.accountRead(n)
 	return
 }
 
+// AccountRead accounts for n bytes having been read without reading
+// them through the Account.
+//
+// This is for transfers which read the data themselves, possibly
+// from several goroutines at once.
+func (acc *Account) AccountRead(n int) {
+	acc.checkStart()
+	acc.accountRead(n)
+}
+
 // Read bytes from the object - see io.Reader
 func (acc *Account) Read(p []byte) (n int, err error) {
 	acc.mu.Lock()
@@ -39,3 +39,17 @@ func (ip *inProgress) get(name string) *Account {
 	defer ip.mu.Unlock()
 	return ip.m[name]
 }
+
+// remaining returns the number of bytes still to be read by the
+// transfers in progress which have a known size
+func (ip *inProgress) remaining() (total int64) {
+	ip.mu.Lock()
+	defer ip.mu.Unlock()
+	for _, acc := range ip.m {
+		bytes, size := acc.progress()
+		if size > bytes {
+			total += size - bytes
+		}
+	}
+	return total
+}
@@ -0,0 +1,52 @@
+// Prometheus metrics for the stats
+
+package accounting
+
+import (
+	"sort"
+
+	"github.com/ncw/rclone/lib/metrics"
+)
+
+// collect adds the current stats to w
+//
+// The totals over all the remotes don't have a remote label.  The
+// bytes and transfers are also given for each remote with a remote
+// label and a direction label of "read" or "write".
+func (s *StatsInfo) collect(w *metrics.Writer) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	w.Add("rclone_bytes_transferred_total", metrics.Counter, "Total bytes transferred.", float64(s.bytes))
+	w.Add("rclone_transfers_total", metrics.Counter, "Total number of files transferred.", float64(s.transfers))
+	w.Add("rclone_checks_total", metrics.Counter, "Total number of files checked.", float64(s.checks))
+	w.Add("rclone_errors_total", metrics.Counter, "Total number of errors.", float64(s.errors))
+	w.Add("rclone_deletes_total", metrics.Counter, "Total number of files deleted.", float64(s.deletes))
+	w.Add("rclone_transfers_in_progress", metrics.Gauge, "Number of files being transferred.", float64(len(s.transferring)))
+	w.Add("rclone_checks_in_progress", metrics.Gauge, "Number of files being checked.", float64(len(s.checking)))
+	speed := 0.0
+	for name := range s.transferring {
+		if info := s.transferInfo[name]; info != nil {
+			_, current := info.acc.speed()
+			speed += current
+		}
+	}
+	w.Add("rclone_speed_bytes_per_second", metrics.Gauge, "Current speed of all the transfers in progress in bytes per second.", speed)
+	names := make([]string, 0, len(s.remotes))
+	for name := range s.remotes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		counts := s.remotes[name]
+		w.Add("rclone_remote_bytes_transferred_total", metrics.Counter, "Total bytes transferred for each remote.", float64(counts.bytesRead), "remote", name, "direction", "read")
+		w.Add("rclone_remote_bytes_transferred_total", metrics.Counter, "Total bytes transferred for each remote.", float64(counts.bytesWritten), "remote", name, "direction", "write")
+		w.Add("rclone_remote_transfers_total", metrics.Counter, "Total number of files transferred for each remote.", float64(counts.transfersRead), "remote", name, "direction", "read")
+		w.Add("rclone_remote_transfers_total", metrics.Counter, "Total number of files transferred for each remote.", float64(counts.transfersWritten), "remote", name, "direction", "write")
+	}
+}
+
+func init() {
+	metrics.Register(func(w *metrics.Writer) {
+		Stats.collect(w)
+	})
+}
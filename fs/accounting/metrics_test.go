@@ -0,0 +1,59 @@
+package accounting
+
+import (
+	"bytes"
+	"io/ioutil"
+	"testing"
+
+	"github.com/ncw/rclone/lib/metrics"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStatsMetrics(t *testing.T) {
+	Stats.ResetCounters()
+	Stats.Bytes(1234)
+	Stats.Transferring("metrics1")
+	Stats.Checking("metrics2")
+	Stats.Errors(1)
+	defer func() {
+		Stats.DoneTransferring("metrics1", true)
+		Stats.DoneChecking("metrics2")
+	}()
+
+	var out bytes.Buffer
+	require.NoError(t, metrics.Write(&out))
+	for _, want := range []string{
+		"# TYPE rclone_bytes_transferred_total counter\nrclone_bytes_transferred_total 1234\n",
+		"rclone_errors_total 1\n",
+		"rclone_transfers_in_progress 1\n",
+		"rclone_checks_in_progress 1\n",
+		"# TYPE rclone_speed_bytes_per_second gauge\n",
+	} {
+		assert.Contains(t, out.String(), want)
+	}
+}
+
+func TestStatsMetricsRemote(t *testing.T) {
+	Stats.ResetCounters()
+	Stats.Transferring("metrics3")
+	in := ioutil.NopCloser(bytes.NewBufferString("hello"))
+	acc := NewAccount(in, testObject{Object: "metrics3", f: testInfo{name: "metricsrc"}}).WithDestination(testInfo{name: "metricdst"})
+	_, err := ioutil.ReadAll(acc)
+	require.NoError(t, err)
+	require.NoError(t, acc.Close())
+	Stats.DoneTransferring("metrics3", true)
+
+	var out bytes.Buffer
+	require.NoError(t, metrics.Write(&out))
+	for _, want := range []string{
+		"# TYPE rclone_remote_bytes_transferred_total counter\n",
+		`rclone_remote_bytes_transferred_total{remote="metricdst",direction="read"} 0` + "\n",
+		`rclone_remote_bytes_transferred_total{remote="metricdst",direction="write"} 5` + "\n",
+		`rclone_remote_bytes_transferred_total{remote="metricsrc",direction="read"} 5` + "\n",
+		`rclone_remote_transfers_total{remote="metricdst",direction="write"} 1` + "\n",
+		`rclone_remote_transfers_total{remote="metricsrc",direction="read"} 1` + "\n",
+	} {
+		assert.Contains(t, out.String(), want)
+	}
+}
@@ -0,0 +1,188 @@
+// Structured stats for --stats-json and the rc
+
+package accounting
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/ncw/rclone/fs/rc"
+)
+
+// maxCompletedTransfers is how many finished transfers are kept in
+// the history
+const maxCompletedTransfers = 100
+
+// TransferStats describes a transfer in progress
+type TransferStats struct {
+	Name       string    `json:"name"`
+	Size       int64     `json:"size"`       // size of the file or -1 if not known
+	Bytes      int64     `json:"bytes"`      // bytes transferred so far
+	Percentage int       `json:"percentage"` // percentage done
+	Speed      float64   `json:"speed"`      // current speed in bytes/s
+	SpeedAvg   float64   `json:"speedAvg"`   // average speed since the start in bytes/s
+	ETA        *int64    `json:"eta"`        // estimated seconds to go or nil if not known
+	StartedAt  time.Time `json:"startedAt"`
+}
+
+// CompletedTransfer describes a finished transfer
+type CompletedTransfer struct {
+	Name        string    `json:"name"`
+	Size        int64     `json:"size"`  // size of the file or -1 if not known
+	Bytes       int64     `json:"bytes"` // bytes transferred
+	StartedAt   time.Time `json:"startedAt"`
+	CompletedAt time.Time `json:"completedAt"`
+	Error       string    `json:"error,omitempty"` // set if the transfer failed
+}
+
+// StatsSnapshot is a copy of the StatsInfo at a point in time
+//
+// Speeds are in bytes/s and times are in seconds.
+type StatsSnapshot struct {
+	Time           time.Time           `json:"time"`
+	Bytes          int64               `json:"bytes"`
+	TotalBytes     int64               `json:"totalBytes"`
+	Speed          float64             `json:"speed"`
+	Errors         int64               `json:"errors"`
+	LastError      string              `json:"lastError,omitempty"`
+	Checks         int64               `json:"checks"`
+	TotalChecks    int64               `json:"totalChecks"`
+	Transfers      int64               `json:"transfers"`
+	TotalTransfers int64               `json:"totalTransfers"`
+	Deletes        int64               `json:"deletes"`
+	ElapsedTime    float64             `json:"elapsedTime"`
+	Checking       []string            `json:"checking"`
+	Transferring   []TransferStats     `json:"transferring"`
+	Completed      []CompletedTransfer `json:"completed"` // the most recent transfers, oldest first
+}
+
+// transferStats makes the TransferStats for the transfer of name
+func transferStats(name string, info *transferInfo) (ts TransferStats) {
+	ts.Name = name
+	ts.Size = -1
+	if info == nil {
+		return ts
+	}
+	ts.StartedAt = info.start
+	acc := info.acc
+	if acc == nil {
+		return ts
+	}
+	ts.Bytes, ts.Size = acc.progress()
+	if ts.Size > 0 {
+		ts.Percentage = int(100 * float64(ts.Bytes) / float64(ts.Size))
+	}
+	ts.SpeedAvg, ts.Speed = acc.speed()
+	if eta, ok := acc.eta(); ok {
+		seconds := int64(eta / time.Second)
+		ts.ETA = &seconds
+	}
+	return ts
+}
+
+// Snapshot returns a structured copy of the stats
+func (s *StatsInfo) Snapshot() *StatsSnapshot {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	now := time.Now()
+	dt := now.Sub(s.start)
+	out := &StatsSnapshot{
+		Time:         now,
+		Bytes:        s.bytes,
+		Errors:       s.errors,
+		Checks:       s.checks,
+		Transfers:    s.transfers,
+		Deletes:      s.deletes,
+		ElapsedTime:  dt.Seconds(),
+		Checking:     make([]string, 0, len(s.checking)),
+		Transferring: make([]TransferStats, 0, len(s.transferring)),
+		Completed:    append([]CompletedTransfer{}, s.completed...),
+	}
+	out.TotalChecks, out.TotalTransfers, out.TotalBytes = s.totals()
+	if dt > 0 {
+		out.Speed = float64(s.bytes) / dt.Seconds()
+	}
+	if s.lastError != nil {
+		out.LastError = s.lastError.Error()
+	}
+	for name := range s.checking {
+		out.Checking = append(out.Checking, name)
+	}
+	sort.Strings(out.Checking)
+	names := make([]string, 0, len(s.transferring))
+	for name := range s.transferring {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		out.Transferring = append(out.Transferring, transferStats(name, s.transferInfo[name]))
+	}
+	return out
+}
+
+// WriteJSON writes a Snapshot of the stats to out as a single line
+// of JSON
+func (s *StatsInfo) WriteJSON(out io.Writer) error {
+	return json.NewEncoder(out).Encode(s.Snapshot())
+}
+
+// rcStats returns the Snapshot of the stats as rc.Params
+func rcStats(ctx context.Context, in rc.Params) (out rc.Params, err error) {
+	buf, err := json.Marshal(Stats.Snapshot())
+	if err != nil {
+		return nil, err
+	}
+	err = json.Unmarshal(buf, &out)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Remote control for the stats
+func init() {
+	rc.Add(rc.Call{
+		Path:  "core/stats",
+		Fn:    rcStats,
+		Title: "Returns stats about current transfers.",
+		Help: `
+This returns all available stats
+
+    rclone rc core/stats
+
+Returns the following values:
+
+    bytes - total transferred bytes since the start of the process
+    totalBytes - total bytes to transfer including those in progress and queued
+    speed - average speed in bytes/s since the start of the process
+    errors - number of errors
+    lastError - the last error string, if any
+    checks - number of checked files
+    totalChecks - total number of checks including those in progress and queued
+    transfers - number of transferred files
+    totalTransfers - total number of transfers including those in progress and queued
+    deletes - number of deleted files
+    elapsedTime - time in seconds since the start of the process
+    checking - an array of names of the files currently being checked
+    transferring - an array of the files currently being transferred
+    completed - an array of the most recently completed transfers
+
+Each transfer in "transferring" has
+
+    name - name of the file
+    size - size of the file or -1 if not known
+    bytes - bytes transferred so far
+    percentage - progress of the transfer
+    speed - current speed in bytes/s
+    speedAvg - average speed in bytes/s
+    eta - estimated seconds to go or null if not known
+    startedAt - time the transfer started
+
+Each transfer in "completed" has the name, size, bytes, startedAt and
+completedAt as above plus "error" if the transfer failed.
+`,
+	})
+}
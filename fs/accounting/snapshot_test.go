@@ -0,0 +1,101 @@
+package accounting
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"testing"
+
+	"github.com/ncw/rclone/fs/rc"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStatsSnapshot(t *testing.T) {
+	Stats.ResetCounters()
+
+	// A transfer in progress
+	Stats.Transferring("snap1")
+	in := ioutil.NopCloser(bytes.NewBuffer(make([]byte, 100)))
+	acc := NewAccountSizeName(in, 400, "snap1")
+	buf := make([]byte, 100)
+	n, err := acc.Read(buf)
+	require.NoError(t, err)
+	assert.Equal(t, 100, n)
+
+	// A transfer which hasn't started reading yet
+	Stats.Transferring("snap2")
+
+	// A file being checked
+	Stats.Checking("snap3")
+
+	s := Stats.Snapshot()
+	assert.Equal(t, int64(100), s.Bytes)
+	assert.Equal(t, []string{"snap3"}, s.Checking)
+	require.Len(t, s.Transferring, 2)
+	tr := s.Transferring[0]
+	assert.Equal(t, "snap1", tr.Name)
+	assert.Equal(t, int64(400), tr.Size)
+	assert.Equal(t, int64(100), tr.Bytes)
+	assert.Equal(t, 25, tr.Percentage)
+	assert.False(t, tr.StartedAt.IsZero())
+	tr = s.Transferring[1]
+	assert.Equal(t, "snap2", tr.Name)
+	assert.Equal(t, int64(-1), tr.Size)
+	assert.Nil(t, tr.ETA)
+
+	// Finish the transfers
+	require.NoError(t, acc.Close())
+	Stats.DoneTransferringError("snap1", nil)
+	Stats.DoneTransferringError("snap2", errors.New("potato"))
+	Stats.DoneChecking("snap3")
+
+	s = Stats.Snapshot()
+	assert.Len(t, s.Transferring, 0)
+	assert.Len(t, s.Checking, 0)
+	assert.Equal(t, int64(1), s.Transfers)
+	require.True(t, len(s.Completed) >= 2)
+	completed := s.Completed[len(s.Completed)-2:]
+	assert.Equal(t, "snap1", completed[0].Name)
+	assert.Equal(t, int64(400), completed[0].Size)
+	assert.Equal(t, int64(100), completed[0].Bytes)
+	assert.Equal(t, "", completed[0].Error)
+	assert.False(t, completed[0].CompletedAt.Before(completed[0].StartedAt))
+	assert.Equal(t, "snap2", completed[1].Name)
+	assert.Equal(t, "potato", completed[1].Error)
+
+	// Check the JSON
+	var out bytes.Buffer
+	require.NoError(t, Stats.WriteJSON(&out))
+	assert.Equal(t, byte('\n'), out.Bytes()[out.Len()-1])
+	assert.Equal(t, 1, bytes.Count(out.Bytes(), []byte("\n")))
+	var decoded map[string]interface{}
+	require.NoError(t, json.Unmarshal(out.Bytes(), &decoded))
+	assert.Equal(t, float64(100), decoded["bytes"])
+	assert.Equal(t, float64(1), decoded["transfers"])
+
+	// Check the rc call
+	rcOut, err := rcStats(context.Background(), rc.Params{})
+	require.NoError(t, err)
+	assert.Equal(t, float64(100), rcOut["bytes"])
+	assert.Equal(t, []interface{}{}, rcOut["transferring"])
+}
+
+func TestStatsCompletedHistory(t *testing.T) {
+	for i := 0; i < maxCompletedTransfers+10; i++ {
+		name := fmt.Sprintf("history%d", i)
+		Stats.Transferring(name)
+		Stats.DoneTransferring(name, i%2 == 0)
+	}
+	s := Stats.Snapshot()
+	require.Len(t, s.Completed, maxCompletedTransfers)
+	assert.Equal(t, "history10", s.Completed[0].Name)
+	assert.Equal(t, "", s.Completed[0].Error)
+	last := s.Completed[maxCompletedTransfers-1]
+	assert.Equal(t, fmt.Sprintf("history%d", maxCompletedTransfers+9), last.Name)
+	assert.Equal(t, errTransferFailed.Error(), last.Error)
+	assert.Equal(t, int64(-1), last.Size)
+}
@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/ncw/rclone/fs"
+	"github.com/pkg/errors"
 )
 
 var (
@@ -22,17 +23,38 @@ func init() {
 
 // StatsInfo accounts all transfers
 type StatsInfo struct {
-	lock         sync.RWMutex
-	bytes        int64
-	errors       int64
-	lastError    error
-	checks       int64
-	checking     stringSet
-	transfers    int64
-	transferring stringSet
-	deletes      int64
-	start        time.Time
-	inProgress   *inProgress
+	lock              sync.RWMutex
+	bytes             int64
+	errors            int64
+	lastError         error
+	checks            int64
+	checking          stringSet
+	checkQueue        int
+	checkQueueSize    int64
+	transfers         int64
+	transferring      stringSet
+	transferInfo      map[string]*transferInfo // more about each transfer in transferring
+	transferQueue     int
+	transferQueueSize int64
+	deletes           int64
+	start             time.Time
+	inProgress        *inProgress
+	completed         []CompletedTransfer      // recently finished transfers, oldest first
+	remotes           map[string]*remoteCounts // counters for each remote by name
+}
+
+// remoteCounts holds the counters kept for each remote
+type remoteCounts struct {
+	bytesRead        int64 // bytes read from the remote
+	bytesWritten     int64 // bytes written to the remote
+	transfersRead    int64 // transfers completed from the remote
+	transfersWritten int64 // transfers completed to the remote
+}
+
+// transferInfo holds what is known about a transfer in progress
+type transferInfo struct {
+	start time.Time // when the transfer started
+	acc   *Account  // the most recent Account for the transfer or nil
 }
 
 // NewStats cretates an initialised StatsInfo
@@ -40,11 +62,36 @@ func NewStats() *StatsInfo {
 	return &StatsInfo{
 		checking:     make(stringSet, fs.Config.Checkers),
 		transferring: make(stringSet, fs.Config.Transfers),
+		transferInfo: make(map[string]*transferInfo, fs.Config.Transfers),
 		start:        time.Now(),
 		inProgress:   newInProgress(),
+		remotes:      make(map[string]*remoteCounts),
 	}
 }
 
+// remote returns the counters for the remote called name
+//
+// Call with lock held
+func (s *StatsInfo) remote(name string) *remoteCounts {
+	counts := s.remotes[name]
+	if counts == nil {
+		counts = &remoteCounts{}
+		s.remotes[name] = counts
+	}
+	return counts
+}
+
+// totals works out the total checks, transfers and bytes from what is
+// done, in progress and queued
+//
+// Call with lock held
+func (s *StatsInfo) totals() (totalChecks, totalTransfers, totalBytes int64) {
+	totalChecks = s.checks + int64(len(s.checking)+s.checkQueue)
+	totalTransfers = s.transfers + int64(len(s.transferring)+s.transferQueue)
+	totalBytes = s.bytes + s.transferQueueSize + s.inProgress.remaining()
+	return totalChecks, totalTransfers, totalBytes
+}
+
 // String convert the StatsInfo to a string for printing
 func (s *StatsInfo) String() string {
 	s.lock.RLock()
@@ -58,21 +105,24 @@ func (s *StatsInfo) String() string {
 	dtRounded := dt - (dt % (time.Second / 10))
 	buf := &bytes.Buffer{}
 
+	totalChecks, totalTransfers, totalBytes := s.totals()
+	etaText := etaString(s.bytes, totalBytes, speed)
+
 	if fs.Config.DataRateUnit == "bits" {
 		speed = speed * 8
 	}
 
 	fmt.Fprintf(buf, `
-Transferred:   %10s (%s)
+Transferred:   %10s / %s, %s (%s), ETA %s
 Errors:        %10d
-Checks:        %10d
-Transferred:   %10d
+Checks:        %10d / %d, %s
+Transferred:   %10d / %d, %s
 Elapsed time:  %10v
 `,
-		fs.SizeSuffix(s.bytes).Unit("Bytes"), fs.SizeSuffix(speed).Unit(strings.Title(fs.Config.DataRateUnit)+"/s"),
+		fs.SizeSuffix(s.bytes).Unit("Bytes"), fs.SizeSuffix(totalBytes).Unit("Bytes"), percent(s.bytes, totalBytes), fs.SizeSuffix(speed).Unit(strings.Title(fs.Config.DataRateUnit)+"/s"), etaText,
 		s.errors,
-		s.checks,
-		s.transfers,
+		s.checks, totalChecks, percent(s.checks, totalChecks),
+		s.transfers, totalTransfers, percent(s.transfers, totalTransfers),
 		dtRounded)
 	if len(s.checking) > 0 {
 		fmt.Fprintf(buf, "Checking:\n%s\n", s.checking)
@@ -83,6 +133,24 @@ Elapsed time:  %10v
 	return buf.String()
 }
 
+// percent returns a/b as a percentage rounded down, or "-" if b is 0
+func percent(a int64, b int64) string {
+	if b <= 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%d%%", int(float64(a)*100/float64(b)))
+}
+
+// etaString returns the time to transfer the rest of total bytes at
+// speed bytes/s as a string or "-" if it can't be worked out
+func etaString(done, total int64, speed float64) string {
+	if total <= 0 || speed <= 0 || done > total {
+		return "-"
+	}
+	eta := time.Duration(float64(total-done)/speed) * time.Second
+	return eta.String()
+}
+
 // Log outputs the StatsInfo to the log
 func (s *StatsInfo) Log() {
 	fs.LogLevelPrintf(fs.Config.StatsLogLevel, nil, "%v\n", s)
@@ -95,6 +163,26 @@ func (s *StatsInfo) Bytes(bytes int64) {
 	s.bytes += bytes
 }
 
+// accountBytes updates the stats for bytes read by acc
+func (s *StatsInfo) accountBytes(acc *Account, bytes int64) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.bytes += bytes
+	if acc.srcName != "" {
+		s.remote(acc.srcName).bytesRead += bytes
+	}
+	if acc.dstName != "" {
+		s.remote(acc.dstName).bytesWritten += bytes
+	}
+}
+
+// GetBytes returns the number of bytes transferred so far
+func (s *StatsInfo) GetBytes() int64 {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	return s.bytes
+}
+
 // Errors updates the stats for errors
 func (s *StatsInfo) Errors(errors int64) {
 	s.lock.Lock()
@@ -133,6 +221,7 @@ func (s *StatsInfo) ResetCounters() {
 	s.checks = 0
 	s.transfers = 0
 	s.deletes = 0
+	s.remotes = make(map[string]*remoteCounts)
 }
 
 // ResetErrors sets the errors count to 0
@@ -172,6 +261,23 @@ func (s *StatsInfo) DoneChecking(remote string) {
 	s.checks++
 }
 
+// SetCheckQueue sets the number of queued checks and their total size
+func (s *StatsInfo) SetCheckQueue(n int, size int64) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.checkQueue = n
+	s.checkQueueSize = size
+}
+
+// SetTransferQueue sets the number of queued transfers and their
+// total size
+func (s *StatsInfo) SetTransferQueue(n int, size int64) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.transferQueue = n
+	s.transferQueueSize = size
+}
+
 // GetTransfers reads the number of transfers
 func (s *StatsInfo) GetTransfers() int64 {
 	s.lock.RLock()
@@ -184,16 +290,68 @@ func (s *StatsInfo) Transferring(remote string) {
 	s.lock.Lock()
 	defer s.lock.Unlock()
 	s.transferring[remote] = struct{}{}
+	s.transferInfo[remote] = &transferInfo{start: time.Now()}
 }
 
+// addAccount notes acc as the current Account for its transfer if
+// there is one in progress
+func (s *StatsInfo) addAccount(acc *Account) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if info := s.transferInfo[acc.name]; info != nil {
+		info.acc = acc
+	}
+}
+
+// errTransferFailed is recorded in the history for transfers
+// finished with DoneTransferring which weren't ok
+var errTransferFailed = errors.New("transfer failed")
+
 // DoneTransferring removes a transfer from the stats
 //
 // if ok is true then it increments the transfers count
 func (s *StatsInfo) DoneTransferring(remote string, ok bool) {
+	var err error
+	if !ok {
+		err = errTransferFailed
+	}
+	s.DoneTransferringError(remote, err)
+}
+
+// DoneTransferringError removes a transfer from the stats and adds it
+// to the history of completed transfers along with err.
+//
+// if err is nil then it increments the transfers count
+func (s *StatsInfo) DoneTransferringError(remote string, err error) {
 	s.lock.Lock()
 	defer s.lock.Unlock()
 	delete(s.transferring, remote)
-	if ok {
+	if err == nil {
 		s.transfers++
 	}
+	completed := CompletedTransfer{
+		Name:        remote,
+		Size:        -1,
+		CompletedAt: time.Now(),
+	}
+	if info := s.transferInfo[remote]; info != nil {
+		completed.StartedAt = info.start
+		if acc := info.acc; acc != nil {
+			completed.Bytes, completed.Size = acc.progress()
+			if err == nil && acc.srcName != "" {
+				s.remote(acc.srcName).transfersRead++
+			}
+			if err == nil && acc.dstName != "" {
+				s.remote(acc.dstName).transfersWritten++
+			}
+		}
+		delete(s.transferInfo, remote)
+	}
+	if err != nil {
+		completed.Error = err.Error()
+	}
+	s.completed = append(s.completed, completed)
+	if len(s.completed) > maxCompletedTransfers {
+		s.completed = s.completed[len(s.completed)-maxCompletedTransfers:]
+	}
 }
@@ -0,0 +1,26 @@
+package accounting
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEtaString(t *testing.T) {
+	for _, test := range []struct {
+		done, total int64
+		speed       float64
+		want        string
+	}{
+		{0, 0, 10, "-"},
+		{0, 100, 0, "-"},
+		{200, 100, 10, "-"},
+		{100, 100, 10, "0s"},
+		{0, 100, 10, "10s"},
+		{50, 100, 0.5, "1m40s"},
+		{0, 7200 * 1024, 1024, "2h0m0s"},
+	} {
+		got := etaString(test.done, test.total, test.speed)
+		assert.Equal(t, test.want, got, "%+v", test)
+	}
+}
@@ -14,7 +14,7 @@ import (
 // Globals
 var (
 	tokenBucketMu     sync.Mutex // protects the token bucket variables
-	tokenBucket       *rate.Limiter
+	tokenBucket       buckets
 	prevTokenBucket   = tokenBucket
 	bwLimitToggledOff = false
 	currLimitMu       sync.Mutex // protects changes to the timeslot
@@ -23,6 +23,51 @@ var (
 
 const maxBurstSize = 1 * 1024 * 1024 // must be bigger than the biggest request
 
+// Indexes into the buckets
+const (
+	bucketCombined = iota // everything when upload and download limits are the same
+	bucketTx              // uploads when the limits are different
+	bucketRx              // downloads when the limits are different
+	bucketSlots
+)
+
+// buckets holds the token buckets for each direction, nil if not
+// limited
+type buckets [bucketSlots]*rate.Limiter
+
+// isSet returns true if any of the buckets are in use
+func (bs buckets) isSet() bool {
+	for _, b := range bs {
+		if b != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// wait waits for n bytes from the buckets which apply to the
+// direction of the transfer
+func (bs buckets) wait(n int, upload, download bool) {
+	waitBucket(bs[bucketCombined], n)
+	if upload {
+		waitBucket(bs[bucketTx], n)
+	}
+	if download {
+		waitBucket(bs[bucketRx], n)
+	}
+}
+
+// waitBucket waits for n bytes from the token bucket b if set
+func waitBucket(b *rate.Limiter, n int) {
+	if b == nil {
+		return
+	}
+	err := b.WaitN(context.Background(), n)
+	if err != nil {
+		fs.Errorf(nil, "Token bucket error: %v", err)
+	}
+}
+
 // make a new empty token bucket with the bandwidth given
 func newTokenBucket(bandwidth fs.SizeSuffix) *rate.Limiter {
 	newTokenBucket := rate.NewLimiter(rate.Limit(bandwidth), maxBurstSize)
@@ -34,14 +79,35 @@ func newTokenBucket(bandwidth fs.SizeSuffix) *rate.Limiter {
 	return newTokenBucket
 }
 
+// newTokenBuckets makes the token buckets for the bandwidth given.
+//
+// If the upload and download limits are the same then a single
+// bucket is shared by all the transfers, otherwise uploads and
+// downloads get their own.
+func newTokenBuckets(bandwidth fs.BwPair) (bs buckets) {
+	if bandwidth.Tx == bandwidth.Rx {
+		if bandwidth.Tx > 0 {
+			bs[bucketCombined] = newTokenBucket(bandwidth.Tx)
+		}
+		return bs
+	}
+	if bandwidth.Tx > 0 {
+		bs[bucketTx] = newTokenBucket(bandwidth.Tx)
+	}
+	if bandwidth.Rx > 0 {
+		bs[bucketRx] = newTokenBucket(bandwidth.Rx)
+	}
+	return bs
+}
+
 // StartTokenBucket starts the token bucket if necessary
 func StartTokenBucket() {
 	currLimitMu.Lock()
 	currLimit := fs.Config.BwLimit.LimitAt(time.Now())
 	currLimitMu.Unlock()
 
-	if currLimit.Bandwidth > 0 {
-		tokenBucket = newTokenBucket(currLimit.Bandwidth)
+	if currLimit.Bandwidth.IsSet() {
+		tokenBucket = newTokenBuckets(currLimit.Bandwidth)
 		fs.Infof(nil, "Starting bandwidth limiter at %vBytes/s", &currLimit.Bandwidth)
 
 		// Start the SIGUSR2 signal handler to toggle bandwidth.
@@ -70,7 +136,7 @@ func StartTokenTicker() {
 				// If bwlimit is toggled off, the change should only
 				// become active on the next toggle, which causes
 				// an exchange of tokenBucket <-> prevTokenBucket
-				var targetBucket **rate.Limiter
+				var targetBucket *buckets
 				if bwLimitToggledOff {
 					targetBucket = &prevTokenBucket
 				} else {
@@ -78,8 +144,8 @@ func StartTokenTicker() {
 				}
 
 				// Set new bandwidth. If unlimited, set tokenbucket to nil.
-				if limitNow.Bandwidth > 0 {
-					*targetBucket = newTokenBucket(limitNow.Bandwidth)
+				*targetBucket = newTokenBuckets(limitNow.Bandwidth)
+				if limitNow.Bandwidth.IsSet() {
 					if bwLimitToggledOff {
 						fs.Logf(nil, "Scheduled bandwidth change. "+
 							"Limit will be set to %vBytes/s when toggled on again.", &limitNow.Bandwidth)
@@ -87,7 +153,6 @@ func StartTokenTicker() {
 						fs.Logf(nil, "Scheduled bandwidth change. Limit set to %vBytes/s", &limitNow.Bandwidth)
 					}
 				} else {
-					*targetBucket = nil
 					fs.Logf(nil, "Scheduled bandwidth change. Bandwidth limits disabled")
 				}
 
@@ -101,25 +166,113 @@ func StartTokenTicker() {
 
 // limitBandwith sleeps for the correct amount of time for the passage
 // of n bytes according to the current bandwidth limit
-func limitBandwidth(n int) {
+//
+// upload and download say which direction the bytes are going
+func limitBandwidth(n int, upload, download bool) {
+	// Take a copy of the buckets so the lock isn't held while
+	// waiting - otherwise transfers in one direction would be
+	// held up behind those throttled in the other
 	tokenBucketMu.Lock()
+	bs := tokenBucket
+	tokenBucketMu.Unlock()
 
 	// Limit the transfer speed if required
-	if tokenBucket != nil {
-		err := tokenBucket.WaitN(context.Background(), n)
+	bs.wait(n, upload, download)
+}
+
+// remoteLimit is the bandwidth limit set with bwlimit in the config
+// of a remote
+type remoteLimit struct {
+	name      string
+	timetable fs.BwTimetable
+	mu        sync.Mutex    // protects the fields below
+	started   bool          // set once the token buckets have been made
+	slot      fs.BwTimeSlot // the time slot in use
+	tx        *rate.Limiter // limits uploads to the remote or nil
+	rx        *rate.Limiter // limits downloads from the remote or nil
+}
+
+var (
+	remoteLimitsMu sync.Mutex
+	remoteLimits   = make(map[string]*remoteLimit) // by remote name, nil if not limited
+)
+
+// getRemoteLimit returns the bandwidth limit configured for the
+// remote f or nil if there isn't one
+func getRemoteLimit(f fs.Info) *remoteLimit {
+	if f == nil {
+		return nil
+	}
+	name := f.Name()
+	remoteLimitsMu.Lock()
+	defer remoteLimitsMu.Unlock()
+	limit, found := remoteLimits[name]
+	if found {
+		return limit
+	}
+	if value, ok := fs.ConfigFileGet(name, "bwlimit"); ok && value != "" {
+		var timetable fs.BwTimetable
+		err := timetable.Set(value)
 		if err != nil {
-			fs.Errorf(nil, "Token bucket error: %v", err)
+			fs.Errorf(nil, "Ignoring bad bwlimit %q for remote %q: %v", value, name, err)
+		} else {
+			limit = &remoteLimit{
+				name:      name,
+				timetable: timetable,
+			}
+			limit.update(time.Now())
+			fs.Infof(nil, "Starting bandwidth limiter for remote %q at %vBytes/s", name, &limit.slot.Bandwidth)
 		}
 	}
+	remoteLimits[name] = limit
+	return limit
+}
 
-	tokenBucketMu.Unlock()
+// update makes new token buckets if the time slot in use has changed
+//
+// call with mu held or before the remoteLimit is in use
+func (l *remoteLimit) update(now time.Time) {
+	slot := l.timetable.LimitAt(now)
+	if l.started {
+		if slot.Bandwidth == l.slot.Bandwidth {
+			return
+		}
+		fs.Logf(nil, "Scheduled bandwidth change for remote %q. Limit set to %vBytes/s", l.name, &slot.Bandwidth)
+	}
+	l.started = true
+	l.slot = slot
+	l.tx, l.rx = nil, nil
+	if slot.Bandwidth.Tx > 0 {
+		l.tx = newTokenBucket(slot.Bandwidth.Tx)
+	}
+	if slot.Bandwidth.Rx > 0 {
+		l.rx = newTokenBucket(slot.Bandwidth.Rx)
+	}
+}
+
+// wait waits for n bytes to be uploaded to or downloaded from the
+// remote.  It does nothing if l is nil.
+func (l *remoteLimit) wait(n int, upload bool) {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	if len(l.timetable) > 1 {
+		l.update(time.Now())
+	}
+	b := l.rx
+	if upload {
+		b = l.tx
+	}
+	l.mu.Unlock()
+	waitBucket(b, n)
 }
 
 // Remote control for the token bucket
 func init() {
 	rc.Add(rc.Call{
 		Path: "core/bwlimit",
-		Fn: func(in rc.Params) (out rc.Params, err error) {
+		Fn: func(ctx context.Context, in rc.Params) (out rc.Params, err error) {
 			ibwlimit, ok := in["rate"]
 			if !ok {
 				return out, errors.Errorf("parameter rate not found")
@@ -138,9 +291,9 @@ func init() {
 			}
 			bw := bws[0]
 			tokenBucketMu.Lock()
-			tokenBucket = newTokenBucket(bw.Bandwidth)
+			tokenBucket = newTokenBuckets(bw.Bandwidth)
 			tokenBucketMu.Unlock()
-			fs.Logf(nil, "Bandwidth limit set to %v", bw.Bandwidth)
+			fs.Logf(nil, "Bandwidth limit set to %v", &bw.Bandwidth)
 			return rc.Params{"rate": bw.Bandwidth.String()}, nil
 		},
 		Title: "Set the bandwidth limit.",
@@ -150,10 +303,12 @@ This sets the bandwidth limit to that passed in.
 Eg
 
     rclone core/bwlimit rate=1M
+    rclone core/bwlimit rate=512k:10M
     rclone core/bwlimit rate=off
 
 The format of the parameter is exactly the same as passed to --bwlimit
-except only one bandwidth may be specified.
+except only one bandwidth may be specified.  Use upload:download to
+set different upload and download limits.
 `,
 	})
 }
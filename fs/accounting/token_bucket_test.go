@@ -0,0 +1,146 @@
+package accounting
+
+import (
+	"bytes"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/ncw/rclone/fs"
+	"github.com/ncw/rclone/fstest/mockobject"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewTokenBuckets(t *testing.T) {
+	bs := newTokenBuckets(fs.BwPair{Tx: -1, Rx: -1})
+	assert.False(t, bs.isSet())
+
+	bs = newTokenBuckets(fs.BwPair{Tx: 1024, Rx: 1024})
+	assert.NotNil(t, bs[bucketCombined])
+	assert.Nil(t, bs[bucketTx])
+	assert.Nil(t, bs[bucketRx])
+
+	bs = newTokenBuckets(fs.BwPair{Tx: 1024, Rx: -1})
+	assert.Nil(t, bs[bucketCombined])
+	assert.NotNil(t, bs[bucketTx])
+	assert.Nil(t, bs[bucketRx])
+	assert.True(t, bs.isSet())
+}
+
+// Test downloads aren't held up by throttled uploads
+func TestLimitBandwidthDirections(t *testing.T) {
+	tokenBucketMu.Lock()
+	oldTokenBucket := tokenBucket
+	tokenBucket = newTokenBuckets(fs.BwPair{Tx: 1024, Rx: -1})
+	tokenBucketMu.Unlock()
+	defer func() {
+		tokenBucketMu.Lock()
+		tokenBucket = oldTokenBucket
+		tokenBucketMu.Unlock()
+	}()
+
+	// This upload needs about 4 seconds of tokens
+	uploading := make(chan struct{})
+	go func() {
+		close(uploading)
+		limitBandwidth(4096, true, false)
+	}()
+	<-uploading
+	time.Sleep(10 * time.Millisecond)
+
+	start := time.Now()
+	limitBandwidth(4096, false, true)
+	assert.True(t, time.Since(start) < time.Second, "download was throttled")
+}
+
+// testInfo is an fs.Info with a name
+type testInfo struct {
+	fs.Info
+	name string
+}
+
+// Name of the remote
+func (f testInfo) Name() string { return f.name }
+
+// testObject is an fs.Object on the remote f
+type testObject struct {
+	mockobject.Object
+	f fs.Info
+}
+
+// Fs returns the remote the object is on
+func (o testObject) Fs() fs.Info { return o.f }
+
+func TestAccountDirectionAndRemoteLimits(t *testing.T) {
+	oldConfigFileGet := fs.ConfigFileGet
+	defer func() {
+		fs.ConfigFileGet = oldConfigFileGet
+		remoteLimitsMu.Lock()
+		remoteLimits = make(map[string]*remoteLimit)
+		remoteLimitsMu.Unlock()
+	}()
+	fs.ConfigFileGet = func(section, key string) (string, bool) {
+		switch section + "." + key {
+		case "disk.type":
+			return "local", true
+		case "limited.bwlimit":
+			return "512k:off", true
+		case "bad.bwlimit":
+			return "potato", true
+		}
+		return "", false
+	}
+
+	newAcc := func(src, dst string) *Account {
+		in := ioutil.NopCloser(bytes.NewBuffer([]byte{1}))
+		acc := NewAccount(in, testObject{Object: "file", f: testInfo{name: src}}).WithDestination(testInfo{name: dst})
+		require.NoError(t, acc.Close())
+		return acc
+	}
+
+	acc := newAcc("local", "disk")
+	assert.False(t, acc.download)
+	assert.False(t, acc.upload)
+
+	acc = newAcc("remote", "local")
+	assert.True(t, acc.download)
+	assert.False(t, acc.upload)
+	assert.Nil(t, acc.srcLimit)
+
+	acc = newAcc("disk", "limited")
+	assert.False(t, acc.download)
+	assert.True(t, acc.upload)
+	require.NotNil(t, acc.dstLimit)
+	assert.NotNil(t, acc.dstLimit.tx)
+	assert.Nil(t, acc.dstLimit.rx)
+
+	acc = newAcc("limited", "bad")
+	assert.True(t, acc.download)
+	assert.True(t, acc.upload)
+	assert.Equal(t, remoteLimits["limited"], acc.srcLimit)
+	assert.Nil(t, acc.dstLimit)
+}
+
+func TestRemoteLimitUpdate(t *testing.T) {
+	var timetable fs.BwTimetable
+	require.NoError(t, timetable.Set("Mon-08:00,512k:10M Sat-00:00,off"))
+	l := &remoteLimit{name: "test", timetable: timetable}
+
+	// Saturday
+	l.update(time.Date(2017, time.April, 22, 12, 0, 0, 0, time.UTC))
+	assert.Nil(t, l.tx)
+	assert.Nil(t, l.rx)
+
+	// Monday
+	l.update(time.Date(2017, time.April, 24, 12, 0, 0, 0, time.UTC))
+	require.NotNil(t, l.tx)
+	require.NotNil(t, l.rx)
+	assert.Equal(t, 512*1024.0, float64(l.tx.Limit()))
+	assert.Equal(t, 10*1024*1024.0, float64(l.rx.Limit()))
+
+	// The buckets are kept if the limit doesn't change
+	tx := l.tx
+	l.update(time.Date(2017, time.April, 25, 12, 0, 0, 0, time.UTC))
+	assert.True(t, tx == l.tx)
+}
@@ -0,0 +1,499 @@
+// Package bisync implements bidirectional synchronisation between
+// two paths.
+//
+// The state of both paths after each successful run is stored in
+// listings in the working directory.  On the next run the current
+// state of each path is compared with its listing to find out which
+// files are new, changed or deleted and the changes are propagated to
+// the other path.
+package bisync
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/ncw/rclone/fs"
+	"github.com/ncw/rclone/fs/accounting"
+	"github.com/ncw/rclone/fs/config"
+	"github.com/ncw/rclone/fs/fserrors"
+	"github.com/ncw/rclone/fs/march"
+	"github.com/ncw/rclone/fs/operations"
+	"github.com/pkg/errors"
+	"github.com/spf13/pflag"
+)
+
+// ConflictResolve describes how to resolve a file which has been
+// changed on both paths
+type ConflictResolve int
+
+// Conflict resolution policies
+const (
+	ConflictResolveRename ConflictResolve = iota // keep both files, renaming them
+	ConflictResolveNewer                         // the newest file wins
+	ConflictResolvePath1                         // the file on path1 wins
+)
+
+func (x ConflictResolve) String() string {
+	switch x {
+	case ConflictResolveRename:
+		return "rename"
+	case ConflictResolveNewer:
+		return "newer"
+	case ConflictResolvePath1:
+		return "path1"
+	}
+	return "unknown"
+}
+
+// Set a ConflictResolve from a string
+func (x *ConflictResolve) Set(s string) error {
+	switch strings.ToLower(s) {
+	case "rename":
+		*x = ConflictResolveRename
+	case "newer":
+		*x = ConflictResolveNewer
+	case "path1":
+		*x = ConflictResolvePath1
+	default:
+		return errors.Errorf("Unknown conflict resolution %q.", s)
+	}
+	return nil
+}
+
+// Type of the value
+func (x *ConflictResolve) Type() string {
+	return "string"
+}
+
+// Check it satisfies the interface
+var _ pflag.Value = (*ConflictResolve)(nil)
+
+// Options controls the bisync
+type Options struct {
+	Resync           bool            // ignore the listings and make both paths contain all the files
+	ConflictResolve  ConflictResolve // how to resolve files changed on both paths
+	MaxDeletePercent int             // refuse to run if more than this percentage of files are deleted on a path
+	Force            bool            // run even if MaxDeletePercent is exceeded
+	Workdir          string          // directory for the listings - defaults to the cache dir
+}
+
+// DefaultOptions returns the default options for Bisync
+func DefaultOptions() Options {
+	return Options{
+		ConflictResolve:  ConflictResolveRename,
+		MaxDeletePercent: 50,
+	}
+}
+
+// Suffixes added to conflicting files when renaming them
+const (
+	conflictSuffix1 = "..path1"
+	conflictSuffix2 = "..path2"
+)
+
+// delta describes how a file has changed since the last run
+type delta int
+
+const (
+	deltaNone delta = iota
+	deltaNew
+	deltaChanged
+	deltaDeleted
+)
+
+func (d delta) String() string {
+	switch d {
+	case deltaNone:
+		return "unchanged"
+	case deltaNew:
+		return "new"
+	case deltaChanged:
+		return "changed"
+	case deltaDeleted:
+		return "deleted"
+	}
+	return "unknown"
+}
+
+// bisyncer holds the state for one bisync run
+type bisyncer struct {
+	ctx      context.Context
+	fs1, fs2 fs.Fs
+	opt      Options
+	// files found on each path
+	path1, path2 map[string]fs.Object
+	// changes since the last run on each path
+	deltas1, deltas2 map[string]delta
+	// conflicts found
+	conflictsMu sync.Mutex
+	conflicts   int
+}
+
+// Bisync makes fs1 and fs2 contain the same files by propagating the
+// changes made on each since the last run to the other.
+//
+// On the first run, or if the listings are lost, opt.Resync must be
+// set.
+func Bisync(ctx context.Context, fs1, fs2 fs.Fs, opt Options) (err error) {
+	if operations.Overlapping(fs1, fs2) {
+		return fserrors.FatalError(errors.New("can't bisync overlapping paths"))
+	}
+	workdir := opt.Workdir
+	if workdir == "" {
+		workdir = filepath.Join(config.CacheDir, "bisync")
+	}
+	err = os.MkdirAll(workdir, 0700)
+	if err != nil {
+		return errors.Wrap(err, "failed to make working directory")
+	}
+	session := filepath.Join(workdir, sessionName(fs1, fs2))
+	listing1Path := session + ".path1.lst"
+	listing2Path := session + ".path2.lst"
+
+	// Stop two bisyncs on the same paths running at once
+	lockPath := session + ".lck"
+	lock, err := os.OpenFile(lockPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+	if err != nil {
+		if os.IsExist(err) {
+			return fserrors.FatalError(errors.Errorf("bisync already running on these paths - if not remove the lock file %q", lockPath))
+		}
+		return errors.Wrap(err, "failed to make lock file")
+	}
+	_ = lock.Close()
+	defer func() {
+		if removeErr := os.Remove(lockPath); removeErr != nil {
+			fs.Errorf(nil, "Failed to remove bisync lock file: %v", removeErr)
+		}
+	}()
+
+	b := &bisyncer{
+		ctx: ctx,
+		fs1: fs1,
+		fs2: fs2,
+		opt: opt,
+	}
+	b.path1, b.path2, err = b.list()
+	if err != nil {
+		return err
+	}
+
+	if opt.Resync {
+		fs.Infof(nil, "Resyncing %v and %v", fs1, fs2)
+		err = b.run(b.resyncFile)
+	} else {
+		err = b.findDeltas(listing1Path, listing2Path)
+		if err != nil {
+			return err
+		}
+		err = b.run(b.syncFile)
+	}
+	if b.conflicts > 0 {
+		fs.Logf(nil, "Bisync found %d conflicts resolved with %v", b.conflicts, opt.ConflictResolve)
+	}
+	if err != nil {
+		return err
+	}
+
+	// Don't update the listings if nothing was done
+	if fs.Config.DryRun {
+		return nil
+	}
+
+	// Record the state of both paths for the next run
+	path1, path2, err := b.list()
+	if err != nil {
+		return errors.Wrap(err, "failed to list paths after bisync")
+	}
+	err = newListing(path1).save(listing1Path)
+	if err != nil {
+		return err
+	}
+	return newListing(path2).save(listing2Path)
+}
+
+// lister collects the objects found on both paths while marching
+type lister struct {
+	mu           sync.Mutex
+	path1, path2 map[string]fs.Object
+}
+
+// SrcOnly is called for a DirEntry found only on path1
+func (l *lister) SrcOnly(src fs.DirEntry) (recurse bool) {
+	switch x := src.(type) {
+	case fs.Object:
+		l.mu.Lock()
+		l.path1[x.Remote()] = x
+		l.mu.Unlock()
+	case fs.Directory:
+		return true
+	}
+	return false
+}
+
+// DstOnly is called for a DirEntry found only on path2
+func (l *lister) DstOnly(dst fs.DirEntry) (recurse bool) {
+	switch x := dst.(type) {
+	case fs.Object:
+		l.mu.Lock()
+		l.path2[x.Remote()] = x
+		l.mu.Unlock()
+	case fs.Directory:
+		return true
+	}
+	return false
+}
+
+// Match is called for a DirEntry found on both paths
+//
+// Both objects are indexed by the name on path1 so that files which
+// only differ in case on a case insensitive path2 are matched up.
+func (l *lister) Match(dst, src fs.DirEntry) (recurse bool) {
+	srcObj, srcIsObj := src.(fs.Object)
+	dstObj, dstIsObj := dst.(fs.Object)
+	switch {
+	case srcIsObj && dstIsObj:
+		l.mu.Lock()
+		l.path1[srcObj.Remote()] = srcObj
+		l.path2[srcObj.Remote()] = dstObj
+		l.mu.Unlock()
+	case srcIsObj, dstIsObj:
+		err := errors.New("can't bisync a file with a directory of the same name")
+		fs.Errorf(src, "%v", err)
+		fs.CountError(err)
+	default:
+		return true
+	}
+	return false
+}
+
+// list the files on both paths
+func (b *bisyncer) list() (path1, path2 map[string]fs.Object, err error) {
+	l := &lister{
+		path1: make(map[string]fs.Object),
+		path2: make(map[string]fs.Object),
+	}
+	// A listing which fails part way through would look like
+	// files had been deleted so any error here must stop the run
+	errorsBefore := accounting.Stats.GetErrors()
+	march.New(b.ctx, b.fs2, b.fs1, "", l).Run()
+	if accounting.Stats.GetErrors() != errorsBefore {
+		return nil, nil, fserrors.FatalError(errors.New("errors listing paths - not continuing"))
+	}
+	if b.ctx.Err() != nil {
+		return nil, nil, b.ctx.Err()
+	}
+	return l.path1, l.path2, nil
+}
+
+// findDeltas loads the listings from the last run and compares them
+// with the current files, checking that there aren't too many
+// deletions
+func (b *bisyncer) findDeltas(listing1Path, listing2Path string) error {
+	prior1, err := loadListing(listing1Path)
+	if err == nil {
+		var prior2 listing
+		prior2, err = loadListing(listing2Path)
+		if err == nil {
+			b.deltas1, err = b.findPathDeltas(b.fs1, "path1", b.path1, prior1)
+			if err == nil {
+				b.deltas2, err = b.findPathDeltas(b.fs2, "path2", b.path2, prior2)
+			}
+		}
+	}
+	if os.IsNotExist(err) {
+		return fserrors.FatalError(errors.New("no listings from a previous run found - run with --resync first"))
+	}
+	return err
+}
+
+// findPathDeltas finds the changes to files on one path
+func (b *bisyncer) findPathDeltas(f fs.Fs, name string, current map[string]fs.Object, prior listing) (deltas map[string]delta, err error) {
+	deltas = make(map[string]delta)
+	var newFiles, changed, deleted int
+	for remote, o := range current {
+		info, found := prior[remote]
+		switch {
+		case !found:
+			deltas[remote] = deltaNew
+			newFiles++
+		case info.changed(o):
+			deltas[remote] = deltaChanged
+			changed++
+		}
+	}
+	for remote := range prior {
+		if _, found := current[remote]; !found {
+			deltas[remote] = deltaDeleted
+			deleted++
+		}
+	}
+	fs.Infof(f, "Bisync %s: %d new, %d changed, %d deleted files", name, newFiles, changed, deleted)
+	if len(prior) > 0 && deleted*100 > b.opt.MaxDeletePercent*len(prior) {
+		err = errors.Errorf("too many deletes on %s: %d of %d files is more than %d%%", name, deleted, len(prior), b.opt.MaxDeletePercent)
+		if !b.opt.Force {
+			return nil, fserrors.FatalError(errors.Wrap(err, "not continuing - use --force to override"))
+		}
+		fs.Logf(f, "Continuing with %v as --force is set", err)
+	}
+	return deltas, nil
+}
+
+// run calls fn for each file on either path and in the listings
+// using --transfers go routines, returning the last error
+func (b *bisyncer) run(fn func(remote string) error) (err error) {
+	remotes := make(map[string]struct{}, len(b.path1))
+	for _, files := range []map[string]fs.Object{b.path1, b.path2} {
+		for remote := range files {
+			remotes[remote] = struct{}{}
+		}
+	}
+	for _, deltas := range []map[string]delta{b.deltas1, b.deltas2} {
+		for remote := range deltas {
+			remotes[remote] = struct{}{}
+		}
+	}
+	sorted := make([]string, 0, len(remotes))
+	for remote := range remotes {
+		sorted = append(sorted, remote)
+	}
+	sort.Strings(sorted)
+
+	var (
+		wg    sync.WaitGroup
+		errMu sync.Mutex
+		in    = make(chan string, fs.Config.Transfers)
+	)
+	for i := 0; i < fs.Config.Transfers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for remote := range in {
+				if b.ctx.Err() != nil {
+					continue
+				}
+				if fnErr := fn(remote); fnErr != nil {
+					fs.Errorf(remote, "Bisync failed: %v", fnErr)
+					fs.CountError(fnErr)
+					errMu.Lock()
+					err = fnErr
+					errMu.Unlock()
+				}
+			}
+		}()
+	}
+	for _, remote := range sorted {
+		in <- remote
+	}
+	close(in)
+	wg.Wait()
+	if err == nil {
+		err = b.ctx.Err()
+	}
+	return err
+}
+
+// copy src to remote on fdst if it needs transferring
+func (b *bisyncer) copy(fdst fs.Fs, dst fs.Object, remote string, src fs.Object) error {
+	if dst != nil && !operations.NeedTransfer(b.ctx, dst, src) {
+		return nil
+	}
+	_, err := operations.Copy(b.ctx, fdst, dst, remote, src)
+	return err
+}
+
+// delete o if it exists
+func (b *bisyncer) delete(o fs.Object) error {
+	if o == nil {
+		return nil
+	}
+	return operations.DeleteFile(b.ctx, o)
+}
+
+// syncFile propagates the changes to remote between the paths
+func (b *bisyncer) syncFile(remote string) error {
+	o1, o2 := b.path1[remote], b.path2[remote]
+	d1, d2 := b.deltas1[remote], b.deltas2[remote]
+	switch {
+	case d1 == deltaNone && d2 == deltaNone:
+		return nil
+	case d2 == deltaNone:
+		fs.Debugf(remote, "File is %v on path1", d1)
+		if d1 == deltaDeleted {
+			return b.delete(o2)
+		}
+		return b.copy(b.fs2, o2, remote, o1)
+	case d1 == deltaNone:
+		fs.Debugf(remote, "File is %v on path2", d2)
+		if d2 == deltaDeleted {
+			return b.delete(o1)
+		}
+		return b.copy(b.fs1, o1, remote, o2)
+	case d1 == deltaDeleted && d2 == deltaDeleted:
+		return nil
+	case d1 == deltaDeleted:
+		// A change wins over a deletion
+		fs.Debugf(remote, "File is deleted on path1 but %v on path2 - keeping it", d2)
+		return b.copy(b.fs1, nil, remote, o2)
+	case d2 == deltaDeleted:
+		fs.Debugf(remote, "File is deleted on path2 but %v on path1 - keeping it", d1)
+		return b.copy(b.fs2, nil, remote, o1)
+	}
+	// The file is new or changed on both paths
+	if operations.Equal(b.ctx, o1, o2) {
+		fs.Debugf(remote, "File is %v on path1 and %v on path2 but identical", d1, d2)
+		return nil
+	}
+	return b.resolveConflict(remote, o1, o2)
+}
+
+// resolveConflict deals with remote which has been changed on both
+// paths according to the conflict resolution policy
+func (b *bisyncer) resolveConflict(remote string, o1, o2 fs.Object) error {
+	b.conflictsMu.Lock()
+	b.conflicts++
+	b.conflictsMu.Unlock()
+	fs.Logf(remote, "Conflict: file changed on both paths - resolving with %v", b.opt.ConflictResolve)
+	switch b.opt.ConflictResolve {
+	case ConflictResolveNewer:
+		if o2.ModTime().After(o1.ModTime()) {
+			return b.copy(b.fs1, o1, remote, o2)
+		}
+		return b.copy(b.fs2, o2, remote, o1)
+	case ConflictResolvePath1:
+		return b.copy(b.fs2, o2, remote, o1)
+	}
+	// Rename both files then copy each to the other path
+	remote1, remote2 := remote+conflictSuffix1, remote+conflictSuffix2
+	if fs.Config.DryRun {
+		fs.Logf(remote, "Not renaming to %q and %q as --dry-run", remote1, remote2)
+		return nil
+	}
+	new1, err := operations.Move(b.ctx, b.fs1, nil, remote1, o1)
+	if err != nil {
+		return errors.Wrap(err, "failed to rename path1 file")
+	}
+	new2, err := operations.Move(b.ctx, b.fs2, nil, remote2, o2)
+	if err != nil {
+		return errors.Wrap(err, "failed to rename path2 file")
+	}
+	_, err = operations.Copy(b.ctx, b.fs2, nil, remote1, new1)
+	if err != nil {
+		return err
+	}
+	_, err = operations.Copy(b.ctx, b.fs1, nil, remote2, new2)
+	return err
+}
+
+// resyncFile makes remote exist on both paths, preferring the path1
+// copy if they differ
+func (b *bisyncer) resyncFile(remote string) error {
+	o1, o2 := b.path1[remote], b.path2[remote]
+	if o1 != nil {
+		return b.copy(b.fs2, o2, remote, o1)
+	}
+	return b.copy(b.fs1, nil, remote, o2)
+}
@@ -0,0 +1,209 @@
+// Test bisync
+
+package bisync
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	_ "github.com/ncw/rclone/backend/all" // import all backends
+	"github.com/ncw/rclone/fs"
+	"github.com/ncw/rclone/fs/accounting"
+	"github.com/ncw/rclone/fs/fserrors"
+	"github.com/ncw/rclone/fstest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Some times used in the tests
+var (
+	t1 = fstest.Time("2001-02-03T04:05:06.499999999Z")
+	t2 = fstest.Time("2011-12-25T12:59:59.123456789Z")
+	t3 = fstest.Time("2011-12-30T12:59:59.000000000Z")
+)
+
+// TestMain drives the tests
+func TestMain(m *testing.M) {
+	fstest.TestMain(m)
+}
+
+// newTestOptions returns options using a temporary working directory
+// and a function to tidy it up
+func newTestOptions(t *testing.T) (Options, func()) {
+	workdir, err := ioutil.TempDir("", "rclone-bisync-test")
+	require.NoError(t, err)
+	opt := DefaultOptions()
+	opt.Workdir = workdir
+	return opt, func() {
+		_ = os.RemoveAll(workdir)
+	}
+}
+
+// run a bisync between the local (path1) and the remote (path2)
+func runBisync(r *fstest.Run, opt Options) error {
+	accounting.Stats.ResetCounters()
+	return Bisync(context.Background(), r.Flocal, r.Fremote, opt)
+}
+
+func TestBisyncNeedsResync(t *testing.T) {
+	r := fstest.NewRun(t)
+	defer r.Finalise()
+	opt, cleanup := newTestOptions(t)
+	defer cleanup()
+	r.Mkdir(r.Fremote)
+
+	err := runBisync(r, opt)
+	require.Error(t, err)
+	assert.True(t, fserrors.IsFatalError(err))
+	assert.Contains(t, err.Error(), "--resync")
+}
+
+func TestBisync(t *testing.T) {
+	r := fstest.NewRun(t)
+	defer r.Finalise()
+	opt, cleanup := newTestOptions(t)
+	defer cleanup()
+
+	file1 := r.WriteFile("one", "one", t1)
+	file2 := r.WriteObject("sub dir/two", "two", t1)
+	file3 := r.WriteBoth("three", "three", t1)
+	file4 := r.WriteBoth("four", "four", t1)
+	fstest.CheckItems(t, r.Flocal, file1, file3, file4)
+	fstest.CheckItems(t, r.Fremote, file2, file3, file4)
+
+	// First run must resync which unions the paths
+	opt.Resync = true
+	require.NoError(t, runBisync(r, opt))
+	fstest.CheckItems(t, r.Flocal, file1, file2, file3, file4)
+	fstest.CheckItems(t, r.Fremote, file1, file2, file3, file4)
+
+	// Nothing to do
+	opt.Resync = false
+	require.NoError(t, runBisync(r, opt))
+	assert.Equal(t, int64(0), accounting.Stats.GetTransfers())
+
+	// Change on path1, delete on path2, new on path2
+	file1 = r.WriteFile("one", "one changed", t2)
+	r.Mkdir(r.Fremote)
+	obj, err := r.Fremote.NewObject(context.Background(), "three")
+	require.NoError(t, err)
+	require.NoError(t, obj.Remove(context.Background()))
+	file5 := r.WriteObject("five", "five", t2)
+
+	require.NoError(t, runBisync(r, opt))
+	fstest.CheckItems(t, r.Flocal, file1, file2, file4, file5)
+	fstest.CheckItems(t, r.Fremote, file1, file2, file4, file5)
+
+	// A change wins over a deletion
+	require.NoError(t, os.Remove(r.LocalName+"/four"))
+	file4 = r.WriteObject("four", "four changed", t2)
+
+	require.NoError(t, runBisync(r, opt))
+	fstest.CheckItems(t, r.Flocal, file1, file2, file4, file5)
+	fstest.CheckItems(t, r.Fremote, file1, file2, file4, file5)
+}
+
+func testBisyncConflict(t *testing.T, conflictResolve ConflictResolve) {
+	r := fstest.NewRun(t)
+	defer r.Finalise()
+	opt, cleanup := newTestOptions(t)
+	defer cleanup()
+	opt.ConflictResolve = conflictResolve
+
+	r.WriteBoth("file", "original", t1)
+	opt.Resync = true
+	require.NoError(t, runBisync(r, opt))
+	opt.Resync = false
+
+	// Change the file differently on both paths with path2 newer
+	file1local := r.WriteFile("file", "path1 version", t2)
+	file1remote := r.WriteObject("file", "path2 version!", t3)
+
+	require.NoError(t, runBisync(r, opt))
+	switch conflictResolve {
+	case ConflictResolveRename:
+		renamed1 := fstest.NewItem("file"+conflictSuffix1, "path1 version", t2)
+		renamed2 := fstest.NewItem("file"+conflictSuffix2, "path2 version!", t3)
+		fstest.CheckItems(t, r.Flocal, renamed1, renamed2)
+		fstest.CheckItems(t, r.Fremote, renamed1, renamed2)
+	case ConflictResolveNewer:
+		fstest.CheckItems(t, r.Flocal, file1remote)
+		fstest.CheckItems(t, r.Fremote, file1remote)
+	case ConflictResolvePath1:
+		fstest.CheckItems(t, r.Flocal, file1local)
+		fstest.CheckItems(t, r.Fremote, file1local)
+	}
+
+	// Check the next run finds nothing to do
+	require.NoError(t, runBisync(r, opt))
+	assert.Equal(t, int64(0), accounting.Stats.GetTransfers())
+}
+
+func TestBisyncConflictRename(t *testing.T) { testBisyncConflict(t, ConflictResolveRename) }
+func TestBisyncConflictNewer(t *testing.T)  { testBisyncConflict(t, ConflictResolveNewer) }
+func TestBisyncConflictPath1(t *testing.T)  { testBisyncConflict(t, ConflictResolvePath1) }
+
+func TestBisyncTooManyDeletes(t *testing.T) {
+	r := fstest.NewRun(t)
+	defer r.Finalise()
+	opt, cleanup := newTestOptions(t)
+	defer cleanup()
+
+	file1 := r.WriteBoth("one", "one", t1)
+	file2 := r.WriteBoth("two", "two", t1)
+	opt.Resync = true
+	require.NoError(t, runBisync(r, opt))
+	opt.Resync = false
+
+	// Delete all the files on path1
+	require.NoError(t, os.Remove(r.LocalName+"/one"))
+	require.NoError(t, os.Remove(r.LocalName+"/two"))
+
+	err := runBisync(r, opt)
+	require.Error(t, err)
+	assert.True(t, fserrors.IsFatalError(err))
+	assert.Contains(t, err.Error(), "too many deletes")
+	fstest.CheckItems(t, r.Fremote, file1, file2)
+
+	// Check --force overrides
+	opt.Force = true
+	require.NoError(t, runBisync(r, opt))
+	fstest.CheckItems(t, r.Fremote)
+}
+
+func TestBisyncDryRun(t *testing.T) {
+	r := fstest.NewRun(t)
+	defer r.Finalise()
+	opt, cleanup := newTestOptions(t)
+	defer cleanup()
+
+	file1 := r.WriteFile("one", "one", t1)
+	r.Mkdir(r.Fremote)
+	opt.Resync = true
+
+	fs.Config.DryRun = true
+	err := runBisync(r, opt)
+	fs.Config.DryRun = false
+	require.NoError(t, err)
+	fstest.CheckItems(t, r.Fremote)
+
+	// The listings shouldn't have been written
+	opt.Resync = false
+	err = runBisync(r, opt)
+	require.Error(t, err)
+
+	opt.Resync = true
+	require.NoError(t, runBisync(r, opt))
+	fstest.CheckItems(t, r.Fremote, file1)
+}
+
+func TestConflictResolveSet(t *testing.T) {
+	var x ConflictResolve
+	for _, want := range []ConflictResolve{ConflictResolveRename, ConflictResolveNewer, ConflictResolvePath1} {
+		require.NoError(t, x.Set(want.String()))
+		assert.Equal(t, want, x)
+	}
+	assert.Error(t, x.Set("potato"))
+}
@@ -0,0 +1,93 @@
+package bisync
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"time"
+
+	"github.com/ncw/rclone/fs"
+	"github.com/pkg/errors"
+)
+
+// fileInfo is the state of a file as recorded in a listing
+type fileInfo struct {
+	Size    int64
+	ModTime time.Time
+}
+
+// listing records the state of the files on one path after a
+// successful bisync, indexed by remote
+type listing map[string]fileInfo
+
+// newListing makes a listing from the objects passed in
+func newListing(objects map[string]fs.Object) listing {
+	l := make(listing, len(objects))
+	for remote, o := range objects {
+		l[remote] = fileInfo{
+			Size:    o.Size(),
+			ModTime: o.ModTime(),
+		}
+	}
+	return l
+}
+
+// changed returns true if o differs from the state recorded in info
+func (info fileInfo) changed(o fs.Object) bool {
+	if info.Size != o.Size() {
+		return true
+	}
+	dt := info.ModTime.Sub(o.ModTime())
+	return dt >= fs.Config.ModifyWindow || dt <= -fs.Config.ModifyWindow
+}
+
+// loadListing reads a listing from path
+//
+// If the file doesn't exist it returns an error satisfying os.IsNotExist
+func loadListing(path string) (l listing, err error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	err = json.Unmarshal(data, &l)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to parse listing %q", path)
+	}
+	return l, nil
+}
+
+// save writes the listing to path atomically
+func (l listing) save(path string) error {
+	data, err := json.Marshal(l)
+	if err != nil {
+		return errors.Wrap(err, "failed to make listing")
+	}
+	err = os.MkdirAll(filepath.Dir(path), 0700)
+	if err != nil {
+		return errors.Wrap(err, "failed to make directory for listing")
+	}
+	tmpPath := path + ".tmp"
+	err = ioutil.WriteFile(tmpPath, data, 0600)
+	if err != nil {
+		return errors.Wrapf(err, "failed to write listing %q", tmpPath)
+	}
+	err = os.Rename(tmpPath, path)
+	if err != nil {
+		return errors.Wrapf(err, "failed to rename listing to %q", path)
+	}
+	return nil
+}
+
+// Characters not allowed in the name of the state files
+var unsafeChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// sessionName makes a name for the state files of a bisync between
+// fs1 and fs2 which is safe to use as a file name
+func sessionName(fs1, fs2 fs.Fs) string {
+	canonical := func(f fs.Fs) string {
+		return unsafeChars.ReplaceAllString(f.Name()+"_"+f.Root(), "_")
+	}
+	return canonical(fs1) + ".." + canonical(fs2)
+}
@@ -9,10 +9,58 @@ import (
 	"github.com/pkg/errors"
 )
 
+// BwPair represents an upload and a download bandwidth
+type BwPair struct {
+	Tx SizeSuffix // upload bandwidth
+	Rx SizeSuffix // download bandwidth
+}
+
+// String returns a printable representation of a BwPair
+func (bp *BwPair) String() string {
+	if bp.Tx == bp.Rx {
+		return bp.Tx.String()
+	}
+	return bp.Tx.String() + ":" + bp.Rx.String()
+}
+
+// Set the bandwidth from a string which is either a single bandwidth
+// used for both upload and download or upload:download
+func (bp *BwPair) Set(s string) (err error) {
+	colon := strings.Index(s, ":")
+	stx, srx := s, ""
+	if colon >= 0 {
+		stx, srx = s[:colon], s[colon+1:]
+	}
+	err = bp.Tx.Set(stx)
+	if err != nil {
+		return err
+	}
+	if colon < 0 {
+		bp.Rx = bp.Tx
+	} else {
+		err = bp.Rx.Set(srx)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// IsSet returns true if either of the bandwidth limits are set
+func (bp *BwPair) IsSet() bool {
+	return bp.Tx > 0 || bp.Rx > 0
+}
+
 // BwTimeSlot represents a bandwidth configuration at a point in time.
 type BwTimeSlot struct {
-	HHMM      int
-	Bandwidth SizeSuffix
+	DayOfTheWeek int // 0 is Sunday as in time.Weekday
+	HHMM         int
+	Bandwidth    BwPair
+}
+
+// minutes returns the number of minutes since the start of the week
+func (ts BwTimeSlot) minutes() int {
+	return (ts.DayOfTheWeek*24+ts.HHMM/100)*60 + ts.HHMM%100
 }
 
 // BwTimetable contains all configured time slots.
@@ -20,18 +68,56 @@ type BwTimetable []BwTimeSlot
 
 // String returns a printable representation of BwTimetable.
 func (x BwTimetable) String() string {
+	if len(x) == 1 && x[0].DayOfTheWeek == 0 && x[0].HHMM == 0 {
+		return x[0].Bandwidth.String()
+	}
 	ret := []string{}
 	for _, ts := range x {
-		ret = append(ret, fmt.Sprintf("%04.4d,%s", ts.HHMM, ts.Bandwidth.String()))
+		ret = append(ret, fmt.Sprintf("%s-%02d:%02d,%s", time.Weekday(ts.DayOfTheWeek).String()[:3], ts.HHMM/100, ts.HHMM%100, ts.Bandwidth.String()))
 	}
 	return strings.Join(ret, " ")
 }
 
+// parseWeekday parses a day of the week as a three letter
+// abbreviation or a full name
+func parseWeekday(dayOfWeek string) (int, error) {
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		name := day.String()
+		if strings.EqualFold(dayOfWeek, name) || strings.EqualFold(dayOfWeek, name[:3]) {
+			return int(day), nil
+		}
+	}
+	return 0, errors.Errorf("invalid day of the week %q", dayOfWeek)
+}
+
+// parseHHMM parses a time of day as HH:MM returning it as HHMM
+func parseHHMM(HHMM string) (int, error) {
+	if len(HHMM) != 5 {
+		return 0, errors.Errorf("invalid time specification (hh:mm): %q", HHMM)
+	}
+	hh, err := strconv.Atoi(HHMM[0:2])
+	if err != nil {
+		return 0, errors.Errorf("invalid hour in time specification %q: %v", HHMM, err)
+	}
+	if hh < 0 || hh > 23 {
+		return 0, errors.Errorf("invalid hour (must be between 00 and 23): %d", hh)
+	}
+	mm, err := strconv.Atoi(HHMM[3:])
+	if err != nil {
+		return 0, errors.Errorf("invalid minute in time specification: %q: %v", HHMM, err)
+	}
+	if mm < 0 || mm > 59 {
+		return 0, errors.Errorf("invalid minute (must be between 00 and 59): %d", mm)
+	}
+	return hh*100 + mm, nil
+}
+
 // Set the bandwidth timetable.
 func (x *BwTimetable) Set(s string) error {
 	// The timetable is formatted as:
-	// "hh:mm,bandwidth hh:mm,banwidth..." ex: "10:00,10G 11:30,1G 18:00,off"
+	// "[Day-]hh:mm,bandwidth [Day-]hh:mm,banwidth..." ex: "Mon-10:00,10G 11:30,1G 18:00,off"
 	// If only a single bandwidth identifier is provided, we assume constant bandwidth.
+	// Each bandwidth can be upload:download, ex: "512k:10M"
 
 	if len(s) == 0 {
 		return errors.New("empty string")
@@ -50,39 +136,42 @@ func (x *BwTimetable) Set(s string) error {
 	for _, tok := range strings.Split(s, " ") {
 		tv := strings.Split(tok, ",")
 
-		// Format must be HH:MM,BW
+		// Format must be [Day-]HH:MM,BW
 		if len(tv) != 2 {
 			return errors.Errorf("invalid time/bandwidth specification: %q", tok)
 		}
 
-		// Basic timespec sanity checking
+		// Find the day of the week if set
+		day := -1
 		HHMM := tv[0]
-		if len(HHMM) != 5 {
-			return errors.Errorf("invalid time specification (hh:mm): %q", HHMM)
-		}
-		hh, err := strconv.Atoi(HHMM[0:2])
-		if err != nil {
-			return errors.Errorf("invalid hour in time specification %q: %v", HHMM, err)
+		if dash := strings.Index(HHMM, "-"); dash >= 0 {
+			var err error
+			day, err = parseWeekday(HHMM[:dash])
+			if err != nil {
+				return err
+			}
+			HHMM = HHMM[dash+1:]
 		}
-		if hh < 0 || hh > 23 {
-			return errors.Errorf("invalid hour (must be between 00 and 23): %q", hh)
-		}
-		mm, err := strconv.Atoi(HHMM[3:])
+
+		hhmm, err := parseHHMM(HHMM)
 		if err != nil {
-			return errors.Errorf("invalid minute in time specification: %q: %v", HHMM, err)
-		}
-		if mm < 0 || mm > 59 {
-			return errors.Errorf("invalid minute (must be between 00 and 59): %q", hh)
+			return err
 		}
 
-		ts := BwTimeSlot{
-			HHMM: (hh * 100) + mm,
-		}
 		// Bandwidth limit for this time slot.
-		if err := ts.Bandwidth.Set(tv[1]); err != nil {
+		var bandwidth BwPair
+		if err := bandwidth.Set(tv[1]); err != nil {
 			return err
 		}
-		*x = append(*x, ts)
+
+		if day >= 0 {
+			*x = append(*x, BwTimeSlot{DayOfTheWeek: day, HHMM: hhmm, Bandwidth: bandwidth})
+		} else {
+			// Without a day the slot applies to every day
+			for day := 0; day < 7; day++ {
+				*x = append(*x, BwTimeSlot{DayOfTheWeek: day, HHMM: hhmm, Bandwidth: bandwidth})
+			}
+		}
 	}
 	return nil
 }
@@ -91,38 +180,37 @@ func (x *BwTimetable) Set(s string) error {
 func (x BwTimetable) LimitAt(tt time.Time) BwTimeSlot {
 	// If the timetable is empty, we return an unlimited BwTimeSlot starting at midnight.
 	if len(x) == 0 {
-		return BwTimeSlot{HHMM: 0, Bandwidth: -1}
+		return BwTimeSlot{HHMM: 0, Bandwidth: BwPair{Tx: -1, Rx: -1}}
 	}
 
-	HHMM := tt.Hour()*100 + tt.Minute()
-
-	// By default, we return the last element in the timetable. This
-	// satisfies two conditions: 1) If there's only one element it
-	// will always be selected, and 2) The last element of the table
-	// will "wrap around" until overriden by an earlier time slot.
-	// there's only one time slot in the timetable.
-	ret := x[len(x)-1]
-
-	mindif := 0
-	first := true
+	// If there is only one element it is always selected
+	if len(x) == 1 {
+		return x[0]
+	}
 
-	// Look for most recent time slot.
+	now := BwTimeSlot{
+		DayOfTheWeek: int(tt.Weekday()),
+		HHMM:         tt.Hour()*100 + tt.Minute(),
+	}.minutes()
+
+	// Look for the most recent time slot this week.  If there
+	// isn't one then the last time slot of the week wraps around
+	// from the previous week.  For slots at the same time the
+	// last one given wins.
+	var ret, last BwTimeSlot
+	retMinutes, lastMinutes := -1, -1
 	for _, ts := range x {
-		// Ignore the past
-		if HHMM < ts.HHMM {
-			continue
-		}
-		dif := ((HHMM / 100 * 60) + (HHMM % 100)) - ((ts.HHMM / 100 * 60) + (ts.HHMM % 100))
-		if first {
-			mindif = dif
-			first = false
+		minutes := ts.minutes()
+		if minutes <= now && minutes >= retMinutes {
+			ret, retMinutes = ts, minutes
 		}
-		if dif <= mindif {
-			mindif = dif
-			ret = ts
+		if minutes >= lastMinutes {
+			last, lastMinutes = ts, minutes
 		}
 	}
-
+	if retMinutes < 0 {
+		return last
+	}
 	return ret
 }
 
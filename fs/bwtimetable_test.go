@@ -12,6 +12,48 @@ import (
 // Check it satisfies the interface
 var _ pflag.Value = (*BwTimetable)(nil)
 
+// everyDay makes a BwTimeSlot for each day of the week
+func everyDay(HHMM int, bandwidth BwPair) (slots BwTimetable) {
+	for day := 0; day < 7; day++ {
+		slots = append(slots, BwTimeSlot{DayOfTheWeek: day, HHMM: HHMM, Bandwidth: bandwidth})
+	}
+	return slots
+}
+
+// bw makes a BwPair with the same upload and download bandwidth
+func bw(bandwidth SizeSuffix) BwPair {
+	return BwPair{Tx: bandwidth, Rx: bandwidth}
+}
+
+func TestBwPairSet(t *testing.T) {
+	for _, test := range []struct {
+		in   string
+		want BwPair
+		err  bool
+	}{
+		{"666", BwPair{Tx: 666 * 1024, Rx: 666 * 1024}, false},
+		{"off", BwPair{Tx: -1, Rx: -1}, false},
+		{"512k:10M", BwPair{Tx: 512 * 1024, Rx: 10 * 1024 * 1024}, false},
+		{"off:1M", BwPair{Tx: -1, Rx: 1024 * 1024}, false},
+		{"1X", BwPair{}, true},
+		{"1M:1X", BwPair{}, true},
+		{"1M:1M:1M", BwPair{}, true},
+	} {
+		var bp BwPair
+		err := bp.Set(test.in)
+		if test.err {
+			require.Error(t, err, test.in)
+			continue
+		}
+		require.NoError(t, err, test.in)
+		assert.Equal(t, test.want, bp, test.in)
+	}
+	assert.Equal(t, "1M", (&BwPair{Tx: 1024 * 1024, Rx: 1024 * 1024}).String())
+	assert.Equal(t, "512k:off", (&BwPair{Tx: 512 * 1024, Rx: -1}).String())
+	assert.True(t, (&BwPair{Tx: -1, Rx: 1}).IsSet())
+	assert.False(t, (&BwPair{Tx: -1, Rx: -1}).IsSet())
+}
+
 func TestBwTimetableSet(t *testing.T) {
 	for _, test := range []struct {
 		in   string
@@ -19,25 +61,40 @@ func TestBwTimetableSet(t *testing.T) {
 		err  bool
 	}{
 		{"", BwTimetable{}, true},
-		{"0", BwTimetable{BwTimeSlot{HHMM: 0, Bandwidth: 0}}, false},
-		{"666", BwTimetable{BwTimeSlot{HHMM: 0, Bandwidth: 666 * 1024}}, false},
-		{"10:20,666", BwTimetable{BwTimeSlot{HHMM: 1020, Bandwidth: 666 * 1024}}, false},
+		{"0", BwTimetable{BwTimeSlot{HHMM: 0, Bandwidth: bw(0)}}, false},
+		{"666", BwTimetable{BwTimeSlot{HHMM: 0, Bandwidth: bw(666 * 1024)}}, false},
+		{"10M:off", BwTimetable{BwTimeSlot{HHMM: 0, Bandwidth: BwPair{Tx: 10 * 1024 * 1024, Rx: -1}}}, false},
+		{"10:20,666", everyDay(1020, bw(666*1024)), false},
 		{
 			"11:00,333 13:40,666 23:50,10M 23:59,off",
+			append(append(append(
+				everyDay(1100, bw(333*1024)),
+				everyDay(1340, bw(666*1024))...),
+				everyDay(2350, bw(10*1024*1024))...),
+				everyDay(2359, bw(-1))...),
+			false,
+		},
+		{
+			"Mon-08:00,512k:10M Sat-00:00,off",
 			BwTimetable{
-				BwTimeSlot{HHMM: 1100, Bandwidth: 333 * 1024},
-				BwTimeSlot{HHMM: 1340, Bandwidth: 666 * 1024},
-				BwTimeSlot{HHMM: 2350, Bandwidth: 10 * 1024 * 1024},
-				BwTimeSlot{HHMM: 2359, Bandwidth: -1},
+				BwTimeSlot{DayOfTheWeek: 1, HHMM: 800, Bandwidth: BwPair{Tx: 512 * 1024, Rx: 10 * 1024 * 1024}},
+				BwTimeSlot{DayOfTheWeek: 6, HHMM: 0, Bandwidth: bw(-1)},
 			},
 			false,
 		},
+		{
+			"sunday-23:00,1M",
+			BwTimetable{BwTimeSlot{DayOfTheWeek: 0, HHMM: 2300, Bandwidth: bw(1024 * 1024)}},
+			false,
+		},
 		{"bad,bad", BwTimetable{}, true},
 		{"bad bad", BwTimetable{}, true},
 		{"bad", BwTimetable{}, true},
 		{"1000X", BwTimetable{}, true},
 		{"2401,666", BwTimetable{}, true},
 		{"1061,666", BwTimetable{}, true},
+		{"Potato-10:00,666", BwTimetable{}, true},
+		{"Mon-10:00,666:1X", BwTimetable{}, true},
 	} {
 		tt := BwTimetable{}
 		err := tt.Set(test.in)
@@ -50,7 +107,52 @@ func TestBwTimetableSet(t *testing.T) {
 	}
 }
 
+func TestParseHHMM(t *testing.T) {
+	for _, test := range []struct {
+		in      string
+		want    int
+		wantErr string
+	}{
+		{"00:00", 0, ""},
+		{"23:59", 2359, ""},
+		{"24:00", 0, "invalid hour (must be between 00 and 23): 24"},
+		{"10:61", 0, "invalid minute (must be between 00 and 59): 61"},
+		{"1000", 0, `invalid time specification (hh:mm): "1000"`},
+	} {
+		got, err := parseHHMM(test.in)
+		if test.wantErr != "" {
+			require.Error(t, err, test.in)
+			assert.Equal(t, test.wantErr, err.Error(), test.in)
+		} else {
+			require.NoError(t, err, test.in)
+		}
+		assert.Equal(t, test.want, got, test.in)
+	}
+}
+
+func TestBwTimetableString(t *testing.T) {
+	for _, in := range []string{
+		"666k",
+		"512k:10M",
+		"Mon-08:00,512k:10M Sat-00:00,off",
+	} {
+		tt := BwTimetable{}
+		require.NoError(t, tt.Set(in))
+		assert.Equal(t, in, tt.String())
+	}
+}
+
 func TestBwTimetableLimitAt(t *testing.T) {
+	daily := append(append(append(
+		everyDay(1100, bw(333*1024)),
+		everyDay(1300, bw(666*1024))...),
+		everyDay(2301, bw(1024*1024))...),
+		everyDay(2350, bw(-1))...)
+	weekly := BwTimetable{
+		BwTimeSlot{DayOfTheWeek: 1, HHMM: 800, Bandwidth: BwPair{Tx: 512 * 1024, Rx: 10 * 1024 * 1024}},
+		BwTimeSlot{DayOfTheWeek: 6, HHMM: 0, Bandwidth: bw(-1)},
+	}
+	// 2017-04-20 is a Thursday
 	for _, test := range []struct {
 		tt   BwTimetable
 		now  time.Time
@@ -59,55 +161,62 @@ func TestBwTimetableLimitAt(t *testing.T) {
 		{
 			BwTimetable{},
 			time.Date(2017, time.April, 20, 15, 0, 0, 0, time.UTC),
-			BwTimeSlot{HHMM: 0, Bandwidth: -1},
+			BwTimeSlot{HHMM: 0, Bandwidth: bw(-1)},
 		},
 		{
-			BwTimetable{BwTimeSlot{HHMM: 1100, Bandwidth: 333 * 1024}},
+			BwTimetable{BwTimeSlot{HHMM: 1100, Bandwidth: bw(333 * 1024)}},
 			time.Date(2017, time.April, 20, 15, 0, 0, 0, time.UTC),
-			BwTimeSlot{HHMM: 1100, Bandwidth: 333 * 1024},
+			BwTimeSlot{HHMM: 1100, Bandwidth: bw(333 * 1024)},
 		},
 		{
-			BwTimetable{
-				BwTimeSlot{HHMM: 1100, Bandwidth: 333 * 1024},
-				BwTimeSlot{HHMM: 1300, Bandwidth: 666 * 1024},
-				BwTimeSlot{HHMM: 2301, Bandwidth: 1024 * 1024},
-				BwTimeSlot{HHMM: 2350, Bandwidth: -1},
-			},
+			daily,
 			time.Date(2017, time.April, 20, 10, 15, 0, 0, time.UTC),
-			BwTimeSlot{HHMM: 2350, Bandwidth: -1},
+			BwTimeSlot{DayOfTheWeek: 3, HHMM: 2350, Bandwidth: bw(-1)},
 		},
 		{
-			BwTimetable{
-				BwTimeSlot{HHMM: 1100, Bandwidth: 333 * 1024},
-				BwTimeSlot{HHMM: 1300, Bandwidth: 666 * 1024},
-				BwTimeSlot{HHMM: 2301, Bandwidth: 1024 * 1024},
-				BwTimeSlot{HHMM: 2350, Bandwidth: -1},
-			},
+			daily,
 			time.Date(2017, time.April, 20, 11, 0, 0, 0, time.UTC),
-			BwTimeSlot{HHMM: 1100, Bandwidth: 333 * 1024},
+			BwTimeSlot{DayOfTheWeek: 4, HHMM: 1100, Bandwidth: bw(333 * 1024)},
 		},
 		{
-			BwTimetable{
-				BwTimeSlot{HHMM: 1100, Bandwidth: 333 * 1024},
-				BwTimeSlot{HHMM: 1300, Bandwidth: 666 * 1024},
-				BwTimeSlot{HHMM: 2301, Bandwidth: 1024 * 1024},
-				BwTimeSlot{HHMM: 2350, Bandwidth: -1},
-			},
+			daily,
 			time.Date(2017, time.April, 20, 13, 1, 0, 0, time.UTC),
-			BwTimeSlot{HHMM: 1300, Bandwidth: 666 * 1024},
+			BwTimeSlot{DayOfTheWeek: 4, HHMM: 1300, Bandwidth: bw(666 * 1024)},
 		},
 		{
-			BwTimetable{
-				BwTimeSlot{HHMM: 1100, Bandwidth: 333 * 1024},
-				BwTimeSlot{HHMM: 1300, Bandwidth: 666 * 1024},
-				BwTimeSlot{HHMM: 2301, Bandwidth: 1024 * 1024},
-				BwTimeSlot{HHMM: 2350, Bandwidth: -1},
-			},
+			daily,
 			time.Date(2017, time.April, 20, 23, 59, 0, 0, time.UTC),
-			BwTimeSlot{HHMM: 2350, Bandwidth: -1},
+			BwTimeSlot{DayOfTheWeek: 4, HHMM: 2350, Bandwidth: bw(-1)},
+		},
+		{
+			// Sunday 10:15 wraps around to Saturday's slot
+			daily,
+			time.Date(2017, time.April, 23, 10, 15, 0, 0, time.UTC),
+			BwTimeSlot{DayOfTheWeek: 6, HHMM: 2350, Bandwidth: bw(-1)},
+		},
+		{
+			weekly,
+			time.Date(2017, time.April, 20, 15, 0, 0, 0, time.UTC),
+			weekly[0],
+		},
+		{
+			// Monday 07:59 is still the weekend
+			weekly,
+			time.Date(2017, time.April, 24, 7, 59, 0, 0, time.UTC),
+			weekly[1],
+		},
+		{
+			weekly,
+			time.Date(2017, time.April, 24, 8, 0, 0, 0, time.UTC),
+			weekly[0],
+		},
+		{
+			weekly,
+			time.Date(2017, time.April, 22, 0, 0, 0, 0, time.UTC),
+			weekly[1],
 		},
 	} {
 		slot := test.tt.LimitAt(test.now)
-		assert.Equal(t, test.want, slot)
+		assert.Equal(t, test.want, slot, test.now.String())
 	}
 }
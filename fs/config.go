@@ -14,7 +14,16 @@ var (
 	//
 	// This is a function pointer to decouple the config
 	// implementation from the fs
-	ConfigFileGet = func(section, key string, defaultVal ...string) string { return "" }
+	ConfigFileGet = func(section, key string) (string, bool) { return "", false }
+
+	// Set a value into the config file and persist it
+	//
+	// This is a function pointer to decouple the config
+	// implementation from the fs
+	ConfigFileSet = func(section, key, value string) (err error) {
+		Errorf(nil, "No config handler to set %q in section %q of the config file", key, section)
+		return nil
+	}
 
 	// CountError counts an error.  If any errors have been
 	// counted then it will exit with a non zero error code.
@@ -26,47 +35,69 @@ var (
 
 // ConfigInfo is filesystem config options
 type ConfigInfo struct {
-	LogLevel              LogLevel
-	StatsLogLevel         LogLevel
-	DryRun                bool
-	CheckSum              bool
-	SizeOnly              bool
-	IgnoreTimes           bool
-	IgnoreExisting        bool
-	IgnoreErrors          bool
-	ModifyWindow          time.Duration
-	Checkers              int
-	Transfers             int
-	ConnectTimeout        time.Duration // Connect timeout
-	Timeout               time.Duration // Data channel timeout
-	Dump                  DumpFlags
-	InsecureSkipVerify    bool // Skip server certificate verification
-	DeleteMode            DeleteMode
-	MaxDelete             int64
-	TrackRenames          bool // Track file renames.
-	LowLevelRetries       int
-	UpdateOlder           bool // Skip files that are newer on the destination
-	NoGzip                bool // Disable compression
-	MaxDepth              int
-	IgnoreSize            bool
-	IgnoreChecksum        bool
-	NoUpdateModTime       bool
-	DataRateUnit          string
-	BackupDir             string
-	Suffix                string
-	UseListR              bool
-	BufferSize            SizeSuffix
-	BwLimit               BwTimetable
-	TPSLimit              float64
-	TPSLimitBurst         int
-	BindAddr              net.IP
-	DisableFeatures       []string
-	UserAgent             string
-	Immutable             bool
-	AutoConfirm           bool
-	StreamingUploadCutoff SizeSuffix
-	StatsFileNameLength   int
-	AskPassword           bool
+	LogLevel                LogLevel
+	StatsLogLevel           LogLevel
+	DryRun                  bool
+	CheckSum                bool
+	SizeOnly                bool
+	IgnoreTimes             bool
+	IgnoreExisting          bool
+	IgnoreErrors            bool
+	ModifyWindow            time.Duration
+	Checkers                int
+	Transfers               int
+	ConnectTimeout          time.Duration // Connect timeout
+	Timeout                 time.Duration // Data channel timeout
+	Dump                    DumpFlags
+	InsecureSkipVerify      bool // Skip server certificate verification
+	DeleteMode              DeleteMode
+	MaxDelete               int64
+	TrackRenames            bool   // Track file renames.
+	TrackRenamesStrategy    string // Comma separated list of ways to match renamed files
+	LowLevelRetries         int
+	UpdateOlder             bool // Skip files that are newer on the destination
+	NoGzip                  bool // Disable compression
+	MaxDepth                int
+	IgnoreSize              bool
+	IgnoreChecksum          bool
+	NoUpdateModTime         bool
+	DataRateUnit            string
+	BackupDir               string
+	Suffix                  string
+	CompareDest             []string // Reference directories to skip identical files found in
+	CopyDest                []string // Reference directories to server side copy identical files from
+	UseListR                bool
+	BufferSize              SizeSuffix
+	BwLimit                 BwTimetable
+	TPSLimit                float64
+	TPSLimitBurst           int
+	BindAddr                net.IP
+	DisableFeatures         []string
+	UserAgent               string
+	Immutable               bool
+	AutoConfirm             bool
+	StreamingUploadCutoff   SizeSuffix
+	StatsFileNameLength     int
+	AskPassword             bool
+	Metadata                bool // Preserve object metadata on copy
+	MultiThreadCutoff       SizeSuffix
+	MultiThreadStreams      int
+	ServerSideAcrossConfigs bool // Allow server side operations between differently named remotes
+	MaxTransfer             SizeSuffix
+	MaxDuration             time.Duration
+	CutoffMode              CutoffMode
+	MaxBacklog              int
+	CheckFirst              bool
+	OrderBy                 string
+	PlanOut                 string
+	ListCutoff              int
+	BackupVersions          bool
+	BackupKeepLast          int
+	BackupKeepDaily         int
+	BackupKeepWeekly        int
+	BackupKeepMonthly       int
+	BackupMaxAge            Duration
+	UseJSONLog              bool // Log as a JSON object per line
 }
 
 // NewConfig creates a new config with everything set to the default
@@ -87,6 +118,7 @@ func NewConfig() *ConfigInfo {
 	c.MaxDelete = -1
 	c.LowLevelRetries = 10
 	c.MaxDepth = -1
+	c.TrackRenamesStrategy = "hash"
 	c.DataRateUnit = "bytes"
 	c.BufferSize = SizeSuffix(16 << 20)
 	c.UserAgent = "rclone/" + Version
@@ -94,6 +126,13 @@ func NewConfig() *ConfigInfo {
 	c.StatsFileNameLength = 40
 	c.AskPassword = true
 	c.TPSLimitBurst = 1
+	c.MultiThreadCutoff = SizeSuffix(250 * 1024 * 1024)
+	c.MultiThreadStreams = 4
+	c.MaxTransfer = -1
+	c.CutoffMode = CutoffModeDefault
+	c.MaxBacklog = 10000
+	c.ListCutoff = 1000000
+	c.BackupMaxAge = DurationOff
 
 	return c
 }
@@ -77,8 +77,9 @@ var (
 )
 
 func init() {
-	// Set the function pointer up in fs
-	fs.ConfigFileGet = FileGet
+	// Set the function pointers up in fs
+	fs.ConfigFileGet = FileGetFlag
+	fs.ConfigFileSet = SetValueAndSave
 }
 
 func getConfigData() *goconfig.ConfigFile {
@@ -475,7 +476,7 @@ func SetValueAndSave(name, key, value string) (err error) {
 	_, err = reloadedConfigFile.GetSection(name)
 	if err != nil {
 		// Section doesn't exist yet so ignore reload
-		return err
+		return nil
 	}
 	// Update the config file with the reloaded version
 	configFile = reloadedConfigFile
@@ -679,7 +680,8 @@ func RemoteConfig(name string) {
 	fmt.Printf("Remote config\n")
 	f := MustFindByName(name)
 	if f.Config != nil {
-		f.Config(name)
+		m := fs.ConfigMap(f, name, nil)
+		f.Config(name, m)
 	}
 }
 
@@ -840,6 +842,9 @@ func NewRemote(name string) {
 	getConfigData().SetValue(name, "type", newType)
 	fs := fs.MustFind(newType)
 	for _, option := range fs.Options {
+		if option.Advanced {
+			continue
+		}
 		getConfigData().SetValue(name, option.Name, ChooseOption(&option))
 	}
 	RemoteConfig(name)
@@ -856,6 +861,9 @@ func EditRemote(fs *fs.RegInfo, name string) {
 	fmt.Printf("Edit remote\n")
 	for {
 		for _, option := range fs.Options {
+			if option.Advanced {
+				continue
+			}
 			key := option.Name
 			value := FileGet(name, key)
 			fmt.Printf("Value %q = %q\n", key, value)
@@ -1019,8 +1027,8 @@ func Authorize(args []string) {
 		log.Fatalf("Invalid number of arguments: %d", len(args))
 	}
 	newType := args[0]
-	fs := fs.MustFind(newType)
-	if fs.Config == nil {
+	f := fs.MustFind(newType)
+	if f.Config == nil {
 		log.Fatalf("Can't authorize fs %q", newType)
 	}
 	// Name used for temporary fs
@@ -1035,14 +1043,8 @@ func Authorize(args []string) {
 		getConfigData().SetValue(name, ConfigClientID, args[1])
 		getConfigData().SetValue(name, ConfigClientSecret, args[2])
 	}
-	fs.Config(name)
-}
-
-// configToEnv converts an config section and name, eg ("myremote",
-// "ignore-size") into an environment name
-// "RCLONE_CONFIG_MYREMOTE_IGNORE_SIZE"
-func configToEnv(section, name string) string {
-	return "RCLONE_CONFIG_" + strings.ToUpper(strings.Replace(section+"_"+name, "-", "_", -1))
+	m := fs.ConfigMap(f, name, nil)
+	f.Config(name, m)
 }
 
 // FileGet gets the config key under section returning the
@@ -1050,7 +1052,7 @@ func configToEnv(section, name string) string {
 //
 // It looks up defaults in the environment if they are present
 func FileGet(section, key string, defaultVal ...string) string {
-	envKey := configToEnv(section, key)
+	envKey := fs.ConfigToEnv(section, key)
 	newValue, found := os.LookupEnv(envKey)
 	if found {
 		defaultVal = []string{newValue}
@@ -1058,12 +1060,21 @@ func FileGet(section, key string, defaultVal ...string) string {
 	return getConfigData().MustValue(section, key, defaultVal...)
 }
 
+// FileGetFlag gets the config key under section returning the value
+// and true if found, or ("", false) otherwise
+//
+// It doesn't look up defaults in the environment
+func FileGetFlag(section, key string) (string, bool) {
+	newValue, err := getConfigData().GetValue(section, key)
+	return newValue, err == nil
+}
+
 // FileGetBool gets the config key under section returning the
 // default or false if not set.
 //
 // It looks up defaults in the environment if they are present
 func FileGetBool(section, key string, defaultVal ...bool) bool {
-	envKey := configToEnv(section, key)
+	envKey := fs.ConfigToEnv(section, key)
 	newValue, found := os.LookupEnv(envKey)
 	if found {
 		newBool, err := strconv.ParseBool(newValue)
@@ -1081,7 +1092,7 @@ func FileGetBool(section, key string, defaultVal ...bool) bool {
 //
 // It looks up defaults in the environment if they are present
 func FileGetInt(section, key string, defaultVal ...int) int {
-	envKey := configToEnv(section, key)
+	envKey := fs.ConfigToEnv(section, key)
 	newValue, found := os.LookupEnv(envKey)
 	if found {
 		newInt, err := strconv.Atoi(newValue)
@@ -45,13 +45,27 @@ func TestCRUD(t *testing.T) {
 	assert.Equal(t, []string{}, getConfigData().GetSectionList())
 
 	// Fake a remote
-	fs.Register(&fs.RegInfo{Name: "config_test_remote"})
+	fs.Register(&fs.RegInfo{
+		Name: "config_test_remote",
+		Options: fs.Options{
+			{
+				Name:    "bool",
+				Default: false,
+			},
+			{
+				Name:     "advanced",
+				Default:  "potato",
+				Advanced: true,
+			},
+		},
+	})
 
 	// add new remote
 	i := 0
 	ReadLine = func() string {
 		answers := []string{
 			"config_test_remote", // type
+			"true",               // bool value
 			"y",                  // looks good, save
 		}
 		i = i + 1
@@ -60,6 +74,9 @@ func TestCRUD(t *testing.T) {
 
 	NewRemote("test")
 	assert.Equal(t, []string{"test"}, configFile.GetSectionList())
+	assert.Equal(t, "true", FileGet("test", "bool"))
+	_, found := FileGetFlag("test", "advanced")
+	assert.False(t, found, "advanced options shouldn't be asked for")
 
 	// Reload the config file to workaround this bug
 	// https://github.com/Unknwon/goconfig/issues/39
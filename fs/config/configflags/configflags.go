@@ -56,6 +56,7 @@ func AddFlags(flagSet *pflag.FlagSet) {
 	flags.BoolVarP(flagSet, &deleteAfter, "delete-after", "", false, "When synchronizing, delete files on destination after transfering")
 	flags.IntVar64P(flagSet, &fs.Config.MaxDelete, "max-delete", "", -1, "When synchronizing, limit the number of deletes")
 	flags.BoolVarP(flagSet, &fs.Config.TrackRenames, "track-renames", "", fs.Config.TrackRenames, "When synchronizing, track file renames and do a server side move if possible")
+	flags.StringVarP(flagSet, &fs.Config.TrackRenamesStrategy, "track-renames-strategy", "", fs.Config.TrackRenamesStrategy, "Strategies to use when synchronizing using track-renames hash|modtime|leaf|size")
 	flags.IntVarP(flagSet, &fs.Config.LowLevelRetries, "low-level-retries", "", fs.Config.LowLevelRetries, "Number of low level retries to do.")
 	flags.BoolVarP(flagSet, &fs.Config.UpdateOlder, "update", "u", fs.Config.UpdateOlder, "Skip files that are newer on the destination.")
 	flags.BoolVarP(flagSet, &fs.Config.NoGzip, "no-gzip-encoding", "", fs.Config.NoGzip, "Don't set Accept-Encoding: gzip.")
@@ -66,6 +67,14 @@ func AddFlags(flagSet *pflag.FlagSet) {
 	flags.BoolVarP(flagSet, &fs.Config.NoUpdateModTime, "no-update-modtime", "", fs.Config.NoUpdateModTime, "Don't update destination mod-time if files identical.")
 	flags.StringVarP(flagSet, &fs.Config.BackupDir, "backup-dir", "", fs.Config.BackupDir, "Make backups into hierarchy based in DIR.")
 	flags.StringVarP(flagSet, &fs.Config.Suffix, "suffix", "", fs.Config.Suffix, "Suffix for use with --backup-dir.")
+	flags.BoolVarP(flagSet, &fs.Config.BackupVersions, "backup-versions", "", fs.Config.BackupVersions, "Add a timestamp to files moved into --backup-dir to keep every version.")
+	flags.IntVarP(flagSet, &fs.Config.BackupKeepLast, "backup-keep-last", "", fs.Config.BackupKeepLast, "Keep the last N versions of each file in --backup-dir.")
+	flags.IntVarP(flagSet, &fs.Config.BackupKeepDaily, "backup-keep-daily", "", fs.Config.BackupKeepDaily, "Keep the newest version of each file in --backup-dir for the last N days.")
+	flags.IntVarP(flagSet, &fs.Config.BackupKeepWeekly, "backup-keep-weekly", "", fs.Config.BackupKeepWeekly, "Keep the newest version of each file in --backup-dir for the last N weeks.")
+	flags.IntVarP(flagSet, &fs.Config.BackupKeepMonthly, "backup-keep-monthly", "", fs.Config.BackupKeepMonthly, "Keep the newest version of each file in --backup-dir for the last N months.")
+	flags.FVarP(flagSet, &fs.Config.BackupMaxAge, "backup-max-age", "", "Keep versions in --backup-dir newer than this in s or suffix ms|s|m|h|d|w|M|y")
+	flags.StringArrayVarP(flagSet, &fs.Config.CompareDest, "compare-dest", "", nil, "Skip files identical to those in this directory on the destination remote (may be repeated).")
+	flags.StringArrayVarP(flagSet, &fs.Config.CopyDest, "copy-dest", "", nil, "Server side copy files identical to those in this directory on the destination remote (may be repeated).")
 	flags.BoolVarP(flagSet, &fs.Config.UseListR, "fast-list", "", fs.Config.UseListR, "Use recursive list if available. Uses more memory but fewer transactions.")
 	flags.Float64VarP(flagSet, &fs.Config.TPSLimit, "tpslimit", "", fs.Config.TPSLimit, "Limit HTTP transactions per second to this.")
 	flags.IntVarP(flagSet, &fs.Config.TPSLimitBurst, "tpslimit-burst", "", fs.Config.TPSLimitBurst, "Max burst of transactions for --tpslimit.")
@@ -75,12 +84,25 @@ func AddFlags(flagSet *pflag.FlagSet) {
 	flags.BoolVarP(flagSet, &fs.Config.Immutable, "immutable", "", fs.Config.Immutable, "Do not modify files. Fail if existing files have been modified.")
 	flags.BoolVarP(flagSet, &fs.Config.AutoConfirm, "auto-confirm", "", fs.Config.AutoConfirm, "If enabled, do not request console confirmation.")
 	flags.IntVarP(flagSet, &fs.Config.StatsFileNameLength, "stats-file-name-length", "", fs.Config.StatsFileNameLength, "Max file name length in stats. 0 for no limit")
+	flags.BoolVarP(flagSet, &fs.Config.Metadata, "metadata", "", fs.Config.Metadata, "If set, preserve metadata when copying objects.")
+	flags.BoolVarP(flagSet, &fs.Config.ServerSideAcrossConfigs, "server-side-across-configs", "", fs.Config.ServerSideAcrossConfigs, "Allow server side operations (eg copy) to work across different configs.")
 	flags.FVarP(flagSet, &fs.Config.LogLevel, "log-level", "", "Log level DEBUG|INFO|NOTICE|ERROR")
 	flags.FVarP(flagSet, &fs.Config.StatsLogLevel, "stats-log-level", "", "Log level to show --stats output DEBUG|INFO|NOTICE|ERROR")
-	flags.FVarP(flagSet, &fs.Config.BwLimit, "bwlimit", "", "Bandwidth limit in kBytes/s, or use suffix b|k|M|G or a full timetable.")
+	flags.BoolVarP(flagSet, &fs.Config.UseJSONLog, "use-json-log", "", fs.Config.UseJSONLog, "Log each message as a JSON object.")
+	flags.FVarP(flagSet, &fs.Config.BwLimit, "bwlimit", "", "Bandwidth limit in kBytes/s, or use suffix b|k|M|G, UPLOAD:DOWNLOAD or a full timetable.")
 	flags.FVarP(flagSet, &fs.Config.BufferSize, "buffer-size", "", "Buffer size when copying files.")
 	flags.FVarP(flagSet, &fs.Config.StreamingUploadCutoff, "streaming-upload-cutoff", "", "Cutoff for switching to chunked upload if file size is unknown. Upload starts after reaching cutoff or when file ends.")
 	flags.FVarP(flagSet, &fs.Config.Dump, "dump", "", "List of items to dump from: "+fs.DumpFlagsList)
+	flags.FVarP(flagSet, &fs.Config.MultiThreadCutoff, "multi-thread-cutoff", "", "Use multi-thread downloads for files above this size.")
+	flags.IntVarP(flagSet, &fs.Config.MultiThreadStreams, "multi-thread-streams", "", fs.Config.MultiThreadStreams, "Max number of streams to use for multi-thread downloads.")
+	flags.FVarP(flagSet, &fs.Config.MaxTransfer, "max-transfer", "", "Maximum size of data to transfer.")
+	flags.DurationVarP(flagSet, &fs.Config.MaxDuration, "max-duration", "", fs.Config.MaxDuration, "Maximum duration rclone will transfer data for.")
+	flags.FVarP(flagSet, &fs.Config.CutoffMode, "cutoff-mode", "", "Mode to stop transfers when reaching the max transfer limit HARD|SOFT|CAUTIOUS")
+	flags.IntVarP(flagSet, &fs.Config.MaxBacklog, "max-backlog", "", fs.Config.MaxBacklog, "Maximum number of objects in sync/copy/move backlog.")
+	flags.BoolVarP(flagSet, &fs.Config.CheckFirst, "check-first", "", fs.Config.CheckFirst, "Do all the checks before starting transfers.")
+	flags.StringVarP(flagSet, &fs.Config.OrderBy, "order-by", "", fs.Config.OrderBy, "Instructions on how to order the transfers, eg 'size,descending'")
+	flags.IntVarP(flagSet, &fs.Config.ListCutoff, "list-cutoff", "", fs.Config.ListCutoff, "To save memory, sort directory listings on disk above this threshold.")
+	flags.StringVarP(flagSet, &fs.Config.PlanOut, "plan-out", "", fs.Config.PlanOut, "Write the actions a sync, copy or move would take to this file instead of doing them.")
 
 }
 
@@ -142,6 +164,22 @@ func SetFlags() {
 		log.Fatalf(`Can only use --suffix with --backup-dir.`)
 	}
 
+	if fs.Config.Suffix != "" && fs.Config.BackupVersions {
+		log.Fatalf(`Can't use --suffix with --backup-versions.`)
+	}
+
+	if fs.Config.BackupVersions && fs.Config.BackupDir == "" {
+		log.Fatalf(`Can only use --backup-versions with --backup-dir.`)
+	}
+
+	if (fs.Config.BackupKeepLast > 0 || fs.Config.BackupKeepDaily > 0 || fs.Config.BackupKeepWeekly > 0 || fs.Config.BackupKeepMonthly > 0 || fs.Config.BackupMaxAge != fs.DurationOff) && !fs.Config.BackupVersions {
+		log.Fatalf(`Can only use --backup-keep-* and --backup-max-age with --backup-versions.`)
+	}
+
+	if len(fs.Config.CompareDest) > 0 && len(fs.Config.CopyDest) > 0 {
+		log.Fatalf(`Can't use --compare-dest with --copy-dest.`)
+	}
+
 	if bindAddr != "" {
 		addrs, err := net.LookupIP(bindAddr)
 		if err != nil {
@@ -0,0 +1,49 @@
+package fs
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// CutoffMode describes what happens when --max-transfer or
+// --max-duration is reached
+type CutoffMode byte
+
+// CutoffMode constants
+const (
+	CutoffModeHard     CutoffMode = iota // stop immediately cancelling transfers
+	CutoffModeSoft                       // don't start any more transfers
+	CutoffModeCautious                   // don't start transfers which would go over the limit
+	CutoffModeDefault  = CutoffModeHard
+)
+
+var cutoffModeToString = []string{
+	CutoffModeHard:     "hard",
+	CutoffModeSoft:     "soft",
+	CutoffModeCautious: "cautious",
+}
+
+// String turns a CutoffMode into a string
+func (m CutoffMode) String() string {
+	if int(m) >= len(cutoffModeToString) {
+		return "unknown"
+	}
+	return cutoffModeToString[m]
+}
+
+// Set a CutoffMode
+func (m *CutoffMode) Set(s string) error {
+	for n, name := range cutoffModeToString {
+		if strings.ToLower(s) == name {
+			*m = CutoffMode(n)
+			return nil
+		}
+	}
+	return errors.Errorf("unknown cutoff mode %q", s)
+}
+
+// Type of the value
+func (m *CutoffMode) Type() string {
+	return "string"
+}
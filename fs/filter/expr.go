@@ -0,0 +1,566 @@
+// Filter expressions (see --filter-expr)
+
+package filter
+
+import (
+	"context"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/ncw/rclone/fs"
+	"github.com/pkg/errors"
+)
+
+// exprKind is the type of a value in a filter expression
+type exprKind int
+
+// Types of value in a filter expression
+const (
+	kindString   exprKind = iota
+	kindSize              // int64 bytes
+	kindTime              // time.Time
+	kindDuration          // time.Duration
+	kindNumber            // literal whose type depends on what it is compared with
+)
+
+// String turns an exprKind into a string
+func (k exprKind) String() string {
+	switch k {
+	case kindString:
+		return "string"
+	case kindSize:
+		return "size"
+	case kindTime:
+		return "time"
+	case kindDuration:
+		return "duration"
+	case kindNumber:
+		return "number"
+	}
+	return "unknown"
+}
+
+// exprEnv is the file a filter expression is evaluated against
+type exprEnv struct {
+	ctx          context.Context
+	now          time.Time // time the expression was parsed
+	remote       string
+	size         int64
+	modTime      time.Time
+	haveModTime  bool
+	o            fs.Object // the object if known, may be nil
+	metadata     fs.Metadata
+	haveMetadata bool
+	err          error // first error reading from the object
+}
+
+// getModTime returns the modification time, reading it from the
+// object if necessary
+func (e *exprEnv) getModTime() time.Time {
+	if !e.haveModTime {
+		if e.o != nil {
+			e.modTime = e.o.ModTime()
+		}
+		e.haveModTime = true
+	}
+	return e.modTime
+}
+
+// getMimeType returns the MIME type of the object, or a guess from
+// the name if there isn't an object
+func (e *exprEnv) getMimeType() string {
+	if e.o != nil {
+		return fs.MimeType(e.o)
+	}
+	return fs.MimeTypeFromName(e.remote)
+}
+
+// getMetadata returns the metadata item key, or "" if not found
+func (e *exprEnv) getMetadata(key string) string {
+	if !e.haveMetadata {
+		if e.o != nil {
+			metadata, err := fs.GetMetadata(e.ctx, e.o)
+			if err != nil && e.err == nil {
+				e.err = errors.Wrap(err, "failed to read metadata")
+			}
+			e.metadata = metadata
+		}
+		e.haveMetadata = true
+	}
+	return e.metadata[key]
+}
+
+// exprGetter reads a value from the exprEnv
+type exprGetter func(e *exprEnv) interface{}
+
+// exprFields are the fields which can be used in filter expressions
+//
+// documented in filtering.md
+var exprFields = map[string]struct {
+	kind exprKind
+	get  exprGetter
+}{
+	"path": {kindString, func(e *exprEnv) interface{} {
+		return e.remote
+	}},
+	"name": {kindString, func(e *exprEnv) interface{} {
+		return path.Base(e.remote)
+	}},
+	"dir": {kindString, func(e *exprEnv) interface{} {
+		return parentDir(e.remote)
+	}},
+	"ext": {kindString, func(e *exprEnv) interface{} {
+		return path.Ext(e.remote)
+	}},
+	"mime": {kindString, func(e *exprEnv) interface{} {
+		return e.getMimeType()
+	}},
+	"size": {kindSize, func(e *exprEnv) interface{} {
+		return e.size
+	}},
+	"modtime": {kindTime, func(e *exprEnv) interface{} {
+		return e.getModTime()
+	}},
+	"age": {kindDuration, func(e *exprEnv) interface{} {
+		return e.now.Sub(e.getModTime())
+	}},
+}
+
+// exprTimeFormats are the formats times may be written in
+var exprTimeFormats = []string{
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+}
+
+// exprOperand is one side of a comparison
+type exprOperand struct {
+	kind  exprKind
+	text  string     // the field name or the literal as written
+	get   exprGetter // nil for a literal
+	value string     // value of a literal
+}
+
+// convert the literal o to kind, with times relative to now
+func (o *exprOperand) convert(kind exprKind, now time.Time) (interface{}, error) {
+	switch kind {
+	case kindString:
+		return o.value, nil
+	case kindSize:
+		if size, err := strconv.ParseInt(o.value, 10, 64); err == nil {
+			return size, nil
+		}
+		var size fs.SizeSuffix
+		if err := size.Set(o.value); err != nil {
+			return nil, errors.Wrapf(err, "bad size %s", o.text)
+		}
+		return int64(size), nil
+	case kindDuration:
+		d, err := fs.ParseDuration(o.value)
+		if err != nil {
+			return nil, errors.Wrapf(err, "bad duration %s", o.text)
+		}
+		return d, nil
+	case kindTime:
+		if o.kind == kindString {
+			if t, err := time.Parse(time.RFC3339, o.value); err == nil {
+				return t, nil
+			}
+			for _, format := range exprTimeFormats {
+				if t, err := time.ParseInLocation(format, o.value, time.Local); err == nil {
+					return t, nil
+				}
+			}
+		}
+		// Durations are relative to now, so -30d is 30 days ago
+		d, err := fs.ParseDuration(o.value)
+		if err != nil {
+			return nil, errors.Errorf("bad time %s", o.text)
+		}
+		return now.Add(d), nil
+	}
+	return nil, errors.Errorf("can't use %s as a %v", o.text, kind)
+}
+
+// exprCompare returns -1, 0 or 1 as a is less than, equal to or
+// greater than b which are both of kind
+func exprCompare(kind exprKind, a, b interface{}) int {
+	switch kind {
+	case kindString:
+		return strings.Compare(a.(string), b.(string))
+	case kindSize:
+		x, y := a.(int64), b.(int64)
+		if x < y {
+			return -1
+		} else if x > y {
+			return 1
+		}
+	case kindDuration:
+		x, y := a.(time.Duration), b.(time.Duration)
+		if x < y {
+			return -1
+		} else if x > y {
+			return 1
+		}
+	case kindTime:
+		x, y := a.(time.Time), b.(time.Time)
+		if x.Before(y) {
+			return -1
+		} else if x.After(y) {
+			return 1
+		}
+	}
+	return 0
+}
+
+// exprFunc evaluates part of a filter expression
+type exprFunc func(e *exprEnv) bool
+
+// expr is a parsed filter expression
+type expr struct {
+	text  string
+	now   time.Time
+	match exprFunc
+}
+
+// exprToken is a lexical token in a filter expression
+type exprToken struct {
+	kind exprKind // kind of literal for tokString and tokNumber
+	tok  int
+	text string
+}
+
+// Types of token
+const (
+	tokEOF = iota
+	tokIdent
+	tokString
+	tokNumber
+	tokOp
+)
+
+// exprOps are the operators, longest first
+var exprOps = []string{"&&", "||", "==", "!=", "<=", ">=", "=~", "!~", "!", "<", ">", "(", ")", "[", "]"}
+
+// lexExpr splits text into tokens
+func lexExpr(text string) (tokens []exprToken, err error) {
+	isIdent := func(c byte) bool {
+		return c == '_' || unicode.IsLetter(rune(c)) || unicode.IsDigit(rune(c))
+	}
+	isDigit := func(i int) bool {
+		return i < len(text) && text[i] >= '0' && text[i] <= '9'
+	}
+	for i := 0; i < len(text); {
+		c := text[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case isDigit(i) || ((c == '-' || c == '+' || c == '.') && isDigit(i+1)):
+			j := i + 1
+			for j < len(text) && (isIdent(text[j]) || text[j] == '.') {
+				j++
+			}
+			tokens = append(tokens, exprToken{tok: tokNumber, kind: kindNumber, text: text[i:j]})
+			i = j
+		case c == '_' || unicode.IsLetter(rune(c)):
+			j := i + 1
+			for j < len(text) && isIdent(text[j]) {
+				j++
+			}
+			tokens = append(tokens, exprToken{tok: tokIdent, text: text[i:j]})
+			i = j
+		case c == '"' || c == '\'':
+			j := i + 1
+			for j < len(text) && text[j] != c {
+				if c == '"' && text[j] == '\\' {
+					j++
+				}
+				j++
+			}
+			if j >= len(text) {
+				return nil, errors.Errorf("unterminated string at %q", text[i:])
+			}
+			tokens = append(tokens, exprToken{tok: tokString, kind: kindString, text: text[i : j+1]})
+			i = j + 1
+		default:
+			found := false
+			for _, op := range exprOps {
+				if strings.HasPrefix(text[i:], op) {
+					tokens = append(tokens, exprToken{tok: tokOp, text: op})
+					i += len(op)
+					found = true
+					break
+				}
+			}
+			if !found {
+				return nil, errors.Errorf("unexpected %q", text[i:])
+			}
+		}
+	}
+	return append(tokens, exprToken{tok: tokEOF, text: "end of expression"}), nil
+}
+
+// exprParser parses a filter expression
+//
+// The grammar is
+//
+//	or         = and { "||" and }
+//	and        = not { "&&" not }
+//	not        = "!" not | "(" or ")" | comparison
+//	comparison = operand op operand
+//	operand    = field | "meta" "[" string "]" | string | number
+type exprParser struct {
+	tokens []exprToken
+	i      int
+	now    time.Time
+}
+
+// peek returns the next token
+func (p *exprParser) peek() exprToken {
+	return p.tokens[p.i]
+}
+
+// next returns the next token and advances past it
+func (p *exprParser) next() exprToken {
+	tok := p.tokens[p.i]
+	if tok.tok != tokEOF {
+		p.i++
+	}
+	return tok
+}
+
+// isOp returns true if the next token is the operator op
+func (p *exprParser) isOp(op string) bool {
+	tok := p.peek()
+	return tok.tok == tokOp && tok.text == op
+}
+
+// expect reads the operator op or returns an error
+func (p *exprParser) expect(op string) error {
+	if !p.isOp(op) {
+		return errors.Errorf("expecting %q but found %q", op, p.peek().text)
+	}
+	p.next()
+	return nil
+}
+
+func (p *exprParser) parseOr() (exprFunc, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.isOp("||") {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		a, b := left, right
+		left = func(e *exprEnv) bool { return a(e) || b(e) }
+	}
+	return left, nil
+}
+
+func (p *exprParser) parseAnd() (exprFunc, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for p.isOp("&&") {
+		p.next()
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		a, b := left, right
+		left = func(e *exprEnv) bool { return a(e) && b(e) }
+	}
+	return left, nil
+}
+
+func (p *exprParser) parseNot() (exprFunc, error) {
+	if p.isOp("!") {
+		p.next()
+		a, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return func(e *exprEnv) bool { return !a(e) }, nil
+	}
+	if p.isOp("(") {
+		p.next()
+		a, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		return a, p.expect(")")
+	}
+	return p.parseComparison()
+}
+
+// unquote the string token tok
+func unquote(tok exprToken) (string, error) {
+	if tok.text[0] == '\'' {
+		return tok.text[1 : len(tok.text)-1], nil
+	}
+	s, err := strconv.Unquote(tok.text)
+	if err != nil {
+		return "", errors.Errorf("bad string %s", tok.text)
+	}
+	return s, nil
+}
+
+func (p *exprParser) parseOperand() (*exprOperand, error) {
+	tok := p.next()
+	switch tok.tok {
+	case tokString:
+		value, err := unquote(tok)
+		if err != nil {
+			return nil, err
+		}
+		return &exprOperand{kind: kindString, text: tok.text, value: value}, nil
+	case tokNumber:
+		return &exprOperand{kind: kindNumber, text: tok.text, value: tok.text}, nil
+	case tokIdent:
+		if tok.text == "meta" {
+			if err := p.expect("["); err != nil {
+				return nil, err
+			}
+			keyTok := p.next()
+			if keyTok.tok != tokString {
+				return nil, errors.Errorf("expecting metadata key string but found %q", keyTok.text)
+			}
+			key, err := unquote(keyTok)
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect("]"); err != nil {
+				return nil, err
+			}
+			return &exprOperand{
+				kind: kindString,
+				text: "meta[" + keyTok.text + "]",
+				get: func(e *exprEnv) interface{} {
+					return e.getMetadata(key)
+				},
+			}, nil
+		}
+		field, found := exprFields[tok.text]
+		if !found {
+			return nil, errors.Errorf("unknown field %q", tok.text)
+		}
+		return &exprOperand{kind: field.kind, text: tok.text, get: field.get}, nil
+	}
+	return nil, errors.Errorf("expecting field or value but found %q", tok.text)
+}
+
+// exprReverse is the operator to use if the operands are swapped
+var exprReverse = map[string]string{
+	"==": "==",
+	"!=": "!=",
+	"<":  ">",
+	"<=": ">=",
+	">":  "<",
+	">=": "<=",
+}
+
+func (p *exprParser) parseComparison() (exprFunc, error) {
+	left, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+	opTok := p.next()
+	op := opTok.text
+	_, isComparison := exprReverse[op]
+	isMatch := op == "=~" || op == "!~"
+	if opTok.tok != tokOp || !(isComparison || isMatch) {
+		return nil, errors.Errorf("expecting comparison after %s but found %q", left.text, op)
+	}
+	right, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+	// Put the field on the left
+	if left.get == nil {
+		if right.get == nil {
+			return nil, errors.Errorf("%s %s %s doesn't use a field", left.text, op, right.text)
+		}
+		if isMatch {
+			return nil, errors.Errorf("the pattern must be on the right of %s", op)
+		}
+		left, right, op = right, left, exprReverse[op]
+	}
+	kind := left.kind
+	if isMatch {
+		if kind != kindString || right.get != nil {
+			return nil, errors.Errorf("%s needs a string field and a pattern", op)
+		}
+		re, err := globToRegexp(right.value)
+		if err != nil {
+			return nil, err
+		}
+		want := op == "=~"
+		get := left.get
+		return func(e *exprEnv) bool {
+			return re.MatchString(get(e).(string)) == want
+		}, nil
+	}
+	getRight := right.get
+	if getRight == nil {
+		value, err := right.convert(kind, p.now)
+		if err != nil {
+			return nil, err
+		}
+		getRight = func(e *exprEnv) interface{} { return value }
+	} else if right.kind != kind {
+		return nil, errors.Errorf("can't compare %s (%v) with %s (%v)", left.text, kind, right.text, right.kind)
+	}
+	getLeft := left.get
+	var test func(c int) bool
+	switch op {
+	case "==":
+		test = func(c int) bool { return c == 0 }
+	case "!=":
+		test = func(c int) bool { return c != 0 }
+	case "<":
+		test = func(c int) bool { return c < 0 }
+	case "<=":
+		test = func(c int) bool { return c <= 0 }
+	case ">":
+		test = func(c int) bool { return c > 0 }
+	case ">=":
+		test = func(c int) bool { return c >= 0 }
+	}
+	return func(e *exprEnv) bool {
+		return test(exprCompare(kind, getLeft(e), getRight(e)))
+	}, nil
+}
+
+// newExpr parses text into a filter expression
+//
+// documented in filtering.md
+func newExpr(text string) (*expr, error) {
+	tokens, err := lexExpr(text)
+	if err != nil {
+		return nil, errors.Wrapf(err, "bad filter expression %q", text)
+	}
+	p := &exprParser{
+		tokens: tokens,
+		now:    time.Now(),
+	}
+	match, err := p.parseOr()
+	if err == nil && p.peek().tok != tokEOF {
+		err = errors.Errorf("unexpected %q", p.peek().text)
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "bad filter expression %q", text)
+	}
+	return &expr{
+		text:  text,
+		now:   p.now,
+		match: match,
+	}, nil
+}
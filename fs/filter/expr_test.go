@@ -0,0 +1,146 @@
+package filter
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ncw/rclone/fs"
+	"github.com/ncw/rclone/fstest/mockobject"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLexExpr(t *testing.T) {
+	tokens, err := lexExpr(`size>=1.5G && !(name =~ "a \"b\"" || meta['x']!=-30d)`)
+	require.NoError(t, err)
+	var got []string
+	for _, tok := range tokens {
+		got = append(got, tok.text)
+	}
+	assert.Equal(t, []string{
+		"size", ">=", "1.5G", "&&", "!", "(", "name", "=~", `"a \"b\""`, "||",
+		"meta", "[", "'x'", "]", "!=", "-30d", ")", "end of expression",
+	}, got)
+
+	for _, bad := range []string{`name == "potato`, `size = 1`, `size > 1 ; true`} {
+		_, err := lexExpr(bad)
+		assert.Error(t, err, bad)
+	}
+}
+
+func TestNewExprErrors(t *testing.T) {
+	for _, test := range []struct {
+		in   string
+		want string
+	}{
+		{``, `expecting field or value but found "end of expression"`},
+		{`potato == 1`, `unknown field "potato"`},
+		{`size`, `expecting comparison after size but found "end of expression"`},
+		{`size > 1 size`, `unexpected "size"`},
+		{`(size > 1`, `expecting ")" but found "end of expression"`},
+		{`1 == 2`, `1 == 2 doesn't use a field`},
+		{`"*.jpg" =~ name`, `the pattern must be on the right of =~`},
+		{`size =~ "*.jpg"`, `=~ needs a string field and a pattern`},
+		{`name =~ path`, `=~ needs a string field and a pattern`},
+		{`size > name`, `can't compare size (size) with name (string)`},
+		{`size > 10X`, `bad size 10X: bad suffix 'X'`},
+		{`age > "yesterday"`, `bad duration "yesterday"`},
+		{`modtime > "yesterday"`, `bad time "yesterday"`},
+		{`meta[x] == ""`, `expecting metadata key string but found "x"`},
+		{`name =~ "[a"`, `mismatched '[' and ']' in glob "[a"`},
+	} {
+		_, err := newExpr(test.in)
+		require.Error(t, err, test.in)
+		assert.Contains(t, err.Error(), test.want, test.in)
+	}
+}
+
+func TestNewFilterExpr(t *testing.T) {
+	f, err := NewFilter(nil)
+	require.NoError(t, err)
+	assert.True(t, f.InActive())
+
+	opt := DefaultOpt
+	opt.FilterExpr = []string{
+		`size > 100 && (name =~ "*.jpg" || modtime < "2015-08-19T16:00:02Z")`,
+		`path !~ "/secret/**" && ext != ".tmp"`,
+	}
+	f, err = NewFilter(&opt)
+	require.NoError(t, err)
+	assert.False(t, f.InActive())
+	testInclude(t, f, []includeTest{
+		{"file1.jpg", 100, 1440000000, false},
+		{"file2.jpg", 101, 1440000004, true},
+		{"file3.png", 101, 1440000004, false},
+		{"file4.png", 101, 1440000001, true},
+		{"file5.tmp", 101, 1440000001, false},
+		{"dir/file6.jpg", 1000, 1440000004, true},
+		{"secret/file7.jpg", 1000, 1440000004, false},
+		{"dir/secret/file8.jpg", 1000, 1440000004, true},
+	})
+	assert.Contains(t, f.DumpFilters(), "--- Filter expressions ---\n"+opt.FilterExpr[0])
+}
+
+func TestExprFields(t *testing.T) {
+	x, err := newExpr(`age > 1h && age <= 2h && modtime > -2h && dir == "a/b" && name == "c.html" && ext == ".html" && mime == "text/html; charset=utf-8" && size == 1k`)
+	require.NoError(t, err)
+	e := &exprEnv{
+		ctx:         context.Background(),
+		now:         x.now,
+		remote:      "a/b/c.html",
+		size:        1024,
+		modTime:     x.now.Add(-90 * time.Minute),
+		haveModTime: true,
+	}
+	assert.True(t, x.match(e))
+	e.modTime = x.now.Add(-3 * time.Hour)
+	assert.False(t, x.match(e))
+	assert.NoError(t, e.err)
+}
+
+// exprObject is an fs.Object with some metadata
+type exprObject struct {
+	mockobject.Object
+	size     int64
+	mimeType string
+	metadata fs.Metadata
+	err      error
+}
+
+// Size returns the size of the file
+func (o exprObject) Size() int64 { return o.size }
+
+// MimeType returns the content type of the Object
+func (o exprObject) MimeType() string { return o.mimeType }
+
+// Metadata returns metadata for an object
+func (o exprObject) Metadata(ctx context.Context) (fs.Metadata, error) {
+	return o.metadata, o.err
+}
+
+func TestFilterExprIncludeObject(t *testing.T) {
+	opt := DefaultOpt
+	opt.FilterExpr = []string{`mime =~ "image/*" || meta["content-language"] == "en"`}
+	f, err := NewFilter(&opt)
+	require.NoError(t, err)
+	for _, test := range []struct {
+		o    exprObject
+		want bool
+	}{
+		{exprObject{Object: "photo.bin", mimeType: "image/png"}, true},
+		{exprObject{Object: "photo.png", mimeType: "application/octet-stream"}, false},
+		{exprObject{Object: "photo.png"}, true},
+		{exprObject{Object: "doc.html", metadata: fs.Metadata{"content-language": "en"}}, true},
+		{exprObject{Object: "doc.html", metadata: fs.Metadata{"content-language": "fr"}}, false},
+		{exprObject{Object: "doc.html"}, false},
+		// errors reading metadata exclude the object
+		{exprObject{Object: "doc.html", err: errors.New("boom")}, false},
+	} {
+		assert.Equal(t, test.want, f.IncludeObject(context.Background(), test.o), test.o.Remote())
+	}
+	// without an object the mime type is read from the name
+	assert.True(t, f.Include("photo.png", 0, time.Now()))
+	assert.False(t, f.Include("photo.bin", 0, time.Now()))
+}
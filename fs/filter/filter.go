@@ -3,12 +3,14 @@ package filter
 
 import (
 	"bufio"
+	"context"
 	"fmt"
 	"log"
 	"os"
 	"path"
 	"regexp"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/ncw/rclone/fs"
@@ -77,27 +79,32 @@ type FilesMap map[string]struct{}
 
 // Opt configues the filter
 type Opt struct {
-	DeleteExcluded bool
-	FilterRule     []string
-	FilterFrom     []string
-	ExcludeRule    []string
-	ExcludeFrom    []string
-	ExcludeFile    string
-	IncludeRule    []string
-	IncludeFrom    []string
-	FilesFrom      []string
-	MinAge         fs.Duration
-	MaxAge         fs.Duration
-	MinSize        fs.SizeSuffix
-	MaxSize        fs.SizeSuffix
+	DeleteExcluded      bool
+	FilterRule          []string
+	FilterFrom          []string
+	ExcludeRule         []string
+	ExcludeFrom         []string
+	ExcludeFile         string
+	IgnoreFile          string
+	IgnoreFileCacheTime fs.Duration
+	IncludeRule         []string
+	IncludeFrom         []string
+	FilesFrom           []string
+	FilesFromRaw        []string
+	FilterExpr          []string
+	MinAge              fs.Duration
+	MaxAge              fs.Duration
+	MinSize             fs.SizeSuffix
+	MaxSize             fs.SizeSuffix
 }
 
 // DefaultOpt is the default config for the filter
 var DefaultOpt = Opt{
-	MinAge:  fs.DurationOff,
-	MaxAge:  fs.DurationOff,
-	MinSize: fs.SizeSuffix(-1),
-	MaxSize: fs.SizeSuffix(-1),
+	IgnoreFileCacheTime: fs.Duration(5 * time.Minute),
+	MinAge:              fs.DurationOff,
+	MaxAge:              fs.DurationOff,
+	MinSize:             fs.SizeSuffix(-1),
+	MaxSize:             fs.SizeSuffix(-1),
 }
 
 // Filter describes any filtering in operation
@@ -107,8 +114,10 @@ type Filter struct {
 	ModTimeTo   time.Time
 	fileRules   rules
 	dirRules    rules
-	files       FilesMap // files if filesFrom
-	dirs        FilesMap // dirs from filesFrom
+	files       FilesMap    // files if filesFrom
+	dirs        FilesMap    // dirs from filesFrom
+	ignore      ignoreCache // filter files read if IgnoreFile
+	exprs       []*expr     // parsed FilterExpr
 }
 
 // NewFilter parses the command line options and creates a Filter
@@ -147,7 +156,7 @@ func NewFilter(opt *Opt) (f *Filter, err error) {
 		addImplicitExclude = true
 	}
 	for _, rule := range f.Opt.IncludeFrom {
-		err := forEachLine(rule, func(line string) error {
+		err := forEachLine(rule, false, func(line string) error {
 			return f.Add(true, line)
 		})
 		if err != nil {
@@ -163,7 +172,7 @@ func NewFilter(opt *Opt) (f *Filter, err error) {
 		foundExcludeRule = true
 	}
 	for _, rule := range f.Opt.ExcludeFrom {
-		err := forEachLine(rule, func(line string) error {
+		err := forEachLine(rule, false, func(line string) error {
 			return f.Add(false, line)
 		})
 		if err != nil {
@@ -183,20 +192,36 @@ func NewFilter(opt *Opt) (f *Filter, err error) {
 		}
 	}
 	for _, rule := range f.Opt.FilterFrom {
-		err := forEachLine(rule, f.AddRule)
+		err := forEachLine(rule, false, f.AddRule)
 		if err != nil {
 			return nil, err
 		}
 	}
 	for _, rule := range f.Opt.FilesFrom {
 		f.initAddFile() // init to show --files-from set even if no files within
-		err := forEachLine(rule, func(line string) error {
+		err := forEachLine(rule, false, func(line string) error {
 			return f.AddFile(line)
 		})
 		if err != nil {
 			return nil, err
 		}
 	}
+	for _, rule := range f.Opt.FilesFromRaw {
+		f.initAddFile() // init to show --files-from-raw set even if no files within
+		err := forEachLine(rule, true, func(line string) error {
+			return f.AddFile(line)
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	for _, text := range f.Opt.FilterExpr {
+		x, err := newExpr(text)
+		if err != nil {
+			return nil, err
+		}
+		f.exprs = append(f.exprs, x)
+	}
 	if addImplicitExclude {
 		err = f.Add(false, "/**")
 		if err != nil {
@@ -323,6 +348,85 @@ func (f *Filter) Files() FilesMap {
 	return f.files
 }
 
+// HaveFilesFrom returns true if --files-from or --files-from-raw
+// has been supplied
+func (f *Filter) HaveFilesFrom() bool {
+	return f.files != nil
+}
+
+var errFilesFromNotSet = errors.New("--files-from not set so can't use Filter.ListR")
+
+// MakeListR makes a function to return all the files in the
+// --files-from list which are in dir by looking each one up with
+// NewObject, rather than listing the directories they are in.
+//
+// The lookups are done in parallel, --checkers at a time. Files in
+// the list which don't exist are skipped.
+func (f *Filter) MakeListR(NewObject func(ctx context.Context, remote string) (fs.Object, error)) fs.ListRFn {
+	return func(ctx context.Context, dir string, callback fs.ListRCallback) error {
+		if !f.HaveFilesFrom() {
+			return errFilesFromNotSet
+		}
+		prefix := ""
+		if dir != "" {
+			prefix = dir + "/"
+		}
+		checkers := fs.Config.Checkers
+		if checkers < 1 {
+			checkers = 1
+		}
+		var (
+			remotes = make(chan string, checkers)
+			wg      sync.WaitGroup
+			mu      sync.Mutex // protects listErr and calls to callback
+			listErr error
+		)
+		failed := func() bool {
+			mu.Lock()
+			defer mu.Unlock()
+			return listErr != nil
+		}
+		for i := 0; i < checkers; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				// Keep reading remotes after an error so the
+				// sender doesn't block
+				for remote := range remotes {
+					if failed() {
+						continue
+					}
+					o, err := NewObject(ctx, remote)
+					switch err {
+					case nil:
+					case fs.ErrorObjectNotFound, fs.ErrorNotAFile, fs.ErrorDirNotFound:
+						fs.Debugf(remote, "Not found so skipping file from --files-from")
+						continue
+					default:
+						err = errors.Wrapf(err, "failed to find %q from --files-from", remote)
+					}
+					mu.Lock()
+					if listErr == nil {
+						if err == nil {
+							err = callback(fs.DirEntries{o})
+						}
+						listErr = err
+					}
+					mu.Unlock()
+				}
+			}()
+		}
+		for remote := range f.files {
+			if strings.HasPrefix(remote, prefix) {
+				remotes <- remote
+			}
+		}
+		close(remotes)
+		wg.Wait()
+		return listErr
+	}
+}
+
 // Clear clears all the filter rules
 func (f *Filter) Clear() {
 	f.fileRules.clear()
@@ -338,7 +442,9 @@ func (f *Filter) InActive() bool {
 		f.Opt.MaxSize < 0 &&
 		f.fileRules.len() == 0 &&
 		f.dirRules.len() == 0 &&
-		len(f.Opt.ExcludeFile) == 0)
+		len(f.Opt.ExcludeFile) == 0 &&
+		len(f.Opt.IgnoreFile) == 0 &&
+		len(f.exprs) == 0)
 }
 
 // includeRemote returns whether this remote passes the filter rules.
@@ -370,12 +476,12 @@ func (f *Filter) ListContainsExcludeFile(entries fs.DirEntries) bool {
 
 // IncludeDirectory returns a function which checks whether this
 // directory should be included in the sync or not.
-func (f *Filter) IncludeDirectory(fs fs.Fs) func(string) (bool, error) {
+func (f *Filter) IncludeDirectory(ctx context.Context, fs fs.Fs) func(string) (bool, error) {
 	return func(remote string) (bool, error) {
 		remote = strings.Trim(remote, "/")
 		// first check if we need to remove directory based on
 		// the exclude file
-		excl, err := f.DirContainsExcludeFile(fs, remote)
+		excl, err := f.DirContainsExcludeFile(ctx, fs, remote)
 		if err != nil {
 			return false, err
 		}
@@ -388,23 +494,32 @@ func (f *Filter) IncludeDirectory(fs fs.Fs) func(string) (bool, error) {
 			_, include := f.dirs[remote]
 			return include, nil
 		}
-		remote += "/"
+		include := true
 		for _, rule := range f.dirRules.rules {
-			if rule.Match(remote) {
-				return rule.Include, nil
+			if rule.Match(remote + "/") {
+				include = rule.Include
+				break
 			}
 		}
 
-		return true, nil
+		// then check the per-directory filter files
+		if include && len(f.Opt.IgnoreFile) > 0 {
+			ignored, err := f.ignored(ctx, fs, remote, true)
+			if err != nil {
+				return false, err
+			}
+			include = !ignored
+		}
+		return include, nil
 	}
 }
 
 // DirContainsExcludeFile checks if exclude file is present in a
 // directroy. If fs is nil, it works properly if ExcludeFile is an
 // empty string (for testing).
-func (f *Filter) DirContainsExcludeFile(fremote fs.Fs, remote string) (bool, error) {
+func (f *Filter) DirContainsExcludeFile(ctx context.Context, fremote fs.Fs, remote string) (bool, error) {
 	if len(f.Opt.ExcludeFile) > 0 {
-		exists, err := fs.FileExists(fremote, path.Join(remote, f.Opt.ExcludeFile))
+		exists, err := fs.FileExists(ctx, fremote, path.Join(remote, f.Opt.ExcludeFile))
 		if err != nil {
 			return false, err
 		}
@@ -418,6 +533,21 @@ func (f *Filter) DirContainsExcludeFile(fremote fs.Fs, remote string) (bool, err
 // Include returns whether this object should be included into the
 // sync or not
 func (f *Filter) Include(remote string, size int64, modTime time.Time) bool {
+	if !f.include(remote, size, modTime) {
+		return false
+	}
+	return f.includeExpr(&exprEnv{
+		ctx:         context.Background(),
+		remote:      remote,
+		size:        size,
+		modTime:     modTime,
+		haveModTime: true,
+	})
+}
+
+// include checks the object against everything but the filter
+// expressions
+func (f *Filter) include(remote string, size int64, modTime time.Time) bool {
 	// filesFrom takes precedence
 	if f.files != nil {
 		_, include := f.files[remote]
@@ -441,22 +571,85 @@ func (f *Filter) Include(remote string, size int64, modTime time.Time) bool {
 // IncludeObject returns whether this object should be included into
 // the sync or not. This is a convenience function to avoid calling
 // o.ModTime(), which is an expensive operation.
-func (f *Filter) IncludeObject(o fs.Object) bool {
+//
+// ctx is used for reading the metadata of the object if required.
+func (f *Filter) IncludeObject(ctx context.Context, o fs.Object) bool {
 	var modTime time.Time
+	haveModTime := !f.ModTimeFrom.IsZero() || !f.ModTimeTo.IsZero()
 
-	if !f.ModTimeFrom.IsZero() || !f.ModTimeTo.IsZero() {
+	if haveModTime {
 		modTime = o.ModTime()
 	} else {
 		modTime = time.Unix(0, 0)
 	}
 
-	return f.Include(o.Remote(), o.Size(), modTime)
+	if !f.include(o.Remote(), o.Size(), modTime) {
+		return false
+	}
+	if !f.includeExpr(&exprEnv{
+		ctx:         ctx,
+		remote:      o.Remote(),
+		size:        o.Size(),
+		modTime:     modTime,
+		haveModTime: haveModTime,
+		o:           o,
+	}) {
+		return false
+	}
+	return f.includeObjectIgnore(ctx, o)
+}
+
+// includeExpr checks the file described by e against the filter
+// expressions if in use
+//
+// If an expression can't be evaluated the file is excluded so that
+// an error can't cause it to be transferred or deleted.
+func (f *Filter) includeExpr(e *exprEnv) bool {
+	if len(f.exprs) == 0 || f.files != nil {
+		return true
+	}
+	for _, x := range f.exprs {
+		e.now = x.now
+		include := x.match(e)
+		if e.err != nil {
+			fs.CountError(e.err)
+			fs.Errorf(e.remote, "Excluding file as failed to evaluate --filter-expr: %v", e.err)
+			return false
+		}
+		if !include {
+			return false
+		}
+	}
+	return true
+}
+
+// includeObjectIgnore checks the object against the per-directory
+// filter files if in use
+//
+// If the filter files can't be read the file is excluded so that
+// an error can't cause it to be transferred or deleted.
+func (f *Filter) includeObjectIgnore(ctx context.Context, o fs.Object) bool {
+	if len(f.Opt.IgnoreFile) == 0 || f.files != nil {
+		return true
+	}
+	fremote, ok := o.Fs().(fs.Fs)
+	if !ok {
+		return true
+	}
+	ignored, err := f.ignored(ctx, fremote, o.Remote(), false)
+	if err != nil {
+		fs.CountError(err)
+		fs.Errorf(o, "Excluding file as failed to read %s filter files: %v", f.Opt.IgnoreFile, err)
+		return false
+	}
+	return !ignored
 }
 
 // forEachLine calls fn on every line in the file pointed to by path
 //
-// It ignores empty lines and lines starting with '#' or ';'
-func forEachLine(path string, fn func(string) error) (err error) {
+// It ignores empty lines. Unless raw is set it also ignores lines
+// starting with '#' or ';' and trims white space from the lines.
+func forEachLine(path string, raw bool, fn func(string) error) (err error) {
 	in, err := os.Open(path)
 	if err != nil {
 		return err
@@ -465,9 +658,15 @@ func forEachLine(path string, fn func(string) error) (err error) {
 	scanner := bufio.NewScanner(in)
 	for scanner.Scan() {
 		line := scanner.Text()
-		line = strings.TrimSpace(line)
-		if len(line) == 0 || line[0] == '#' || line[0] == ';' {
-			continue
+		if raw {
+			if len(line) == 0 {
+				continue
+			}
+		} else {
+			line = strings.TrimSpace(line)
+			if len(line) == 0 || line[0] == '#' || line[0] == ';' {
+				continue
+			}
 		}
 		err := fn(line)
 		if err != nil {
@@ -494,5 +693,11 @@ func (f *Filter) DumpFilters() string {
 	for _, dirRule := range f.dirRules.rules {
 		rules = append(rules, dirRule.String())
 	}
+	if len(f.exprs) > 0 {
+		rules = append(rules, "--- Filter expressions ---")
+		for _, x := range f.exprs {
+			rules = append(rules, x.text)
+		}
+	}
 	return strings.Join(rules, "\n")
 }
@@ -1,14 +1,19 @@
 package filter
 
 import (
+	"context"
 	"fmt"
 	"io/ioutil"
 	"os"
+	"sort"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/ncw/rclone/fs"
+	"github.com/ncw/rclone/fstest/mockobject"
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -124,7 +129,7 @@ type includeDirTest struct {
 
 func testDirInclude(t *testing.T, f *Filter, tests []includeDirTest) {
 	for _, test := range tests {
-		got, err := f.IncludeDirectory(nil)(test.in)
+		got, err := f.IncludeDirectory(context.Background(), nil)(test.in)
 		require.NoError(t, err)
 		assert.Equal(t, test.want, got, test.in)
 	}
@@ -151,6 +156,75 @@ func TestNewFilterIncludeFiles(t *testing.T) {
 	assert.False(t, f.InActive())
 }
 
+func TestFilterMakeListR(t *testing.T) {
+	f, err := NewFilter(nil)
+	require.NoError(t, err)
+
+	// Check error if no files
+	listR := f.MakeListR(nil)
+	err = listR(context.Background(), "", nil)
+	assert.EqualError(t, err, errFilesFromNotSet.Error())
+	assert.False(t, f.HaveFilesFrom())
+
+	// Add some files
+	for _, path := range []string{
+		"a",
+		"b",
+		"c/d",
+		"c/e",
+		"missing",
+		"c/missing",
+	} {
+		require.NoError(t, f.AddFile(path))
+	}
+	assert.True(t, f.HaveFilesFrom())
+
+	// NewObject function for MakeListR
+	newObjects := FilesMap{}
+	var newObjectMu sync.Mutex
+	NewObject := func(ctx context.Context, remote string) (fs.Object, error) {
+		newObjectMu.Lock()
+		defer newObjectMu.Unlock()
+		if remote == "error" {
+			return nil, errors.New("boom")
+		}
+		if strings.HasSuffix(remote, "missing") {
+			return nil, fs.ErrorObjectNotFound
+		}
+		newObjects[remote] = struct{}{}
+		return mockobject.Object(remote), nil
+	}
+
+	// Callback for ListRFn
+	var listRObjects []string
+	listRcallback := func(entries fs.DirEntries) error {
+		for _, entry := range entries {
+			listRObjects = append(listRObjects, entry.Remote())
+		}
+		return nil
+	}
+
+	// Make the listR and test it
+	listR = f.MakeListR(NewObject)
+	listRObjects = nil
+	require.NoError(t, listR(context.Background(), "", listRcallback))
+	sort.Strings(listRObjects)
+	assert.Equal(t, []string{"a", "b", "c/d", "c/e"}, listRObjects)
+	assert.Len(t, newObjects, 4)
+
+	// Check only the files in the directory are returned
+	listRObjects = nil
+	require.NoError(t, listR(context.Background(), "c", listRcallback))
+	sort.Strings(listRObjects)
+	assert.Equal(t, []string{"c/d", "c/e"}, listRObjects)
+
+	// Now check an error is returned from NewObject
+	require.NoError(t, f.AddFile("error"))
+	err = listR(context.Background(), "", listRcallback)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "boom")
+}
+
 func TestNewFilterIncludeFilesDirs(t *testing.T) {
 	f, err := NewFilter(nil)
 	require.NoError(t, err)
@@ -382,12 +456,20 @@ five
 		require.NoError(t, err)
 	}()
 	lines := []string{}
-	err := forEachLine(file, func(s string) error {
+	err := forEachLine(file, false, func(s string) error {
 		lines = append(lines, s)
 		return nil
 	})
 	require.NoError(t, err)
 	assert.Equal(t, "one,two,three,four,five,six", strings.Join(lines, ","))
+
+	lines = []string{}
+	err = forEachLine(file, true, func(s string) error {
+		lines = append(lines, s)
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "; comment,one,# another comment,two, # indented comment,three  ,four    ,five,  six  ", strings.Join(lines, ","))
 }
 
 func TestFilterMatchesFromDocs(t *testing.T) {
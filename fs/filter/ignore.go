@@ -0,0 +1,364 @@
+// Per-directory gitignore style filter files (see --ignore-file)
+
+package filter
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"io"
+	"path"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ncw/rclone/fs"
+	"github.com/pkg/errors"
+)
+
+// ignoreRule is one rule from a per-directory filter file
+type ignoreRule struct {
+	re      *regexp.Regexp
+	negate  bool // if set re-include what the rule matches
+	dirOnly bool // if set only match directories
+}
+
+// match returns true if the rule matches remote which is relative to
+// the directory the filter file is in
+func (r *ignoreRule) match(remote string, isDir bool) bool {
+	if r.dirOnly && !isDir {
+		return false
+	}
+	return r.re.MatchString(remote)
+}
+
+// ignoreFile is the parsed contents of a per-directory filter file
+type ignoreFile struct {
+	rules []ignoreRule
+}
+
+// parseIgnoreFile reads gitignore style rules from in
+func parseIgnoreFile(in io.Reader) (*ignoreFile, error) {
+	file := &ignoreFile{}
+	scanner := bufio.NewScanner(in)
+	for scanner.Scan() {
+		line := scanner.Text()
+		line = strings.TrimSuffix(line, "\r")
+		if len(line) == 0 || line[0] == '#' {
+			continue
+		}
+		// Trailing spaces are ignored unless they are escaped
+		for strings.HasSuffix(line, " ") && !strings.HasSuffix(line, `\ `) {
+			line = line[:len(line)-1]
+		}
+		var rule ignoreRule
+		if strings.HasPrefix(line, "!") {
+			rule.negate = true
+			line = line[1:]
+		}
+		if strings.HasSuffix(line, "/") {
+			rule.dirOnly = true
+			line = strings.TrimRight(line, "/")
+		}
+		if line == "" {
+			continue
+		}
+		re, err := ignoreToRegexp(line)
+		if err != nil {
+			return nil, err
+		}
+		rule.re = re
+		file.rules = append(file.rules, rule)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return file, nil
+}
+
+// ignoreToRegexp converts a gitignore style pattern, without any
+// leading '!' or trailing '/', into a regexp
+//
+// documented in filtering.md
+func ignoreToRegexp(pattern string) (*regexp.Regexp, error) {
+	var re bytes.Buffer
+	// Patterns with a '/' in are relative to the directory of
+	// the filter file, others match at any level below it
+	if strings.Contains(pattern, "/") {
+		pattern = strings.TrimPrefix(pattern, "/")
+		_, _ = re.WriteRune('^')
+	} else {
+		_, _ = re.WriteString("(^|/)")
+	}
+	for i := 0; i < len(pattern); i++ {
+		c := pattern[i]
+		switch c {
+		case '*':
+			atStart := i == 0 || pattern[i-1] == '/'
+			if strings.HasPrefix(pattern[i:], "**") && atStart {
+				rest := pattern[i+2:]
+				if rest == "" {
+					// trailing "**" matches everything
+					_, _ = re.WriteString(`.*`)
+					i++
+					continue
+				} else if rest[0] == '/' {
+					// "**/" matches zero or more directories
+					_, _ = re.WriteString(`(.*/)?`)
+					i += 2
+					continue
+				}
+			}
+			// Other consecutive stars are treated as one
+			for i+1 < len(pattern) && pattern[i+1] == '*' {
+				i++
+			}
+			_, _ = re.WriteString(`[^/]*`)
+		case '?':
+			_, _ = re.WriteString(`[^/]`)
+		case '[':
+			// Find the end of the class allowing for a leading
+			// ']' and [:alpha:] style classes
+			j := i + 1
+			if j < len(pattern) && (pattern[j] == '!' || pattern[j] == '^') {
+				j++
+			}
+			if j < len(pattern) && pattern[j] == ']' {
+				j++
+			}
+			for j < len(pattern) && pattern[j] != ']' {
+				if strings.HasPrefix(pattern[j:], "[:") {
+					if end := strings.Index(pattern[j+2:], ":]"); end >= 0 {
+						j += end + 4
+						continue
+					}
+				}
+				j++
+			}
+			if j >= len(pattern) {
+				return nil, errors.Errorf("mismatched '[' and ']' in pattern %q", pattern)
+			}
+			class := pattern[i+1 : j]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			_, _ = re.WriteString("[" + class + "]")
+			i = j
+		case '\\':
+			if i+1 < len(pattern) {
+				i++
+			}
+			_, _ = re.WriteString(regexp.QuoteMeta(pattern[i : i+1]))
+		default:
+			_, _ = re.WriteString(regexp.QuoteMeta(pattern[i : i+1]))
+		}
+	}
+	_, _ = re.WriteRune('$')
+	result, err := regexp.Compile(re.String())
+	if err != nil {
+		return nil, errors.Wrapf(err, "bad ignore pattern %q (regexp %q)", pattern, re.String())
+	}
+	return result, nil
+}
+
+// ignoreCache holds the filter files read from each Fs and the
+// directories found to be ignored
+type ignoreCache struct {
+	mu      sync.Mutex
+	files   map[string]*ignoreFile // filter files by key, nil if not present
+	dirs    map[string]bool        // whether directories are ignored by key
+	sources map[fs.Fs]fs.Fs        // Fs to read the filter files from instead
+	expires time.Time              // when files and dirs should be read again
+}
+
+// expireIgnoreCache empties the cache of filter files once it is
+// older than --ignore-file-cache-time so that changes to them are
+// noticed by long running commands such as mount.
+func (f *Filter) expireIgnoreCache() {
+	f.ignore.mu.Lock()
+	defer f.ignore.mu.Unlock()
+	now := time.Now()
+	if now.Before(f.ignore.expires) {
+		return
+	}
+	// Start the clock the first time through
+	if !f.ignore.expires.IsZero() {
+		f.ignore.files = nil
+		f.ignore.dirs = nil
+	}
+	f.ignore.expires = now.Add(time.Duration(f.Opt.IgnoreFileCacheTime))
+}
+
+// SetIgnoreSource makes the filter files for fdst be read from fsrc
+// instead, so that the paths ignored in the source of a sync are
+// ignored in the destination too and aren't deleted from it.
+//
+// It returns a function to undo it.
+func (f *Filter) SetIgnoreSource(fdst, fsrc fs.Fs) func() {
+	if len(f.Opt.IgnoreFile) == 0 || fdst == fsrc {
+		return func() {}
+	}
+	f.ignore.mu.Lock()
+	if f.ignore.sources == nil {
+		f.ignore.sources = make(map[fs.Fs]fs.Fs)
+	}
+	f.ignore.sources[fdst] = fsrc
+	f.ignore.mu.Unlock()
+	return func() {
+		f.ignore.mu.Lock()
+		if f.ignore.sources[fdst] == fsrc {
+			delete(f.ignore.sources, fdst)
+		}
+		f.ignore.mu.Unlock()
+	}
+}
+
+// ignoreSource returns the Fs to read the filter files for fremote from
+func (f *Filter) ignoreSource(fremote fs.Fs) fs.Fs {
+	f.ignore.mu.Lock()
+	defer f.ignore.mu.Unlock()
+	if fsrc, ok := f.ignore.sources[fremote]; ok {
+		return fsrc
+	}
+	return fremote
+}
+
+// ignoreKey makes a key for dir in fremote for the ignoreCache
+func ignoreKey(fremote fs.Fs, dir string) string {
+	return fremote.Name() + ":" + fremote.Root() + "\x00" + dir
+}
+
+// parentDir returns the parent directory of remote or "" for the root
+func parentDir(remote string) string {
+	dir := path.Dir(remote)
+	if dir == "." || dir == "/" {
+		return ""
+	}
+	return dir
+}
+
+// ignoreFile reads the filter file in dir of fremote, returning nil
+// if there isn't one
+func (f *Filter) ignoreFile(ctx context.Context, fremote fs.Fs, dir string) (file *ignoreFile, err error) {
+	key := ignoreKey(fremote, dir)
+	f.ignore.mu.Lock()
+	file, found := f.ignore.files[key]
+	f.ignore.mu.Unlock()
+	if found {
+		return file, nil
+	}
+	remote := path.Join(dir, f.Opt.IgnoreFile)
+	o, err := fremote.NewObject(ctx, remote)
+	switch err {
+	case nil:
+		in, err := o.Open(ctx)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to open %q", remote)
+		}
+		file, err = parseIgnoreFile(in)
+		closeErr := in.Close()
+		if err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to read %q", remote)
+		}
+		fs.Debugf(fremote, "Read %d filter rules from %q", len(file.rules), remote)
+	case fs.ErrorObjectNotFound, fs.ErrorNotAFile, fs.ErrorDirNotFound, fs.ErrorPermissionDenied:
+		file = nil
+	default:
+		return nil, err
+	}
+	f.ignore.mu.Lock()
+	if f.ignore.files == nil {
+		f.ignore.files = make(map[string]*ignoreFile)
+	}
+	f.ignore.files[key] = file
+	f.ignore.mu.Unlock()
+	return file, nil
+}
+
+// ignoredBy checks remote against the filter files in its parent
+// directories, the deepest taking precedence, ignoring whether its
+// parent directories are ignored
+func (f *Filter) ignoredBy(ctx context.Context, fremote fs.Fs, remote string, isDir bool) (ignored bool, err error) {
+	// Make a list of the parent directories, root first
+	var dirs []string
+	for dir := parentDir(remote); ; dir = parentDir(dir) {
+		dirs = append(dirs, dir)
+		if dir == "" {
+			break
+		}
+	}
+	for i := len(dirs) - 1; i >= 0; i-- {
+		dir := dirs[i]
+		file, err := f.ignoreFile(ctx, fremote, dir)
+		if err != nil {
+			return false, err
+		}
+		if file == nil {
+			continue
+		}
+		relative := remote
+		if dir != "" {
+			relative = remote[len(dir)+1:]
+		}
+		// The last matching rule wins
+		for j := range file.rules {
+			rule := &file.rules[j]
+			if rule.match(relative, isDir) {
+				ignored = !rule.negate
+			}
+		}
+	}
+	return ignored, nil
+}
+
+// ignoredDir returns whether the directory dir in fremote, or any of
+// its parents, are ignored by the filter files
+func (f *Filter) ignoredDir(ctx context.Context, fremote fs.Fs, dir string) (ignored bool, err error) {
+	if dir == "" {
+		return false, nil
+	}
+	key := ignoreKey(fremote, dir)
+	f.ignore.mu.Lock()
+	ignored, found := f.ignore.dirs[key]
+	f.ignore.mu.Unlock()
+	if found {
+		return ignored, nil
+	}
+	// A file can't be re-included if its parent is ignored
+	ignored, err = f.ignoredDir(ctx, fremote, parentDir(dir))
+	if err == nil && !ignored {
+		ignored, err = f.ignoredBy(ctx, fremote, dir, true)
+	}
+	if err != nil {
+		return false, err
+	}
+	f.ignore.mu.Lock()
+	if f.ignore.dirs == nil {
+		f.ignore.dirs = make(map[string]bool)
+	}
+	f.ignore.dirs[key] = ignored
+	f.ignore.mu.Unlock()
+	return ignored, nil
+}
+
+// ignored returns whether remote in fremote is ignored by the
+// per-directory filter files named by --ignore-file
+//
+// If fremote is the destination of a sync the filter files are read
+// from the source, see SetIgnoreSource.
+func (f *Filter) ignored(ctx context.Context, fremote fs.Fs, remote string, isDir bool) (bool, error) {
+	f.expireIgnoreCache()
+	fremote = f.ignoreSource(fremote)
+	if isDir {
+		return f.ignoredDir(ctx, fremote, remote)
+	}
+	ignored, err := f.ignoredDir(ctx, fremote, parentDir(remote))
+	if err != nil || ignored {
+		return ignored, err
+	}
+	return f.ignoredBy(ctx, fremote, remote, false)
+}
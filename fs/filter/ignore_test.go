@@ -0,0 +1,210 @@
+package filter
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ncw/rclone/fs"
+	"github.com/ncw/rclone/fstest/mockobject"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIgnoreToRegexp(t *testing.T) {
+	for _, test := range []struct {
+		in    string
+		want  string
+		error string
+	}{
+		{`potato`, `(^|/)potato$`, ``},
+		{`/potato`, `^potato$`, ``},
+		{`dir/potato`, `^dir/potato$`, ``},
+		{`*.jpg`, `(^|/)[^/]*\.jpg$`, ``},
+		{`potato?sausage`, `(^|/)potato[^/]sausage$`, ``},
+		{`potat[oa]`, `(^|/)potat[oa]$`, ``},
+		{`potat[!oa]`, `(^|/)potat[^oa]$`, ``},
+		{`potat[]a]`, `(^|/)potat[]a]$`, ``},
+		{`potat[[:alpha:]]or`, `(^|/)potat[[:alpha:]]or$`, ``},
+		{`a{b,c}+(d)`, `(^|/)a\{b,c\}\+\(d\)$`, ``},
+		{`\#potato`, `(^|/)#potato$`, ``},
+		{`\!potato`, `(^|/)!potato$`, ``},
+		{`potato\ `, `(^|/)potato $`, ``},
+		{`**/potato`, `^(.*/)?potato$`, ``},
+		{`dir/**`, `^dir/.*$`, ``},
+		{`a/**/b`, `^a/(.*/)?b$`, ``},
+		{`**`, `(^|/).*$`, ``},
+		{`potato**`, `(^|/)potato[^/]*$`, ``},
+		{`potat[oa`, ``, `mismatched '[' and ']' in pattern "potat[oa"`},
+	} {
+		got, err := ignoreToRegexp(test.in)
+		if test.error == "" {
+			require.NoError(t, err, test.in)
+			assert.Equal(t, test.want, got.String(), test.in)
+		} else {
+			require.Error(t, err, test.in)
+			assert.Contains(t, err.Error(), test.error, test.in)
+		}
+	}
+}
+
+func TestParseIgnoreFile(t *testing.T) {
+	file, err := parseIgnoreFile(strings.NewReader(`# comment
+
+*.o
+!keep.o
+build/
+/top   
+\#hash
+`))
+	require.NoError(t, err)
+	var got []string
+	for _, rule := range file.rules {
+		flags := ""
+		if rule.negate {
+			flags += "!"
+		}
+		if rule.dirOnly {
+			flags += "/"
+		}
+		got = append(got, flags+" "+rule.re.String())
+	}
+	assert.Equal(t, []string{
+		` (^|/)[^/]*\.o$`,
+		`! (^|/)keep\.o$`,
+		`/ (^|/)build$`,
+		` ^top$`,
+		` (^|/)#hash$`,
+	}, got)
+}
+
+// ignoreFs is a minimal fs.Fs for testing the filter files
+type ignoreFs struct {
+	fs.Fs
+}
+
+func (f ignoreFs) Name() string { return "test" }
+func (f ignoreFs) Root() string { return "root" }
+func (f ignoreFs) NewObject(ctx context.Context, remote string) (fs.Object, error) {
+	return nil, fs.ErrorObjectNotFound
+}
+
+func TestIgnored(t *testing.T) {
+	ctx := context.Background()
+	f, err := NewFilter(nil)
+	require.NoError(t, err)
+	f.Opt.IgnoreFile = ".rcloneignore"
+	assert.False(t, f.InActive())
+	fremote := ignoreFs{}
+
+	// Set up the filter files as if they had been read
+	addFile := func(dir, rules string) {
+		file, err := parseIgnoreFile(strings.NewReader(rules))
+		require.NoError(t, err)
+		if f.ignore.files == nil {
+			f.ignore.files = make(map[string]*ignoreFile)
+		}
+		f.ignore.files[ignoreKey(fremote, dir)] = file
+	}
+	addFile("", "*.o\n!keep.o\nbuild/\n/top.txt\nsub/anchored.txt\n")
+	addFile("sub", "!*.o\nlocal.txt\n")
+
+	includeDirectory := f.IncludeDirectory(ctx, fremote)
+	for _, test := range []struct {
+		remote string
+		isDir  bool
+		want   bool
+	}{
+		{"file.txt", false, true},
+		{"file.o", false, false},
+		{"keep.o", false, true},
+		{"other/file.o", false, false},
+		{"build", true, false},
+		{"build", false, true},
+		{"other/build", true, false},
+		{"other/build/file.txt", false, false},
+		{"top.txt", false, false},
+		{"other/top.txt", false, true},
+		{"sub/anchored.txt", false, false},
+		{"other/sub/anchored.txt", false, true},
+		{"sub/file.o", false, true},
+		{"sub/deeper/file.o", false, true},
+		{"sub/local.txt", false, false},
+		{"local.txt", false, true},
+		{"sub/build", true, false},
+		{"sub/build/keep.o", false, false},
+	} {
+		what := test.remote
+		var got bool
+		if test.isDir {
+			what += "/"
+			got, err = includeDirectory(test.remote)
+			require.NoError(t, err)
+		} else {
+			ignored, err := f.ignored(ctx, fremote, test.remote, false)
+			require.NoError(t, err)
+			got = !ignored
+		}
+		assert.Equal(t, test.want, got, what)
+	}
+}
+
+// ignoreCountFs is an fs.Fs which counts the calls to NewObject and
+// returns err from them
+type ignoreCountFs struct {
+	ignoreFs
+	calls *int
+	err   error
+}
+
+func (f ignoreCountFs) NewObject(ctx context.Context, remote string) (fs.Object, error) {
+	*f.calls++
+	return nil, f.err
+}
+
+func TestIgnoredCacheExpiry(t *testing.T) {
+	ctx := context.Background()
+	f, err := NewFilter(nil)
+	require.NoError(t, err)
+	f.Opt.IgnoreFile = ".rcloneignore"
+	calls := 0
+	fremote := ignoreCountFs{calls: &calls, err: fs.ErrorObjectNotFound}
+
+	// Read once then cached
+	for i := 0; i < 2; i++ {
+		ignored, err := f.ignored(ctx, fremote, "file.txt", false)
+		require.NoError(t, err)
+		assert.False(t, ignored)
+		assert.Equal(t, 1, calls)
+	}
+
+	// Read again once the cache has expired
+	f.ignore.expires = time.Now().Add(-time.Second)
+	_, err = f.ignored(ctx, fremote, "file.txt", false)
+	require.NoError(t, err)
+	assert.Equal(t, 2, calls)
+}
+
+// ignoreObject is an fs.Object on fremote
+type ignoreObject struct {
+	mockobject.Object
+	fremote fs.Fs
+}
+
+func (o ignoreObject) Fs() fs.Info { return o.fremote }
+
+func TestIncludeObjectIgnoreError(t *testing.T) {
+	ctx := context.Background()
+	f, err := NewFilter(nil)
+	require.NoError(t, err)
+	f.Opt.IgnoreFile = ".rcloneignore"
+	calls := 0
+	fremote := ignoreCountFs{calls: &calls, err: errors.New("read failed")}
+
+	// Excluded if the filter files can't be read
+	o := ignoreObject{Object: mockobject.Object("file.txt"), fremote: fremote}
+	assert.False(t, f.IncludeObject(ctx, o))
+	assert.Equal(t, 1, calls)
+}
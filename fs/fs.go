@@ -2,6 +2,9 @@
 package fs
 
 import (
+	"bytes"
+	"context"
+	"fmt"
 	"io"
 	"io/ioutil"
 	"log"
@@ -14,6 +17,8 @@ import (
 	"strings"
 	"time"
 
+	"github.com/ncw/rclone/fs/config/configmap"
+	"github.com/ncw/rclone/fs/config/configstruct"
 	"github.com/ncw/rclone/fs/driveletter"
 	"github.com/ncw/rclone/fs/hash"
 	"github.com/pkg/errors"
@@ -69,23 +74,120 @@ type RegInfo struct {
 	Name string
 	// Description of this fs - defaults to Name
 	Description string
+	// Prefix for command line flags for this fs - defaults to Name if not set
+	Prefix string
 	// Create a new file system.  If root refers to an existing
 	// object, then it should return a Fs which which points to
 	// the parent of that object and ErrorIsFile.
-	NewFs func(name string, root string) (Fs, error) `json:"-"`
+	NewFs func(name string, root string, config configmap.Mapper) (Fs, error) `json:"-"`
 	// Function to call to help with config
-	Config func(string) `json:"-"`
+	Config func(name string, config configmap.Mapper) `json:"-"`
 	// Options for the Fs configuration
-	Options []Option
+	Options Options
+}
+
+// Options is a slice of configuration Option for a backend
+type Options []Option
+
+// Set the default values for the options
+func (os Options) setValues() {
+	for i := range os {
+		o := &os[i]
+		if o.Default == nil {
+			o.Default = ""
+		}
+	}
+}
+
+// Get the Option corresponding to name or return nil if not found
+func (os Options) Get(name string) *Option {
+	for i := range os {
+		opt := &os[i]
+		if opt.Name == name {
+			return opt
+		}
+	}
+	return nil
 }
 
 // Option is describes an option for the config wizard
+//
+// This also describes command line options and environment variables
 type Option struct {
-	Name       string
-	Help       string
-	Optional   bool
-	IsPassword bool
-	Examples   OptionExamples `json:",omitempty"`
+	Name       string         // Name of the option in snake_case
+	Help       string         // Help, the first line only is used for the command line help
+	Default    interface{}    // default value, nil => ""
+	Value      interface{}    // value to be set by flags
+	Examples   OptionExamples `json:",omitempty"` // config examples
+	ShortOpt   string         // the short option for this if required
+	Optional   bool           // set if the option can be left blank in the config wizard
+	IsPassword bool           // set if the option is a password
+	NoPrefix   bool           // set if the option for this should not use the backend prefix
+	Advanced   bool           // set if this is an advanced config option not asked for by the config wizard
+}
+
+// GetValue gets the current current value which is the default if not set
+func (o *Option) GetValue() interface{} {
+	val := o.Value
+	if val == nil {
+		val = o.Default
+		if val == nil {
+			val = ""
+		}
+	}
+	return val
+}
+
+// String turns Option into a string
+func (o *Option) String() string {
+	return fmt.Sprint(o.GetValue())
+}
+
+// Set a Option from a string
+func (o *Option) Set(s string) (err error) {
+	newValue, err := configstruct.StringToInterface(o.GetValue(), s)
+	if err != nil {
+		return err
+	}
+	o.Value = newValue
+	return nil
+}
+
+type typer interface {
+	Type() string
+}
+
+// Type of the value
+func (o *Option) Type() string {
+	v := o.GetValue()
+
+	// Try to call Type method on non-pointer
+	if do, ok := v.(typer); ok {
+		return do.Type()
+	}
+
+	// Try to call Type method on pointer
+	pv := reflect.New(reflect.TypeOf(v))
+	pv.Elem().Set(reflect.ValueOf(v))
+	if do, ok := pv.Interface().(typer); ok {
+		return do.Type()
+	}
+
+	return reflect.TypeOf(v).Name()
+}
+
+// FlagName for the option
+func (o *Option) FlagName(prefix string) string {
+	name := strings.Replace(o.Name, "_", "-", -1) // convert snake_case to kebab-case
+	if !o.NoPrefix {
+		name = prefix + "-" + name
+	}
+	return name
+}
+
+// EnvVarName for the option
+func (o *Option) EnvVarName(prefix string) string {
+	return OptionToEnv(prefix + "-" + o.Name)
 }
 
 // OptionExamples is a slice of examples
@@ -113,6 +215,10 @@ type OptionExample struct {
 //
 // Fs modules  should use this in an init() function
 func Register(info *RegInfo) {
+	info.Options.setValues()
+	if info.Prefix == "" {
+		info.Prefix = info.Name
+	}
 	Registry = append(Registry, info)
 }
 
@@ -129,28 +235,28 @@ type Fs interface {
 	//
 	// This should return ErrDirNotFound if the directory isn't
 	// found.
-	List(dir string) (entries DirEntries, err error)
+	List(ctx context.Context, dir string) (entries DirEntries, err error)
 
 	// NewObject finds the Object at remote.  If it can't be found
 	// it returns the error ErrorObjectNotFound.
-	NewObject(remote string) (Object, error)
+	NewObject(ctx context.Context, remote string) (Object, error)
 
 	// Put in to the remote path with the modTime given of the given size
 	//
 	// May create the object even if it returns an error - if so
 	// will return the object and the error, otherwise will return
 	// nil and the error
-	Put(in io.Reader, src ObjectInfo, options ...OpenOption) (Object, error)
+	Put(ctx context.Context, in io.Reader, src ObjectInfo, options ...OpenOption) (Object, error)
 
 	// Mkdir makes the directory (container, bucket)
 	//
 	// Shouldn't return an error if it already exists
-	Mkdir(dir string) error
+	Mkdir(ctx context.Context, dir string) error
 
 	// Rmdir removes the directory (container, bucket) if empty
 	//
 	// Return an error if it doesn't exist or isn't empty
-	Rmdir(dir string) error
+	Rmdir(ctx context.Context, dir string) error
 }
 
 // Info provides a read only interface to information about a filesystem.
@@ -179,16 +285,16 @@ type Object interface {
 	ObjectInfo
 
 	// SetModTime sets the metadata on the object to set the modification date
-	SetModTime(time.Time) error
+	SetModTime(ctx context.Context, t time.Time) error
 
 	// Open opens the file for read.  Call Close() on the returned io.ReadCloser
-	Open(options ...OpenOption) (io.ReadCloser, error)
+	Open(ctx context.Context, options ...OpenOption) (io.ReadCloser, error)
 
 	// Update in to the object with the modTime given of the given size
-	Update(in io.Reader, src ObjectInfo, options ...OpenOption) error
+	Update(ctx context.Context, in io.Reader, src ObjectInfo, options ...OpenOption) error
 
 	// Removes this object
-	Remove() error
+	Remove(ctx context.Context) error
 }
 
 // ObjectInfo provides read only information about an object.
@@ -244,6 +350,13 @@ type MimeTyper interface {
 	MimeType() string
 }
 
+// HashSetter is an optional interface for Object
+type HashSetter interface {
+	// HashSet returns the hashes of the types asked for as
+	// lowercase hex strings, calculating them in one pass
+	HashSet(types hash.Set) (map[hash.Type]string, error)
+}
+
 // ObjectUnWrapper is an optional interface for Object
 type ObjectUnWrapper interface {
 	// UnWrap returns the Object that this Object is wrapping or
@@ -251,6 +364,24 @@ type ObjectUnWrapper interface {
 	UnWrap() Object
 }
 
+// ObjectResolver is an optional interface for Object
+type ObjectResolver interface {
+	// Resolve returns the Object that this Object stands in for,
+	// for instance if it was read back from a listing stored on
+	// disk.  It should be used before passing the Object to a
+	// backend method which needs the backend's own Object type.
+	Resolve(ctx context.Context) (Object, error)
+}
+
+// ResolveObject returns the Object o stands in for if it is an
+// ObjectResolver, otherwise o
+func ResolveObject(ctx context.Context, o Object) (Object, error) {
+	if do, ok := o.(ObjectResolver); ok {
+		return do.Resolve(ctx)
+	}
+	return o, nil
+}
+
 // ListRCallback defines a callback function for ListR to use
 //
 // It is called for each tranche of entries read from the listing and
@@ -258,7 +389,7 @@ type ObjectUnWrapper interface {
 type ListRCallback func(entries DirEntries) error
 
 // ListRFn is defines the call used to recursively list a directory
-type ListRFn func(dir string, callback ListRCallback) error
+type ListRFn func(ctx context.Context, dir string, callback ListRCallback) error
 
 // Features describe the optional features of the Fs
 type Features struct {
@@ -267,6 +398,8 @@ type Features struct {
 	DuplicateFiles          bool // allows duplicate files
 	ReadMimeType            bool // can read the mime type of objects
 	WriteMimeType           bool // can set the mime type of objects
+	ReadMetadata            bool // can read the metadata of objects
+	WriteMetadata           bool // can write the metadata of objects
 	CanHaveEmptyDirectories bool // can have empty directories
 	BucketBased             bool // is bucket based (like s3, swift etc)
 
@@ -276,7 +409,7 @@ type Features struct {
 	// quicker than just running Remove() on the result of List()
 	//
 	// Return an error if it doesn't exist
-	Purge func() error
+	Purge func(ctx context.Context) error
 
 	// Copy src to this remote using server side copy operations.
 	//
@@ -284,10 +417,11 @@ type Features struct {
 	//
 	// It returns the destination Object and a possible error
 	//
-	// Will only be called if src.Fs().Name() == f.Name()
+	// Will only be called if src.Fs().Name() == f.Name() or if
+	// src is a compatible remote - see ServerSideCompatible
 	//
 	// If it isn't possible then return fs.ErrorCantCopy
-	Copy func(src Object, remote string) (Object, error)
+	Copy func(ctx context.Context, src Object, remote string) (Object, error)
 
 	// Move src to this remote using server side move operations.
 	//
@@ -295,25 +429,27 @@ type Features struct {
 	//
 	// It returns the destination Object and a possible error
 	//
-	// Will only be called if src.Fs().Name() == f.Name()
+	// Will only be called if src.Fs().Name() == f.Name() or if
+	// src is a compatible remote - see ServerSideCompatible
 	//
 	// If it isn't possible then return fs.ErrorCantMove
-	Move func(src Object, remote string) (Object, error)
+	Move func(ctx context.Context, src Object, remote string) (Object, error)
 
 	// DirMove moves src, srcRemote to this remote at dstRemote
 	// using server side move operations.
 	//
-	// Will only be called if src.Fs().Name() == f.Name()
+	// Will only be called if src.Fs().Name() == f.Name() or if
+	// src is a compatible remote - see ServerSideCompatible
 	//
 	// If it isn't possible then return fs.ErrorCantDirMove
 	//
 	// If destination exists then return fs.ErrorDirExists
-	DirMove func(src Fs, srcRemote, dstRemote string) error
+	DirMove func(ctx context.Context, src Fs, srcRemote, dstRemote string) error
 
 	// ChangeNotify calls the passed function with a path
 	// that has had changes. If the implementation
 	// uses polling, it should adhere to the given interval.
-	ChangeNotify func(func(string, EntryType), time.Duration) chan bool
+	ChangeNotify func(context.Context, func(string, EntryType), time.Duration) chan bool
 
 	// UnWrap returns the Fs that this Fs is wrapping
 	UnWrap func() Fs
@@ -329,7 +465,7 @@ type Features struct {
 	DirCacheFlush func()
 
 	// PublicLink generates a public link to the remote path (usually readable by anyone)
-	PublicLink func(remote string) (string, error)
+	PublicLink func(ctx context.Context, remote string) (string, error)
 
 	// Put in to the remote path with the modTime given of the given size
 	//
@@ -339,24 +475,24 @@ type Features struct {
 	//
 	// May create duplicates or return errors if src already
 	// exists.
-	PutUnchecked func(in io.Reader, src ObjectInfo, options ...OpenOption) (Object, error)
+	PutUnchecked func(ctx context.Context, in io.Reader, src ObjectInfo, options ...OpenOption) (Object, error)
 
 	// PutStream uploads to the remote path with the modTime given of indeterminate size
 	//
 	// May create the object even if it returns an error - if so
 	// will return the object and the error, otherwise will return
 	// nil and the error
-	PutStream func(in io.Reader, src ObjectInfo, options ...OpenOption) (Object, error)
+	PutStream func(ctx context.Context, in io.Reader, src ObjectInfo, options ...OpenOption) (Object, error)
 
 	// MergeDirs merges the contents of all the directories passed
 	// in into the first one and rmdirs the other directories.
-	MergeDirs func([]Directory) error
+	MergeDirs func(ctx context.Context, dirs []Directory) error
 
 	// CleanUp the trash in the Fs
 	//
 	// Implement this if you have a way of emptying the trash or
 	// otherwise cleaning up old versions of files.
-	CleanUp func() error
+	CleanUp func(ctx context.Context) error
 
 	// ListR lists the objects and directories of the Fs starting
 	// from dir recursively into out.
@@ -375,6 +511,41 @@ type Features struct {
 	// Don't implement this unless you have a more efficient way
 	// of listing recursively that doing a directory traversal.
 	ListR ListRFn
+
+	// ListP lists the objects and directories of the Fs in dir
+	// non recursively, calling callback with each page of
+	// entries as they are read rather than returning them all
+	// at once like List.
+	//
+	// dir should be "" to list the root, and should not have
+	// trailing slashes.
+	//
+	// This should return ErrDirNotFound if the directory isn't
+	// found.
+	//
+	// The entries need not be in any particular order.  If
+	// callback returns an error then the listing will stop
+	// immediately.
+	//
+	// Implement this if the listing is read in pages so huge
+	// directories don't need to be held in memory all at once.
+	ListP ListRFn
+
+	// About gets quota information from the Fs
+	About func(ctx context.Context) (*Usage, error)
+
+	// OpenWriterAt opens with a handle for random access writes
+	//
+	// Pass in the remote desired and the size if known.
+	//
+	// It truncates any existing object
+	OpenWriterAt func(ctx context.Context, remote string, size int64) (WriterAtCloser, error)
+
+	// ServerSideCompatible returns true if Copy, Move and DirMove
+	// from src will work even though src is a differently named
+	// remote.  It will only be called with src of the same type
+	// as this Fs.
+	ServerSideCompatible func(src Info) bool
 }
 
 // Disable nil's out the named feature.  If it isn't found then it
@@ -464,6 +635,18 @@ func (ft *Features) Fill(f Fs) *Features {
 	if do, ok := f.(ListRer); ok {
 		ft.ListR = do.ListR
 	}
+	if do, ok := f.(ListPer); ok {
+		ft.ListP = do.ListP
+	}
+	if do, ok := f.(Abouter); ok {
+		ft.About = do.About
+	}
+	if do, ok := f.(OpenWriterAter); ok {
+		ft.OpenWriterAt = do.OpenWriterAt
+	}
+	if do, ok := f.(ServerSideCompatibler); ok {
+		ft.ServerSideCompatible = do.ServerSideCompatible
+	}
 	return ft.DisableList(Config.DisableFeatures)
 }
 
@@ -479,6 +662,8 @@ func (ft *Features) Mask(f Fs) *Features {
 	ft.DuplicateFiles = ft.DuplicateFiles && mask.DuplicateFiles
 	ft.ReadMimeType = ft.ReadMimeType && mask.ReadMimeType
 	ft.WriteMimeType = ft.WriteMimeType && mask.WriteMimeType
+	ft.ReadMetadata = ft.ReadMetadata && mask.ReadMetadata
+	ft.WriteMetadata = ft.WriteMetadata && mask.WriteMetadata
 	ft.CanHaveEmptyDirectories = ft.CanHaveEmptyDirectories && mask.CanHaveEmptyDirectories
 	ft.BucketBased = ft.BucketBased && mask.BucketBased
 	if mask.Purge == nil {
@@ -517,6 +702,18 @@ func (ft *Features) Mask(f Fs) *Features {
 	if mask.ListR == nil {
 		ft.ListR = nil
 	}
+	if mask.ListP == nil {
+		ft.ListP = nil
+	}
+	if mask.About == nil {
+		ft.About = nil
+	}
+	if mask.OpenWriterAt == nil {
+		ft.OpenWriterAt = nil
+	}
+	if mask.ServerSideCompatible == nil {
+		ft.ServerSideCompatible = nil
+	}
 	return ft.DisableList(Config.DisableFeatures)
 }
 
@@ -552,7 +749,7 @@ type Purger interface {
 	// quicker than just running Remove() on the result of List()
 	//
 	// Return an error if it doesn't exist
-	Purge() error
+	Purge(ctx context.Context) error
 }
 
 // Copier is an optional interface for Fs
@@ -563,10 +760,11 @@ type Copier interface {
 	//
 	// It returns the destination Object and a possible error
 	//
-	// Will only be called if src.Fs().Name() == f.Name()
+	// Will only be called if src.Fs().Name() == f.Name() or if
+	// src is a compatible remote - see ServerSideCompatible
 	//
 	// If it isn't possible then return fs.ErrorCantCopy
-	Copy(src Object, remote string) (Object, error)
+	Copy(ctx context.Context, src Object, remote string) (Object, error)
 }
 
 // Mover is an optional interface for Fs
@@ -577,10 +775,11 @@ type Mover interface {
 	//
 	// It returns the destination Object and a possible error
 	//
-	// Will only be called if src.Fs().Name() == f.Name()
+	// Will only be called if src.Fs().Name() == f.Name() or if
+	// src is a compatible remote - see ServerSideCompatible
 	//
 	// If it isn't possible then return fs.ErrorCantMove
-	Move(src Object, remote string) (Object, error)
+	Move(ctx context.Context, src Object, remote string) (Object, error)
 }
 
 // DirMover is an optional interface for Fs
@@ -588,12 +787,13 @@ type DirMover interface {
 	// DirMove moves src, srcRemote to this remote at dstRemote
 	// using server side move operations.
 	//
-	// Will only be called if src.Fs().Name() == f.Name()
+	// Will only be called if src.Fs().Name() == f.Name() or if
+	// src is a compatible remote - see ServerSideCompatible
 	//
 	// If it isn't possible then return fs.ErrorCantDirMove
 	//
 	// If destination exists then return fs.ErrorDirExists
-	DirMove(src Fs, srcRemote, dstRemote string) error
+	DirMove(ctx context.Context, src Fs, srcRemote, dstRemote string) error
 }
 
 // ChangeNotifier is an optional interface for Fs
@@ -601,7 +801,7 @@ type ChangeNotifier interface {
 	// ChangeNotify calls the passed function with a path
 	// that has had changes. If the implementation
 	// uses polling, it should adhere to the given interval.
-	ChangeNotify(func(string, EntryType), time.Duration) chan bool
+	ChangeNotify(context.Context, func(string, EntryType), time.Duration) chan bool
 }
 
 // UnWrapper is an optional interfaces for Fs
@@ -635,7 +835,7 @@ type PutUncheckeder interface {
 	//
 	// May create duplicates or return errors if src already
 	// exists.
-	PutUnchecked(in io.Reader, src ObjectInfo, options ...OpenOption) (Object, error)
+	PutUnchecked(ctx context.Context, in io.Reader, src ObjectInfo, options ...OpenOption) (Object, error)
 }
 
 // PutStreamer is an optional interface for Fs
@@ -645,20 +845,20 @@ type PutStreamer interface {
 	// May create the object even if it returns an error - if so
 	// will return the object and the error, otherwise will return
 	// nil and the error
-	PutStream(in io.Reader, src ObjectInfo, options ...OpenOption) (Object, error)
+	PutStream(ctx context.Context, in io.Reader, src ObjectInfo, options ...OpenOption) (Object, error)
 }
 
 // PublicLinker is an optional interface for Fs
 type PublicLinker interface {
 	// PublicLink generates a public link to the remote path (usually readable by anyone)
-	PublicLink(remote string) (string, error)
+	PublicLink(ctx context.Context, remote string) (string, error)
 }
 
 // MergeDirser is an option interface for Fs
 type MergeDirser interface {
 	// MergeDirs merges the contents of all the directories passed
 	// in into the first one and rmdirs the other directories.
-	MergeDirs([]Directory) error
+	MergeDirs(ctx context.Context, dirs []Directory) error
 }
 
 // CleanUpper is an optional interfaces for Fs
@@ -667,7 +867,7 @@ type CleanUpper interface {
 	//
 	// Implement this if you have a way of emptying the trash or
 	// otherwise cleaning up old versions of files.
-	CleanUp() error
+	CleanUp(ctx context.Context) error
 }
 
 // ListRer is an optional interfaces for Fs
@@ -688,7 +888,74 @@ type ListRer interface {
 	//
 	// Don't implement this unless you have a more efficient way
 	// of listing recursively that doing a directory traversal.
-	ListR(dir string, callback ListRCallback) error
+	ListR(ctx context.Context, dir string, callback ListRCallback) error
+}
+
+// ListPer is an optional interfaces for Fs
+type ListPer interface {
+	// ListP lists the objects and directories of the Fs in dir
+	// non recursively, calling callback with each page of
+	// entries as they are read.
+	//
+	// dir should be "" to list the root, and should not have
+	// trailing slashes.
+	//
+	// This should return ErrDirNotFound if the directory isn't
+	// found.
+	//
+	// The entries need not be in any particular order.  If
+	// callback returns an error then the listing will stop
+	// immediately.
+	ListP(ctx context.Context, dir string, callback ListRCallback) error
+}
+
+// Abouter is an optional interface for Fs
+type Abouter interface {
+	// About gets quota information from the Fs
+	About(ctx context.Context) (*Usage, error)
+}
+
+// OpenWriterAter is an optional interface for Fs
+type OpenWriterAter interface {
+	// OpenWriterAt opens with a handle for random access writes
+	//
+	// Pass in the remote desired and the size if known.
+	//
+	// It truncates any existing object
+	OpenWriterAt(ctx context.Context, remote string, size int64) (WriterAtCloser, error)
+}
+
+// ServerSideCompatibler is an optional interface for Fs
+type ServerSideCompatibler interface {
+	// ServerSideCompatible returns true if Copy, Move and DirMove
+	// from src will work even though src is a differently named
+	// remote.  It will only be called with src of the same type
+	// as this Fs.
+	ServerSideCompatible(src Info) bool
+}
+
+// WriterAtCloser wraps io.WriterAt and io.Closer
+type WriterAtCloser interface {
+	io.WriterAt
+	io.Closer
+}
+
+// Usage is returned by the About call
+//
+// If a value is nil then it isn't supported by that backend
+type Usage struct {
+	Total   *int64 `json:"total,omitempty"`   // quota of bytes that can be used
+	Used    *int64 `json:"used,omitempty"`    // bytes in use
+	Trashed *int64 `json:"trashed,omitempty"` // bytes in trash
+	Other   *int64 `json:"other,omitempty"`   // other usage eg gmail in drive
+	Free    *int64 `json:"free,omitempty"`    // bytes which can be uploaded before reaching the quota
+}
+
+// NewUsageValue makes a valid value for the Usage struct
+func NewUsageValue(value int64) *int64 {
+	p := new(int64)
+	*p = value
+	return p
 }
 
 // RangeSeeker is the interface that wraps the RangeSeek method.
@@ -747,25 +1014,313 @@ func MustFind(name string) *RegInfo {
 // Matcher is a pattern to match an rclone URL
 var Matcher = regexp.MustCompile(`^([\w_ -]+):(.*)$`)
 
-// ParseRemote deconstructs a path into configName, fsPath, looking up
-// the fsName in the config file (returning NotFoundInConfigFile if not found)
-func ParseRemote(path string) (fsInfo *RegInfo, configName, fsPath string, err error) {
-	parts := Matcher.FindStringSubmatch(path)
+// remoteNameMatcher matches the name of a remote at the start of a
+// path, either a remote in the config file like "remote" or a backend
+// name like ":s3", followed by the start of the connection string
+// parameters or the end of the remote name.
+//
+// A "," is only the start of the parameters if it is followed by a
+// parameter name and "=", "," or ":", so that local paths like "a,b"
+// or "backup, 2019/file:x" aren't taken for remotes.
+var remoteNameMatcher = regexp.MustCompile(`^(:?[\w_ -]+)(?::|,[\w-]*[=,:])`)
+
+// splitRemote splits path into the remote name, any connection string
+// parameters and the path within the remote.
+//
+// The path is of the form remote,key=value,key2=value2:path and the
+// remote name will be returned as "" if path doesn't start with one.
+// If the "," isn't followed by a parameter or there is no ":" after
+// it then path is a local path with a comma in, eg "photos, 2019/x".
+//
+// Values may be enclosed in double quotes if they contain "," or
+// ":", with "" standing for a single literal double quote.  A key
+// without a value is set to "true".
+func splitRemote(path string) (configName string, params configmap.Simple, fsPath string, err error) {
+	parts := remoteNameMatcher.FindStringSubmatch(path)
+	if parts == nil {
+		return "", nil, path, nil
+	}
+	configName = parts[1]
+	rest := path[len(configName):]
+	if rest[0] == ',' && !strings.Contains(rest, ":") {
+		return "", nil, path, nil
+	}
+	if rest[0] == ',' {
+		params, rest, err = parseParams(rest[1:])
+		if err != nil {
+			return "", nil, "", errors.Wrapf(err, "failed to parse connection string %q", path)
+		}
+	}
+	// rest now starts with the ":"
+	return configName, params, rest[1:], nil
+}
+
+// parseParams parses connection string parameters from the start of
+// in up to the ":" which terminates the remote name.
+//
+// It returns the parameters and the rest of in starting with the ":"
+func parseParams(in string) (params configmap.Simple, rest string, err error) {
+	params = configmap.Simple{}
+	for {
+		i := strings.IndexAny(in, "=,:")
+		if i < 0 {
+			return nil, "", errors.New("missing \":\" after parameters")
+		}
+		key := in[:i]
+		if key == "" {
+			return nil, "", errors.New("empty parameter name")
+		}
+		value := "true"
+		in = in[i:]
+		if in[0] == '=' {
+			value, in, err = parseValue(in[1:])
+			if err != nil {
+				return nil, "", errors.Wrapf(err, "bad value for parameter %q", key)
+			}
+		}
+		params[key] = value
+		if in == "" {
+			return nil, "", errors.New("missing \":\" after parameters")
+		}
+		if in[0] == ':' {
+			return params, in, nil
+		}
+		// skip the ","
+		in = in[1:]
+	}
+}
+
+// parseValue parses a single possibly quoted parameter value from the
+// start of in returning the rest of in starting with "," or ":" if
+// there is any
+func parseValue(in string) (value, rest string, err error) {
+	if !strings.HasPrefix(in, `"`) {
+		i := strings.IndexAny(in, ",:")
+		if i < 0 {
+			return in, "", nil
+		}
+		return in[:i], in[i:], nil
+	}
+	var out bytes.Buffer
+	in = in[1:]
+	for {
+		i := strings.IndexByte(in, '"')
+		if i < 0 {
+			return "", "", errors.New("unterminated quoted value")
+		}
+		out.WriteString(in[:i])
+		in = in[i+1:]
+		if !strings.HasPrefix(in, `"`) {
+			break
+		}
+		// "" is an escaped "
+		out.WriteByte('"')
+		in = in[1:]
+	}
+	if in != "" && in[0] != ',' && in[0] != ':' {
+		return "", "", errors.New("unexpected characters after quoted value")
+	}
+	return out.String(), in, nil
+}
+
+// parseRemote deconstructs a path into fsInfo, configName, fsPath and
+// any connection string parameters
+func parseRemote(path string) (fsInfo *RegInfo, configName, fsPath string, params configmap.Simple, err error) {
+	configName, params, fsPath, err = splitRemote(path)
+	if err != nil {
+		return nil, "", "", nil, err
+	}
 	var fsName string
-	fsName, configName, fsPath = "local", "local", path
-	if parts != nil && !driveletter.IsDriveLetter(parts[1]) {
-		configName, fsPath = parts[1], parts[2]
-		fsName = ConfigFileGet(configName, "type")
-		if fsName == "" {
-			return nil, "", "", ErrorNotFoundInConfigFile
+	switch {
+	case configName == "" || driveletter.IsDriveLetter(configName):
+		fsName, configName, fsPath, params = "local", "local", path, nil
+	case configName[0] == ':':
+		// An on the fly remote like :s3: configured entirely
+		// from the connection string and the environment
+		fsName = configName[1:]
+	default:
+		var ok bool
+		fsName, ok = ConfigMap(nil, configName, params).Get("type")
+		if !ok {
+			return nil, "", "", nil, ErrorNotFoundInConfigFile
 		}
 	}
 	// change native directory separators to / if there are any
 	fsPath = filepath.ToSlash(fsPath)
 	fsInfo, err = Find(fsName)
+	return fsInfo, configName, fsPath, params, err
+}
+
+// ParseRemote deconstructs a path into configName, fsPath, looking up
+// the fsName in the config file (returning NotFoundInConfigFile if not found)
+func ParseRemote(path string) (fsInfo *RegInfo, configName, fsPath string, err error) {
+	fsInfo, configName, fsPath, _, err = parseRemote(path)
 	return fsInfo, configName, fsPath, err
 }
 
+// ConfigToEnv converts a config section and name, eg ("myremote",
+// "ignore-size") into an environment name
+// "RCLONE_CONFIG_MYREMOTE_IGNORE_SIZE"
+func ConfigToEnv(section, name string) string {
+	return "RCLONE_CONFIG_" + strings.ToUpper(strings.Replace(section+"_"+name, "-", "_", -1))
+}
+
+// OptionToEnv converts an option name, eg "ignore-size" into an
+// environment name "RCLONE_IGNORE_SIZE"
+func OptionToEnv(name string) string {
+	return "RCLONE_" + strings.ToUpper(strings.Replace(name, "-", "_", -1))
+}
+
+// A configmap.Getter to read from the environment RCLONE_CONFIG_backend_option_name
+type configEnvVars string
+
+// Get a config item from the environment variables if possible
+func (configName configEnvVars) Get(key string) (value string, ok bool) {
+	return os.LookupEnv(ConfigToEnv(string(configName), key))
+}
+
+// A configmap.Getter to read from the environment RCLONE_option_name
+type optionEnvVars struct {
+	fsInfo *RegInfo
+}
+
+// Get a config item from the option environment variables if possible
+func (oev optionEnvVars) Get(key string) (value string, ok bool) {
+	opt := oev.fsInfo.Options.Get(key)
+	if opt == nil {
+		return "", false
+	}
+	// For options with NoPrefix set, check without prefix too
+	if opt.NoPrefix {
+		value, ok = os.LookupEnv(OptionToEnv(key))
+		if ok {
+			return value, ok
+		}
+	}
+	return os.LookupEnv(opt.EnvVarName(oev.fsInfo.Prefix))
+}
+
+// A configmap.Getter to read either the default value or the set
+// value from the RegInfo.Options
+type regInfoValues struct {
+	fsInfo     *RegInfo
+	useDefault bool
+}
+
+// override the values in configMap with the either the flag values or
+// the default values
+func (r *regInfoValues) Get(key string) (value string, ok bool) {
+	opt := r.fsInfo.Options.Get(key)
+	if opt != nil && (r.useDefault || opt.Value != nil) {
+		return opt.String(), true
+	}
+	return "", false
+}
+
+// A configmap.Setter to read from the config file
+type getConfigFile string
+
+// Get a config item from the config file
+func (section getConfigFile) Get(key string) (value string, ok bool) {
+	value, ok = ConfigFileGet(string(section), key)
+	// Ignore empty lines in the config file
+	if value == "" {
+		ok = false
+	}
+	return value, ok
+}
+
+// Set a config item into the config file
+func (section getConfigFile) Set(key, value string) {
+	Debugf(nil, "Saving config %q in section %q of the config file", key, section)
+	err := ConfigFileSet(string(section), key, value)
+	if err != nil {
+		Errorf(nil, "Failed saving config %q in section %q of the config file: %v", key, section, err)
+	}
+}
+
+// ConfigMap creates a configmap.Map from the *RegInfo, the configName
+// and any connection string parameters passed in.
+//
+// If fsInfo is nil then the returned configmap.Map should only be
+// used for reading non backend specific parameters, such as "type".
+//
+// Values are looked up in this order, the first found being used
+//
+//   - the connection string parameters
+//   - the command line flags for the backend, eg --s3-chunk-size
+//   - the remote environment variables, eg RCLONE_CONFIG_MYREMOTE_REGION
+//   - the backend environment variables, eg RCLONE_S3_CHUNK_SIZE
+//   - the config file
+//   - the default values of the backend options
+//
+// Remotes created on the fly, eg ":s3:bucket", don't read from or
+// write to the config file.
+func ConfigMap(fsInfo *RegInfo, configName string, connectionStringParams configmap.Simple) (config *configmap.Map) {
+	onTheFly := strings.HasPrefix(configName, ":")
+
+	// Create the config
+	config = configmap.New()
+
+	// Read the connection string parameters
+	if connectionStringParams != nil {
+		config.AddGetter(connectionStringParams)
+	}
+
+	// Read the flag values
+	if fsInfo != nil {
+		config.AddGetter(&regInfoValues{fsInfo, false})
+	}
+
+	// Read the remote environment vars
+	if !onTheFly {
+		config.AddGetter(configEnvVars(configName))
+	}
+
+	// Read the backend environment vars
+	if fsInfo != nil {
+		config.AddGetter(optionEnvVars{fsInfo: fsInfo})
+	}
+
+	// Read the config file
+	if !onTheFly {
+		config.AddGetter(getConfigFile(configName))
+	}
+
+	// Read the default values
+	if fsInfo != nil {
+		config.AddGetter(&regInfoValues{fsInfo, true})
+	}
+
+	// Set values into the connection string parameters so they
+	// can be read back and into the config file if there is one
+	if connectionStringParams != nil {
+		config.AddSetter(connectionStringParams)
+	} else if onTheFly {
+		config.AddSetter(configmap.Simple{})
+	}
+	if !onTheFly {
+		config.AddSetter(getConfigFile(configName))
+	}
+	return config
+}
+
+// ConfigFs makes the config for calling NewFs with.
+//
+// It parses the path which is of the form remote:path
+//
+// Remotes are looked up in the config file.  If the remote isn't
+// found then NotFoundInConfigFile will be returned.
+func ConfigFs(path string) (fsInfo *RegInfo, configName, fsPath string, config *configmap.Map, err error) {
+	// Parse the remote path
+	fsInfo, configName, fsPath, params, err := parseRemote(path)
+	if err != nil {
+		return
+	}
+	config = ConfigMap(fsInfo, configName, params)
+	return
+}
+
 // NewFs makes a new Fs object from the path
 //
 // The path is of the form remote:path
@@ -773,14 +1328,19 @@ func ParseRemote(path string) (fsInfo *RegInfo, configName, fsPath string, err e
 // Remotes are looked up in the config file.  If the remote isn't
 // found then NotFoundInConfigFile will be returned.
 //
+// The path may also be of the form :backend:path to make a remote
+// without a config file, and in either case the remote name may be
+// followed by connection string parameters which override the config,
+// eg :s3,region=eu-west-1:bucket/path
+//
 // On Windows avoid single character remote names as they can be mixed
 // up with drive letters.
 func NewFs(path string) (Fs, error) {
-	fsInfo, configName, fsPath, err := ParseRemote(path)
+	fsInfo, configName, fsPath, config, err := ConfigFs(path)
 	if err != nil {
 		return nil, err
 	}
-	return fsInfo.NewFs(configName, fsPath)
+	return fsInfo.NewFs(configName, fsPath, config)
 }
 
 // TemporaryLocalFs creates a local FS in the OS's temporary directory.
@@ -809,8 +1369,8 @@ func CheckClose(c io.Closer, err *error) {
 
 // FileExists returns true if a file remote exists.
 // If remote is a directory, FileExists returns false.
-func FileExists(fs Fs, remote string) (bool, error) {
-	_, err := fs.NewObject(remote)
+func FileExists(ctx context.Context, fs Fs, remote string) (bool, error) {
+	_, err := fs.NewObject(ctx, remote)
 	if err != nil {
 		if err == ErrorObjectNotFound || err == ErrorNotAFile || err == ErrorPermissionDenied {
 			return false, nil
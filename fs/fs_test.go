@@ -1,15 +1,19 @@
 package fs
 
 import (
+	"context"
+	"os"
 	"strings"
 	"testing"
 
+	"github.com/ncw/rclone/fs/config/configmap"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestFeaturesDisable(t *testing.T) {
 	ft := new(Features)
-	ft.Copy = func(src Object, remote string) (Object, error) {
+	ft.Copy = func(ctx context.Context, src Object, remote string) (Object, error) {
 		return nil, nil
 	}
 	ft.CaseInsensitive = true
@@ -35,7 +39,7 @@ func TestFeaturesList(t *testing.T) {
 
 func TestFeaturesDisableList(t *testing.T) {
 	ft := new(Features)
-	ft.Copy = func(src Object, remote string) (Object, error) {
+	ft.Copy = func(ctx context.Context, src Object, remote string) (Object, error) {
 		return nil, nil
 	}
 	ft.CaseInsensitive = true
@@ -52,3 +56,176 @@ func TestFeaturesDisableList(t *testing.T) {
 	assert.False(t, ft.CaseInsensitive)
 	assert.False(t, ft.DuplicateFiles)
 }
+
+func TestSplitRemote(t *testing.T) {
+	for _, test := range []struct {
+		in         string
+		wantName   string
+		wantParams configmap.Simple
+		wantPath   string
+		wantErr    string
+	}{
+		{"", "", nil, "", ""},
+		{"/path/to/file", "", nil, "/path/to/file", ""},
+		{"path/to,file", "", nil, "path/to,file", ""},
+		{"remote:", "remote", nil, "", ""},
+		{"remote:path/to/file", "remote", nil, "path/to/file", ""},
+		{"remote:path:with:colons", "remote", nil, "path:with:colons", ""},
+		{":s3:bucket/path", ":s3", nil, "bucket/path", ""},
+		{":s3,region=eu-west-1:bucket/path", ":s3", configmap.Simple{"region": "eu-west-1"}, "bucket/path", ""},
+		{"remote,a=1,b=2:path", "remote", configmap.Simple{"a": "1", "b": "2"}, "path", ""},
+		{"remote,env_auth:", "remote", configmap.Simple{"env_auth": "true"}, "", ""},
+		{"remote,a=:path", "remote", configmap.Simple{"a": ""}, "path", ""},
+		{`:s3,endpoint="http://host:9000",b=2:bucket`, ":s3", configmap.Simple{"endpoint": "http://host:9000", "b": "2"}, "bucket", ""},
+		{`remote,a="x,""y""":path`, "remote", configmap.Simple{"a": `x,"y"`}, "path", ""},
+		{"a,b", "", nil, "a,b", ""},
+		{"photos, 2019/x", "", nil, "photos, 2019/x", ""},
+		{"backup, 2019/file:x", "", nil, "backup, 2019/file:x", ""},
+		{"backup,2019 file:x", "", nil, "backup,2019 file:x", ""},
+		{"backup,/file:x", "", nil, "backup,/file:x", ""},
+		{"remote,a=1", "", nil, "remote,a=1", ""},
+		{"remote,a=1/path", "", nil, "remote,a=1/path", ""},
+		{"remote,a=1:path,b", "remote", configmap.Simple{"a": "1"}, "path,b", ""},
+		{"remote,=1:path", "", nil, "", `failed to parse connection string "remote,=1:path": empty parameter name`},
+		{`remote,a="1:path`, "", nil, "", `failed to parse connection string "remote,a=\"1:path": bad value for parameter "a": unterminated quoted value`},
+		{`remote,a="1"x:path`, "", nil, "", `failed to parse connection string "remote,a=\"1\"x:path": bad value for parameter "a": unexpected characters after quoted value`},
+	} {
+		gotName, gotParams, gotPath, gotErr := splitRemote(test.in)
+		if test.wantErr != "" {
+			assert.EqualError(t, gotErr, test.wantErr, test.in)
+			continue
+		}
+		require.NoError(t, gotErr, test.in)
+		assert.Equal(t, test.wantName, gotName, test.in)
+		assert.Equal(t, test.wantParams, gotParams, test.in)
+		assert.Equal(t, test.wantPath, gotPath, test.in)
+	}
+}
+
+func TestOption(t *testing.T) {
+	d := &Option{
+		Name:    "potato",
+		Default: SizeSuffix(0),
+	}
+	assert.Equal(t, "0", d.String())
+	assert.Equal(t, "int64", d.Type())
+	assert.Equal(t, "test-potato", d.FlagName("test"))
+	assert.Equal(t, "RCLONE_TEST_POTATO", d.EnvVarName("test"))
+	err := d.Set("1M")
+	require.NoError(t, err)
+	assert.Equal(t, SizeSuffix(1<<20), d.Value)
+	assert.Equal(t, "1M", d.String())
+	err = d.Set("sausage")
+	assert.Error(t, err)
+
+	b := &Option{
+		Name:     "bean_stew",
+		Default:  false,
+		NoPrefix: true,
+	}
+	assert.Equal(t, "false", b.String())
+	assert.Equal(t, "bool", b.Type())
+	assert.Equal(t, "bean-stew", b.FlagName("test"))
+}
+
+func TestConfigMap(t *testing.T) {
+	oldConfigFileGet := ConfigFileGet
+	defer func() { ConfigFileGet = oldConfigFileGet }()
+	ConfigFileGet = func(section, key string) (string, bool) {
+		if section != "myremote" {
+			return "", false
+		}
+		value, ok := map[string]string{
+			"type":   "config_map_test",
+			"a":      "file",
+			"b":      "file",
+			"c":      "file",
+			"d":      "file",
+			"e":      "",
+			"random": "file",
+		}[key]
+		return value, ok
+	}
+	fsInfo := &RegInfo{
+		Name: "config_map_test",
+		Options: Options{
+			{Name: "a", Default: "default"},
+			{Name: "b", Default: "default"},
+			{Name: "c", Default: "default"},
+			{Name: "d", Default: "default"},
+			{Name: "e", Default: "default"},
+			{Name: "f", Default: "default"},
+		},
+	}
+	Register(fsInfo)
+	defer func() { Registry = Registry[:len(Registry)-1] }()
+	assert.Equal(t, "config_map_test", fsInfo.Prefix)
+
+	// Set one value from the flags
+	require.NoError(t, fsInfo.Options.Get("a").Set("flag"))
+	defer func() { fsInfo.Options.Get("a").Value = nil }()
+
+	// and others from the environment
+	for key, value := range map[string]string{
+		"RCLONE_CONFIG_MYREMOTE_B": "remote_env",
+		"RCLONE_CONFIG_MYREMOTE_C": "remote_env",
+		"RCLONE_CONFIG_MAP_TEST_C": "option_env",
+		"RCLONE_CONFIG_MAP_TEST_D": "option_env",
+	} {
+		require.NoError(t, os.Setenv(key, value))
+		defer func(key string) { _ = os.Unsetenv(key) }(key)
+	}
+
+	gotInfo, configName, fsPath, m, err := ConfigFs("myremote,a=param:path")
+	require.NoError(t, err)
+	assert.Equal(t, fsInfo, gotInfo)
+	assert.Equal(t, "myremote", configName)
+	assert.Equal(t, "path", fsPath)
+	for _, test := range []struct {
+		key   string
+		value string
+		found bool
+	}{
+		{"a", "param", true},
+		{"b", "remote_env", true},
+		{"c", "remote_env", true},
+		{"d", "option_env", true},
+		{"e", "default", true},
+		{"f", "default", true},
+		{"random", "file", true},
+		{"missing", "", false},
+	} {
+		value, found := m.Get(test.key)
+		assert.Equal(t, test.value, value, test.key)
+		assert.Equal(t, test.found, found, test.key)
+	}
+
+	// Without the param the flag wins
+	_, _, _, m, err = ConfigFs("myremote:path")
+	require.NoError(t, err)
+	value, _ := m.Get("a")
+	assert.Equal(t, "flag", value)
+
+	// Remotes made on the fly don't read the config file or the
+	// remote environment variables
+	_, configName, _, m, err = ConfigFs(":config_map_test:path")
+	require.NoError(t, err)
+	assert.Equal(t, ":config_map_test", configName)
+	for _, test := range []struct {
+		key   string
+		value string
+		found bool
+	}{
+		{"b", "default", true},
+		{"d", "option_env", true},
+		{"random", "", false},
+	} {
+		value, found := m.Get(test.key)
+		assert.Equal(t, test.value, value, test.key)
+		assert.Equal(t, test.found, found, test.key)
+	}
+
+	// Unknown remotes give an error
+	_, _, _, _, err = ConfigFs("notfound:path")
+	assert.Equal(t, ErrorNotFoundInConfigFile, err)
+}
@@ -2,6 +2,7 @@
 package fserrors
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"net/http"
@@ -248,6 +249,12 @@ func ShouldRetry(err error) bool {
 
 	// Find root cause if available
 	retriable, err := Cause(err)
+
+	// Never retry if the context was cancelled or timed out
+	if err == context.Canceled || err == context.DeadlineExceeded {
+		return false
+	}
+
 	if retriable {
 		return true
 	}
@@ -1,6 +1,7 @@
 package fserrors
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"net"
@@ -95,6 +96,9 @@ func TestShouldRetry(t *testing.T) {
 		{makeNetErr(syscall.EAGAIN), true},
 		{makeNetErr(syscall.Errno(123123123)), false},
 		{&url.Error{Op: "post", URL: "/", Err: io.EOF}, true},
+		{context.Canceled, false},
+		{&url.Error{Op: "post", URL: "/", Err: context.Canceled}, false},
+		{&url.Error{Op: "post", URL: "/", Err: context.DeadlineExceeded}, false},
 		{&url.Error{Op: "post", URL: "/", Err: errUseOfClosedNetworkConnection}, true},
 		{&url.Error{Op: "post", URL: "/", Err: fmt.Errorf("net/http: HTTP/1.x transport connection broken: %v", fmt.Errorf("http: ContentLength=%d with Body length %d", 100663336, 99590598))}, true},
 		{
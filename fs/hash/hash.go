@@ -3,13 +3,18 @@ package hash
 import (
 	"crypto/md5"
 	"crypto/sha1"
+	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
 	"hash"
+	"hash/crc32"
 	"io"
 	"strings"
 
 	"github.com/ncw/rclone/backend/dropbox/dbhash"
+	"github.com/ncw/rclone/backend/onedrive/quickxorhash"
+	"github.com/ncw/rclone/lib/whirlpool"
+	"github.com/ncw/rclone/lib/xxhash64"
 	"github.com/pkg/errors"
 )
 
@@ -20,32 +25,97 @@ type Type int
 // if it is requested to deliver an unsupported hash type.
 var ErrUnsupported = errors.New("hash type not supported")
 
-const (
-	// MD5 indicates MD5 support
-	MD5 Type = 1 << iota
-
-	// SHA1 indicates SHA-1 support
-	SHA1
+// None indicates no hashes are supported
+const None Type = 0
 
-	// Dropbox indicates Dropbox special hash
-	// https://www.dropbox.com/developers/reference/content-hash
-	Dropbox
+// hashDefinition describes a registered hash
+type hashDefinition struct {
+	name     string           // name of the hash as shown to the user
+	width    int              // width of the hex encoded hash in characters
+	newFunc  func() hash.Hash // make a new hasher
+	hashType Type             // the Type allocated to the hash
+}
 
-	// None indicates no hashes are supported
-	None Type = 0
+var (
+	type2hash = map[Type]*hashDefinition{}
+	name2hash = map[string]*hashDefinition{}
 )
 
 // Supported returns a set of all the supported hashes by
 // HashStream and MultiHasher.
-var Supported = NewHashSet(MD5, SHA1, Dropbox)
+//
+// It is updated by RegisterHash.
+var Supported = Set(None)
 
 // Width returns the width in characters for any HashType
-var Width = map[Type]int{
-	MD5:     32,
-	SHA1:    40,
-	Dropbox: 64,
+//
+// It is updated by RegisterHash.
+var Width = map[Type]int{}
+
+// RegisterHash adds a new hash algorithm to the registry and returns
+// the Type allocated to it.
+//
+// name is the name used to select the hash on the command line and
+// shown in listings, width is the length of the hex encoded hash and
+// newFunc makes a new hasher.
+//
+// This should be called from a package level var or init() before
+// any hashing is done.
+func RegisterHash(name string, width int, newFunc func() hash.Hash) Type {
+	if _, found := name2hash[name]; found {
+		panic(fmt.Sprintf("internal error: hash %q registered twice", name))
+	}
+	bit := uint(len(type2hash))
+	if bit >= 63 {
+		panic("internal error: too many hashes registered")
+	}
+	hashType := Type(1 << bit)
+	definition := &hashDefinition{
+		name:     name,
+		width:    width,
+		newFunc:  newFunc,
+		hashType: hashType,
+	}
+	type2hash[hashType] = definition
+	name2hash[name] = definition
+	Supported.Add(hashType)
+	Width[hashType] = width
+	return hashType
 }
 
+var (
+	// MD5 indicates MD5 support
+	MD5 = RegisterHash("MD5", 32, md5.New)
+
+	// SHA1 indicates SHA-1 support
+	SHA1 = RegisterHash("SHA-1", 40, sha1.New)
+
+	// Dropbox indicates Dropbox special hash
+	// https://www.dropbox.com/developers/reference/content-hash
+	Dropbox = RegisterHash("DropboxHash", 64, dbhash.New)
+
+	// QuickXorHash indicates the OneDrive for Business hash
+	// https://docs.microsoft.com/en-us/onedrive/developer/code-snippets/quickxorhash
+	QuickXorHash = RegisterHash("QuickXorHash", 40, quickxorhash.New)
+
+	// SHA256 indicates SHA-256 support
+	SHA256 = RegisterHash("SHA-256", 64, sha256.New)
+
+	// CRC32C indicates CRC-32C (Castagnoli) support as used by
+	// Google Cloud Storage
+	CRC32C = RegisterHash("CRC-32C", 8, func() hash.Hash {
+		return crc32.New(crc32.MakeTable(crc32.Castagnoli))
+	})
+
+	// Whirlpool indicates Whirlpool support
+	Whirlpool = RegisterHash("Whirlpool", 128, whirlpool.New)
+
+	// XXH64 indicates 64 bit xxHash support
+	XXH64 = RegisterHash("XXH64", 16, func() hash.Hash {
+		return xxhash64.New()
+	})
+)
+
 // Stream will calculate hashes of all supported hash types.
 func Stream(r io.Reader) (map[Type]string, error) {
 	return StreamTypes(r, Supported)
@@ -72,35 +142,28 @@ func StreamTypes(r io.Reader, set Set) (map[Type]string, error) {
 // String returns a string representation of the hash type.
 // The function will panic if the hash type is unknown.
 func (h Type) String() string {
-	switch h {
-	case None:
+	if h == None {
 		return "None"
-	case MD5:
-		return "MD5"
-	case SHA1:
-		return "SHA-1"
-	case Dropbox:
-		return "DropboxHash"
-	default:
+	}
+	definition, found := type2hash[h]
+	if !found {
 		err := fmt.Sprintf("internal error: unknown hash type: 0x%x", int(h))
 		panic(err)
 	}
+	return definition.name
 }
 
 // Set a Type from a flag
 func (h *Type) Set(s string) error {
-	switch s {
-	case "None":
+	if s == "None" {
 		*h = None
-	case "MD5":
-		*h = MD5
-	case "SHA-1":
-		*h = SHA1
-	case "DropboxHash":
-		*h = Dropbox
-	default:
+		return nil
+	}
+	definition, found := name2hash[s]
+	if !found {
 		return errors.Errorf("Unknown hash type %q", s)
 	}
+	*h = definition.hashType
 	return nil
 }
 
@@ -119,17 +182,12 @@ func fromTypes(set Set) (map[Type]hash.Hash, error) {
 	var hashers = make(map[Type]hash.Hash)
 	types := set.Array()
 	for _, t := range types {
-		switch t {
-		case MD5:
-			hashers[t] = md5.New()
-		case SHA1:
-			hashers[t] = sha1.New()
-		case Dropbox:
-			hashers[t] = dbhash.New()
-		default:
+		definition, found := type2hash[t]
+		if !found {
 			err := fmt.Sprintf("internal error: Unsupported hash type %v", t)
 			panic(err)
 		}
+		hashers[t] = definition.newFunc()
 	}
 	return hashers, nil
 }
@@ -275,15 +333,19 @@ func (h Set) Count() int {
 	return int(x >> 56)
 }
 
+// Names returns the names of the hash types in the set.
+// The function will panic if it contains an unknown type.
+func (h Set) Names() (names []string) {
+	for _, v := range h.Array() {
+		names = append(names, v.String())
+	}
+	return names
+}
+
 // String returns a string representation of the hash set.
 // The function will panic if it contains an unknown type.
 func (h Set) String() string {
-	a := h.Array()
-	var r []string
-	for _, v := range a {
-		r = append(r, v.String())
-	}
-	return "[" + strings.Join(r, ", ") + "]"
+	return "[" + strings.Join(h.Names(), ", ") + "]"
 }
 
 // Equals checks to see if src == dst, but ignores empty strings
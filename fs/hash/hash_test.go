@@ -2,6 +2,8 @@ package hash_test
 
 import (
 	"bytes"
+	gohash "hash"
+	"hash/crc32"
 	"io"
 	"testing"
 
@@ -69,18 +71,28 @@ var hashTestSet = []hashTest{
 	{
 		input: []byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14},
 		output: map[hash.Type]string{
-			hash.MD5:     "bf13fc19e5151ac57d4252e0e0f87abe",
-			hash.SHA1:    "3ab6543c08a75f292a5ecedac87ec41642d12166",
-			hash.Dropbox: "214d2fcf3566e94c99ad2f59bd993daca46d8521a0c447adf4b324f53fddc0c7",
+			hash.MD5:          "bf13fc19e5151ac57d4252e0e0f87abe",
+			hash.SHA1:         "3ab6543c08a75f292a5ecedac87ec41642d12166",
+			hash.Dropbox:      "214d2fcf3566e94c99ad2f59bd993daca46d8521a0c447adf4b324f53fddc0c7",
+			hash.QuickXorHash: "0110c000085000031c0001095ec00218d0000700",
+			hash.SHA256:       "c839e57675862af5c21bd0a15413c3ec579e0d5522dab600bc6c3489b05b8f54",
+			hash.CRC32C:       "4d8ae017",
+			hash.Whirlpool:    "eddf52133d4566d763f716e853d6e4efbabd29e2c2e63f56747b1596172851d34c2df9944beb6640dbdbe3d9b4eb61180720a79e3d15baff31c91e43d63869a4",
+			hash.XXH64:        "13cd7ced0c4af679",
 		},
 	},
 	// Empty data set
 	{
 		input: []byte{},
 		output: map[hash.Type]string{
-			hash.MD5:     "d41d8cd98f00b204e9800998ecf8427e",
-			hash.SHA1:    "da39a3ee5e6b4b0d3255bfef95601890afd80709",
-			hash.Dropbox: "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855",
+			hash.MD5:          "d41d8cd98f00b204e9800998ecf8427e",
+			hash.SHA1:         "da39a3ee5e6b4b0d3255bfef95601890afd80709",
+			hash.Dropbox:      "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855",
+			hash.QuickXorHash: "0000000000000000000000000000000000000000",
+			hash.SHA256:       "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855",
+			hash.CRC32C:       "00000000",
+			hash.Whirlpool:    "19fa61d75522a4669b44e39c1d2e1726c530232130d407f89afee0964997f7a73e83be698b288febcf88e3e03c4f0757ea8964e59b63d93708b138cc42a66eb3",
+			hash.XXH64:        "ef46db3751d8e999",
 		},
 	},
 }
@@ -165,3 +177,38 @@ func TestHashStringer(t *testing.T) {
 	h = hash.None
 	assert.Equal(t, h.String(), "None")
 }
+
+func TestHashWidth(t *testing.T) {
+	for _, test := range hashTestSet {
+		for k, v := range test.output {
+			assert.Equal(t, hash.Width[k], len(v), k.String())
+		}
+	}
+}
+
+func TestTypeSetString(t *testing.T) {
+	for _, ht := range append(hash.Supported.Array(), hash.None) {
+		var got hash.Type
+		require.NoError(t, got.Set(ht.String()))
+		assert.Equal(t, ht, got)
+	}
+	var h hash.Type
+	assert.Error(t, h.Set("potato"))
+}
+
+// This modifies hash.Supported so should be the last test
+func TestRegisterHash(t *testing.T) {
+	newCRC32 := func() gohash.Hash {
+		return crc32.NewIEEE()
+	}
+	ht := hash.RegisterHash("TestHash", 8, newCRC32)
+	assert.Equal(t, "TestHash", ht.String())
+	assert.True(t, hash.Supported.Contains(ht))
+	assert.Equal(t, 8, hash.Width[ht])
+	sums, err := hash.StreamTypes(bytes.NewBufferString("123456789"), hash.NewHashSet(ht))
+	require.NoError(t, err)
+	assert.Equal(t, "cbf43926", sums[ht])
+	assert.Panics(t, func() {
+		hash.RegisterHash("TestHash", 8, newCRC32)
+	})
+}
@@ -2,6 +2,7 @@
 package list
 
 import (
+	"context"
 	"sort"
 	"strings"
 
@@ -18,9 +19,9 @@ import (
 // files and directories passing the filter will be added.
 //
 // Files will be returned in sorted order
-func DirSorted(f fs.Fs, includeAll bool, dir string) (entries fs.DirEntries, err error) {
+func DirSorted(ctx context.Context, f fs.Fs, includeAll bool, dir string) (entries fs.DirEntries, err error) {
 	// Get unfiltered entries from the fs
-	entries, err = f.List(dir)
+	entries, err = f.List(ctx, dir)
 	if err != nil {
 		return nil, err
 	}
@@ -31,11 +32,71 @@ func DirSorted(f fs.Fs, includeAll bool, dir string) (entries fs.DirEntries, err
 		fs.Debugf(dir, "Excluded from sync (and deletion)")
 		return nil, nil
 	}
-	return filterAndSortDir(entries, includeAll, dir, filter.Active.IncludeObject, filter.Active.IncludeDirectory(f))
+	return filterAndSortDir(entries, includeAll, dir, includeObject(ctx), filter.Active.IncludeDirectory(ctx, f))
+}
+
+// DirPaged reads Object and *Dir for the given Fs calling callback
+// with each page of entries as it is read.
+//
+// dir is the start directory, "" for root
+//
+// If includeAll is specified all files will be added, otherwise only
+// files and directories passing the filter will be added.
+//
+// The entries in each page are filtered but not sorted.  If the Fs
+// doesn't support ListP then callback is called once with the whole
+// sorted directory as DirSorted would return it.
+func DirPaged(ctx context.Context, f fs.Fs, includeAll bool, dir string, callback fs.ListRCallback) (err error) {
+	listP := f.Features().ListP
+	// The exclude file could be in any page so fall back to
+	// reading the whole directory if it is in use
+	if listP == nil || (!includeAll && filter.Active.Opt.ExcludeFile != "") {
+		entries, err := DirSorted(ctx, f, includeAll, dir)
+		if err != nil {
+			return err
+		}
+		return callback(entries)
+	}
+	includeDirectory := filter.Active.IncludeDirectory(ctx, f)
+	return listP(ctx, dir, func(entries fs.DirEntries) error {
+		entries, err := filterDir(entries, includeAll, dir, includeObject(ctx), includeDirectory)
+		if err != nil {
+			return err
+		}
+		return callback(entries)
+	})
+}
+
+// includeObject returns a function which checks whether an object
+// passes the active filter using ctx
+func includeObject(ctx context.Context) func(fs.Object) bool {
+	return func(o fs.Object) bool {
+		return filter.Active.IncludeObject(ctx, o)
+	}
 }
 
 // filter (if required) and check the entries, then sort them
 func filterAndSortDir(entries fs.DirEntries, includeAll bool, dir string,
+	IncludeObject func(o fs.Object) bool,
+	IncludeDirectory func(remote string) (bool, error)) (newEntries fs.DirEntries, err error) {
+	entries, err = filterDir(entries, includeAll, dir, IncludeObject, IncludeDirectory)
+	if err != nil {
+		return nil, err
+	}
+
+	// Sort the directory entries by Remote
+	//
+	// We use a stable sort here just in case there are
+	// duplicates. Assuming the remote delivers the entries in a
+	// consistent order, this will give the best user experience
+	// in syncing as it will use the first entry for the sync
+	// comparison.
+	sort.Stable(entries)
+	return entries, nil
+}
+
+// filter (if required) and check the entries
+func filterDir(entries fs.DirEntries, includeAll bool, dir string,
 	IncludeObject func(o fs.Object) bool,
 	IncludeDirectory func(remote string) (bool, error)) (newEntries fs.DirEntries, err error) {
 	newEntries = entries[:0] // in place filter
@@ -88,15 +149,5 @@ func filterAndSortDir(entries fs.DirEntries, includeAll bool, dir string,
 			newEntries = append(newEntries, entry)
 		}
 	}
-	entries = newEntries
-
-	// Sort the directory entries by Remote
-	//
-	// We use a stable sort here just in case there are
-	// duplicates. Assuming the remote delivers the entries in a
-	// consistent order, this will give the best user experience
-	// in syncing as it will use the first entry for the sync
-	// comparison.
-	sort.Stable(entries)
-	return entries, nil
+	return newEntries, nil
 }
@@ -1,8 +1,11 @@
 package fs
 
 import (
+	"encoding/json"
 	"fmt"
 	"log"
+	"strings"
+	"time"
 
 	"github.com/pkg/errors"
 )
@@ -73,9 +76,99 @@ var LogPrint = func(level LogLevel, text string) {
 	log.Print(text)
 }
 
+// LogValueItem is a keyed value which can be passed as an argument
+// to the logging calls to add structured information to the JSON log
+type LogValueItem struct {
+	key    string
+	value  interface{}
+	render bool
+}
+
+// LogValue returns a LogValueItem which adds key: value to the JSON
+// log and prints value in the text log.
+func LogValue(key string, value interface{}) LogValueItem {
+	return LogValueItem{key: key, value: value, render: true}
+}
+
+// LogValueHide returns a LogValueItem which adds key: value to the
+// JSON log but prints as an empty string in the text log.  This is
+// useful for adding fields which would clutter the text log.
+func LogValueHide(key string, value interface{}) LogValueItem {
+	return LogValueItem{key: key, value: value, render: false}
+}
+
+// String returns the representation of the value or an empty string
+// if it shouldn't be printed in the text log
+func (j LogValueItem) String() string {
+	if !j.render {
+		return ""
+	}
+	return fmt.Sprint(j.value)
+}
+
+// logFsName returns the remote:path of the Fs o refers to or "" if
+// it doesn't refer to one
+func logFsName(o interface{}) string {
+	var f Info
+	switch x := o.(type) {
+	case Info:
+		f = x
+	case ObjectInfo:
+		f = x.Fs()
+	}
+	if f == nil {
+		return ""
+	}
+	return f.Name() + ":" + f.Root()
+}
+
+// logJSON returns the log entry as a line of JSON
+//
+// Errors and LogValueItems in args are added as fields.
+func logJSON(level LogLevel, o interface{}, text string, args []interface{}) string {
+	fields := map[string]interface{}{
+		"level": strings.ToLower(level.String()),
+		"time":  time.Now().Format(time.RFC3339Nano),
+		"msg":   text,
+	}
+	if object := fmt.Sprintf("%v", o); o != nil && object != "" {
+		fields["object"] = object
+		fields["objectType"] = fmt.Sprintf("%T", o)
+		if fsName := logFsName(o); fsName != "" {
+			fields["fs"] = fsName
+		}
+	}
+	for _, arg := range args {
+		switch x := arg.(type) {
+		case LogValueItem:
+			fields[x.key] = x.value
+		case error:
+			fields["error"] = x.Error()
+			fields["errorType"] = fmt.Sprintf("%T", errors.Cause(x))
+		}
+	}
+	out, err := json.Marshal(fields)
+	if err != nil {
+		// Fall back to the printed values if any can't be encoded
+		for key, value := range fields {
+			if _, ok := value.(string); !ok {
+				fields[key] = fmt.Sprint(value)
+			}
+		}
+		out, _ = json.Marshal(fields)
+	}
+	return string(out)
+}
+
 // LogPrintf produces a log string from the arguments passed in
+//
+// If --use-json-log is set then the log string is a JSON object.
 func LogPrintf(level LogLevel, o interface{}, text string, args ...interface{}) {
 	out := fmt.Sprintf(text, args...)
+	if Config.UseJSONLog {
+		LogPrint(level, logJSON(level, o, out, args))
+		return
+	}
 	if o != nil {
 		out = fmt.Sprintf("%v: %s", o, out)
 	}
@@ -2,9 +2,7 @@
 package log
 
 import (
-	"io"
 	"log"
-	"os"
 	"reflect"
 	"runtime"
 	"strings"
@@ -15,11 +13,17 @@ import (
 
 // Flags
 var (
-	logFile        = flags.StringP("log-file", "", "", "Log everything to this file")
-	useSyslog      = flags.BoolP("syslog", "", false, "Use Syslog for logging")
-	syslogFacility = flags.StringP("syslog-facility", "", "DAEMON", "Facility for syslog, eg KERN,USER,...")
+	logFile           = flags.StringP("log-file", "", "", "Log everything to this file")
+	useSyslog         = flags.BoolP("syslog", "", false, "Use Syslog for logging")
+	syslogFacility    = flags.StringP("syslog-facility", "", "DAEMON", "Facility for syslog, eg KERN,USER,...")
+	logFileMaxSize    = fs.SizeSuffix(-1)
+	logFileMaxBackups = flags.IntP("log-file-max-backups", "", 5, "Number of rotated log files to keep with --log-file-max-size")
 )
 
+func init() {
+	flags.VarP(&logFileMaxSize, "log-file-max-size", "", "Rotate the --log-file when it reaches this size.")
+}
+
 // fnName returns the name of the calling +2 function
 func fnName() string {
 	pc, _, _, ok := runtime.Caller(2)
@@ -68,16 +72,19 @@ func Trace(o interface{}, format string, a ...interface{}) func(string, ...inter
 func InitLogging() {
 	// Log file output
 	if *logFile != "" {
-		f, err := os.OpenFile(*logFile, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0640)
+		f, err := openRotatingFile(*logFile, int64(logFileMaxSize), *logFileMaxBackups, redirectStderr)
 		if err != nil {
 			log.Fatalf("Failed to open log file: %v", err)
 		}
-		_, err = f.Seek(0, io.SeekEnd)
-		if err != nil {
-			fs.Errorf(nil, "Failed to seek log file to end: %v", err)
-		}
 		log.SetOutput(f)
-		redirectStderr(f)
+	}
+
+	// JSON output - the JSON has its own timestamp and level
+	if fs.Config.UseJSONLog {
+		log.SetFlags(0)
+		fs.LogPrint = func(level fs.LogLevel, text string) {
+			log.Print(text)
+		}
 	}
 
 	// Syslog output
@@ -88,3 +95,8 @@ func InitLogging() {
 		startSysLog()
 	}
 }
+
+// Redirected returns true if the log has been redirected from stderr
+func Redirected() bool {
+	return *useSyslog || *logFile != ""
+}
@@ -0,0 +1,111 @@
+// Size based rotation of the log file
+
+package log
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sync"
+)
+
+// rotatingFile is an io.Writer for the log file which renames it to
+// file.1, file.2, ... and starts a new one when it grows too big
+type rotatingFile struct {
+	mu         sync.Mutex
+	path       string
+	maxSize    int64            // rotate before growing beyond this, <= 0 for never
+	maxBackups int              // number of rotated files to keep
+	onOpen     func(f *os.File) // called with each file opened, may be nil
+	f          *os.File
+	size       int64 // bytes in f
+}
+
+// openRotatingFile opens the log file at path for appending
+func openRotatingFile(path string, maxSize int64, maxBackups int, onOpen func(f *os.File)) (*rotatingFile, error) {
+	r := &rotatingFile{
+		path:       path,
+		maxSize:    maxSize,
+		maxBackups: maxBackups,
+		onOpen:     onOpen,
+	}
+	err := r.open()
+	if err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// open opens the log file
+//
+// Errors after the file is open are written to it as it can't be
+// logged from here.
+func (r *rotatingFile) open() error {
+	f, err := os.OpenFile(r.path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0640)
+	if err != nil {
+		return err
+	}
+	size, err := f.Seek(0, io.SeekEnd)
+	if err != nil {
+		size = 0
+		_, _ = fmt.Fprintf(f, "Failed to seek log file to end: %v\n", err)
+	}
+	r.f, r.size = f, size
+	if r.onOpen != nil {
+		r.onOpen(f)
+	}
+	return nil
+}
+
+// backupName returns the name of the i-th rotated file
+func (r *rotatingFile) backupName(i int) string {
+	if i == 0 {
+		return r.path
+	}
+	return fmt.Sprintf("%s.%d", r.path, i)
+}
+
+// rotate closes the log file, shuffles up the rotated files deleting
+// the oldest and opens a new log file
+//
+// call with mu held
+func (r *rotatingFile) rotate() error {
+	err := r.f.Close()
+	if err != nil {
+		return err
+	}
+	// Remove the oldest first as rename can't overwrite on all OSes
+	err = os.Remove(r.backupName(r.maxBackups))
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	for i := r.maxBackups; i > 0; i-- {
+		err = os.Rename(r.backupName(i-1), r.backupName(i))
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return r.open()
+}
+
+// Write writes p to the log file rotating it first if necessary
+func (r *rotatingFile) Write(p []byte) (n int, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.maxSize > 0 && r.size > 0 && r.size+int64(len(p)) > r.maxSize {
+		rotateErr := r.rotate()
+		if rotateErr != nil {
+			// Carry on with the old file if possible, or
+			// reopen it if it was closed
+			if _, statErr := r.f.Stat(); statErr != nil {
+				if openErr := r.open(); openErr != nil {
+					return 0, openErr
+				}
+			}
+			_, _ = fmt.Fprintf(r.f, "Failed to rotate log file: %v\n", rotateErr)
+		}
+	}
+	n, err = r.f.Write(p)
+	r.size += int64(n)
+	return n, err
+}
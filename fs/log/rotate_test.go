@@ -0,0 +1,93 @@
+package log
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRotatingFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rclone-log-test")
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, os.RemoveAll(dir))
+	}()
+	path := filepath.Join(dir, "rclone.log")
+	require.NoError(t, ioutil.WriteFile(path, []byte("old\n"), 0600))
+
+	opened := 0
+	r, err := openRotatingFile(path, 10, 2, func(f *os.File) { opened++ })
+	require.NoError(t, err)
+	defer func() {
+		_ = r.f.Close()
+	}()
+	assert.Equal(t, 1, opened)
+	assert.Equal(t, int64(4), r.size)
+
+	contents := func(name string) string {
+		data, err := ioutil.ReadFile(name)
+		if os.IsNotExist(err) {
+			return "<missing>"
+		}
+		require.NoError(t, err)
+		return string(data)
+	}
+	write := func(s string) {
+		n, err := r.Write([]byte(s))
+		require.NoError(t, err)
+		assert.Equal(t, len(s), n)
+	}
+
+	// Appends to the existing file while it fits
+	write("12345\n")
+	assert.Equal(t, "old\n12345\n", contents(path))
+	assert.Equal(t, "<missing>", contents(path+".1"))
+
+	// Rotates when it would grow too big
+	write("abc\n")
+	assert.Equal(t, 2, opened)
+	assert.Equal(t, "abc\n", contents(path))
+	assert.Equal(t, "old\n12345\n", contents(path+".1"))
+
+	// A line bigger than the maximum is still written whole
+	write("0123456789012\n")
+	assert.Equal(t, "0123456789012\n", contents(path))
+	assert.Equal(t, "abc\n", contents(path+".1"))
+	assert.Equal(t, "old\n12345\n", contents(path+".2"))
+
+	// Only maxBackups files are kept
+	write("def\n")
+	assert.Equal(t, "def\n", contents(path))
+	assert.Equal(t, "0123456789012\n", contents(path+".1"))
+	assert.Equal(t, "abc\n", contents(path+".2"))
+	assert.Equal(t, "<missing>", contents(path+".3"))
+	assert.Equal(t, 4, opened)
+}
+
+func TestRotatingFileNoMaxSize(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rclone-log-test")
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, os.RemoveAll(dir))
+	}()
+	path := filepath.Join(dir, "rclone.log")
+
+	r, err := openRotatingFile(path, -1, 2, nil)
+	require.NoError(t, err)
+	defer func() {
+		_ = r.f.Close()
+	}()
+	for i := 0; i < 10; i++ {
+		_, err = r.Write([]byte("0123456789\n"))
+		require.NoError(t, err)
+	}
+	fi, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, int64(110), fi.Size())
+	_, err = os.Stat(path + ".1")
+	assert.True(t, os.IsNotExist(err))
+}
@@ -1,6 +1,79 @@
 package fs
 
-import "github.com/spf13/pflag"
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
 
 // Check it satisfies the interface
 var _ pflag.Value = (*LogLevel)(nil)
+
+func TestLogValue(t *testing.T) {
+	assert.Equal(t, "123", LogValue("size", 123).String())
+	assert.Equal(t, "", LogValueHide("size", 123).String())
+	assert.Equal(t, "Copied (new)", fmt.Sprintf("%s%v", "Copied (new)", LogValueHide("size", 123)))
+}
+
+type logTestError struct{}
+
+func (logTestError) Error() string { return "potato" }
+
+func TestLogJSON(t *testing.T) {
+	decode := func(in string) (out map[string]interface{}) {
+		require.NoError(t, json.Unmarshal([]byte(in), &out))
+		return out
+	}
+
+	out := decode(logJSON(LogLevelNotice, nil, "hello", nil))
+	assert.Equal(t, "notice", out["level"])
+	assert.Equal(t, "hello", out["msg"])
+	_, err := time.Parse(time.RFC3339Nano, out["time"].(string))
+	assert.NoError(t, err)
+	assert.NotContains(t, out, "object")
+
+	err = errors.Wrap(logTestError{}, "failed")
+	out = decode(logJSON(LogLevelError, "file.txt", "Copied: failed: potato", []interface{}{LogValueHide("size", 42), err}))
+	assert.Equal(t, "error", out["level"])
+	assert.Equal(t, "file.txt", out["object"])
+	assert.Equal(t, "string", out["objectType"])
+	assert.Equal(t, float64(42), out["size"])
+	assert.Equal(t, "failed: potato", out["error"])
+	assert.Equal(t, "fs.logTestError", out["errorType"])
+
+	// values which can't be encoded are printed
+	out = decode(logJSON(LogLevelDebug, nil, "hello", []interface{}{LogValue("fn", func() {})}))
+	assert.Equal(t, "hello", out["msg"])
+	assert.Contains(t, out["fn"], "0x")
+}
+
+func TestLogPrintfJSON(t *testing.T) {
+	oldLogPrint, oldUseJSONLog := LogPrint, Config.UseJSONLog
+	defer func() {
+		LogPrint, Config.UseJSONLog = oldLogPrint, oldUseJSONLog
+	}()
+	var gotLevel LogLevel
+	var gotText string
+	LogPrint = func(level LogLevel, text string) {
+		gotLevel, gotText = level, text
+	}
+
+	Config.UseJSONLog = false
+	LogPrintf(LogLevelInfo, "file.txt", "Deleted%v", LogValueHide("size", 1))
+	assert.Equal(t, LogLevelInfo, gotLevel)
+	assert.Equal(t, "file.txt: Deleted", gotText)
+
+	Config.UseJSONLog = true
+	LogPrintf(LogLevelInfo, "file.txt", "Deleted%v", LogValueHide("size", 1))
+	var out map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(gotText), &out))
+	assert.Equal(t, "Deleted", out["msg"])
+	assert.Equal(t, "file.txt", out["object"])
+	assert.Equal(t, float64(1), out["size"])
+}
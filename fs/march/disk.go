@@ -0,0 +1,686 @@
+// This file contains the machinery for matching directory listings
+// which are too big to sort in memory (see --list-cutoff)
+
+package march
+
+import (
+	"bufio"
+	"container/heap"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/ncw/rclone/fs"
+	"github.com/ncw/rclone/fs/hash"
+	"github.com/ncw/rclone/fs/list"
+	"github.com/pkg/errors"
+)
+
+// listing is a directory listing which is either held in memory or,
+// if it was too big, has been sorted on disk
+type listing struct {
+	entries fs.DirEntries // the entries if held in memory
+	disk    *diskSorter   // the entries if sorted on disk or nil
+}
+
+// onDisk returns true if the listing has been sorted on disk
+func (l *listing) onDisk() bool {
+	return l != nil && l.disk != nil
+}
+
+// dirEntries returns the in memory entries of the listing
+func (l *listing) dirEntries() fs.DirEntries {
+	if l == nil {
+		return nil
+	}
+	return l.entries
+}
+
+// iter returns an iterator over the entries of the listing in
+// matchEntries order
+func (l *listing) iter(transforms []matchTransformFn) (matchIter, error) {
+	if l.onDisk() {
+		return l.disk.iter()
+	}
+	return &sliceIter{es: newMatchEntries(l.dirEntries(), transforms)}, nil
+}
+
+// remove any temporary files the listing is using
+func (l *listing) remove() {
+	if l.onDisk() {
+		l.disk.remove()
+	}
+}
+
+// listDirPaged lists dir reading it a page at a time.
+//
+// If the number of entries goes above --list-cutoff then the entries
+// are sorted in runs onto disk rather than being kept in memory.
+func (m *March) listDirPaged(f fs.Fs, includeAll bool, dir string) (l *listing, err error) {
+	cutoff := fs.Config.ListCutoff
+	l = &listing{}
+	err = list.DirPaged(m.ctx, f, includeAll, dir, func(entries fs.DirEntries) (err error) {
+		l.entries = append(l.entries, entries...)
+		if cutoff <= 0 || len(l.entries) <= cutoff {
+			return nil
+		}
+		if l.disk == nil {
+			fs.Debugf(f, "Listing of %q has more than %d entries - sorting on disk", dir, cutoff)
+			l.disk, err = newDiskSorter(m.ctx, f, m.transforms, m.hashType)
+			if err != nil {
+				return err
+			}
+		}
+		err = l.disk.add(l.entries)
+		l.entries = nil
+		return err
+	})
+	if err == nil && l.onDisk() && len(l.entries) > 0 {
+		err = l.disk.add(l.entries)
+		l.entries = nil
+	}
+	if err != nil {
+		l.remove()
+		return nil, err
+	}
+	return l, nil
+}
+
+// processJobStream works out what to do for job when one or both of
+// the listings are sorted on disk.
+//
+// It matches the listings as it reads them rather than reading them
+// into memory first so the callbacks are called in name order.
+func (m *March) processJobStream(job listDirJob, srcList, dstList *listing) (jobs []listDirJob) {
+	srcIter, err := srcList.iter(m.transforms)
+	if err != nil {
+		fs.Errorf(job.srcRemote, "error reading source directory: %v", err)
+		fs.CountError(err)
+		return nil
+	}
+	dstIter, err := dstList.iter(m.transforms)
+	if err != nil {
+		fs.Errorf(job.dstRemote, "error reading destination directory: %v", err)
+		fs.CountError(err)
+		return nil
+	}
+	aborted := false
+	err = matchStream(srcIter, dstIter, func(src, dst fs.DirEntry) bool {
+		if m.aborting() {
+			aborted = true
+			return false
+		}
+		switch {
+		case dst == nil:
+			jobs = m.srcOnly(job, src, jobs)
+		case src == nil:
+			jobs = m.dstOnly(job, dst, jobs)
+		default:
+			jobs = m.match(job, matchPair{src: src, dst: dst}, jobs)
+		}
+		return true
+	})
+	if aborted {
+		return nil
+	}
+	if err != nil {
+		fs.Errorf(job.srcRemote, "error reading sorted directory listing: %v", err)
+		fs.CountError(err)
+		return nil
+	}
+	return jobs
+}
+
+// matchIter returns matchEntry~s in matchEntries order
+type matchIter interface {
+	// next returns the next entry or ok == false if there are
+	// no more entries
+	next() (me matchEntry, ok bool, err error)
+}
+
+// sliceIter is a matchIter over an in memory matchEntries
+type sliceIter struct {
+	es matchEntries
+	i  int
+}
+
+// next returns the next entry
+func (it *sliceIter) next() (me matchEntry, ok bool, err error) {
+	if it.i >= len(it.es) {
+		return me, false, nil
+	}
+	me = it.es[it.i]
+	it.es[it.i] = matchEntry{} // free the memory
+	it.i++
+	return me, true, nil
+}
+
+// matchCursor reads a matchIter dropping duplicates and checking the
+// order as it goes
+type matchCursor struct {
+	it      matchIter
+	where   string     // "source" or "destination"
+	cur     matchEntry // the current entry if ok
+	ok      bool       // set if cur is valid
+	started bool       // set if we've read an entry
+	prev    string     // the name of the previous entry
+}
+
+// advance the cursor to the next entry with a different name
+func (c *matchCursor) advance() error {
+	for {
+		me, ok, err := c.it.next()
+		if err != nil {
+			c.ok = false
+			return err
+		}
+		c.ok = ok
+		if !ok {
+			return nil
+		}
+		if c.started {
+			if me.name == c.prev {
+				fs.Logf(me.entry, "Duplicate %s found in %s - ignoring", fs.DirEntryType(me.entry), c.where)
+				continue
+			} else if me.name < c.prev {
+				// this should never happen since we sort the listings
+				panic("Out of order listing in " + c.where)
+			}
+		}
+		c.started = true
+		c.prev = me.name
+		c.cur = me
+		return nil
+	}
+}
+
+// matchStream merges the src and dst iterators calling fn for each
+// entry with src or dst set to nil if the entry is only on one
+// side.  If fn returns false the matching stops.
+//
+// This does the same as matchListings but without needing the
+// listings in memory.
+func matchStream(srcIter, dstIter matchIter, fn func(src, dst fs.DirEntry) bool) error {
+	src := matchCursor{it: srcIter, where: "source"}
+	dst := matchCursor{it: dstIter, where: "destination"}
+	if err := src.advance(); err != nil {
+		return err
+	}
+	if err := dst.advance(); err != nil {
+		return err
+	}
+	for src.ok || dst.ok {
+		var carryOn bool
+		var err error
+		switch {
+		case src.ok && dst.ok && src.cur.name == dst.cur.name:
+			carryOn = fn(src.cur.entry, dst.cur.entry)
+			err = src.advance()
+			if err == nil {
+				err = dst.advance()
+			}
+		case !dst.ok || (src.ok && src.cur.name < dst.cur.name):
+			carryOn = fn(src.cur.entry, nil)
+			err = src.advance()
+		default:
+			carryOn = fn(nil, dst.cur.entry)
+			err = dst.advance()
+		}
+		if !carryOn {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// diskEntry is the form a matchEntry is stored in on disk
+type diskEntry struct {
+	Name    string     `json:"n"`
+	Leaf    string     `json:"l"`
+	Remote  string     `json:"r"`
+	Dir     bool       `json:"d,omitempty"`
+	Size    int64      `json:"s"`
+	ModTime *time.Time `json:"t,omitempty"` // nil if not read
+	Hash    *string    `json:"h,omitempty"` // nil if not read
+	Items   int64      `json:"i,omitempty"`
+	ID      string     `json:"id,omitempty"`
+}
+
+// needModTime returns whether the comparison of the objects needs
+// their modification times.
+//
+// Reading the modification time can take a request per object on
+// some backends (eg s3) so it is only stored on disk if it is likely
+// to be needed - it is read when asked for otherwise.
+func needModTime() bool {
+	return !fs.Config.SizeOnly && !fs.Config.CheckSum
+}
+
+// needHash returns whether the comparison of the objects needs their
+// hashes.
+//
+// With --checksum the hashes are compared instead of the modification
+// times so they are stored on disk to save finding every object again
+// when the listing is read back.
+func needHash() bool {
+	return fs.Config.CheckSum
+}
+
+// newDiskEntry makes a diskEntry from a matchEntry
+//
+// If hashType isn't hash.None then the hash of that type is stored
+// too.
+func newDiskEntry(me *matchEntry, withModTime bool, hashType hash.Type) *diskEntry {
+	de := &diskEntry{
+		Name:   me.name,
+		Leaf:   me.leaf,
+		Remote: me.entry.Remote(),
+		Size:   me.entry.Size(),
+	}
+	dir, isDir := me.entry.(fs.Directory)
+	if isDir || withModTime {
+		modTime := me.entry.ModTime()
+		de.ModTime = &modTime
+	}
+	if o, isObject := me.entry.(fs.Object); isObject && hashType != hash.None {
+		sum, err := o.Hash(hashType)
+		if err == nil {
+			de.Hash = &sum
+		} else {
+			fs.Debugf(o, "Failed to read hash for sorted listing: %v", err)
+		}
+	}
+	if isDir {
+		de.Dir = true
+		de.Items = dir.Items()
+		de.ID = dir.ID()
+	}
+	return de
+}
+
+// matchEntry turns the diskEntry back into a matchEntry with objects
+// from f.  hashType is the type of the hash stored, if any.
+func (de *diskEntry) matchEntry(ctx context.Context, f fs.Fs, hashType hash.Type) matchEntry {
+	var entry fs.DirEntry
+	if de.Dir {
+		var modTime time.Time
+		if de.ModTime != nil {
+			modTime = *de.ModTime
+		}
+		entry = fs.NewDir(de.Remote, modTime).SetSize(de.Size).SetItems(de.Items).SetID(de.ID)
+	} else {
+		entry = &diskObject{
+			ctx:      ctx,
+			f:        f,
+			remote:   de.Remote,
+			size:     de.Size,
+			modTime:  de.ModTime,
+			hashType: hashType,
+			hash:     de.Hash,
+		}
+	}
+	return matchEntry{
+		entry: entry,
+		leaf:  de.Leaf,
+		name:  de.Name,
+	}
+}
+
+// diskSorter sorts a listing by writing it to disk in sorted runs
+// then merging the runs as they are read back
+type diskSorter struct {
+	ctx        context.Context
+	f          fs.Fs
+	transforms []matchTransformFn
+	hashType   hash.Type  // hash to store with the entries if needed
+	dir        string     // temporary directory holding the runs
+	runs       []string   // file names of the sorted runs
+	mu         sync.Mutex // protects open
+	open       []*os.File // files opened for reading
+}
+
+// newDiskSorter makes a new diskSorter for entries from f
+//
+// hashType is the hash that the objects will be compared with.
+func newDiskSorter(ctx context.Context, f fs.Fs, transforms []matchTransformFn, hashType hash.Type) (*diskSorter, error) {
+	dir, err := ioutil.TempDir("", "rclone-list-")
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to make temporary directory for listing")
+	}
+	return &diskSorter{
+		ctx:        ctx,
+		f:          f,
+		transforms: transforms,
+		hashType:   hashType,
+		dir:        dir,
+	}, nil
+}
+
+// add sorts entries and writes them to disk as a new run
+func (ds *diskSorter) add(entries fs.DirEntries) (err error) {
+	es := newMatchEntries(entries, ds.transforms)
+	withModTime := needModTime()
+	hashType := hash.None
+	if needHash() {
+		hashType = ds.hashType
+	}
+	name := filepath.Join(ds.dir, fmt.Sprintf("run-%06d.json", len(ds.runs)))
+	fd, err := os.Create(name)
+	if err != nil {
+		return errors.Wrap(err, "failed to create sorted listing")
+	}
+	defer fs.CheckClose(fd, &err)
+	out := bufio.NewWriter(fd)
+	enc := json.NewEncoder(out)
+	for i := range es {
+		err = enc.Encode(newDiskEntry(&es[i], withModTime, hashType))
+		if err != nil {
+			return errors.Wrap(err, "failed to write sorted listing")
+		}
+	}
+	err = out.Flush()
+	if err != nil {
+		return errors.Wrap(err, "failed to write sorted listing")
+	}
+	ds.runs = append(ds.runs, name)
+	return nil
+}
+
+// iter opens the runs returning an iterator which merges them
+func (ds *diskSorter) iter() (matchIter, error) {
+	it := &diskIter{ds: ds}
+	for i, name := range ds.runs {
+		fd, err := os.Open(name)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to open sorted listing")
+		}
+		ds.mu.Lock()
+		ds.open = append(ds.open, fd)
+		ds.mu.Unlock()
+		run := &diskRun{
+			index: i,
+			dec:   json.NewDecoder(bufio.NewReader(fd)),
+		}
+		ok, err := run.read(ds)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			it.runs = append(it.runs, run)
+		}
+	}
+	heap.Init(it)
+	return it, nil
+}
+
+// remove closes any open files and removes the runs from disk
+func (ds *diskSorter) remove() {
+	ds.mu.Lock()
+	for _, fd := range ds.open {
+		_ = fd.Close()
+	}
+	ds.open = nil
+	ds.mu.Unlock()
+	err := os.RemoveAll(ds.dir)
+	if err != nil {
+		fs.Errorf(nil, "Failed to remove temporary listing: %v", err)
+	}
+}
+
+// diskRun is a sorted run being read back from disk
+type diskRun struct {
+	index int           // index of the run so the merge is stable
+	dec   *json.Decoder // reads the run
+	head  matchEntry    // the current entry
+}
+
+// read the next entry from the run into head returning ok = false if
+// there are no more entries
+func (run *diskRun) read(ds *diskSorter) (ok bool, err error) {
+	var de diskEntry
+	err = run.dec.Decode(&de)
+	if err == io.EOF {
+		return false, nil
+	} else if err != nil {
+		return false, errors.Wrap(err, "failed to read sorted listing")
+	}
+	run.head = de.matchEntry(ds.ctx, ds.f, ds.hashType)
+	return true, nil
+}
+
+// diskIter merges the runs of a diskSorter
+//
+// It is a heap of runs ordered by their head entries.
+type diskIter struct {
+	ds   *diskSorter
+	runs []*diskRun
+}
+
+// Len satisfy heap.Interface
+func (it *diskIter) Len() int {
+	return len(it.runs)
+}
+
+// Less satisfy heap.Interface
+//
+// Equal entries are ordered by run which keeps the merge stable
+func (it *diskIter) Less(i, j int) bool {
+	a, b := it.runs[i], it.runs[j]
+	if a.head.less(&b.head) {
+		return true
+	}
+	if b.head.less(&a.head) {
+		return false
+	}
+	return a.index < b.index
+}
+
+// Swap satisfy heap.Interface
+func (it *diskIter) Swap(i, j int) {
+	it.runs[i], it.runs[j] = it.runs[j], it.runs[i]
+}
+
+// Push satisfy heap.Interface
+func (it *diskIter) Push(x interface{}) {
+	it.runs = append(it.runs, x.(*diskRun))
+}
+
+// Pop satisfy heap.Interface
+func (it *diskIter) Pop() interface{} {
+	n := len(it.runs)
+	run := it.runs[n-1]
+	it.runs[n-1] = nil
+	it.runs = it.runs[:n-1]
+	return run
+}
+
+// next returns the smallest entry from all the runs
+func (it *diskIter) next() (me matchEntry, ok bool, err error) {
+	if len(it.runs) == 0 {
+		return me, false, nil
+	}
+	run := it.runs[0]
+	me = run.head
+	more, err := run.read(it.ds)
+	if err != nil {
+		return me, false, err
+	}
+	if more {
+		heap.Fix(it, 0)
+	} else {
+		heap.Pop(it)
+	}
+	return me, true, nil
+}
+
+// diskObject stands in for an Object read back from a listing sorted
+// on disk.
+//
+// It answers what it can from the listing and finds the real Object
+// with NewObject when it is needed.
+type diskObject struct {
+	ctx      context.Context
+	f        fs.Fs
+	remote   string
+	size     int64
+	modTime  *time.Time // nil if it wasn't read from the listing
+	hashType hash.Type  // type of hash
+	hash     *string    // nil if it wasn't read from the listing
+	mu       sync.Mutex
+	o        fs.Object // the real Object once found
+}
+
+// Resolve finds the real Object this stands in for
+func (o *diskObject) Resolve(ctx context.Context) (fs.Object, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.o == nil {
+		obj, err := o.f.NewObject(ctx, o.remote)
+		if err != nil {
+			return nil, err
+		}
+		o.o = obj
+	}
+	return o.o, nil
+}
+
+// resolved returns the real Object if it has been found or nil
+func (o *diskObject) resolved() fs.Object {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.o
+}
+
+// String returns a description of the Object
+func (o *diskObject) String() string {
+	if o == nil {
+		return "<nil>"
+	}
+	return o.remote
+}
+
+// Remote returns the remote path
+func (o *diskObject) Remote() string {
+	return o.remote
+}
+
+// ModTime returns the modification date of the file
+//
+// If it wasn't stored in the listing then the real Object is found
+// to read it.
+func (o *diskObject) ModTime() time.Time {
+	if obj := o.resolved(); obj != nil {
+		return obj.ModTime()
+	}
+	if o.modTime != nil {
+		return *o.modTime
+	}
+	obj, err := o.Resolve(o.ctx)
+	if err != nil {
+		// Count the error so that sync doesn't delete files
+		// based on a comparison which couldn't be made
+		fs.CountError(err)
+		fs.Errorf(o, "Failed to read modification time: %v", err)
+		return time.Now()
+	}
+	return obj.ModTime()
+}
+
+// Size returns the size of the file
+func (o *diskObject) Size() int64 {
+	if obj := o.resolved(); obj != nil {
+		return obj.Size()
+	}
+	return o.size
+}
+
+// Fs returns read only access to the Fs that this object is part of
+func (o *diskObject) Fs() fs.Info {
+	return o.f
+}
+
+// Hash returns the selected checksum of the file
+//
+// If it was stored in the listing then it is returned from there,
+// otherwise the real Object is found to read it.
+func (o *diskObject) Hash(ty hash.Type) (string, error) {
+	if obj := o.resolved(); obj != nil {
+		return obj.Hash(ty)
+	}
+	if o.hash != nil && ty == o.hashType {
+		return *o.hash, nil
+	}
+	obj, err := o.Resolve(o.ctx)
+	if err != nil {
+		return "", err
+	}
+	return obj.Hash(ty)
+}
+
+// Storable says whether this object can be stored
+func (o *diskObject) Storable() bool {
+	return true
+}
+
+// SetModTime sets the metadata on the object to set the modification date
+func (o *diskObject) SetModTime(ctx context.Context, t time.Time) error {
+	obj, err := o.Resolve(ctx)
+	if err != nil {
+		return err
+	}
+	return obj.SetModTime(ctx, t)
+}
+
+// Open opens the file for read.  Call Close() on the returned io.ReadCloser
+func (o *diskObject) Open(ctx context.Context, options ...fs.OpenOption) (io.ReadCloser, error) {
+	obj, err := o.Resolve(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return obj.Open(ctx, options...)
+}
+
+// Update in to the object with the modTime given of the given size
+func (o *diskObject) Update(ctx context.Context, in io.Reader, src fs.ObjectInfo, options ...fs.OpenOption) error {
+	obj, err := o.Resolve(ctx)
+	if err != nil {
+		return err
+	}
+	return obj.Update(ctx, in, src, options...)
+}
+
+// Remove this object
+func (o *diskObject) Remove(ctx context.Context) error {
+	obj, err := o.Resolve(ctx)
+	if err != nil {
+		return err
+	}
+	return obj.Remove(ctx)
+}
+
+// Metadata returns metadata for the object
+func (o *diskObject) Metadata(ctx context.Context) (fs.Metadata, error) {
+	obj, err := o.Resolve(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return fs.GetMetadata(ctx, obj)
+}
+
+// Check the interfaces are satisfied
+var (
+	_ fs.Object         = (*diskObject)(nil)
+	_ fs.ObjectResolver = (*diskObject)(nil)
+	_ fs.Metadataer     = (*diskObject)(nil)
+	_ matchIter         = (*sliceIter)(nil)
+	_ matchIter         = (*diskIter)(nil)
+)
@@ -0,0 +1,178 @@
+package march
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ncw/rclone/fs"
+	"github.com/ncw/rclone/fs/hash"
+	"github.com/ncw/rclone/fstest/mockdir"
+	"github.com/ncw/rclone/fstest/mockobject"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiskSorter(t *testing.T) {
+	ctx := context.Background()
+	ds, err := newDiskSorter(ctx, nil, []matchTransformFn{strings.ToLower}, hash.None)
+	require.NoError(t, err)
+
+	// Write the entries in three runs
+	require.NoError(t, ds.add(fs.DirEntries{
+		mockobject.Object("dir/c"),
+		mockobject.Object("dir/A"),
+		mockdir.New("dir/e"),
+	}))
+	require.NoError(t, ds.add(fs.DirEntries{
+		mockobject.Object("dir/b"),
+		mockobject.Object("dir/a"),
+	}))
+	require.NoError(t, ds.add(fs.DirEntries{
+		mockobject.Object("dir/d"),
+		mockobject.Object("dir/b"),
+	}))
+	assert.Equal(t, 3, len(ds.runs))
+
+	it, err := ds.iter()
+	require.NoError(t, err)
+	var got []string
+	for {
+		me, ok, err := it.next()
+		require.NoError(t, err)
+		if !ok {
+			break
+		}
+		got = append(got, me.name+"|"+me.leaf+"|"+me.entry.Remote()+"|"+fs.DirEntryType(me.entry))
+	}
+	assert.Equal(t, []string{
+		"a|A|dir/A|object",
+		"a|a|dir/a|object",
+		"b|b|dir/b|object",
+		"b|b|dir/b|object",
+		"c|c|dir/c|object",
+		"d|d|dir/d|object",
+		"e|e|dir/e|directory",
+	}, got)
+
+	// Check the temporary files are removed
+	ds.remove()
+	_, err = os.Stat(ds.dir)
+	assert.True(t, os.IsNotExist(err))
+}
+
+// modTimeCounter is an Object which counts the calls to ModTime
+type modTimeCounter struct {
+	mockobject.Object
+	calls *int
+}
+
+// ModTime counts the call and returns a fixed time
+func (o modTimeCounter) ModTime() time.Time {
+	*o.calls++
+	return time.Date(2019, 1, 2, 3, 4, 5, 0, time.UTC)
+}
+
+func TestNewDiskEntryModTime(t *testing.T) {
+	calls := 0
+	o := modTimeCounter{Object: mockobject.Object("dir/a"), calls: &calls}
+	me := matchEntry{entry: o, leaf: "a", name: "a"}
+
+	// Not read unless asked for
+	de := newDiskEntry(&me, false, hash.None)
+	assert.Equal(t, 0, calls)
+	assert.Nil(t, de.ModTime)
+	entry := de.matchEntry(context.Background(), nil, hash.None).entry.(*diskObject)
+	assert.Nil(t, entry.modTime)
+
+	// Read and stored if asked for
+	de = newDiskEntry(&me, true, hash.None)
+	assert.Equal(t, 1, calls)
+	require.NotNil(t, de.ModTime)
+	entry = de.matchEntry(context.Background(), nil, hash.None).entry.(*diskObject)
+	assert.Equal(t, o.ModTime(), entry.ModTime())
+
+	// Always stored for directories
+	me = matchEntry{entry: mockdir.New("dir/b"), leaf: "b", name: "b"}
+	de = newDiskEntry(&me, false, hash.None)
+	assert.NotNil(t, de.ModTime)
+}
+
+// hashCounter is an Object which counts the calls to Hash
+type hashCounter struct {
+	mockobject.Object
+	calls *int
+}
+
+// Hash counts the call and returns a fixed hash
+func (o hashCounter) Hash(ty hash.Type) (string, error) {
+	*o.calls++
+	return "0123456789abcdef", nil
+}
+
+func TestNewDiskEntryHash(t *testing.T) {
+	calls := 0
+	o := hashCounter{Object: mockobject.Object("dir/a"), calls: &calls}
+	me := matchEntry{entry: o, leaf: "a", name: "a"}
+
+	// Not read unless asked for
+	de := newDiskEntry(&me, false, hash.None)
+	assert.Equal(t, 0, calls)
+	assert.Nil(t, de.Hash)
+
+	// Read and stored if asked for
+	de = newDiskEntry(&me, false, hash.MD5)
+	assert.Equal(t, 1, calls)
+	require.NotNil(t, de.Hash)
+	entry := de.matchEntry(context.Background(), nil, hash.MD5).entry.(*diskObject)
+
+	// Returned without finding the object for the stored type
+	sum, err := entry.Hash(hash.MD5)
+	require.NoError(t, err)
+	assert.Equal(t, "0123456789abcdef", sum)
+	assert.Nil(t, entry.resolved())
+}
+
+func TestMatchStreamDisk(t *testing.T) {
+	ctx := context.Background()
+	ds, err := newDiskSorter(ctx, nil, nil, hash.None)
+	require.NoError(t, err)
+	defer ds.remove()
+
+	require.NoError(t, ds.add(fs.DirEntries{
+		mockobject.Object("c"),
+		mockobject.Object("a"),
+	}))
+	require.NoError(t, ds.add(fs.DirEntries{
+		mockobject.Object("d"),
+		mockobject.Object("a"),
+	}))
+	srcIter, err := ds.iter()
+	require.NoError(t, err)
+	dstIter := &sliceIter{es: newMatchEntries(fs.DirEntries{
+		mockobject.Object("b"),
+		mockobject.Object("c"),
+	}, nil)}
+
+	var got []string
+	err = matchStream(srcIter, dstIter, func(src, dst fs.DirEntry) bool {
+		switch {
+		case dst == nil:
+			got = append(got, "srcOnly "+src.Remote())
+		case src == nil:
+			got = append(got, "dstOnly "+dst.Remote())
+		default:
+			got = append(got, "match "+src.Remote())
+		}
+		return true
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"srcOnly a",
+		"dstOnly b",
+		"match c",
+		"srcOnly d",
+	}, got)
+}
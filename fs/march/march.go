@@ -10,7 +10,7 @@ import (
 
 	"github.com/ncw/rclone/fs"
 	"github.com/ncw/rclone/fs/filter"
-	"github.com/ncw/rclone/fs/list"
+	"github.com/ncw/rclone/fs/hash"
 	"github.com/ncw/rclone/fs/walk"
 	"golang.org/x/text/unicode/norm"
 )
@@ -28,6 +28,7 @@ type March struct {
 	srcListDir listDirFn // function to call to list a directory in the src
 	dstListDir listDirFn // function to call to list a directory in the dst
 	transforms []matchTransformFn
+	hashType   hash.Type // hash in common between fsrc and fdst
 }
 
 // Marcher is called on each match
@@ -49,6 +50,7 @@ func New(ctx context.Context, fdst, fsrc fs.Fs, dir string, callback Marcher) *M
 		dir:      dir,
 		callback: callback,
 	}
+	m.hashType = fsrc.Hashes().Overlap(fdst.Hashes()).GetOne()
 	m.srcListDir = m.makeListDir(fsrc, false)
 	m.dstListDir = m.makeListDir(fdst, filter.Active.Opt.DeleteExcluded)
 	// Now create the matching transform
@@ -66,14 +68,18 @@ func New(ctx context.Context, fdst, fsrc fs.Fs, dir string, callback Marcher) *M
 	return m
 }
 
-// list a directory into entries, err
-type listDirFn func(dir string) (entries fs.DirEntries, err error)
+// list a directory into a listing, err
+type listDirFn func(dir string) (l *listing, err error)
 
 // makeListDir makes a listing function for the given fs and includeAll flags
+//
+// If --fast-list is in use, or --files-from is in use and the files
+// can be looked up directly, then the whole tree is read at the start.
 func (m *March) makeListDir(f fs.Fs, includeAll bool) listDirFn {
-	if !fs.Config.UseListR || f.Features().ListR == nil {
-		return func(dir string) (entries fs.DirEntries, err error) {
-			return list.DirSorted(f, includeAll, dir)
+	filesFrom := !includeAll && filter.Active.HaveFilesFrom()
+	if !filesFrom && (!fs.Config.UseListR || f.Features().ListR == nil) {
+		return func(dir string) (l *listing, err error) {
+			return m.listDirPaged(f, includeAll, dir)
 		}
 	}
 	var (
@@ -82,11 +88,11 @@ func (m *March) makeListDir(f fs.Fs, includeAll bool) listDirFn {
 		dirs    walk.DirTree
 		dirsErr error
 	)
-	return func(dir string) (entries fs.DirEntries, err error) {
+	return func(dir string) (l *listing, err error) {
 		mu.Lock()
 		defer mu.Unlock()
 		if !started {
-			dirs, dirsErr = walk.NewDirTree(f, m.dir, includeAll, fs.Config.MaxDepth)
+			dirs, dirsErr = walk.NewDirTree(m.ctx, f, m.dir, includeAll, fs.Config.MaxDepth)
 			started = true
 		}
 		if dirsErr != nil {
@@ -94,11 +100,10 @@ func (m *March) makeListDir(f fs.Fs, includeAll bool) listDirFn {
 		}
 		entries, ok := dirs[dir]
 		if !ok {
-			err = fs.ErrorDirNotFound
-		} else {
-			delete(dirs, dir)
+			return nil, fs.ErrorDirNotFound
 		}
-		return entries, err
+		delete(dirs, dir)
+		return &listing{entries: entries}, nil
 	}
 }
 
@@ -123,6 +128,9 @@ func (m *March) Run() {
 		dstDepth = fs.MaxLevel
 	}
 
+	// Ignore the same paths in the destination as in the source
+	defer filter.Active.SetIgnoreSource(m.fdst, m.fsrc)()
+
 	// Start some directory listing go routines
 	var wg sync.WaitGroup         // sync closing of go routines
 	var traversing sync.WaitGroup // running directory traversals
@@ -131,27 +139,24 @@ func (m *March) Run() {
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
-			for {
-				select {
-				case <-m.ctx.Done():
-					return
-				case job, ok := <-in:
-					if !ok {
-						return
-					}
-					jobs := m.processJob(job)
-					if len(jobs) > 0 {
-						traversing.Add(len(jobs))
-						go func() {
-							// Now we have traversed this directory, send these
-							// jobs off for traversal in the background
-							for _, newJob := range jobs {
-								in <- newJob
-							}
-						}()
-					}
-					traversing.Done()
+			// Keep reading jobs until in is closed even if the
+			// context is cancelled so the traversal can finish
+			for job := range in {
+				var jobs []listDirJob
+				if !m.aborting() {
+					jobs = m.processJob(job)
+				}
+				if len(jobs) > 0 {
+					traversing.Add(len(jobs))
+					go func() {
+						// Now we have traversed this directory, send these
+						// jobs off for traversal in the background
+						for _, newJob := range jobs {
+							in <- newJob
+						}
+					}()
 				}
+				traversing.Done()
 			}
 		}()
 	}
@@ -196,10 +201,12 @@ func (es matchEntries) Len() int { return len(es) }
 func (es matchEntries) Swap(i, j int) { es[i], es[j] = es[j], es[i] }
 
 // Less is part of sort.Interface.
-//
-// Compare in order (name, leaf, remote)
 func (es matchEntries) Less(i, j int) bool {
-	ei, ej := &es[i], &es[j]
+	return es[i].less(&es[j])
+}
+
+// less compares in order (name, leaf, remote)
+func (ei *matchEntry) less(ej *matchEntry) bool {
 	if ei.name == ej.name {
 		if ei.leaf == ej.leaf {
 			return ei.entry.Remote() < ej.entry.Remote()
@@ -323,7 +330,7 @@ func matchListings(srcListEntries, dstListEntries fs.DirEntries, transforms []ma
 // returns errors using processError
 func (m *March) processJob(job listDirJob) (jobs []listDirJob) {
 	var (
-		srcList, dstList       fs.DirEntries
+		srcList, dstList       *listing
 		srcListErr, dstListErr error
 		wg                     sync.WaitGroup
 	)
@@ -346,6 +353,8 @@ func (m *March) processJob(job listDirJob) (jobs []listDirJob) {
 
 	// Wait for listings to complete and report errors
 	wg.Wait()
+	defer srcList.remove()
+	defer dstList.remove()
 	if srcListErr != nil {
 		fs.Errorf(job.srcRemote, "error reading source directory: %v", srcListErr)
 		fs.CountError(srcListErr)
@@ -359,48 +368,74 @@ func (m *March) processJob(job listDirJob) (jobs []listDirJob) {
 		return nil
 	}
 
+	// If either listing was too big to sort in memory then
+	// stream the matches from disk
+	if srcList.onDisk() || dstList.onDisk() {
+		return m.processJobStream(job, srcList, dstList)
+	}
+
 	// Work out what to do and do it
-	srcOnly, dstOnly, matches := matchListings(srcList, dstList, m.transforms)
+	srcOnly, dstOnly, matches := matchListings(srcList.dirEntries(), dstList.dirEntries(), m.transforms)
 	for _, src := range srcOnly {
 		if m.aborting() {
 			return nil
 		}
-		recurse := m.callback.SrcOnly(src)
-		if recurse && job.srcDepth > 0 {
-			jobs = append(jobs, listDirJob{
-				srcRemote: src.Remote(),
-				srcDepth:  job.srcDepth - 1,
-				noDst:     true,
-			})
-		}
-
+		jobs = m.srcOnly(job, src, jobs)
 	}
 	for _, dst := range dstOnly {
 		if m.aborting() {
 			return nil
 		}
-		recurse := m.callback.DstOnly(dst)
-		if recurse && job.dstDepth > 0 {
-			jobs = append(jobs, listDirJob{
-				dstRemote: dst.Remote(),
-				dstDepth:  job.dstDepth - 1,
-				noSrc:     true,
-			})
-		}
+		jobs = m.dstOnly(job, dst, jobs)
 	}
 	for _, match := range matches {
 		if m.aborting() {
 			return nil
 		}
-		recurse := m.callback.Match(match.dst, match.src)
-		if recurse && job.srcDepth > 0 && job.dstDepth > 0 {
-			jobs = append(jobs, listDirJob{
-				srcRemote: match.src.Remote(),
-				dstRemote: match.dst.Remote(),
-				srcDepth:  job.srcDepth - 1,
-				dstDepth:  job.dstDepth - 1,
-			})
-		}
+		jobs = m.match(job, match, jobs)
+	}
+	return jobs
+}
+
+// srcOnly calls the SrcOnly callback for src, appending a job to
+// jobs if it needs recursing into
+func (m *March) srcOnly(job listDirJob, src fs.DirEntry, jobs []listDirJob) []listDirJob {
+	recurse := m.callback.SrcOnly(src)
+	if recurse && job.srcDepth > 0 {
+		jobs = append(jobs, listDirJob{
+			srcRemote: src.Remote(),
+			srcDepth:  job.srcDepth - 1,
+			noDst:     true,
+		})
+	}
+	return jobs
+}
+
+// dstOnly calls the DstOnly callback for dst, appending a job to
+// jobs if it needs recursing into
+func (m *March) dstOnly(job listDirJob, dst fs.DirEntry, jobs []listDirJob) []listDirJob {
+	recurse := m.callback.DstOnly(dst)
+	if recurse && job.dstDepth > 0 {
+		jobs = append(jobs, listDirJob{
+			dstRemote: dst.Remote(),
+			dstDepth:  job.dstDepth - 1,
+			noSrc:     true,
+		})
+	}
+	return jobs
+}
+
+// match calls the Match callback for match, appending a job to jobs
+// if it needs recursing into
+func (m *March) match(job listDirJob, match matchPair, jobs []listDirJob) []listDirJob {
+	recurse := m.callback.Match(match.dst, match.src)
+	if recurse && job.srcDepth > 0 && job.dstDepth > 0 {
+		jobs = append(jobs, listDirJob{
+			srcRemote: match.src.Remote(),
+			dstRemote: match.dst.Remote(),
+			srcDepth:  job.srcDepth - 1,
+			dstDepth:  job.dstDepth - 1,
+		})
 	}
 	return jobs
 }
@@ -9,6 +9,7 @@ import (
 	"github.com/ncw/rclone/fs"
 	"github.com/ncw/rclone/fstest/mockobject"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestNewMatchEntries(t *testing.T) {
@@ -167,5 +168,29 @@ func TestMatchListings(t *testing.T) {
 		assert.Equal(t, test.srcOnly, srcOnly, test.what)
 		assert.Equal(t, test.dstOnly, dstOnly, test.what)
 		assert.Equal(t, test.matches, matches, test.what)
+		// check streaming the matches gives the same results
+		srcOnly, dstOnly, matches = streamListings(t, srcList, dstList, test.transforms)
+		assert.Equal(t, test.srcOnly, srcOnly, test.what)
+		assert.Equal(t, test.dstOnly, dstOnly, test.what)
+		assert.Equal(t, test.matches, matches, test.what)
 	}
 }
+
+// streamListings does the same as matchListings but using matchStream
+func streamListings(t *testing.T, srcList, dstList fs.DirEntries, transforms []matchTransformFn) (srcOnly fs.DirEntries, dstOnly fs.DirEntries, matches []matchPair) {
+	srcIter := &sliceIter{es: newMatchEntries(srcList, transforms)}
+	dstIter := &sliceIter{es: newMatchEntries(dstList, transforms)}
+	err := matchStream(srcIter, dstIter, func(src, dst fs.DirEntry) bool {
+		switch {
+		case dst == nil:
+			srcOnly = append(srcOnly, src)
+		case src == nil:
+			dstOnly = append(dstOnly, dst)
+		default:
+			matches = append(matches, matchPair{src: src, dst: dst})
+		}
+		return true
+	})
+	require.NoError(t, err)
+	return srcOnly, dstOnly, matches
+}
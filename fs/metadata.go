@@ -0,0 +1,73 @@
+package fs
+
+import "context"
+
+// Metadata represents Object metadata in a standardised form
+//
+// The keys are lower case strings.  Each backend documents which
+// keys it reads and writes - any keys a backend doesn't understand
+// are stored as user metadata if the backend supports it, otherwise
+// they are ignored.
+type Metadata map[string]string
+
+// Set k to v on m
+//
+// If m is nil, then it will get made
+func (m *Metadata) Set(k, v string) {
+	if *m == nil {
+		*m = make(Metadata, 1)
+	}
+	(*m)[k] = v
+}
+
+// Merge other into m
+//
+// If m is nil, then it will get made
+func (m *Metadata) Merge(other Metadata) {
+	for k, v := range other {
+		m.Set(k, v)
+	}
+}
+
+// Metadataer is an optional interface for Object
+type Metadataer interface {
+	// Metadata returns metadata for an object
+	//
+	// It should return nil if there is no Metadata
+	Metadata(ctx context.Context) (Metadata, error)
+}
+
+// GetMetadata from an ObjectInfo
+//
+// If the object has no metadata then metadata will be nil
+func GetMetadata(ctx context.Context, o ObjectInfo) (metadata Metadata, err error) {
+	do, ok := o.(Metadataer)
+	if !ok {
+		return nil, nil
+	}
+	return do.Metadata(ctx)
+}
+
+// GetMetadataOptions reads the metadata from the ObjectInfo and
+// merges in any MetadataOption found in options.
+//
+// This is for backends to call in Put and Update to find out which
+// metadata they should write.  It returns nil unless --metadata is in
+// use.
+func GetMetadataOptions(ctx context.Context, o ObjectInfo, options []OpenOption) (metadata Metadata, err error) {
+	if !Config.Metadata {
+		return nil, nil
+	}
+	srcMetadata, err := GetMetadata(ctx, o)
+	if err != nil {
+		return nil, err
+	}
+	// Copy the metadata so we don't modify the source's copy
+	metadata.Merge(srcMetadata)
+	for _, option := range options {
+		if metadataOption, ok := option.(MetadataOption); ok {
+			metadata.Merge(Metadata(metadataOption))
+		}
+	}
+	return metadata, nil
+}
@@ -0,0 +1,64 @@
+package fs
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetadataSet(t *testing.T) {
+	var m Metadata
+	assert.Nil(t, m)
+	m.Set("key", "value")
+	assert.NotNil(t, m)
+	assert.Equal(t, "value", m["key"])
+	m.Set("key", "value2")
+	assert.Equal(t, "value2", m["key"])
+}
+
+func TestMetadataMerge(t *testing.T) {
+	var m Metadata
+	m.Merge(nil)
+	assert.Nil(t, m)
+	m.Merge(Metadata{"a": "1", "b": "2"})
+	m.Merge(Metadata{"b": "3", "c": "4"})
+	assert.Equal(t, Metadata{"a": "1", "b": "3", "c": "4"}, m)
+}
+
+// metadataObject is a minimal ObjectInfo with metadata for testing
+type metadataObject struct {
+	ObjectInfo
+	metadata Metadata
+}
+
+// Metadata returns the metadata of the object
+func (o metadataObject) Metadata(ctx context.Context) (Metadata, error) {
+	return o.metadata, nil
+}
+
+func TestGetMetadataOptions(t *testing.T) {
+	ctx := context.Background()
+	o := metadataObject{metadata: Metadata{"a": "1", "b": "2"}}
+	options := []OpenOption{MetadataOption{"b": "3"}}
+
+	oldMetadata := Config.Metadata
+	defer func() {
+		Config.Metadata = oldMetadata
+	}()
+
+	Config.Metadata = false
+	m, err := GetMetadataOptions(ctx, o, options)
+	require.NoError(t, err)
+	assert.Nil(t, m)
+
+	Config.Metadata = true
+	m, err = GetMetadataOptions(ctx, o, options)
+	require.NoError(t, err)
+	assert.Equal(t, Metadata{"a": "1", "b": "3"}, m)
+
+	m, err = GetMetadata(ctx, metadataObject{})
+	require.NoError(t, err)
+	assert.Nil(t, m)
+}
@@ -0,0 +1,238 @@
+package operations
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"sort"
+	"time"
+
+	"github.com/ncw/rclone/fs"
+	"github.com/ncw/rclone/fs/list"
+	"github.com/ncw/rclone/fs/walk"
+	"github.com/ncw/rclone/lib/version"
+)
+
+// backupRemote returns the name remote should be stored as in
+// --backup-dir, with either --suffix or a version added.
+func backupRemote(remote string) string {
+	if fs.Config.BackupVersions {
+		return version.Add(remote, time.Now().UTC())
+	}
+	return remote + fs.Config.Suffix
+}
+
+// MoveBackupDir moves dst into backupDir, overwriting anything
+// already there with the same name.
+//
+// The file name has --suffix added to it, or a version if
+// --backup-versions is set.
+func MoveBackupDir(ctx context.Context, backupDir fs.Fs, dst fs.Object) (err error) {
+	remote := backupRemote(dst.Remote())
+	overwritten, _ := backupDir.NewObject(ctx, remote)
+	_, err = Move(ctx, backupDir, overwritten, remote, dst)
+	return err
+}
+
+// BackupVersion describes a version of a file stored in --backup-dir
+// with --backup-versions
+type BackupVersion struct {
+	Remote  string    // path of the file this is a version of
+	Version time.Time // when the version was made
+	Object  fs.Object // the version in the backup dir
+}
+
+// backupVersions is a slice of BackupVersion~s which sorts by Remote
+// then newest first
+type backupVersions []BackupVersion
+
+// Len is part of sort.Interface.
+func (vs backupVersions) Len() int { return len(vs) }
+
+// Swap is part of sort.Interface.
+func (vs backupVersions) Swap(i, j int) { vs[i], vs[j] = vs[j], vs[i] }
+
+// Less is part of sort.Interface.
+func (vs backupVersions) Less(i, j int) bool {
+	if vs[i].Remote == vs[j].Remote {
+		return vs[i].Version.After(vs[j].Version)
+	}
+	return vs[i].Remote < vs[j].Remote
+}
+
+// ListBackupVersions lists the versions of files in backupDir.
+//
+// If remote is not "" then only the versions of that file are
+// returned.
+//
+// The versions are sorted by the path of the file then newest first.
+// Files in backupDir without a version are ignored.
+func ListBackupVersions(ctx context.Context, backupDir fs.Fs, remote string) (versions []BackupVersion, err error) {
+	add := func(entries fs.DirEntries) {
+		for _, entry := range entries {
+			o, ok := entry.(fs.Object)
+			if !ok {
+				continue
+			}
+			t, original := version.Remove(o.Remote())
+			if t.IsZero() || (remote != "" && original != remote) {
+				continue
+			}
+			versions = append(versions, BackupVersion{
+				Remote:  original,
+				Version: t,
+				Object:  o,
+			})
+		}
+	}
+	if remote != "" {
+		// Only need to read the directory the file is in
+		dir := path.Dir(remote)
+		if dir == "." {
+			dir = ""
+		}
+		entries, err := list.DirSorted(ctx, backupDir, true, dir)
+		if err == fs.ErrorDirNotFound {
+			return nil, nil
+		} else if err != nil {
+			return nil, err
+		}
+		add(entries)
+	} else {
+		err = walk.Walk(ctx, backupDir, "", true, -1, func(dirPath string, entries fs.DirEntries, err error) error {
+			if err == fs.ErrorDirNotFound && dirPath == "" {
+				// The backup dir hasn't been made yet
+				return nil
+			} else if err != nil {
+				return err
+			}
+			add(entries)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	sort.Sort(backupVersions(versions))
+	return versions, nil
+}
+
+// backupRetention returns true if a --backup-keep-* or
+// --backup-max-age retention policy is in use
+func backupRetention() bool {
+	return fs.Config.BackupKeepLast > 0 ||
+		fs.Config.BackupKeepDaily > 0 ||
+		fs.Config.BackupKeepWeekly > 0 ||
+		fs.Config.BackupKeepMonthly > 0 ||
+		fs.Config.BackupMaxAge != fs.DurationOff
+}
+
+// keepBackupVersions works out which of the versions of a single
+// file, sorted newest first, are kept by the retention policy
+func keepBackupVersions(versions []BackupVersion, now time.Time) (keep []bool) {
+	keep = make([]bool, len(versions))
+	for i, v := range versions {
+		if i < fs.Config.BackupKeepLast {
+			keep[i] = true
+		}
+		if fs.Config.BackupMaxAge != fs.DurationOff && now.Sub(v.Version) < time.Duration(fs.Config.BackupMaxAge) {
+			keep[i] = true
+		}
+	}
+	// Keep the newest version in each of the n most recent
+	// periods which have a version in
+	keepPeriods := func(n int, period func(t time.Time) string) {
+		last := ""
+		for i, v := range versions {
+			if n <= 0 {
+				break
+			}
+			p := period(v.Version.Local())
+			if p != last {
+				keep[i] = true
+				last = p
+				n--
+			}
+		}
+	}
+	keepPeriods(fs.Config.BackupKeepDaily, func(t time.Time) string {
+		return t.Format("2006-01-02")
+	})
+	keepPeriods(fs.Config.BackupKeepWeekly, func(t time.Time) string {
+		year, week := t.ISOWeek()
+		return fmt.Sprintf("%04d-%02d", year, week)
+	})
+	keepPeriods(fs.Config.BackupKeepMonthly, func(t time.Time) string {
+		return t.Format("2006-01")
+	})
+	return keep
+}
+
+// PruneBackupDir deletes the versions in backupDir which aren't kept
+// by the --backup-keep-* and --backup-max-age retention policy.
+//
+// It does nothing if no retention policy is set.
+func PruneBackupDir(ctx context.Context, backupDir fs.Fs) error {
+	if !backupRetention() {
+		return nil
+	}
+	versions, err := ListBackupVersions(ctx, backupDir, "")
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	for i := 0; i < len(versions); {
+		// Find the versions of this file
+		j := i + 1
+		for j < len(versions) && versions[j].Remote == versions[i].Remote {
+			j++
+		}
+		keep := keepBackupVersions(versions[i:j], now)
+		for k := i; k < j; k++ {
+			if keep[k-i] {
+				continue
+			}
+			fs.Debugf(versions[k].Object, "Removing old version of %q from backup dir", versions[k].Remote)
+			err = DeleteFile(ctx, versions[k].Object)
+			if err != nil {
+				return err
+			}
+		}
+		i = j
+	}
+	return nil
+}
+
+// FindBackupVersion finds the version of remote in backupDir which
+// was current at t.
+//
+// A version is stamped with the time it was moved into backupDir,
+// which is when it stopped being current, so the version current at
+// t is the oldest version stamped after t.  If there is no such
+// version then the live file was current at t and it returns a nil
+// version and no error.
+//
+// If t is zero then it finds the newest version.
+//
+// It returns fs.ErrorObjectNotFound if there are no versions of remote.
+func FindBackupVersion(ctx context.Context, backupDir fs.Fs, remote string, t time.Time) (*BackupVersion, error) {
+	versions, err := ListBackupVersions(ctx, backupDir, remote)
+	if err != nil {
+		return nil, err
+	}
+	if len(versions) == 0 {
+		return nil, fs.ErrorObjectNotFound
+	}
+	if t.IsZero() {
+		return &versions[0], nil
+	}
+	// The versions are newest first
+	var current *BackupVersion
+	for i := range versions {
+		if !versions[i].Version.After(t) {
+			break
+		}
+		current = &versions[i]
+	}
+	return current, nil
+}
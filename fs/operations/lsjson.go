@@ -0,0 +1,160 @@
+package operations
+
+import (
+	"context"
+	"path"
+	"time"
+
+	"github.com/ncw/rclone/backend/crypt"
+	"github.com/ncw/rclone/fs"
+	"github.com/ncw/rclone/fs/hash"
+	"github.com/ncw/rclone/fs/walk"
+	"github.com/pkg/errors"
+)
+
+// ListJSONItem in the struct which gets marshalled for each line
+type ListJSONItem struct {
+	Path      string
+	Name      string
+	Encrypted string `json:",omitempty"`
+	Size      int64
+	ModTime   Timestamp //`json:",omitempty"`
+	IsDir     bool
+	Hashes    map[string]string `json:",omitempty"`
+	Metadata  fs.Metadata       `json:",omitempty"`
+}
+
+// Timestamp a time in RFC3339 format with Nanosecond precision secongs
+type Timestamp time.Time
+
+// MarshalJSON turns a Timestamp into JSON
+func (t Timestamp) MarshalJSON() (out []byte, err error) {
+	tt := time.Time(t)
+	if tt.IsZero() {
+		return []byte(`""`), nil
+	}
+	return []byte(`"` + tt.Format(time.RFC3339Nano) + `"`), nil
+}
+
+// ListJSONOpt describes the options for ListJSON
+type ListJSONOpt struct {
+	Recurse       bool     `json:"recurse"`
+	NoModTime     bool     `json:"noModTime"`
+	ShowEncrypted bool     `json:"showEncrypted"`
+	ShowHash      bool     `json:"showHash"`
+	HashTypes     []string `json:"hashTypes"` // hash types to show if ShowHash is set, all supported if empty
+}
+
+// ListJSON lists fsrc using the options in opt calling callback for each item
+func ListJSON(ctx context.Context, fsrc fs.Fs, remote string, opt *ListJSONOpt, callback func(*ListJSONItem) error) error {
+	var cipher crypt.Cipher
+	if opt.ShowEncrypted {
+		if _, ok := fsrc.(*crypt.Fs); !ok {
+			return errors.New("The remote needs to be of type \"crypt\"")
+		}
+		_, _, _, config, err := fs.ConfigFs(fsrc.Name() + ":" + fsrc.Root())
+		if err != nil {
+			return errors.Wrap(err, "ListJSON failed to load config for crypt remote")
+		}
+		cipher, err = crypt.NewCipher(config)
+		if err != nil {
+			return errors.Wrap(err, "ListJSON failed to make new crypt remote")
+		}
+	}
+	hashTypes := hash.Supported
+	if len(opt.HashTypes) > 0 {
+		hashTypes = hash.Set(hash.None)
+		for _, name := range opt.HashTypes {
+			var hashType hash.Type
+			err := hashType.Set(name)
+			if err != nil {
+				return err
+			}
+			hashTypes.Add(hashType)
+		}
+	}
+	err := walk.Walk(ctx, fsrc, remote, false, ConfigMaxDepth(opt.Recurse), func(dirPath string, entries fs.DirEntries, err error) error {
+		if err != nil {
+			fs.CountError(err)
+			fs.Errorf(dirPath, "error listing: %v", err)
+			return nil
+		}
+		for _, entry := range entries {
+			item := ListJSONItem{
+				Path: entry.Remote(),
+				Name: path.Base(entry.Remote()),
+				Size: entry.Size(),
+			}
+			if !opt.NoModTime {
+				item.ModTime = Timestamp(entry.ModTime())
+			}
+			if cipher != nil {
+				switch entry.(type) {
+				case fs.Directory:
+					item.Encrypted = cipher.EncryptDirName(path.Base(entry.Remote()))
+				case fs.Object:
+					item.Encrypted = cipher.EncryptFileName(path.Base(entry.Remote()))
+				default:
+					fs.Errorf(nil, "Unknown type %T in listing", entry)
+				}
+			}
+			switch x := entry.(type) {
+			case fs.Directory:
+				item.IsDir = true
+			case fs.Object:
+				item.IsDir = false
+				if opt.ShowHash {
+					item.Hashes = listJSONHashes(x, hashTypes.Overlap(x.Fs().Hashes()))
+				}
+				if fs.Config.Metadata {
+					metadata, err := fs.GetMetadata(ctx, x)
+					if err != nil {
+						fs.Errorf(x, "Failed to read metadata: %v", err)
+					} else {
+						item.Metadata = metadata
+					}
+				}
+			default:
+				fs.Errorf(nil, "Unknown type %T in listing", entry)
+			}
+			err = callback(&item)
+			if err != nil {
+				return errors.Wrap(err, "callback failed in ListJSON")
+			}
+
+		}
+		return nil
+	})
+	if err != nil {
+		return errors.Wrap(err, "error in ListJSON")
+	}
+	return nil
+}
+
+// listJSONHashes returns the hashes of types for o, reading them all
+// in one go if the Object supports it
+func listJSONHashes(o fs.Object, types hash.Set) map[string]string {
+	hashes := make(map[string]string)
+	if do, ok := o.(fs.HashSetter); ok {
+		values, err := do.HashSet(types)
+		if err != nil {
+			fs.Errorf(o, "Failed to read hashes: %v", err)
+			return hashes
+		}
+		for hashType, value := range values {
+			if value != "" {
+				hashes[hashType.String()] = value
+			}
+		}
+		return hashes
+	}
+	for _, hashType := range types.Array() {
+		value, err := o.Hash(hashType)
+		if err != nil {
+			fs.Errorf(o, "Failed to read hash: %v", err)
+		} else if value != "" {
+			hashes[hashType.String()] = value
+		}
+	}
+	return hashes
+}
@@ -0,0 +1,249 @@
+package operations
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"sync"
+
+	"github.com/ncw/rclone/fs"
+	"github.com/ncw/rclone/fs/accounting"
+	"github.com/pkg/errors"
+)
+
+const (
+	multithreadChunkSize     = 64 << 10
+	multithreadChunkSizeMask = multithreadChunkSize - 1
+	multithreadBufferSize    = 32 * 1024
+	multithreadTempSuffix    = ".rclone-multi-thread" // suffix for the copy when replacing an existing object
+)
+
+// Return a boolean as to whether we should use multi thread copy for
+// this transfer.  dst is the existing destination object or nil.
+func doMultiThreadCopy(f fs.Fs, src, dst fs.Object) bool {
+	// Disable multi thread if...
+
+	// ...it isn't configured
+	if fs.Config.MultiThreadStreams <= 1 {
+		return false
+	}
+	// ...size of object is less than cutoff
+	if src.Size() < int64(fs.Config.MultiThreadCutoff) {
+		return false
+	}
+	// ...destination doesn't support it
+	if f.Features().OpenWriterAt == nil {
+		return false
+	}
+	// ...metadata is being preserved as it can't be set afterwards
+	if fs.Config.Metadata {
+		return false
+	}
+	// ...the destination exists and the copy can't be moved over it
+	if dst != nil && f.Features().Move == nil {
+		return false
+	}
+	return true
+}
+
+// state for a multi-thread copy
+type multiThreadCopyState struct {
+	ctx      context.Context
+	partSize int64
+	size     int64
+	wc       fs.WriterAtCloser
+	src      fs.Object
+	acc      *accounting.Account
+	streams  int
+}
+
+// Copy a single stream into place
+func (mc *multiThreadCopyState) copyStream(stream int) (err error) {
+	start := int64(stream) * mc.partSize
+	if start >= mc.size {
+		return nil
+	}
+	end := start + mc.partSize
+	if end > mc.size {
+		end = mc.size
+	}
+
+	fs.Debugf(mc.src, "multi-thread copy: stream %d/%d (%d-%d) size %v starting", stream+1, mc.streams, start, end, fs.SizeSuffix(end-start))
+
+	rc, err := mc.src.Open(mc.ctx, &fs.RangeOption{Start: start, End: end - 1})
+	if err != nil {
+		return errors.Wrap(err, "multi-thread copy: failed to open source")
+	}
+	defer fs.CheckClose(rc, &err)
+
+	// Copy the data
+	buf := make([]byte, multithreadBufferSize)
+	offset := start
+	for {
+		// Check if context cancelled and exit if so
+		if mc.ctx.Err() != nil {
+			return mc.ctx.Err()
+		}
+		nr, er := rc.Read(buf)
+		if nr > 0 {
+			mc.acc.AccountRead(nr)
+			nw, ew := mc.wc.WriteAt(buf[0:nr], offset)
+			if nw > 0 {
+				offset += int64(nw)
+			}
+			if ew != nil {
+				err = ew
+				break
+			}
+			if nr != nw {
+				err = io.ErrShortWrite
+				break
+			}
+		}
+		if er != nil {
+			if er != io.EOF {
+				err = er
+			}
+			break
+		}
+	}
+
+	if err != nil {
+		return errors.Wrap(err, "multi-thread copy: failed to copy chunk")
+	}
+	if offset != end {
+		return errors.Errorf("multi-thread copy: stream %d/%d (%d-%d) size %v: wrong length copied %d", stream+1, mc.streams, start, end, fs.SizeSuffix(end-start), offset-start)
+	}
+
+	fs.Debugf(mc.src, "multi-thread copy: stream %d/%d (%d-%d) size %v finished", stream+1, mc.streams, start, end, fs.SizeSuffix(end-start))
+	return nil
+}
+
+// Calculate the chunk sizes and updated number of streams
+func (mc *multiThreadCopyState) calculateChunks() {
+	partSize := mc.size / int64(mc.streams)
+	// Round partition size up so partSize * streams >= size
+	if (mc.size % int64(mc.streams)) != 0 {
+		partSize++
+	}
+	// round partSize up to nearest multithreadChunkSize boundary
+	mc.partSize = (partSize + multithreadChunkSizeMask) &^ multithreadChunkSizeMask
+	// recalculate number of streams
+	mc.streams = int(mc.size / mc.partSize)
+	// round streams up so partSize * streams >= size
+	if (mc.size % mc.partSize) != 0 {
+		mc.streams++
+	}
+}
+
+// Copy src to (f, remote) using streams download threads and the
+// OpenWriterAt feature
+//
+// dst is the existing object at remote or nil.  If it is set the copy
+// is written to a temporary name and moved over dst once complete so
+// a failed copy leaves dst as it was.
+func multiThreadCopy(ctx context.Context, f fs.Fs, remote string, src, dst fs.Object, streams int) (newDst fs.Object, err error) {
+	openWriterAt := f.Features().OpenWriterAt
+	if openWriterAt == nil {
+		return nil, errors.New("multi-thread copy: OpenWriterAt not supported")
+	}
+	if src.Size() < 0 {
+		return nil, errors.New("multi-thread copy: can't copy unknown sized file")
+	}
+	if src.Size() == 0 {
+		return nil, errors.New("multi-thread copy: can't copy zero sized file")
+	}
+
+	streamCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	mc := &multiThreadCopyState{
+		ctx:     streamCtx,
+		size:    src.Size(),
+		src:     src,
+		streams: streams,
+	}
+	mc.calculateChunks()
+
+	// Make accounting - the data is read by the streams directly
+	// so this is only used for the stats
+	mc.acc = accounting.NewAccount(ioutil.NopCloser(nil), src).WithDestination(f)
+	defer fs.CheckClose(mc.acc, &err)
+
+	// Write to a temporary name if replacing an existing object
+	writeRemote := remote
+	if dst != nil {
+		if f.Features().Move == nil {
+			return nil, errors.New("multi-thread copy: can't replace existing object without Move")
+		}
+		writeRemote = remote + multithreadTempSuffix
+	}
+
+	// create write file handle
+	mc.wc, err = openWriterAt(ctx, writeRemote, mc.size)
+	if err != nil {
+		return nil, errors.Wrap(err, "multi-thread copy: failed to open destination")
+	}
+
+	fs.Debugf(src, "Starting multi-thread copy with %d parts of size %v", mc.streams, fs.SizeSuffix(mc.partSize))
+	var (
+		wg       sync.WaitGroup
+		errMu    sync.Mutex
+		firstErr error
+	)
+	for stream := 0; stream < mc.streams; stream++ {
+		wg.Add(1)
+		go func(stream int) {
+			defer wg.Done()
+			err := mc.copyStream(stream)
+			if err != nil {
+				errMu.Lock()
+				if firstErr == nil {
+					firstErr = err
+					// stop the other streams
+					cancel()
+				}
+				errMu.Unlock()
+			}
+		}(stream)
+	}
+	wg.Wait()
+	err = firstErr
+	closeErr := mc.wc.Close()
+	if err == nil && closeErr != nil {
+		err = errors.Wrap(closeErr, "multi-thread copy: failed to close object after copy")
+	}
+	if err != nil {
+		// Remove the partially written object - this is never
+		// the existing dst as that isn't written to
+		if o, findErr := f.NewObject(ctx, writeRemote); findErr == nil {
+			removeFailedCopy(ctx, o)
+		}
+		return nil, err
+	}
+
+	obj, err := f.NewObject(ctx, writeRemote)
+	if err != nil {
+		return nil, errors.Wrap(err, "multi-thread copy: failed to find object after copy")
+	}
+
+	err = obj.SetModTime(ctx, src.ModTime())
+	switch err {
+	case nil, fs.ErrorCantSetModTime, fs.ErrorCantSetModTimeWithoutDelete:
+	default:
+		removeFailedCopy(ctx, obj)
+		return nil, errors.Wrap(err, "multi-thread copy: failed to set modification time")
+	}
+
+	// Move the copy over the existing object
+	if writeRemote != remote {
+		movedObj, err := f.Features().Move(ctx, obj, remote)
+		if err != nil {
+			removeFailedCopy(ctx, obj)
+			return nil, errors.Wrap(err, "multi-thread copy: failed to move copy over existing object")
+		}
+		obj = movedObj
+	}
+
+	fs.Debugf(src, "Finished multi-thread copy with %d parts of size %v", mc.streams, fs.SizeSuffix(mc.partSize))
+	return obj, nil
+}
@@ -0,0 +1,135 @@
+package operations
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/ncw/rclone/fs"
+	"github.com/ncw/rclone/fstest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var multithreadTime = fstest.Time("2001-02-03T04:05:06.499999999Z")
+
+func TestDoMultiThreadCopy(t *testing.T) {
+	r := fstest.NewRun(t)
+	defer r.Finalise()
+	if r.Flocal.Features().OpenWriterAt == nil {
+		t.Skip("OpenWriterAt not supported")
+	}
+
+	oldStreams, oldCutoff, oldMetadata := fs.Config.MultiThreadStreams, fs.Config.MultiThreadCutoff, fs.Config.Metadata
+	defer func() {
+		fs.Config.MultiThreadStreams, fs.Config.MultiThreadCutoff, fs.Config.Metadata = oldStreams, oldCutoff, oldMetadata
+	}()
+	fs.Config.MultiThreadStreams = 4
+	fs.Config.MultiThreadCutoff = 50
+
+	file1 := r.WriteObject("file1", "this is 100 bytes long", multithreadTime)
+	src, err := r.Fremote.NewObject(context.Background(), file1.Path)
+	require.NoError(t, err)
+
+	assert.False(t, doMultiThreadCopy(r.Flocal, src, nil), "below cutoff")
+	fs.Config.MultiThreadCutoff = 10
+	assert.True(t, doMultiThreadCopy(r.Flocal, src, nil))
+	fs.Config.MultiThreadStreams = 1
+	assert.False(t, doMultiThreadCopy(r.Flocal, src, nil), "not enough streams")
+	fs.Config.MultiThreadStreams = 4
+	fs.Config.Metadata = true
+	assert.False(t, doMultiThreadCopy(r.Flocal, src, nil), "metadata")
+}
+
+func TestMultithreadCalculateChunks(t *testing.T) {
+	for _, test := range []struct {
+		size         int64
+		streams      int
+		wantPartSize int64
+		wantStreams  int
+	}{
+		{size: 1, streams: 10, wantPartSize: multithreadChunkSize, wantStreams: 1},
+		{size: 1 << 20, streams: 1, wantPartSize: 1 << 20, wantStreams: 1},
+		{size: 1 << 20, streams: 2, wantPartSize: 1 << 19, wantStreams: 2},
+		{size: (1 << 20) + 1, streams: 2, wantPartSize: (1 << 19) + multithreadChunkSize, wantStreams: 2},
+		{size: (1 << 20) - 1, streams: 2, wantPartSize: (1 << 19), wantStreams: 2},
+	} {
+		t.Run(fmt.Sprintf("%+v", test), func(t *testing.T) {
+			mc := &multiThreadCopyState{
+				size:    test.size,
+				streams: test.streams,
+			}
+			mc.calculateChunks()
+			assert.Equal(t, test.wantPartSize, mc.partSize)
+			assert.Equal(t, test.wantStreams, mc.streams)
+		})
+	}
+}
+
+func TestMultithreadCopy(t *testing.T) {
+	r := fstest.NewRun(t)
+	defer r.Finalise()
+	if r.Flocal.Features().OpenWriterAt == nil {
+		t.Skip("OpenWriterAt not supported")
+	}
+
+	for _, test := range []struct {
+		size    int
+		streams int
+	}{
+		{size: multithreadChunkSize*2 - 1, streams: 2},
+		{size: multithreadChunkSize * 2, streams: 2},
+		{size: multithreadChunkSize*2 + 1, streams: 2},
+		{size: multithreadChunkSize*10 + 12345, streams: 4},
+	} {
+		t.Run(fmt.Sprintf("%+v", test), func(t *testing.T) {
+			contents := fstest.RandomString(test.size)
+			file1 := r.WriteObject("file1", contents, multithreadTime)
+			fstest.CheckItems(t, r.Fremote, file1)
+			fstest.CheckItems(t, r.Flocal)
+
+			src, err := r.Fremote.NewObject(context.Background(), "file1")
+			require.NoError(t, err)
+
+			dst, err := multiThreadCopy(context.Background(), r.Flocal, "file1", src, nil, test.streams)
+			require.NoError(t, err)
+			assert.Equal(t, src.Size(), dst.Size())
+			assert.Equal(t, "file1", dst.Remote())
+
+			fstest.CheckItems(t, r.Flocal, file1)
+			require.NoError(t, dst.Remove(context.Background()))
+		})
+	}
+}
+
+func TestMultithreadCopyExisting(t *testing.T) {
+	r := fstest.NewRun(t)
+	defer r.Finalise()
+	ctx := context.Background()
+	if r.Flocal.Features().OpenWriterAt == nil || r.Flocal.Features().Move == nil {
+		t.Skip("OpenWriterAt or Move not supported")
+	}
+
+	contents := fstest.RandomString(multithreadChunkSize*2 + 1)
+	file1 := r.WriteObject("file1", contents, multithreadTime)
+	src, err := r.Fremote.NewObject(ctx, "file1")
+	require.NoError(t, err)
+
+	// Replace an existing object
+	r.WriteFile("file1", "old contents", fstest.Time("2011-12-25T12:59:59.123456789Z"))
+	dst, err := r.Flocal.NewObject(ctx, "file1")
+	require.NoError(t, err)
+	newDst, err := multiThreadCopy(ctx, r.Flocal, "file1", src, dst, 2)
+	require.NoError(t, err)
+	assert.Equal(t, "file1", newDst.Remote())
+	fstest.CheckItems(t, r.Flocal, file1)
+
+	// A failed copy leaves the existing object alone
+	old := r.WriteFile("file1", "old contents", fstest.Time("2011-12-25T12:59:59.123456789Z"))
+	dst, err = r.Flocal.NewObject(ctx, "file1")
+	require.NoError(t, err)
+	require.NoError(t, src.Remove(ctx))
+	_, err = multiThreadCopy(ctx, r.Flocal, "file1", src, dst, 2)
+	require.Error(t, err)
+	fstest.CheckItems(t, r.Flocal, old)
+}
@@ -4,11 +4,13 @@ package operations
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"log"
 	"path"
+	"reflect"
 	"sort"
 	"strconv"
 	"strings"
@@ -92,8 +94,8 @@ func CheckHashes(src fs.ObjectInfo, dst fs.Object) (equal bool, ht hash.Type, er
 //
 // Otherwise the file is considered to be not equal including if there
 // were errors reading info.
-func Equal(src fs.ObjectInfo, dst fs.Object) bool {
-	return equal(src, dst, fs.Config.SizeOnly, fs.Config.CheckSum)
+func Equal(ctx context.Context, src fs.ObjectInfo, dst fs.Object) bool {
+	return equal(ctx, src, dst, fs.Config.SizeOnly, fs.Config.CheckSum, !fs.Config.NoUpdateModTime)
 }
 
 // sizeDiffers compare the size of src and dst taking into account the
@@ -105,7 +107,7 @@ func sizeDiffers(src, dst fs.ObjectInfo) bool {
 	return src.Size() != dst.Size()
 }
 
-func equal(src fs.ObjectInfo, dst fs.Object, sizeOnly, checkSum bool) bool {
+func equal(ctx context.Context, src fs.ObjectInfo, dst fs.Object, sizeOnly, checkSum, updateModTime bool) bool {
 	if sizeDiffers(src, dst) {
 		fs.Debugf(src, "Sizes differ (src %d vs dst %d)", src.Size(), dst.Size())
 		return false
@@ -161,7 +163,7 @@ func equal(src fs.ObjectInfo, dst fs.Object, sizeOnly, checkSum bool) bool {
 	}
 
 	// mod time differs but hash is the same to reset mod time if required
-	if !fs.Config.NoUpdateModTime {
+	if updateModTime {
 		if fs.Config.DryRun {
 			fs.Logf(src, "Not updating modification time as --dry-run")
 		} else {
@@ -172,7 +174,7 @@ func equal(src fs.ObjectInfo, dst fs.Object, sizeOnly, checkSum bool) bool {
 				return false
 			}
 			// Update the mtime of the dst object here
-			err := dst.SetModTime(srcModTime)
+			err := dst.SetModTime(ctx, srcModTime)
 			if err == fs.ErrorCantSetModTime {
 				fs.Debugf(dst, "src and dst identical but can't set mod time without re-uploading")
 				return false
@@ -181,7 +183,7 @@ func equal(src fs.ObjectInfo, dst fs.Object, sizeOnly, checkSum bool) bool {
 				// Remove the file if BackupDir isn't set.  If BackupDir is set we would rather have the old file
 				// put in the BackupDir than deleted which is what will happen if we don't delete it.
 				if fs.Config.BackupDir == "" {
-					err = dst.Remove()
+					err = dst.Remove(ctx)
 					if err != nil {
 						fs.Errorf(dst, "failed to delete before re-upload: %v", err)
 					}
@@ -201,12 +203,12 @@ func equal(src fs.ObjectInfo, dst fs.Object, sizeOnly, checkSum bool) bool {
 // Used to remove a failed copy
 //
 // Returns whether the file was succesfully removed or not
-func removeFailedCopy(dst fs.Object) bool {
+func removeFailedCopy(ctx context.Context, dst fs.Object) bool {
 	if dst == nil {
 		return false
 	}
 	fs.Infof(dst, "Removing failed copy")
-	removeErr := dst.Remove()
+	removeErr := dst.Remove(ctx)
 	if removeErr != nil {
 		fs.Infof(dst, "Failed to remove failed copy: %s", removeErr)
 		return false
@@ -234,20 +236,36 @@ func (o *overrideRemoteObject) MimeType() string {
 	return ""
 }
 
-// Check interface is satisfied
-var _ fs.MimeTyper = (*overrideRemoteObject)(nil)
+// Metadata returns the metadata of the underlying object or nil if
+// it has none
+func (o *overrideRemoteObject) Metadata(ctx context.Context) (fs.Metadata, error) {
+	return fs.GetMetadata(ctx, o.Object)
+}
+
+// Check interfaces are satisfied
+var (
+	_ fs.MimeTyper  = (*overrideRemoteObject)(nil)
+	_ fs.Metadataer = (*overrideRemoteObject)(nil)
+)
 
 // Copy src object to dst or f if nil.  If dst is nil then it uses
 // remote as the name of the new object.
 //
 // It returns the destination object if possible.  Note that this may
 // be nil.
-func Copy(f fs.Fs, dst fs.Object, remote string, src fs.Object) (newDst fs.Object, err error) {
+func Copy(ctx context.Context, f fs.Fs, dst fs.Object, remote string, src fs.Object) (newDst fs.Object, err error) {
 	newDst = dst
 	if fs.Config.DryRun {
 		fs.Logf(src, "Not copying as --dry-run")
 		return newDst, nil
 	}
+	// Server side copies need the backend's own Object
+	src, err = fs.ResolveObject(ctx, src)
+	if err != nil {
+		fs.CountError(err)
+		fs.Errorf(src, "Failed to copy: %v", err)
+		return newDst, err
+	}
 	maxTries := fs.Config.LowLevelRetries
 	tries := 0
 	doUpdate := dst != nil
@@ -267,8 +285,8 @@ func Copy(f fs.Fs, dst fs.Object, remote string, src fs.Object) (newDst fs.Objec
 		// Try server side copy first - if has optional interface and
 		// is same underlying remote
 		actionTaken = "Copied (server side copy)"
-		if doCopy := f.Features().Copy; doCopy != nil && SameConfig(src.Fs(), f) {
-			newDst, err = doCopy(src, remote)
+		if doCopy := f.Features().Copy; doCopy != nil && ServerSideCompatible(f, src.Fs()) {
+			newDst, err = doCopy(ctx, src, remote)
 			if err == nil {
 				dst = newDst
 			}
@@ -277,28 +295,42 @@ func Copy(f fs.Fs, dst fs.Object, remote string, src fs.Object) (newDst fs.Objec
 		}
 		// If can't server side copy, do it manually
 		if err == fs.ErrorCantCopy {
-			var in0 io.ReadCloser
-			in0, err = src.Open(hashOption)
-			if err != nil {
-				err = errors.Wrap(err, "failed to open source object")
-			} else {
-				in := accounting.NewAccount(in0, src).WithBuffer() // account and buffer the transfer
-				var wrappedSrc fs.ObjectInfo = src
-				// We try to pass the original object if possible
-				if src.Remote() != remote {
-					wrappedSrc = &overrideRemoteObject{Object: src, remote: remote}
-				}
+			if doMultiThreadCopy(f, src, dst) {
+				var copiedDst fs.Object
+				copiedDst, err = multiThreadCopy(ctx, f, remote, src, dst, fs.Config.MultiThreadStreams)
 				if doUpdate {
-					actionTaken = "Copied (replaced existing)"
-					err = dst.Update(in, wrappedSrc, hashOption)
+					actionTaken = "Multi-thread Copied (replaced existing)"
 				} else {
-					actionTaken = "Copied (new)"
-					dst, err = f.Put(in, wrappedSrc, hashOption)
+					actionTaken = "Multi-thread Copied (new)"
 				}
-				closeErr := in.Close()
 				if err == nil {
-					newDst = dst
-					err = closeErr
+					dst = copiedDst
+					newDst = copiedDst
+				}
+			} else {
+				var in0 io.ReadCloser
+				in0, err = src.Open(ctx, hashOption)
+				if err != nil {
+					err = errors.Wrap(err, "failed to open source object")
+				} else {
+					in := accounting.NewAccount(in0, src).WithDestination(f).WithBuffer() // account and buffer the transfer
+					var wrappedSrc fs.ObjectInfo = src
+					// We try to pass the original object if possible
+					if src.Remote() != remote {
+						wrappedSrc = &overrideRemoteObject{Object: src, remote: remote}
+					}
+					if doUpdate {
+						actionTaken = "Copied (replaced existing)"
+						err = dst.Update(ctx, in, wrappedSrc, hashOption)
+					} else {
+						actionTaken = "Copied (new)"
+						dst, err = f.Put(ctx, in, wrappedSrc, hashOption)
+					}
+					closeErr := in.Close()
+					if err == nil {
+						newDst = dst
+						err = closeErr
+					}
 				}
 			}
 		}
@@ -306,6 +338,10 @@ func Copy(f fs.Fs, dst fs.Object, remote string, src fs.Object) (newDst fs.Objec
 		if tries >= maxTries {
 			break
 		}
+		// Don't retry if the transfer has been cancelled
+		if ctx.Err() != nil {
+			break
+		}
 		// Retry if err returned a retry error
 		if fserrors.IsRetryError(err) || fserrors.ShouldRetry(err) {
 			fs.Debugf(src, "Received error: %v - low level retry %d/%d", err, tries, maxTries)
@@ -325,7 +361,7 @@ func Copy(f fs.Fs, dst fs.Object, remote string, src fs.Object) (newDst fs.Objec
 		err = errors.Errorf("corrupted on transfer: sizes differ %d vs %d", src.Size(), dst.Size())
 		fs.Errorf(dst, "%v", err)
 		fs.CountError(err)
-		removeFailedCopy(dst)
+		removeFailedCopy(ctx, dst)
 		return newDst, err
 	}
 
@@ -348,13 +384,13 @@ func Copy(f fs.Fs, dst fs.Object, remote string, src fs.Object) (newDst fs.Objec
 				err = errors.Errorf("corrupted on transfer: %v hash differ %q vs %q", hashType, srcSum, dstSum)
 				fs.Errorf(dst, "%v", err)
 				fs.CountError(err)
-				removeFailedCopy(dst)
+				removeFailedCopy(ctx, dst)
 				return newDst, err
 			}
 		}
 	}
 
-	fs.Infof(src, actionTaken)
+	fs.Infof(src, "%s%v", actionTaken, fs.LogValueHide("size", src.Size()))
 	return newDst, err
 }
 
@@ -363,26 +399,33 @@ func Copy(f fs.Fs, dst fs.Object, remote string, src fs.Object) (newDst fs.Objec
 //
 // It returns the destination object if possible.  Note that this may
 // be nil.
-func Move(fdst fs.Fs, dst fs.Object, remote string, src fs.Object) (newDst fs.Object, err error) {
+func Move(ctx context.Context, fdst fs.Fs, dst fs.Object, remote string, src fs.Object) (newDst fs.Object, err error) {
 	newDst = dst
 	if fs.Config.DryRun {
 		fs.Logf(src, "Not moving as --dry-run")
 		return newDst, nil
 	}
+	// Server side moves need the backend's own Object
+	src, err = fs.ResolveObject(ctx, src)
+	if err != nil {
+		fs.CountError(err)
+		fs.Errorf(src, "Failed to move: %v", err)
+		return newDst, err
+	}
 	// See if we have Move available
-	if doMove := fdst.Features().Move; doMove != nil && SameConfig(src.Fs(), fdst) {
+	if doMove := fdst.Features().Move; doMove != nil && ServerSideCompatible(fdst, src.Fs()) {
 		// Delete destination if it exists
 		if dst != nil {
-			err = DeleteFile(dst)
+			err = DeleteFile(ctx, dst)
 			if err != nil {
 				return newDst, err
 			}
 		}
 		// Move dst <- src
-		newDst, err = doMove(src, remote)
+		newDst, err = doMove(ctx, src, remote)
 		switch err {
 		case nil:
-			fs.Infof(src, "Moved (server side)")
+			fs.Infof(src, "Moved (server side)%v", fs.LogValueHide("size", src.Size()))
 			return newDst, nil
 		case fs.ErrorCantMove:
 			fs.Debugf(src, "Can't move, switching to copy")
@@ -393,13 +436,13 @@ func Move(fdst fs.Fs, dst fs.Object, remote string, src fs.Object) (newDst fs.Ob
 		}
 	}
 	// Move not found or didn't work so copy dst <- src
-	newDst, err = Copy(fdst, dst, remote, src)
+	newDst, err = Copy(ctx, fdst, dst, remote, src)
 	if err != nil {
 		fs.Errorf(src, "Not deleting source as copy failed: %v", err)
 		return newDst, err
 	}
 	// Delete src if no error on copy
-	return newDst, DeleteFile(src)
+	return newDst, DeleteFile(ctx, src)
 }
 
 // CanServerSideMove returns true if fdst support server side moves or
@@ -418,7 +461,7 @@ func CanServerSideMove(fdst fs.Fs) bool {
 //
 // If backupDir is set then it moves the file to there instead of
 // deleting
-func DeleteFileWithBackupDir(dst fs.Object, backupDir fs.Fs) (err error) {
+func DeleteFileWithBackupDir(ctx context.Context, dst fs.Object, backupDir fs.Fs) (err error) {
 	accounting.Stats.Checking(dst.Remote())
 	numDeletes := accounting.Stats.Deletes(1)
 	if fs.Config.MaxDelete != -1 && numDeletes > fs.Config.MaxDelete {
@@ -431,21 +474,19 @@ func DeleteFileWithBackupDir(dst fs.Object, backupDir fs.Fs) (err error) {
 	if fs.Config.DryRun {
 		fs.Logf(dst, "Not %s as --dry-run", actioning)
 	} else if backupDir != nil {
-		if !SameConfig(dst.Fs(), backupDir) {
+		if !ServerSideCompatible(backupDir, dst.Fs()) {
 			err = errors.New("parameter to --backup-dir has to be on the same remote as destination")
 		} else {
-			remoteWithSuffix := dst.Remote() + fs.Config.Suffix
-			overwritten, _ := backupDir.NewObject(remoteWithSuffix)
-			_, err = Move(backupDir, overwritten, remoteWithSuffix, dst)
+			err = MoveBackupDir(ctx, backupDir, dst)
 		}
 	} else {
-		err = dst.Remove()
+		err = dst.Remove(ctx)
 	}
 	if err != nil {
 		fs.CountError(err)
 		fs.Errorf(dst, "Couldn't %s: %v", action, err)
 	} else if !fs.Config.DryRun {
-		fs.Infof(dst, actioned)
+		fs.Infof(dst, "%s%v", actioned, fs.LogValueHide("size", dst.Size()))
 	}
 	accounting.Stats.DoneChecking(dst.Remote())
 	return err
@@ -455,8 +496,8 @@ func DeleteFileWithBackupDir(dst fs.Object, backupDir fs.Fs) (err error) {
 //
 // If useBackupDir is set and --backup-dir is in effect then it moves
 // the file to there instead of deleting
-func DeleteFile(dst fs.Object) (err error) {
-	return DeleteFileWithBackupDir(dst, nil)
+func DeleteFile(ctx context.Context, dst fs.Object) (err error) {
+	return DeleteFileWithBackupDir(ctx, dst, nil)
 }
 
 // DeleteFilesWithBackupDir removes all the files passed in the
@@ -464,7 +505,7 @@ func DeleteFile(dst fs.Object) (err error) {
 //
 // If backupDir is set the files will be placed into that directory
 // instead of being deleted.
-func DeleteFilesWithBackupDir(toBeDeleted fs.ObjectsChan, backupDir fs.Fs) error {
+func DeleteFilesWithBackupDir(ctx context.Context, toBeDeleted fs.ObjectsChan, backupDir fs.Fs) error {
 	var wg sync.WaitGroup
 	wg.Add(fs.Config.Transfers)
 	var errorCount int32
@@ -474,7 +515,7 @@ func DeleteFilesWithBackupDir(toBeDeleted fs.ObjectsChan, backupDir fs.Fs) error
 		go func() {
 			defer wg.Done()
 			for dst := range toBeDeleted {
-				err := DeleteFileWithBackupDir(dst, backupDir)
+				err := DeleteFileWithBackupDir(ctx, dst, backupDir)
 				if err != nil {
 					atomic.AddInt32(&errorCount, 1)
 					if fserrors.IsFatalError(err) {
@@ -499,8 +540,8 @@ func DeleteFilesWithBackupDir(toBeDeleted fs.ObjectsChan, backupDir fs.Fs) error
 }
 
 // DeleteFiles removes all the files passed in the channel
-func DeleteFiles(toBeDeleted fs.ObjectsChan) error {
-	return DeleteFilesWithBackupDir(toBeDeleted, nil)
+func DeleteFiles(ctx context.Context, toBeDeleted fs.ObjectsChan) error {
+	return DeleteFilesWithBackupDir(ctx, toBeDeleted, nil)
 }
 
 // Read a Objects into add() for the given Fs.
@@ -510,8 +551,8 @@ func DeleteFiles(toBeDeleted fs.ObjectsChan) error {
 //
 // Each object is passed ito the function provided.  If that returns
 // an error then the listing will be aborted and that error returned.
-func readFilesFn(f fs.Fs, includeAll bool, dir string, add func(fs.Object) error) (err error) {
-	return walk.Walk(f, "", includeAll, fs.Config.MaxDepth, func(dirPath string, entries fs.DirEntries, err error) error {
+func readFilesFn(ctx context.Context, f fs.Fs, includeAll bool, dir string, add func(fs.Object) error) (err error) {
+	return walk.Walk(ctx, f, "", includeAll, fs.Config.MaxDepth, func(dirPath string, entries fs.DirEntries, err error) error {
 		if err != nil {
 			return err
 		}
@@ -525,6 +566,45 @@ func SameConfig(fdst, fsrc fs.Info) bool {
 	return fdst.Name() == fsrc.Name()
 }
 
+// ServerSideCompatible returns true if server side Copy, Move and
+// DirMove from fsrc to fdst may be attempted.
+//
+// This is true if they use the same config file entry.  It is also
+// true if they are differently named remotes of the same type which
+// fdst declares compatible, eg because they use the same credentials
+// and endpoint, or if --server-side-across-configs is set.
+//
+// Remotes which wrap other remotes, like crypt and cache, are only
+// compatible if fdst declares them so, eg because they wrap the same
+// remote with the same config, even with --server-side-across-configs
+// as copying between crypt remotes with different keys would corrupt
+// the files.
+func ServerSideCompatible(fdst fs.Fs, fsrc fs.Info) bool {
+	wraps := fdst.Features().UnWrap != nil
+	if wraps {
+		// Always use the wrapping remote's own check as remotes
+		// made from connection strings can share a name but not
+		// a config.  It isn't in the features as they are masked
+		// by the remote being wrapped.
+		if do, ok := fdst.(fs.ServerSideCompatibler); ok {
+			return do.ServerSideCompatible(fsrc)
+		}
+	}
+	if SameConfig(fdst, fsrc) {
+		return true
+	}
+	if reflect.TypeOf(fdst) != reflect.TypeOf(fsrc) || wraps {
+		return false
+	}
+	if fs.Config.ServerSideAcrossConfigs {
+		return true
+	}
+	if do := fdst.Features().ServerSideCompatible; do != nil {
+		return do(fsrc)
+	}
+	return false
+}
+
 // Same returns true if fdst and fsrc point to the same underlying Fs
 func Same(fdst, fsrc fs.Info) bool {
 	return SameConfig(fdst, fsrc) && fdst.Root() == fsrc.Root()
@@ -553,47 +633,129 @@ func Overlapping(fdst, fsrc fs.Info) bool {
 //
 // it returns true if differences were found
 // it also returns whether it couldn't be hashed
-func checkIdentical(dst, src fs.Object) (differ bool, noHash bool) {
+func checkIdentical(ctx context.Context, dst, src fs.Object) (differ bool, noHash bool, err error) {
 	same, ht, err := CheckHashes(src, dst)
 	if err != nil {
 		// CheckHashes will log and count errors
-		return true, false
+		return true, false, err
 	}
 	if ht == hash.None {
-		return false, true
+		return false, true, nil
 	}
 	if !same {
 		err = errors.Errorf("%v differ", ht)
 		fs.Errorf(src, "%v", err)
 		fs.CountError(err)
-		return true, false
+		return true, false, nil
 	}
-	return false, false
+	return false, false, nil
 }
 
 // checkFn is the the type of the checking function used in CheckFn()
-type checkFn func(a, b fs.Object) (differ bool, noHash bool)
+//
+// It should return differ set if the objects differ and noHash set
+// if they couldn't be hashed.  Any error returned should already
+// have been logged and counted.
+type checkFn func(ctx context.Context, a, b fs.Object) (differ bool, noHash bool, err error)
+
+// CheckOpt contains the options for the Check functions
+//
+// Each of the io.Writers is optional and if set receives a report
+// of the files in that category, one path per line.
+type CheckOpt struct {
+	Fdst, Fsrc   fs.Fs     // the destination and source to check
+	Check        checkFn   // function to use for checking
+	Combined     io.Writer // all files each with a leading status character
+	MissingOnSrc io.Writer // files only in the destination
+	MissingOnDst io.Writer // files only in the source
+	Match        io.Writer // files which are identical
+	Differ       io.Writer // files which differ
+	Error        io.Writer // files which had errors while checking
+	JSON         io.Writer // all files and their status as a JSON array
+}
+
+// Status characters used in the combined report
+const (
+	checkMatch        = '='
+	checkMissingOnSrc = '-'
+	checkMissingOnDst = '+'
+	checkDiffer       = '*'
+	checkError        = '!'
+)
+
+// checkStatus is the name of each status in the JSON report
+var checkStatus = map[rune]string{
+	checkMatch:        "match",
+	checkMissingOnSrc: "missing-on-src",
+	checkMissingOnDst: "missing-on-dst",
+	checkDiffer:       "differ",
+	checkError:        "error",
+}
+
+// CheckJSONItem is an entry in the JSON report from check
+type CheckJSONItem struct {
+	Path   string
+	Status string
+	Error  string `json:",omitempty"`
+}
 
 // checkMarch is used to march over two Fses in the same way as
 // sync/copy
 type checkMarch struct {
-	fdst, fsrc      fs.Fs
-	check           checkFn
+	ctx             context.Context
+	opt             *CheckOpt
 	differences     int32
 	noHashes        int32
 	srcFilesMissing int32
 	dstFilesMissing int32
+	jsonMu          sync.Mutex // protects jsonItems and writes to opt.JSON
+	jsonItems       int
+}
+
+// report writes o to out and the combined and JSON reports with the
+// status passed in
+func (c *checkMarch) report(o fs.DirEntry, out io.Writer, status rune, err error) {
+	if out != nil {
+		syncFprintf(out, "%s\n", o.Remote())
+	}
+	if c.opt.Combined != nil {
+		syncFprintf(c.opt.Combined, "%c %s\n", status, o.Remote())
+	}
+	if c.opt.JSON != nil {
+		item := CheckJSONItem{
+			Path:   o.Remote(),
+			Status: checkStatus[status],
+		}
+		if err != nil {
+			item.Error = err.Error()
+		}
+		data, jsonErr := json.Marshal(item)
+		if jsonErr != nil {
+			fs.Errorf(o, "Failed to make JSON report: %v", jsonErr)
+			return
+		}
+		c.jsonMu.Lock()
+		if c.jsonItems > 0 {
+			syncFprintf(c.opt.JSON, ",\n")
+		} else {
+			syncFprintf(c.opt.JSON, "\n")
+		}
+		syncFprintf(c.opt.JSON, "%s", data)
+		c.jsonItems++
+		c.jsonMu.Unlock()
+	}
 }
 
 // DstOnly have an object which is in the destination only
 func (c *checkMarch) DstOnly(dst fs.DirEntry) (recurse bool) {
 	switch dst.(type) {
 	case fs.Object:
-		err := errors.Errorf("File not in %v", c.fsrc)
+		err := errors.Errorf("File not in %v", c.opt.Fsrc)
 		fs.Errorf(dst, "%v", err)
 		fs.CountError(err)
 		atomic.AddInt32(&c.differences, 1)
 		atomic.AddInt32(&c.srcFilesMissing, 1)
+		c.report(dst, c.opt.MissingOnSrc, checkMissingOnSrc, nil)
 	case fs.Directory:
 		// Do the same thing to the entire contents of the directory
 		return true
@@ -607,11 +769,12 @@ func (c *checkMarch) DstOnly(dst fs.DirEntry) (recurse bool) {
 func (c *checkMarch) SrcOnly(src fs.DirEntry) (recurse bool) {
 	switch src.(type) {
 	case fs.Object:
-		err := errors.Errorf("File not in %v", c.fdst)
+		err := errors.Errorf("File not in %v", c.opt.Fdst)
 		fs.Errorf(src, "%v", err)
 		fs.CountError(err)
 		atomic.AddInt32(&c.differences, 1)
 		atomic.AddInt32(&c.dstFilesMissing, 1)
+		c.report(src, c.opt.MissingOnDst, checkMissingOnDst, nil)
 	case fs.Directory:
 		// Do the same thing to the entire contents of the directory
 		return true
@@ -622,19 +785,19 @@ func (c *checkMarch) SrcOnly(src fs.DirEntry) (recurse bool) {
 }
 
 // check to see if two objects are identical using the check function
-func (c *checkMarch) checkIdentical(dst, src fs.Object) (differ bool, noHash bool) {
+func (c *checkMarch) checkIdentical(dst, src fs.Object) (differ bool, noHash bool, err error) {
 	accounting.Stats.Checking(src.Remote())
 	defer accounting.Stats.DoneChecking(src.Remote())
 	if sizeDiffers(src, dst) {
 		err := errors.Errorf("Sizes differ")
 		fs.Errorf(src, "%v", err)
 		fs.CountError(err)
-		return true, false
+		return true, false, nil
 	}
 	if fs.Config.SizeOnly {
-		return false, false
+		return false, false, nil
 	}
-	return c.check(dst, src)
+	return c.opt.Check(c.ctx, dst, src)
 }
 
 // Match is called when src and dst are present, so sync src to dst
@@ -643,21 +806,27 @@ func (c *checkMarch) Match(dst, src fs.DirEntry) (recurse bool) {
 	case fs.Object:
 		dstX, ok := dst.(fs.Object)
 		if ok {
-			differ, noHash := c.checkIdentical(dstX, srcX)
-			if differ {
+			differ, noHash, err := c.checkIdentical(dstX, srcX)
+			if err != nil {
 				atomic.AddInt32(&c.differences, 1)
+				c.report(src, c.opt.Error, checkError, err)
+			} else if differ {
+				atomic.AddInt32(&c.differences, 1)
+				c.report(src, c.opt.Differ, checkDiffer, nil)
 			} else {
 				fs.Debugf(dstX, "OK")
+				c.report(src, c.opt.Match, checkMatch, nil)
 			}
 			if noHash {
 				atomic.AddInt32(&c.noHashes, 1)
 			}
 		} else {
-			err := errors.Errorf("is file on %v but directory on %v", c.fsrc, c.fdst)
+			err := errors.Errorf("is file on %v but directory on %v", c.opt.Fsrc, c.opt.Fdst)
 			fs.Errorf(src, "%v", err)
 			fs.CountError(err)
 			atomic.AddInt32(&c.differences, 1)
 			atomic.AddInt32(&c.dstFilesMissing, 1)
+			c.report(src, c.opt.MissingOnDst, checkMissingOnDst, nil)
 		}
 	case fs.Directory:
 		// Do the same thing to the entire contents of the directory
@@ -665,11 +834,12 @@ func (c *checkMarch) Match(dst, src fs.DirEntry) (recurse bool) {
 		if ok {
 			return true
 		}
-		err := errors.Errorf("is file on %v but directory on %v", c.fdst, c.fsrc)
+		err := errors.Errorf("is file on %v but directory on %v", c.opt.Fdst, c.opt.Fsrc)
 		fs.Errorf(dst, "%v", err)
 		fs.CountError(err)
 		atomic.AddInt32(&c.differences, 1)
 		atomic.AddInt32(&c.srcFilesMissing, 1)
+		c.report(dst, c.opt.MissingOnSrc, checkMissingOnSrc, nil)
 
 	default:
 		panic("Bad object in DirEntries")
@@ -677,24 +847,33 @@ func (c *checkMarch) Match(dst, src fs.DirEntry) (recurse bool) {
 	return false
 }
 
-// CheckFn checks the files in fsrc and fdst according to Size and
-// hash using checkFunction on each file to check the hashes.
+// CheckFn checks the files in opt.Fsrc and opt.Fdst according to
+// Size and hash using opt.Check on each file to check the hashes.
 //
-// checkFunction sees if dst and src are identical
+// opt.Check sees if dst and src are identical
 //
 // it returns true if differences were found
 // it also returns whether it couldn't be hashed
-func CheckFn(fdst, fsrc fs.Fs, check checkFn) error {
+//
+// The files are reported to the io.Writers in opt as they are
+// checked.
+func CheckFn(ctx context.Context, opt *CheckOpt) error {
 	c := &checkMarch{
-		fdst:  fdst,
-		fsrc:  fsrc,
-		check: check,
+		ctx: ctx,
+		opt: opt,
 	}
+	fdst, fsrc := opt.Fdst, opt.Fsrc
 
 	// set up a march over fdst and fsrc
-	m := march.New(context.Background(), fdst, fsrc, "", c)
+	if opt.JSON != nil {
+		syncFprintf(opt.JSON, "[")
+	}
+	m := march.New(ctx, fdst, fsrc, "", c)
 	fs.Infof(fdst, "Waiting for checks to finish")
 	m.Run()
+	if opt.JSON != nil {
+		syncFprintf(opt.JSON, "\n]\n")
+	}
 
 	if c.dstFilesMissing > 0 {
 		fs.Logf(fdst, "%d files missing", c.dstFilesMissing)
@@ -713,9 +892,11 @@ func CheckFn(fdst, fsrc fs.Fs, check checkFn) error {
 	return nil
 }
 
-// Check the files in fsrc and fdst according to Size and hash
-func Check(fdst, fsrc fs.Fs) error {
-	return CheckFn(fdst, fsrc, checkIdentical)
+// Check the files in opt.Fsrc and opt.Fdst according to Size and hash
+func Check(ctx context.Context, opt *CheckOpt) error {
+	optCopy := *opt
+	optCopy.Check = checkIdentical
+	return CheckFn(ctx, &optCopy)
 }
 
 // CheckEqualReaders checks to see if in1 and in2 have the same
@@ -752,15 +933,15 @@ func CheckEqualReaders(in1, in2 io.Reader) (differ bool, err error) {
 // reading all their bytes if necessary.
 //
 // it returns true if differences were found
-func CheckIdentical(dst, src fs.Object) (differ bool, err error) {
-	in1, err := dst.Open()
+func CheckIdentical(ctx context.Context, dst, src fs.Object) (differ bool, err error) {
+	in1, err := dst.Open(ctx)
 	if err != nil {
 		return true, errors.Wrapf(err, "failed to open %q", dst)
 	}
 	in1 = accounting.NewAccount(in1, dst).WithBuffer() // account and buffer the transfer
 	defer fs.CheckClose(in1, &err)
 
-	in2, err := src.Open()
+	in2, err := src.Open(ctx)
 	if err != nil {
 		return true, errors.Wrapf(err, "failed to open %q", src)
 	}
@@ -770,26 +951,27 @@ func CheckIdentical(dst, src fs.Object) (differ bool, err error) {
 	return CheckEqualReaders(in1, in2)
 }
 
-// CheckDownload checks the files in fsrc and fdst according to Size
-// and the actual contents of the files.
-func CheckDownload(fdst, fsrc fs.Fs) error {
-	check := func(a, b fs.Object) (differ bool, noHash bool) {
-		differ, err := CheckIdentical(a, b)
+// CheckDownload checks the files in opt.Fsrc and opt.Fdst according
+// to Size and the actual contents of the files.
+func CheckDownload(ctx context.Context, opt *CheckOpt) error {
+	optCopy := *opt
+	optCopy.Check = func(ctx context.Context, a, b fs.Object) (differ bool, noHash bool, err error) {
+		differ, err = CheckIdentical(ctx, a, b)
 		if err != nil {
 			fs.CountError(err)
 			fs.Errorf(a, "Failed to download: %v", err)
-			return true, true
+			return true, false, err
 		}
-		return differ, false
+		return differ, false, nil
 	}
-	return CheckFn(fdst, fsrc, check)
+	return CheckFn(ctx, &optCopy)
 }
 
 // ListFn lists the Fs to the supplied function
 //
 // Lists in parallel which may get them out of order
-func ListFn(f fs.Fs, fn func(fs.Object)) error {
-	return walk.Walk(f, "", false, fs.Config.MaxDepth, func(dirPath string, entries fs.DirEntries, err error) error {
+func ListFn(ctx context.Context, f fs.Fs, fn func(fs.Object)) error {
+	return walk.Walk(ctx, f, "", false, fs.Config.MaxDepth, func(dirPath string, entries fs.DirEntries, err error) error {
 		if err != nil {
 			// FIXME count errors and carry on for listing
 			return err
@@ -816,8 +998,8 @@ func syncFprintf(w io.Writer, format string, a ...interface{}) {
 // Shows size and path - obeys includes and excludes
 //
 // Lists in parallel which may get them out of order
-func List(f fs.Fs, w io.Writer) error {
-	return ListFn(f, func(o fs.Object) {
+func List(ctx context.Context, f fs.Fs, w io.Writer) error {
+	return ListFn(ctx, f, func(o fs.Object) {
 		syncFprintf(w, "%9d %s\n", o.Size(), o.Remote())
 	})
 }
@@ -827,8 +1009,8 @@ func List(f fs.Fs, w io.Writer) error {
 // Shows size, mod time and path - obeys includes and excludes
 //
 // Lists in parallel which may get them out of order
-func ListLong(f fs.Fs, w io.Writer) error {
-	return ListFn(f, func(o fs.Object) {
+func ListLong(ctx context.Context, f fs.Fs, w io.Writer) error {
+	return ListFn(ctx, f, func(o fs.Object) {
 		accounting.Stats.Checking(o.Remote())
 		modTime := o.ModTime()
 		accounting.Stats.DoneChecking(o.Remote())
@@ -842,8 +1024,8 @@ func ListLong(f fs.Fs, w io.Writer) error {
 // excludes
 //
 // Lists in parallel which may get them out of order
-func Md5sum(f fs.Fs, w io.Writer) error {
-	return hashLister(hash.MD5, f, w)
+func Md5sum(ctx context.Context, f fs.Fs, w io.Writer) error {
+	return hashLister(ctx, hash.MD5, f, w)
 }
 
 // Sha1sum list the Fs to the supplied writer
@@ -851,8 +1033,8 @@ func Md5sum(f fs.Fs, w io.Writer) error {
 // Obeys includes and excludes
 //
 // Lists in parallel which may get them out of order
-func Sha1sum(f fs.Fs, w io.Writer) error {
-	return hashLister(hash.SHA1, f, w)
+func Sha1sum(ctx context.Context, f fs.Fs, w io.Writer) error {
+	return hashLister(ctx, hash.SHA1, f, w)
 }
 
 // DropboxHashSum list the Fs to the supplied writer
@@ -860,8 +1042,8 @@ func Sha1sum(f fs.Fs, w io.Writer) error {
 // Obeys includes and excludes
 //
 // Lists in parallel which may get them out of order
-func DropboxHashSum(f fs.Fs, w io.Writer) error {
-	return hashLister(hash.Dropbox, f, w)
+func DropboxHashSum(ctx context.Context, f fs.Fs, w io.Writer) error {
+	return hashLister(ctx, hash.Dropbox, f, w)
 }
 
 // hashSum returns the human readable hash for ht passed in.  This may
@@ -879,8 +1061,8 @@ func hashSum(ht hash.Type, o fs.Object) string {
 	return sum
 }
 
-func hashLister(ht hash.Type, f fs.Fs, w io.Writer) error {
-	return ListFn(f, func(o fs.Object) {
+func hashLister(ctx context.Context, ht hash.Type, f fs.Fs, w io.Writer) error {
+	return ListFn(ctx, f, func(o fs.Object) {
 		sum := hashSum(ht, o)
 		syncFprintf(w, "%*s  %s\n", hash.Width[ht], sum, o.Remote())
 	})
@@ -889,8 +1071,8 @@ func hashLister(ht hash.Type, f fs.Fs, w io.Writer) error {
 // Count counts the objects and their sizes in the Fs
 //
 // Obeys includes and excludes
-func Count(f fs.Fs) (objects int64, size int64, err error) {
-	err = ListFn(f, func(o fs.Object) {
+func Count(ctx context.Context, f fs.Fs) (objects int64, size int64, err error) {
+	err = ListFn(ctx, f, func(o fs.Object) {
 		atomic.AddInt64(&objects, 1)
 		atomic.AddInt64(&size, o.Size())
 	})
@@ -907,8 +1089,8 @@ func ConfigMaxDepth(recursive bool) int {
 }
 
 // ListDir lists the directories/buckets/containers in the Fs to the supplied writer
-func ListDir(f fs.Fs, w io.Writer) error {
-	return walk.Walk(f, "", false, ConfigMaxDepth(false), func(dirPath string, entries fs.DirEntries, err error) error {
+func ListDir(ctx context.Context, f fs.Fs, w io.Writer) error {
+	return walk.Walk(ctx, f, "", false, ConfigMaxDepth(false), func(dirPath string, entries fs.DirEntries, err error) error {
 		if err != nil {
 			// FIXME count errors and carry on for listing
 			return err
@@ -923,13 +1105,13 @@ func ListDir(f fs.Fs, w io.Writer) error {
 }
 
 // Mkdir makes a destination directory or container
-func Mkdir(f fs.Fs, dir string) error {
+func Mkdir(ctx context.Context, f fs.Fs, dir string) error {
 	if fs.Config.DryRun {
 		fs.Logf(fs.LogDirName(f, dir), "Not making directory as dry run is set")
 		return nil
 	}
 	fs.Debugf(fs.LogDirName(f, dir), "Making directory")
-	err := f.Mkdir(dir)
+	err := f.Mkdir(ctx, dir)
 	if err != nil {
 		fs.CountError(err)
 		return err
@@ -939,18 +1121,18 @@ func Mkdir(f fs.Fs, dir string) error {
 
 // TryRmdir removes a container but not if not empty.  It doesn't
 // count errors but may return one.
-func TryRmdir(f fs.Fs, dir string) error {
+func TryRmdir(ctx context.Context, f fs.Fs, dir string) error {
 	if fs.Config.DryRun {
 		fs.Logf(fs.LogDirName(f, dir), "Not deleting as dry run is set")
 		return nil
 	}
 	fs.Debugf(fs.LogDirName(f, dir), "Removing directory")
-	return f.Rmdir(dir)
+	return f.Rmdir(ctx, dir)
 }
 
 // Rmdir removes a container but not if not empty
-func Rmdir(f fs.Fs, dir string) error {
-	err := TryRmdir(f, dir)
+func Rmdir(ctx context.Context, f fs.Fs, dir string) error {
+	err := TryRmdir(ctx, f, dir)
 	if err != nil {
 		fs.CountError(err)
 		return err
@@ -959,7 +1141,7 @@ func Rmdir(f fs.Fs, dir string) error {
 }
 
 // Purge removes a directory and all of its contents
-func Purge(f fs.Fs, dir string) error {
+func Purge(ctx context.Context, f fs.Fs, dir string) error {
 	doFallbackPurge := true
 	var err error
 	if dir == "" {
@@ -969,7 +1151,7 @@ func Purge(f fs.Fs, dir string) error {
 			if fs.Config.DryRun {
 				fs.Logf(f, "Not purging as --dry-run set")
 			} else {
-				err = doPurge()
+				err = doPurge(ctx)
 				if err == fs.ErrorCantPurge {
 					doFallbackPurge = true
 				}
@@ -978,11 +1160,11 @@ func Purge(f fs.Fs, dir string) error {
 	}
 	if doFallbackPurge {
 		// DeleteFiles and Rmdir observe --dry-run
-		err = DeleteFiles(listToChan(f, dir))
+		err = DeleteFiles(ctx, listToChan(ctx, f, dir))
 		if err != nil {
 			return err
 		}
-		err = Rmdirs(f, "", false)
+		err = Rmdirs(ctx, f, dir, false)
 	}
 	if err != nil {
 		fs.CountError(err)
@@ -993,13 +1175,13 @@ func Purge(f fs.Fs, dir string) error {
 
 // Delete removes all the contents of a container.  Unlike Purge, it
 // obeys includes and excludes.
-func Delete(f fs.Fs) error {
+func Delete(ctx context.Context, f fs.Fs) error {
 	delete := make(fs.ObjectsChan, fs.Config.Transfers)
 	delErr := make(chan error, 1)
 	go func() {
-		delErr <- DeleteFiles(delete)
+		delErr <- DeleteFiles(ctx, delete)
 	}()
-	err := ListFn(f, func(o fs.Object) {
+	err := ListFn(ctx, f, func(o fs.Object) {
 		delete <- o
 	})
 	close(delete)
@@ -1011,7 +1193,7 @@ func Delete(f fs.Fs) error {
 }
 
 // dedupeRename renames the objs slice to different names
-func dedupeRename(remote string, objs []fs.Object) {
+func dedupeRename(ctx context.Context, remote string, objs []fs.Object) {
 	f := objs[0].Fs()
 	doMove := f.Features().Move
 	if doMove == nil {
@@ -1022,7 +1204,7 @@ func dedupeRename(remote string, objs []fs.Object) {
 	for i, o := range objs {
 		newName := fmt.Sprintf("%s-%d%s", base, i+1, ext)
 		if !fs.Config.DryRun {
-			newObj, err := doMove(o, newName)
+			newObj, err := doMove(ctx, o, newName)
 			if err != nil {
 				fs.CountError(err)
 				fs.Errorf(o, "Failed to rename: %v", err)
@@ -1036,18 +1218,18 @@ func dedupeRename(remote string, objs []fs.Object) {
 }
 
 // dedupeDeleteAllButOne deletes all but the one in keep
-func dedupeDeleteAllButOne(keep int, remote string, objs []fs.Object) {
+func dedupeDeleteAllButOne(ctx context.Context, keep int, remote string, objs []fs.Object) {
 	for i, o := range objs {
 		if i == keep {
 			continue
 		}
-		_ = DeleteFile(o)
+		_ = DeleteFile(ctx, o)
 	}
 	fs.Logf(remote, "Deleted %d extra copies", len(objs)-1)
 }
 
 // dedupeDeleteIdentical deletes all but one of identical (by hash) copies
-func dedupeDeleteIdentical(remote string, objs []fs.Object) []fs.Object {
+func dedupeDeleteIdentical(ctx context.Context, remote string, objs []fs.Object) []fs.Object {
 	// See how many of these duplicates are identical
 	byHash := make(map[string][]fs.Object, len(objs))
 	for _, o := range objs {
@@ -1063,7 +1245,7 @@ func dedupeDeleteIdentical(remote string, objs []fs.Object) []fs.Object {
 		if len(hashObjs) > 1 {
 			fs.Logf(remote, "Deleting %d/%d identical duplicates (md5sum %q)", len(hashObjs)-1, len(hashObjs), md5sum)
 			for _, o := range hashObjs[1:] {
-				_ = DeleteFile(o)
+				_ = DeleteFile(ctx, o)
 			}
 		}
 		objs = append(objs, hashObjs[0])
@@ -1073,7 +1255,7 @@ func dedupeDeleteIdentical(remote string, objs []fs.Object) []fs.Object {
 }
 
 // dedupeInteractive interactively dedupes the slice of objects
-func dedupeInteractive(remote string, objs []fs.Object) {
+func dedupeInteractive(ctx context.Context, remote string, objs []fs.Object) {
 	fmt.Printf("%s: %d duplicates remain\n", remote, len(objs))
 	for i, o := range objs {
 		md5sum, err := o.Hash(hash.MD5)
@@ -1086,9 +1268,9 @@ func dedupeInteractive(remote string, objs []fs.Object) {
 	case 's':
 	case 'k':
 		keep := config.ChooseNumber("Enter the number of the file to keep", 1, len(objs))
-		dedupeDeleteAllButOne(keep-1, remote, objs)
+		dedupeDeleteAllButOne(ctx, keep-1, remote, objs)
 	case 'r':
-		dedupeRename(remote, objs)
+		dedupeRename(ctx, remote, objs)
 	}
 }
 
@@ -1161,9 +1343,9 @@ func (x *DeduplicateMode) Type() string {
 var _ pflag.Value = (*DeduplicateMode)(nil)
 
 // dedupeFindDuplicateDirs scans f for duplicate directories
-func dedupeFindDuplicateDirs(f fs.Fs) ([][]fs.Directory, error) {
+func dedupeFindDuplicateDirs(ctx context.Context, f fs.Fs) ([][]fs.Directory, error) {
 	duplicateDirs := [][]fs.Directory{}
-	err := walk.Walk(f, "", true, fs.Config.MaxDepth, func(dirPath string, entries fs.DirEntries, err error) error {
+	err := walk.Walk(ctx, f, "", true, fs.Config.MaxDepth, func(dirPath string, entries fs.DirEntries, err error) error {
 		if err != nil {
 			return err
 		}
@@ -1185,7 +1367,7 @@ func dedupeFindDuplicateDirs(f fs.Fs) ([][]fs.Directory, error) {
 }
 
 // dedupeMergeDuplicateDirs merges all the duplicate directories found
-func dedupeMergeDuplicateDirs(f fs.Fs, duplicateDirs [][]fs.Directory) error {
+func dedupeMergeDuplicateDirs(ctx context.Context, f fs.Fs, duplicateDirs [][]fs.Directory) error {
 	mergeDirs := f.Features().MergeDirs
 	if mergeDirs == nil {
 		return errors.Errorf("%v: can't merge directories", f)
@@ -1197,7 +1379,7 @@ func dedupeMergeDuplicateDirs(f fs.Fs, duplicateDirs [][]fs.Directory) error {
 	for _, dirs := range duplicateDirs {
 		if !fs.Config.DryRun {
 			fs.Infof(dirs[0], "Merging contents of duplicate directories")
-			err := mergeDirs(dirs)
+			err := mergeDirs(ctx, dirs)
 			if err != nil {
 				return errors.Wrap(err, "merge duplicate dirs")
 			}
@@ -1212,20 +1394,20 @@ func dedupeMergeDuplicateDirs(f fs.Fs, duplicateDirs [][]fs.Directory) error {
 // Deduplicate interactively finds duplicate files and offers to
 // delete all but one or rename them to be different. Only useful with
 // Google Drive which can have duplicate file names.
-func Deduplicate(f fs.Fs, mode DeduplicateMode) error {
+func Deduplicate(ctx context.Context, f fs.Fs, mode DeduplicateMode) error {
 	fs.Infof(f, "Looking for duplicates using %v mode.", mode)
 
 	// Find duplicate directories first and fix them - repeat
 	// until all fixed
 	for {
-		duplicateDirs, err := dedupeFindDuplicateDirs(f)
+		duplicateDirs, err := dedupeFindDuplicateDirs(ctx, f)
 		if err != nil {
 			return err
 		}
 		if len(duplicateDirs) == 0 {
 			break
 		}
-		err = dedupeMergeDuplicateDirs(f, duplicateDirs)
+		err = dedupeMergeDuplicateDirs(ctx, f, duplicateDirs)
 		if err != nil {
 			return err
 		}
@@ -1236,7 +1418,7 @@ func Deduplicate(f fs.Fs, mode DeduplicateMode) error {
 
 	// Now find duplicate files
 	files := map[string][]fs.Object{}
-	err := walk.Walk(f, "", true, fs.Config.MaxDepth, func(dirPath string, entries fs.DirEntries, err error) error {
+	err := walk.Walk(ctx, f, "", true, fs.Config.MaxDepth, func(dirPath string, entries fs.DirEntries, err error) error {
 		if err != nil {
 			return err
 		}
@@ -1252,24 +1434,24 @@ func Deduplicate(f fs.Fs, mode DeduplicateMode) error {
 	for remote, objs := range files {
 		if len(objs) > 1 {
 			fs.Logf(remote, "Found %d duplicates - deleting identical copies", len(objs))
-			objs = dedupeDeleteIdentical(remote, objs)
+			objs = dedupeDeleteIdentical(ctx, remote, objs)
 			if len(objs) <= 1 {
 				fs.Logf(remote, "All duplicates removed")
 				continue
 			}
 			switch mode {
 			case DeduplicateInteractive:
-				dedupeInteractive(remote, objs)
+				dedupeInteractive(ctx, remote, objs)
 			case DeduplicateFirst:
-				dedupeDeleteAllButOne(0, remote, objs)
+				dedupeDeleteAllButOne(ctx, 0, remote, objs)
 			case DeduplicateNewest:
 				sort.Sort(objectsSortedByModTime(objs)) // sort oldest first
-				dedupeDeleteAllButOne(len(objs)-1, remote, objs)
+				dedupeDeleteAllButOne(ctx, len(objs)-1, remote, objs)
 			case DeduplicateOldest:
 				sort.Sort(objectsSortedByModTime(objs)) // sort oldest first
-				dedupeDeleteAllButOne(0, remote, objs)
+				dedupeDeleteAllButOne(ctx, 0, remote, objs)
 			case DeduplicateRename:
-				dedupeRename(remote, objs)
+				dedupeRename(ctx, remote, objs)
 			case DeduplicateSkip:
 				// skip
 			default:
@@ -1286,11 +1468,11 @@ func Deduplicate(f fs.Fs, mode DeduplicateMode) error {
 // channel.
 //
 // If the error was ErrorDirNotFound then it will be ignored
-func listToChan(f fs.Fs, dir string) fs.ObjectsChan {
+func listToChan(ctx context.Context, f fs.Fs, dir string) fs.ObjectsChan {
 	o := make(fs.ObjectsChan, fs.Config.Checkers)
 	go func() {
 		defer close(o)
-		_ = walk.Walk(f, dir, true, fs.Config.MaxDepth, func(dirPath string, entries fs.DirEntries, err error) error {
+		_ = walk.Walk(ctx, f, dir, true, fs.Config.MaxDepth, func(dirPath string, entries fs.DirEntries, err error) error {
 			if err != nil {
 				if err == fs.ErrorDirNotFound {
 					return nil
@@ -1310,7 +1492,7 @@ func listToChan(f fs.Fs, dir string) fs.ObjectsChan {
 }
 
 // CleanUp removes the trash for the Fs
-func CleanUp(f fs.Fs) error {
+func CleanUp(ctx context.Context, f fs.Fs) error {
 	doCleanUp := f.Features().CleanUp
 	if doCleanUp == nil {
 		return errors.Errorf("%v doesn't support cleanup", f)
@@ -1319,7 +1501,7 @@ func CleanUp(f fs.Fs) error {
 		fs.Logf(f, "Not running cleanup as --dry-run set")
 		return nil
 	}
-	return doCleanUp()
+	return doCleanUp(ctx)
 }
 
 // wrap a Reader and a Closer together into a ReadCloser
@@ -1336,13 +1518,13 @@ type readCloser struct {
 //
 // if count < 0 then it will be ignored
 // if count >= 0 then only that many characters will be output
-func Cat(f fs.Fs, w io.Writer, offset, count int64) error {
+func Cat(ctx context.Context, f fs.Fs, w io.Writer, offset, count int64) error {
 	var mu sync.Mutex
-	return ListFn(f, func(o fs.Object) {
+	return ListFn(ctx, f, func(o fs.Object) {
 		var err error
 		accounting.Stats.Transferring(o.Remote())
 		defer func() {
-			accounting.Stats.DoneTransferring(o.Remote(), err == nil)
+			accounting.Stats.DoneTransferringError(o.Remote(), err)
 		}()
 		opt := fs.RangeOption{Start: offset, End: -1}
 		size := o.Size()
@@ -1356,7 +1538,7 @@ func Cat(f fs.Fs, w io.Writer, offset, count int64) error {
 		if opt.Start > 0 || opt.End >= 0 {
 			options = append(options, &opt)
 		}
-		in, err := o.Open(options...)
+		in, err := o.Open(ctx, options...)
 		if err != nil {
 			fs.CountError(err)
 			fs.Errorf(o, "Failed to open: %v", err)
@@ -1389,18 +1571,20 @@ func Cat(f fs.Fs, w io.Writer, offset, count int64) error {
 }
 
 // Rcat reads data from the Reader until EOF and uploads it to a file on remote
-func Rcat(fdst fs.Fs, dstFileName string, in io.ReadCloser, modTime time.Time) (dst fs.Object, err error) {
+func Rcat(ctx context.Context, fdst fs.Fs, dstFileName string, in io.ReadCloser, modTime time.Time) (dst fs.Object, err error) {
 	accounting.Stats.Transferring(dstFileName)
-	in = accounting.NewAccountSizeName(in, -1, dstFileName).WithBuffer()
+	in = accounting.NewAccountSizeName(in, -1, dstFileName).WithDestination(fdst).WithBuffer()
 	defer func() {
-		accounting.Stats.DoneTransferring(dstFileName, err == nil)
+		accounting.Stats.DoneTransferringError(dstFileName, err)
 		if otherErr := in.Close(); otherErr != nil {
 			fs.Debugf(fdst, "Rcat: failed to close source: %v", err)
 		}
 	}()
 
-	hashOption := &fs.HashesOption{Hashes: fdst.Hashes()}
-	hash, err := hash.NewMultiHasherTypes(fdst.Hashes())
+	// Only calculate the hash which will be used to check the upload
+	hashes := hash.Set(fdst.Hashes().GetOne())
+	hashOption := &fs.HashesOption{Hashes: hashes}
+	hash, err := hash.NewMultiHasherTypes(hashes)
 	if err != nil {
 		return nil, err
 	}
@@ -1409,7 +1593,7 @@ func Rcat(fdst fs.Fs, dstFileName string, in io.ReadCloser, modTime time.Time) (
 
 	compare := func(dst fs.Object) error {
 		src := object.NewStaticObjectInfo(dstFileName, modTime, int64(readCounter.BytesRead()), false, hash.Sums(), fdst)
-		if !Equal(src, dst) {
+		if !Equal(ctx, src, dst) {
 			err = errors.Errorf("corrupted on transfer")
 			fs.CountError(err)
 			fs.Errorf(dst, "%v", err)
@@ -1423,7 +1607,7 @@ func Rcat(fdst fs.Fs, dstFileName string, in io.ReadCloser, modTime time.Time) (
 	if n, err := io.ReadFull(trackingIn, buf); err == io.EOF || err == io.ErrUnexpectedEOF {
 		fs.Debugf(fdst, "File to upload is small (%d bytes), uploading instead of streaming", n)
 		src := object.NewMemoryObject(dstFileName, modTime, buf[:n])
-		return Copy(fdst, nil, dstFileName, src)
+		return Copy(ctx, fdst, nil, dstFileName, src)
 	}
 
 	// Make a new ReadCloser with the bits we've already read
@@ -1441,7 +1625,7 @@ func Rcat(fdst fs.Fs, dstFileName string, in io.ReadCloser, modTime time.Time) (
 			return nil, errors.Wrap(err, "Failed to create temporary local FS to spool file")
 		}
 		defer func() {
-			err := Purge(tmpLocalFs, "")
+			err := Purge(ctx, tmpLocalFs, "")
 			if err != nil {
 				fs.Infof(tmpLocalFs, "Failed to cleanup temporary FS: %v", err)
 			}
@@ -1457,7 +1641,7 @@ func Rcat(fdst fs.Fs, dstFileName string, in io.ReadCloser, modTime time.Time) (
 	}
 
 	objInfo := object.NewStaticObjectInfo(dstFileName, modTime, -1, false, nil, nil)
-	if dst, err = fStreamTo.Features().PutStream(in, objInfo, hashOption); err != nil {
+	if dst, err = fStreamTo.Features().PutStream(ctx, in, objInfo, hashOption); err != nil {
 		return dst, err
 	}
 	if err = compare(dst); err != nil {
@@ -1465,26 +1649,26 @@ func Rcat(fdst fs.Fs, dstFileName string, in io.ReadCloser, modTime time.Time) (
 	}
 	if !canStream {
 		// copy dst (which is the local object we have just streamed to) to the remote
-		return Copy(fdst, nil, dstFileName, dst)
+		return Copy(ctx, fdst, nil, dstFileName, dst)
 	}
 	return dst, nil
 }
 
 // PublicLink adds a "readable by anyone with link" permission on the given file or folder.
-func PublicLink(f fs.Fs, remote string) (string, error) {
+func PublicLink(ctx context.Context, f fs.Fs, remote string) (string, error) {
 	doPublicLink := f.Features().PublicLink
 	if doPublicLink == nil {
 		return "", errors.Errorf("%v doesn't support public links", f)
 	}
-	return doPublicLink(remote)
+	return doPublicLink(ctx, remote)
 }
 
 // Rmdirs removes any empty directories (or directories only
 // containing empty directories) under f, including f.
-func Rmdirs(f fs.Fs, dir string, leaveRoot bool) error {
+func Rmdirs(ctx context.Context, f fs.Fs, dir string, leaveRoot bool) error {
 	dirEmpty := make(map[string]bool)
-	dirEmpty[""] = !leaveRoot
-	err := walk.Walk(f, dir, true, fs.Config.MaxDepth, func(dirPath string, entries fs.DirEntries, err error) error {
+	dirEmpty[dir] = !leaveRoot
+	err := walk.Walk(ctx, f, dir, true, fs.Config.MaxDepth, func(dirPath string, entries fs.DirEntries, err error) error {
 		if err != nil {
 			fs.CountError(err)
 			fs.Errorf(f, "Failed to list %q: %v", dirPath, err)
@@ -1531,7 +1715,7 @@ func Rmdirs(f fs.Fs, dir string, leaveRoot bool) error {
 	sort.Strings(toDelete)
 	for i := len(toDelete) - 1; i >= 0; i-- {
 		dir := toDelete[i]
-		err := TryRmdir(f, dir)
+		err := TryRmdir(ctx, f, dir)
 		if err != nil {
 			fs.CountError(err)
 			fs.Errorf(dir, "Failed to rmdir: %v", err)
@@ -1546,7 +1730,18 @@ func Rmdirs(f fs.Fs, dir string, leaveRoot bool) error {
 //
 // Returns a flag which indicates whether the file needs to be
 // transferred or not.
-func NeedTransfer(dst, src fs.Object) bool {
+func NeedTransfer(ctx context.Context, dst, src fs.Object) bool {
+	return needTransfer(ctx, dst, src, !fs.Config.NoUpdateModTime)
+}
+
+// NeedTransferNoUpdate checks to see if src needs to be copied to dst
+// as NeedTransfer does, but never updates the modification time of
+// dst if the files are otherwise identical.
+func NeedTransferNoUpdate(ctx context.Context, dst, src fs.Object) bool {
+	return needTransfer(ctx, dst, src, false)
+}
+
+func needTransfer(ctx context.Context, dst, src fs.Object, updateModTime bool) bool {
 	if dst == nil {
 		fs.Debugf(src, "Couldn't find file - need to transfer")
 		return true
@@ -1589,7 +1784,7 @@ func NeedTransfer(dst, src fs.Object) bool {
 		}
 	} else {
 		// Check to see if changed or not
-		if Equal(src, dst) {
+		if equal(ctx, src, dst, fs.Config.SizeOnly, fs.Config.CheckSum, updateModTime) {
 			fs.Debugf(src, "Unchanged skipping")
 			return false
 		}
@@ -1597,8 +1792,66 @@ func NeedTransfer(dst, src fs.Object) bool {
 	return true
 }
 
+// ReferenceDir is a reference directory from --compare-dest or
+// --copy-dest
+type ReferenceDir struct {
+	Fs   fs.Fs
+	Copy bool // set for --copy-dest, otherwise it is --compare-dest
+}
+
+// CompareOrCopyDest looks for a file identical to src in the
+// reference directories passed in (from --compare-dest or
+// --copy-dest).  The reference files are never modified.
+//
+// With a --compare-dest ref finding an identical file means that src
+// doesn't need transferring.  With a --copy-dest ref the identical
+// file is server side copied to fdst instead of transferring src,
+// moving any existing dst into backupDir first if set.
+//
+// It returns noNeedTransfer set if src doesn't need transferring.
+func CompareOrCopyDest(ctx context.Context, fdst fs.Fs, dst, src fs.Object, refs []ReferenceDir, backupDir fs.Fs) (noNeedTransfer bool, err error) {
+	for _, refDir := range refs {
+		ref := refDir.Fs
+		refObj, err := ref.NewObject(ctx, src.Remote())
+		if err == fs.ErrorObjectNotFound || err == fs.ErrorNotAFile {
+			continue
+		} else if err != nil {
+			return false, err
+		}
+		if !equal(ctx, src, refObj, fs.Config.SizeOnly, fs.Config.CheckSum, false) {
+			continue
+		}
+		if !refDir.Copy {
+			fs.Debugf(src, "Identical file found in --compare-dest %v, skipping", ref)
+			return true, nil
+		}
+		if dst != nil && backupDir != nil {
+			err = MoveBackupDir(ctx, backupDir, dst)
+			if err != nil {
+				return false, err
+			}
+			dst = nil
+		}
+		newDst, err := Copy(ctx, fdst, dst, src.Remote(), refObj)
+		if err != nil {
+			return false, err
+		}
+		if newDst == nil {
+			// Only happens with --dry-run
+			return true, nil
+		}
+		// Check the copy and fix up its modification time if required
+		if !Equal(ctx, src, newDst) {
+			fs.Debugf(src, "File copied from --copy-dest %v differs, transferring", ref)
+			return false, nil
+		}
+		return true, nil
+	}
+	return false, nil
+}
+
 // moveOrCopyFile moves or copies a single file possibly to a new name
-func moveOrCopyFile(fdst fs.Fs, fsrc fs.Fs, dstFileName string, srcFileName string, cp bool) (err error) {
+func moveOrCopyFile(ctx context.Context, fdst fs.Fs, fsrc fs.Fs, dstFileName string, srcFileName string, cp bool) (err error) {
 	dstFilePath := path.Join(fdst.Root(), dstFileName)
 	srcFilePath := path.Join(fsrc.Root(), srcFileName)
 	if fdst.Name() == fsrc.Name() && dstFilePath == srcFilePath {
@@ -1613,27 +1866,27 @@ func moveOrCopyFile(fdst fs.Fs, fsrc fs.Fs, dstFileName string, srcFileName stri
 	}
 
 	// Find src object
-	srcObj, err := fsrc.NewObject(srcFileName)
+	srcObj, err := fsrc.NewObject(ctx, srcFileName)
 	if err != nil {
 		return err
 	}
 
 	// Find dst object if it exists
-	dstObj, err := fdst.NewObject(dstFileName)
+	dstObj, err := fdst.NewObject(ctx, dstFileName)
 	if err == fs.ErrorObjectNotFound {
 		dstObj = nil
 	} else if err != nil {
 		return err
 	}
 
-	if NeedTransfer(dstObj, srcObj) {
+	if NeedTransfer(ctx, dstObj, srcObj) {
 		accounting.Stats.Transferring(srcFileName)
-		_, err = Op(fdst, dstObj, dstFileName, srcObj)
-		accounting.Stats.DoneTransferring(srcFileName, err == nil)
+		_, err = Op(ctx, fdst, dstObj, dstFileName, srcObj)
+		accounting.Stats.DoneTransferringError(srcFileName, err)
 	} else {
 		accounting.Stats.Checking(srcFileName)
 		if !cp {
-			err = DeleteFile(srcObj)
+			err = DeleteFile(ctx, srcObj)
 		}
 		defer accounting.Stats.DoneChecking(srcFileName)
 	}
@@ -1641,13 +1894,13 @@ func moveOrCopyFile(fdst fs.Fs, fsrc fs.Fs, dstFileName string, srcFileName stri
 }
 
 // MoveFile moves a single file possibly to a new name
-func MoveFile(fdst fs.Fs, fsrc fs.Fs, dstFileName string, srcFileName string) (err error) {
-	return moveOrCopyFile(fdst, fsrc, dstFileName, srcFileName, false)
+func MoveFile(ctx context.Context, fdst fs.Fs, fsrc fs.Fs, dstFileName string, srcFileName string) (err error) {
+	return moveOrCopyFile(ctx, fdst, fsrc, dstFileName, srcFileName, false)
 }
 
 // CopyFile moves a single file possibly to a new name
-func CopyFile(fdst fs.Fs, fsrc fs.Fs, dstFileName string, srcFileName string) (err error) {
-	return moveOrCopyFile(fdst, fsrc, dstFileName, srcFileName, true)
+func CopyFile(ctx context.Context, fdst fs.Fs, fsrc fs.Fs, dstFileName string, srcFileName string) (err error) {
+	return moveOrCopyFile(ctx, fdst, fsrc, dstFileName, srcFileName, true)
 }
 
 // ListFormat defines files information print format
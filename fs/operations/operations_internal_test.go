@@ -38,3 +38,49 @@ func TestSizeDiffers(t *testing.T) {
 		assert.Equal(t, test.want, got, fmt.Sprintf("ignoreSize=%v, srcSize=%v, dstSize=%v", test.ignoreSize, test.srcSize, test.dstSize))
 	}
 }
+
+func TestKeepBackupVersions(t *testing.T) {
+	defer func(keepLast, keepDaily, keepWeekly, keepMonthly int, maxAge fs.Duration) {
+		fs.Config.BackupKeepLast = keepLast
+		fs.Config.BackupKeepDaily = keepDaily
+		fs.Config.BackupKeepWeekly = keepWeekly
+		fs.Config.BackupKeepMonthly = keepMonthly
+		fs.Config.BackupMaxAge = maxAge
+	}(fs.Config.BackupKeepLast, fs.Config.BackupKeepDaily, fs.Config.BackupKeepWeekly, fs.Config.BackupKeepMonthly, fs.Config.BackupMaxAge)
+
+	now := time.Date(2019, 3, 15, 12, 0, 0, 0, time.Local)
+	var versions []BackupVersion
+	for _, ago := range []time.Duration{
+		time.Hour,           // 0: today
+		2 * time.Hour,       // 1: today
+		26 * time.Hour,      // 2: yesterday
+		27 * time.Hour,      // 3: yesterday
+		10 * 24 * time.Hour, // 4: last week
+		40 * 24 * time.Hour, // 5: last month
+		41 * 24 * time.Hour, // 6: last month
+	} {
+		versions = append(versions, BackupVersion{Remote: "file", Version: now.Add(-ago)})
+	}
+
+	for _, test := range []struct {
+		keepLast, keepDaily, keepWeekly, keepMonthly int
+		maxAge                                       fs.Duration
+		want                                         []bool
+	}{
+		{0, 0, 0, 0, fs.DurationOff, []bool{false, false, false, false, false, false, false}},
+		{3, 0, 0, 0, fs.DurationOff, []bool{true, true, true, false, false, false, false}},
+		{0, 2, 0, 0, fs.DurationOff, []bool{true, false, true, false, false, false, false}},
+		{0, 0, 2, 0, fs.DurationOff, []bool{true, false, false, false, true, false, false}},
+		{0, 0, 0, 2, fs.DurationOff, []bool{true, false, false, false, false, true, false}},
+		{0, 0, 0, 0, fs.Duration(24 * time.Hour), []bool{true, true, false, false, false, false, false}},
+		{1, 1, 0, 2, fs.Duration(90 * time.Minute), []bool{true, false, false, false, false, true, false}},
+	} {
+		fs.Config.BackupKeepLast = test.keepLast
+		fs.Config.BackupKeepDaily = test.keepDaily
+		fs.Config.BackupKeepWeekly = test.keepWeekly
+		fs.Config.BackupKeepMonthly = test.keepMonthly
+		fs.Config.BackupMaxAge = test.maxAge
+		got := keepBackupVersions(versions, now)
+		assert.Equal(t, test.want, got, fmt.Sprintf("%+v", test))
+	}
+}
@@ -0,0 +1,266 @@
+// Remote control for the operations
+
+package operations
+
+import (
+	"context"
+	"strings"
+
+	"github.com/ncw/rclone/fs"
+	"github.com/ncw/rclone/fs/rc"
+	"github.com/pkg/errors"
+)
+
+func init() {
+	rc.Add(rc.Call{
+		Path:  "operations/list",
+		Fn:    rcList,
+		Title: "List the given remote and path in JSON format",
+		Help: `This takes the following parameters
+
+- fs - a remote name string eg "drive:"
+- remote - a path within that remote eg "dir"
+- recurse - if set recurse into directories
+- noModTime - if set don't read the modification times
+- showEncrypted - if set show the encrypted names of a crypt remote
+- showHash - if set return a dictionary of hashes
+
+The result is
+
+- list
+  - This is an array of objects as described in the lsjson command
+
+See the lsjson command for more information on the above and examples.
+`,
+		AuthRequired: true,
+	})
+	rc.Add(rc.Call{
+		Path:  "operations/about",
+		Fn:    rcAbout,
+		Title: "Return the space used on the remote",
+		Help: `This takes the following parameters
+
+- fs - a remote name string eg "drive:"
+
+The result is as returned from rclone about --json - any of total,
+used, trashed, other and free which the remote supports.
+`,
+		AuthRequired: true,
+	})
+	for _, cp := range []bool{false, true} {
+		cp := cp
+		name := "Move"
+		if cp {
+			name = "Copy"
+		}
+		rc.Add(rc.Call{
+			Path: "operations/" + strings.ToLower(name) + "file",
+			Fn: func(ctx context.Context, in rc.Params) (rc.Params, error) {
+				return rcMoveOrCopyFile(ctx, in, cp)
+			},
+			Title: name + " a file from source remote to destination remote",
+			Help: `This takes the following parameters
+
+- srcFs - a remote name string eg "drive:" for the source
+- srcRemote - a path within that remote eg "file.txt" for the source
+- dstFs - a remote name string eg "drive2:" for the destination
+- dstRemote - a path within that remote eg "file2.txt" for the destination
+`,
+			AuthRequired: true,
+		})
+	}
+	for _, op := range []struct {
+		name  string
+		title string
+		help  string
+		fn    func(ctx context.Context, f fs.Fs, remote string) error
+	}{
+		{name: "mkdir", title: "Make a destination directory or container", fn: Mkdir},
+		{name: "purge", title: "Remove a directory or container and all of its contents", fn: Purge},
+		{name: "deletefile", title: "Remove the single file pointed to", fn: rcDeleteFile, help: `
+
+The remote must point to a file, not a directory.`},
+	} {
+		op := op
+		rc.Add(rc.Call{
+			Path: "operations/" + op.name,
+			Fn: func(ctx context.Context, in rc.Params) (rc.Params, error) {
+				f, remote, err := rc.GetFsAndRemote(in)
+				if err != nil {
+					return nil, err
+				}
+				return nil, op.fn(ctx, f, remote)
+			},
+			Title: op.title,
+			Help: `This takes the following parameters
+
+- fs - a remote name string eg "drive:"
+- remote - a path within that remote eg "dir"` + op.help + `
+`,
+			AuthRequired: true,
+		})
+	}
+	rc.Add(rc.Call{
+		Path:  "operations/size",
+		Fn:    rcSize,
+		Title: "Count the number of bytes and files in remote",
+		Help: `This takes the following parameters
+
+- fs - a remote name string eg "drive:path/to/dir"
+
+Returns
+
+- count - number of files
+- bytes - number of bytes in those files
+
+See the size command for more information on the above.
+`,
+		AuthRequired: true,
+	})
+	rc.Add(rc.Call{
+		Path:  "operations/publiclink",
+		Fn:    rcPublicLink,
+		Title: "Create or retrieve a public link to the given file or folder.",
+		Help: `This takes the following parameters
+
+- fs - a remote name string eg "drive:"
+- remote - a path within that remote eg "dir"
+
+Returns
+
+- url - URL of the resource
+
+See the link command for more information on the above.
+`,
+		AuthRequired: true,
+	})
+}
+
+// List the directory
+func rcList(ctx context.Context, in rc.Params) (out rc.Params, err error) {
+	f, remote, err := rc.GetFsAndRemote(in)
+	if err != nil {
+		return nil, err
+	}
+	var opt ListJSONOpt
+	for _, flag := range []struct {
+		key   string
+		value *bool
+	}{
+		{"recurse", &opt.Recurse},
+		{"noModTime", &opt.NoModTime},
+		{"showEncrypted", &opt.ShowEncrypted},
+		{"showHash", &opt.ShowHash},
+	} {
+		*flag.value, err = in.GetBool(flag.key)
+		if rc.NotErrParamNotFound(err) {
+			return nil, err
+		}
+	}
+	var list = []*ListJSONItem{}
+	err = ListJSON(ctx, f, remote, &opt, func(item *ListJSONItem) error {
+		list = append(list, item)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	out = make(rc.Params)
+	out["list"] = list
+	return out, nil
+}
+
+// Return the space used on the remote
+func rcAbout(ctx context.Context, in rc.Params) (out rc.Params, err error) {
+	f, _, err := rc.GetFsFile(in)
+	if err != nil {
+		return nil, err
+	}
+	doAbout := f.Features().About
+	if doAbout == nil {
+		return nil, errors.Errorf("%v doesn't support about", f)
+	}
+	u, err := doAbout(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "about call failed")
+	}
+	out = make(rc.Params)
+	for _, item := range []struct {
+		key   string
+		value *int64
+	}{
+		{"total", u.Total},
+		{"used", u.Used},
+		{"trashed", u.Trashed},
+		{"other", u.Other},
+		{"free", u.Free},
+	} {
+		if item.value != nil {
+			out[item.key] = *item.value
+		}
+	}
+	return out, nil
+}
+
+// Copy or move a single file
+func rcMoveOrCopyFile(ctx context.Context, in rc.Params, cp bool) (out rc.Params, err error) {
+	srcFs, srcRemote, err := rc.GetFsAndRemoteNamed(in, "srcFs", "srcRemote")
+	if err != nil {
+		return nil, err
+	}
+	dstFs, dstRemote, err := rc.GetFsAndRemoteNamed(in, "dstFs", "dstRemote")
+	if err != nil {
+		return nil, err
+	}
+	return nil, moveOrCopyFile(ctx, dstFs, srcFs, dstRemote, srcRemote, cp)
+}
+
+// Delete the single file at remote
+func rcDeleteFile(ctx context.Context, f fs.Fs, remote string) error {
+	o, err := f.NewObject(ctx, remote)
+	if err != nil {
+		return err
+	}
+	return DeleteFile(ctx, o)
+}
+
+// Count the number of bytes and files in the remote
+func rcSize(ctx context.Context, in rc.Params) (out rc.Params, err error) {
+	f, fileName, err := rc.GetFsFile(in)
+	if err != nil {
+		return nil, err
+	}
+	var count, bytes int64
+	if fileName != "" {
+		// Just the one file
+		o, err := f.NewObject(ctx, fileName)
+		if err != nil {
+			return nil, err
+		}
+		count, bytes = 1, o.Size()
+	} else {
+		count, bytes, err = Count(ctx, f)
+		if err != nil {
+			return nil, err
+		}
+	}
+	out = make(rc.Params)
+	out["count"] = count
+	out["bytes"] = bytes
+	return out, nil
+}
+
+// Make a public link to the remote
+func rcPublicLink(ctx context.Context, in rc.Params) (out rc.Params, err error) {
+	f, remote, err := rc.GetFsAndRemote(in)
+	if err != nil {
+		return nil, err
+	}
+	url, err := PublicLink(ctx, f, remote)
+	if err != nil {
+		return nil, err
+	}
+	out = make(rc.Params)
+	out["url"] = url
+	return out, nil
+}
@@ -0,0 +1,175 @@
+package operations_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ncw/rclone/fs"
+	"github.com/ncw/rclone/fs/operations"
+	"github.com/ncw/rclone/fs/rc"
+	"github.com/ncw/rclone/fstest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func rcNewRun(t *testing.T, method string) (*fstest.Run, *rc.Call) {
+	if *fstest.RemoteName != "" {
+		t.Skip("Skipping test on non local remote")
+	}
+	r := fstest.NewRun(t)
+	call := rc.Find(method)
+	require.NotNil(t, call)
+	return r, call
+}
+
+// operations/copyfile and operations/movefile
+func TestRcCopyfileAndMovefile(t *testing.T) {
+	r, call := rcNewRun(t, "operations/copyfile")
+	defer r.Finalise()
+	file1 := r.WriteFile("file1", "file1 contents", t1)
+	r.Mkdir(r.Fremote)
+	fstest.CheckItems(t, r.Flocal, file1)
+	fstest.CheckItems(t, r.Fremote)
+
+	in := rc.Params{
+		"srcFs":     r.LocalName,
+		"srcRemote": "file1",
+		"dstFs":     r.FremoteName,
+		"dstRemote": "file1-renamed",
+	}
+	out, err := call.Fn(context.Background(), in)
+	require.NoError(t, err)
+	assert.Nil(t, out)
+
+	file1renamed := file1
+	file1renamed.Path = "file1-renamed"
+	fstest.CheckItems(t, r.Flocal, file1)
+	fstest.CheckItems(t, r.Fremote, file1renamed)
+
+	call = rc.Find("operations/movefile")
+	require.NotNil(t, call)
+	in["dstRemote"] = "file1-moved"
+	_, err = call.Fn(context.Background(), in)
+	require.NoError(t, err)
+
+	file1moved := file1
+	file1moved.Path = "file1-moved"
+	fstest.CheckItems(t, r.Flocal)
+	fstest.CheckItems(t, r.Fremote, file1renamed, file1moved)
+}
+
+// operations/deletefile, operations/mkdir and operations/purge
+func TestRcDeletefileMkdirPurge(t *testing.T) {
+	r, call := rcNewRun(t, "operations/deletefile")
+	defer r.Finalise()
+	file1 := r.WriteObject("file1", "file1 contents", t1)
+	file2 := r.WriteObject("subdir/file2", "file2 contents", t2)
+	fstest.CheckItems(t, r.Fremote, file1, file2)
+
+	_, err := call.Fn(context.Background(), rc.Params{
+		"fs":     r.FremoteName,
+		"remote": "file1",
+	})
+	require.NoError(t, err)
+	fstest.CheckItems(t, r.Fremote, file2)
+
+	// deleting a missing file is an error
+	_, err = call.Fn(context.Background(), rc.Params{
+		"fs":     r.FremoteName,
+		"remote": "file1",
+	})
+	assert.Equal(t, fs.ErrorObjectNotFound, err)
+
+	call = rc.Find("operations/mkdir")
+	require.NotNil(t, call)
+	_, err = call.Fn(context.Background(), rc.Params{
+		"fs":     r.FremoteName,
+		"remote": "newdir",
+	})
+	require.NoError(t, err)
+	fstest.CheckListingWithPrecision(t, r.Fremote, []fstest.Item{file2}, []string{"newdir", "subdir"}, fs.Config.ModifyWindow)
+
+	call = rc.Find("operations/purge")
+	require.NotNil(t, call)
+	_, err = call.Fn(context.Background(), rc.Params{
+		"fs":     r.FremoteName,
+		"remote": "subdir",
+	})
+	require.NoError(t, err)
+	fstest.CheckListingWithPrecision(t, r.Fremote, []fstest.Item{}, []string{"newdir"}, fs.Config.ModifyWindow)
+}
+
+// operations/list
+func TestRcList(t *testing.T) {
+	r, call := rcNewRun(t, "operations/list")
+	defer r.Finalise()
+	file1 := r.WriteObject("a", "a", t1)
+	file2 := r.WriteObject("subdir/b", "bb", t2)
+	fstest.CheckItems(t, r.Fremote, file1, file2)
+
+	list := func(in rc.Params) []*operations.ListJSONItem {
+		out, err := call.Fn(context.Background(), in)
+		require.NoError(t, err)
+		items, ok := out["list"].([]*operations.ListJSONItem)
+		require.True(t, ok)
+		return items
+	}
+
+	items := list(rc.Params{
+		"fs":     r.FremoteName,
+		"remote": "",
+	})
+	require.Len(t, items, 2)
+	assert.Equal(t, "a", items[0].Path)
+	assert.Equal(t, int64(1), items[0].Size)
+	assert.False(t, items[0].IsDir)
+	assert.Equal(t, "subdir", items[1].Path)
+	assert.True(t, items[1].IsDir)
+
+	items = list(rc.Params{
+		"fs":      r.FremoteName,
+		"remote":  "",
+		"recurse": true,
+	})
+	require.Len(t, items, 3)
+	assert.Equal(t, "subdir/b", items[2].Path)
+	assert.Equal(t, "b", items[2].Name)
+	assert.Equal(t, int64(2), items[2].Size)
+
+	items = list(rc.Params{
+		"fs":     r.FremoteName,
+		"remote": "subdir",
+	})
+	require.Len(t, items, 1)
+	assert.Equal(t, "subdir/b", items[0].Path)
+
+	// bad parameters
+	_, err := call.Fn(context.Background(), rc.Params{
+		"fs": r.FremoteName,
+	})
+	assert.True(t, rc.IsErrParamNotFound(err))
+	_, err = call.Fn(context.Background(), rc.Params{
+		"fs":      r.FremoteName,
+		"remote":  "",
+		"recurse": "potato",
+	})
+	assert.True(t, rc.IsErrParamInvalid(err))
+}
+
+// operations/size
+func TestRcSize(t *testing.T) {
+	r, call := rcNewRun(t, "operations/size")
+	defer r.Finalise()
+	file1 := r.WriteObject("small", "1234567890", t2)
+	file2 := r.WriteObject("subdir/medium", "------------------------------------------------------------", t1)
+	fstest.CheckItems(t, r.Fremote, file1, file2)
+
+	out, err := call.Fn(context.Background(), rc.Params{
+		"fs": r.FremoteName,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, rc.Params{
+		"count": int64(2),
+		"bytes": int64(70),
+	}, out)
+}
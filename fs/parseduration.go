@@ -1,6 +1,7 @@
 package fs
 
 import (
+	"fmt"
 	"strconv"
 	"strings"
 	"time"
@@ -51,6 +52,12 @@ func ParseDuration(age string) (time.Duration, error) {
 		return time.Duration(DurationOff), nil
 	}
 
+	// Attempt to parse as a time.Duration first
+	d, err := time.ParseDuration(age)
+	if err == nil {
+		return d, nil
+	}
+
 	for _, ageSuffix := range ageSuffixes {
 		if strings.HasSuffix(age, ageSuffix.Suffix) {
 			numberString := age[:len(age)-len(ageSuffix.Suffix)]
@@ -77,6 +84,15 @@ func (d *Duration) Set(s string) error {
 	return nil
 }
 
+// Scan implements the fmt.Scanner interface
+func (d *Duration) Scan(s fmt.ScanState, ch rune) error {
+	token, err := s.Token(true, nil)
+	if err != nil {
+		return err
+	}
+	return d.Set(string(token))
+}
+
 // Type of the value
 func (d Duration) Type() string {
 	return "duration"
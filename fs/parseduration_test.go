@@ -32,6 +32,7 @@ func TestParseDuration(t *testing.T) {
 		{"-1s", -time.Second, false},
 		{"1.s", time.Second, false},
 		{"1x", 0, true},
+		{"1h2m3s", time.Hour + 2*time.Minute + 3*time.Second, false},
 		{"off", time.Duration(DurationOff), false},
 	} {
 		duration, err := ParseDuration(test.in)
@@ -0,0 +1,156 @@
+// Cache the Fs used by the remote control calls
+
+package rc
+
+import (
+	"path"
+	"sync"
+	"time"
+
+	"github.com/ncw/rclone/fs"
+	"github.com/pkg/errors"
+)
+
+// fsCacheExpireDuration is how long an Fs which hasn't been used is
+// kept in the cache
+var fsCacheExpireDuration = 5 * time.Minute
+
+// cacheEntry is an Fs in the cache
+type cacheEntry struct {
+	f        fs.Fs
+	fileName string    // name of the file if the Fs string pointed to one
+	lastUsed time.Time // when the Fs was last asked for
+}
+
+var (
+	fsCacheMu sync.Mutex
+	fsCache   = make(map[string]*cacheEntry) // by the string used to make them
+)
+
+// expireFsCache removes the Fs which haven't been used recently
+//
+// Call with fsCacheMu held
+func expireFsCache(now time.Time) {
+	for fsString, entry := range fsCache {
+		if now.Sub(entry.lastUsed) > fsCacheExpireDuration {
+			delete(fsCache, fsString)
+		}
+	}
+}
+
+// getFs gets the Fs for fsString from the cache or makes it with
+// fs.NewFs
+//
+// If fsString points to a file then the Fs is for the directory the
+// file is in and fileName is the name of the file.
+func getFs(fsString string) (f fs.Fs, fileName string, err error) {
+	now := time.Now()
+	fsCacheMu.Lock()
+	expireFsCache(now)
+	entry := fsCache[fsString]
+	if entry != nil {
+		entry.lastUsed = now
+	}
+	fsCacheMu.Unlock()
+	if entry != nil {
+		return entry.f, entry.fileName, nil
+	}
+
+	// Make the Fs without the lock held as it may take a while
+	f, err = fs.NewFs(fsString)
+	switch err {
+	case fs.ErrorIsFile:
+		_, _, fsPath, parseErr := fs.ParseRemote(fsString)
+		if parseErr != nil {
+			return nil, "", parseErr
+		}
+		fileName = path.Base(fsPath)
+	case nil:
+	default:
+		return nil, "", err
+	}
+
+	fsCacheMu.Lock()
+	defer fsCacheMu.Unlock()
+	// Use the Fs made by another call in the meantime if there is one
+	if entry := fsCache[fsString]; entry != nil {
+		entry.lastUsed = now
+		return entry.f, entry.fileName, nil
+	}
+	fsCache[fsString] = &cacheEntry{
+		f:        f,
+		fileName: fileName,
+		lastUsed: now,
+	}
+	return f, fileName, nil
+}
+
+// GetFsNamedFile gets an fs.Fs named fsName from the params which
+// may point to a file.
+//
+// If it points to a file then the Fs is for the directory the file is
+// in and fileName is the name of the file, as the commands do for
+// their source.
+//
+// The Fs is made with fs.NewFs the first time it is used and reused
+// after that so the listings and connections are kept between calls.
+// It is forgotten once it hasn't been used for a while.
+func GetFsNamedFile(in Params, fsName string) (f fs.Fs, fileName string, err error) {
+	fsString, err := in.GetString(fsName)
+	if err != nil {
+		return nil, "", err
+	}
+	return getFs(fsString)
+}
+
+// GetFsNamed gets an fs.Fs named fsName from the params which must
+// point to a directory
+func GetFsNamed(in Params, fsName string) (f fs.Fs, err error) {
+	f, fileName, err := GetFsNamedFile(in, fsName)
+	if err != nil {
+		return nil, err
+	}
+	if fileName != "" {
+		return nil, ErrParamInvalid{errors.Errorf("%s %q is a file not a directory", fsName, in[fsName])}
+	}
+	return f, nil
+}
+
+// GetFs gets an fs.Fs named "fs" from the params
+func GetFs(in Params) (f fs.Fs, err error) {
+	return GetFsNamed(in, "fs")
+}
+
+// GetFsFile gets an fs.Fs named "fs" from the params which may point
+// to a file - see GetFsNamedFile
+func GetFsFile(in Params) (f fs.Fs, fileName string, err error) {
+	return GetFsNamedFile(in, "fs")
+}
+
+// GetFsAndRemoteNamed gets the fsName parameter from in, makes a
+// remote or fetches it from the cache then gets the remoteName
+// parameter from in too.
+//
+// If the fsName parameter points to a file then remote is relative
+// to it.
+func GetFsAndRemoteNamed(in Params, fsName, remoteName string) (f fs.Fs, remote string, err error) {
+	remote, err = in.GetString(remoteName)
+	if err != nil {
+		return nil, "", err
+	}
+	f, fileName, err := GetFsNamedFile(in, fsName)
+	if err != nil {
+		return nil, "", err
+	}
+	if fileName != "" {
+		remote = path.Join(fileName, remote)
+	}
+	return f, remote, nil
+}
+
+// GetFsAndRemote gets the "fs" parameter from in, makes a remote or
+// fetches it from the cache then gets the "remote" parameter from in
+// too.
+func GetFsAndRemote(in Params) (f fs.Fs, remote string, err error) {
+	return GetFsAndRemoteNamed(in, "fs", "remote")
+}
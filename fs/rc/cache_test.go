@@ -0,0 +1,72 @@
+package rc
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	_ "github.com/ncw/rclone/backend/local"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetFsFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rclone-rc-cache")
+	require.NoError(t, err)
+	defer func() {
+		_ = os.RemoveAll(dir)
+	}()
+	file := filepath.Join(dir, "file.txt")
+	require.NoError(t, ioutil.WriteFile(file, []byte("hello"), 0600))
+
+	// A directory
+	in := Params{"fs": dir, "remote": "file.txt"}
+	f, fileName, err := GetFsFile(in)
+	require.NoError(t, err)
+	assert.Equal(t, "", fileName)
+	f2, err := GetFs(in)
+	require.NoError(t, err)
+	assert.True(t, f == f2, "Fs not cached")
+	_, remote, err := GetFsAndRemote(in)
+	require.NoError(t, err)
+	assert.Equal(t, "file.txt", remote)
+
+	// A file
+	in = Params{"fs": file, "remote": ""}
+	f, fileName, err = GetFsFile(in)
+	require.NoError(t, err)
+	assert.Equal(t, "file.txt", fileName)
+	_, err = GetFs(in)
+	require.Error(t, err)
+	assert.True(t, IsErrParamInvalid(err))
+	_, remote, err = GetFsAndRemote(in)
+	require.NoError(t, err)
+	assert.Equal(t, "file.txt", remote)
+	o, err := f.NewObject(context.Background(), remote)
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), o.Size())
+}
+
+func TestFsCacheExpire(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rclone-rc-cache")
+	require.NoError(t, err)
+	defer func() {
+		_ = os.RemoveAll(dir)
+	}()
+
+	f, _, err := getFs(dir)
+	require.NoError(t, err)
+	fsCacheMu.Lock()
+	entry := fsCache[dir]
+	require.NotNil(t, entry)
+	entry.lastUsed = time.Now().Add(-2 * fsCacheExpireDuration)
+	fsCacheMu.Unlock()
+
+	// An expired Fs is made again
+	f2, _, err := getFs(dir)
+	require.NoError(t, err)
+	assert.False(t, f == f2, "Fs not expired")
+}
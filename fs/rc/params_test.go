@@ -0,0 +1,101 @@
+package rc
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParamsGet(t *testing.T) {
+	in := Params{
+		"ok": 1,
+	}
+	v1, e1 := in.Get("ok")
+	assert.NoError(t, e1)
+	assert.Equal(t, 1, v1)
+	v2, e2 := in.Get("notOK")
+	assert.Error(t, e2)
+	assert.Equal(t, nil, v2)
+	assert.Equal(t, ErrParamNotFound("notOK"), e2)
+	assert.True(t, IsErrParamNotFound(e2))
+	assert.False(t, NotErrParamNotFound(e2))
+	assert.False(t, NotErrParamNotFound(nil))
+}
+
+func TestParamsGetString(t *testing.T) {
+	in := Params{
+		"string":    "one",
+		"notString": 17,
+	}
+	v1, e1 := in.GetString("string")
+	assert.NoError(t, e1)
+	assert.Equal(t, "one", v1)
+	v2, e2 := in.GetString("notOK")
+	assert.True(t, IsErrParamNotFound(e2))
+	assert.Equal(t, "", v2)
+	v3, e3 := in.GetString("notString")
+	assert.True(t, IsErrParamInvalid(e3))
+	assert.True(t, NotErrParamNotFound(e3))
+	assert.Equal(t, "", v3)
+}
+
+func TestParamsGetInt64(t *testing.T) {
+	for _, test := range []struct {
+		value     interface{}
+		result    int64
+		errString string
+	}{
+		{"123", 123, ""},
+		{"123x", 0, "couldn't parse"},
+		{int(12), 12, ""},
+		{int64(13), 13, ""},
+		{float64(14), 14, ""},
+		{float64(14.5), 0, "isn't an integer"},
+		{json.Number("15"), 15, ""},
+		{true, 0, "expecting int64"},
+	} {
+		in := Params{
+			"key": test.value,
+		}
+		v1, e1 := in.GetInt64("key")
+		if test.errString == "" {
+			assert.NoError(t, e1, test.value)
+		} else {
+			assert.Contains(t, e1.Error(), test.errString, test.value)
+			assert.True(t, IsErrParamInvalid(e1), test.value)
+		}
+		assert.Equal(t, test.result, v1, test.value)
+	}
+	_, e2 := Params{}.GetInt64("notOK")
+	assert.True(t, IsErrParamNotFound(e2))
+}
+
+func TestParamsGetBool(t *testing.T) {
+	for _, test := range []struct {
+		value     interface{}
+		result    bool
+		errString string
+	}{
+		{true, true, ""},
+		{false, false, ""},
+		{"true", true, ""},
+		{"false", false, ""},
+		{"fasle", false, "couldn't parse"},
+		{int(12), false, "expecting bool"},
+	} {
+		in := Params{
+			"key": test.value,
+		}
+		v1, e1 := in.GetBool("key")
+		if test.errString == "" {
+			assert.NoError(t, e1, test.value)
+		} else {
+			assert.Contains(t, e1.Error(), test.errString, test.value)
+			assert.True(t, IsErrParamInvalid(e1), test.value)
+		}
+		assert.Equal(t, test.result, v1, test.value)
+	}
+	_, e2 := Params{}.GetBool("notOK")
+	assert.True(t, IsErrParamNotFound(e2))
+}
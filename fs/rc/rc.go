@@ -10,11 +10,13 @@ package rc
 import (
 	"encoding/json"
 	"io"
+	"mime"
 	"net/http"
 	"strings"
 
 	"github.com/ncw/rclone/cmd/serve/httplib"
 	"github.com/ncw/rclone/fs"
+	"github.com/ncw/rclone/lib/metrics"
 	"github.com/pkg/errors"
 )
 
@@ -22,12 +24,14 @@ import (
 type Options struct {
 	HTTPOptions httplib.Options
 	Enabled     bool
+	NoAuth      bool // set to allow calls which need auth without it
 }
 
 // DefaultOpt is the default values used for Options
 var DefaultOpt = Options{
 	HTTPOptions: httplib.DefaultOpt,
 	Enabled:     false,
+	NoAuth:      false,
 }
 
 func init() {
@@ -44,6 +48,7 @@ func Start(opt *Options) {
 
 // server contains everything to run the server
 type server struct {
+	opt *Options
 	srv *httplib.Server
 }
 
@@ -51,9 +56,11 @@ func newServer(opt *Options) *server {
 	// Serve on the DefaultServeMux so can have global registrations appear
 	mux := http.DefaultServeMux
 	s := &server{
+		opt: opt,
 		srv: httplib.NewServer(mux, &opt.HTTPOptions),
 	}
 	mux.HandleFunc("/", s.handler)
+	mux.Handle("/metrics", metrics.Handler())
 	return s
 }
 
@@ -67,6 +74,11 @@ func (s *server) serve() {
 	s.srv.Wait()
 }
 
+// authConfigured returns whether the server checks the user
+func (s *server) authConfigured() bool {
+	return s.opt.HTTPOptions.HtPasswd != "" || s.opt.HTTPOptions.BasicUser != ""
+}
+
 // WriteJSON writes JSON in out to w
 func WriteJSON(w io.Writer, out Params) error {
 	enc := json.NewEncoder(w)
@@ -104,6 +116,21 @@ func (s *server) handler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Calls which change things are only allowed with JSON input
+	// and authentication so they can't be made by cross site
+	// requests from a browser
+	contentType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if call.AuthRequired {
+		if !s.authConfigured() && !s.opt.NoAuth {
+			writeError(errors.Errorf("authentication must be set up on the rc server to use %q or the --rc-no-auth flag must be in use", path), http.StatusForbidden)
+			return
+		}
+		if contentType != "application/json" {
+			writeError(errors.Errorf("content type %q not allowed for %q - application/json required", contentType, path), http.StatusUnsupportedMediaType)
+			return
+		}
+	}
+
 	// Parse the POST and URL parameters into r.Form
 	err := r.ParseForm()
 	if err != nil {
@@ -120,7 +147,7 @@ func (s *server) handler(w http.ResponseWriter, r *http.Request) {
 	fs.Debugf(nil, "form = %+v", r.Form)
 
 	// Parse a JSON blob from the input
-	if r.Header.Get("Content-Type") == "application/json" {
+	if contentType == "application/json" {
 		err := json.NewDecoder(r.Body).Decode(&in)
 		if err != nil {
 			writeError(errors.Wrap(err, "failed to read input JSON"), http.StatusBadRequest)
@@ -129,12 +156,20 @@ func (s *server) handler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	fs.Debugf(nil, "rc: %q: with parameters %+v", path, in)
-	out, err := call.Fn(in)
+	out, err := call.Fn(r.Context(), in)
 	if err != nil {
-		writeError(errors.Wrap(err, "remote control command failed"), http.StatusInternalServerError)
+		status := http.StatusInternalServerError
+		if IsErrParamNotFound(err) || IsErrParamInvalid(err) {
+			status = http.StatusBadRequest
+		}
+		writeError(errors.Wrap(err, "remote control command failed"), status)
 		return
 	}
 
+	if out == nil {
+		out = make(Params)
+	}
+
 	fs.Debugf(nil, "rc: %q: reply %+v: %v", path, out, err)
 	err = WriteJSON(w, out)
 	if err != nil {
@@ -0,0 +1,48 @@
+package rc
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func init() {
+	Add(Call{
+		Path:         "rc/test-auth",
+		Fn:           rcNoop,
+		Title:        "Test call which requires auth",
+		AuthRequired: true,
+	})
+}
+
+func TestHandlerAuthRequired(t *testing.T) {
+	for _, test := range []struct {
+		name        string
+		user        string
+		noAuth      bool
+		path        string
+		contentType string
+		body        string
+		wantStatus  int
+	}{
+		{name: "no auth call", path: "rc/noop", contentType: "application/x-www-form-urlencoded", body: "a=1", wantStatus: http.StatusOK},
+		{name: "no auth configured", path: "rc/test-auth", contentType: "application/json", body: `{"a":1}`, wantStatus: http.StatusForbidden},
+		{name: "form with auth", user: "user", path: "rc/test-auth", contentType: "application/x-www-form-urlencoded", body: "a=1", wantStatus: http.StatusUnsupportedMediaType},
+		{name: "text with no-auth", noAuth: true, path: "rc/test-auth", contentType: "text/plain", body: `{"a":1}`, wantStatus: http.StatusUnsupportedMediaType},
+		{name: "JSON with auth", user: "user", path: "rc/test-auth", contentType: "application/json; charset=utf-8", body: `{"a":1}`, wantStatus: http.StatusOK},
+		{name: "JSON with no-auth", noAuth: true, path: "rc/test-auth", contentType: "application/json", body: `{"a":1}`, wantStatus: http.StatusOK},
+	} {
+		opt := DefaultOpt
+		opt.HTTPOptions.BasicUser = test.user
+		opt.NoAuth = test.noAuth
+		s := &server{opt: &opt}
+		r := httptest.NewRequest("POST", "/"+test.path, strings.NewReader(test.body))
+		r.Header.Set("Content-Type", test.contentType)
+		w := httptest.NewRecorder()
+		s.handler(w, r)
+		assert.Equal(t, test.wantStatus, w.Code, test.name)
+	}
+}
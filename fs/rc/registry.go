@@ -3,6 +3,7 @@
 package rc
 
 import (
+	"context"
 	"strings"
 	"sync"
 
@@ -13,15 +14,18 @@ import (
 type Params map[string]interface{}
 
 // Func defines a type for a remote control function
-type Func func(in Params) (out Params, err error)
+//
+// ctx is cancelled if the client making the call goes away.
+type Func func(ctx context.Context, in Params) (out Params, err error)
 
 // Call defines info about a remote control function and is used in
 // the Add function to create new entry points.
 type Call struct {
-	Path  string // path to activate this RC
-	Fn    Func   `json:"-"` // function to call
-	Title string // help for the function
-	Help  string // multi-line markdown formatted help
+	Path         string // path to activate this RC
+	Fn           Func   `json:"-"` // function to call
+	Title        string // help for the function
+	Help         string // multi-line markdown formatted help
+	AuthRequired bool   // if set then this call requires authorisation to be set
 }
 
 // Registry holds the list of all the registered remote control functions
@@ -71,3 +75,9 @@ var registry = NewRegistry()
 func Add(call Call) {
 	registry.add(call)
 }
+
+// Find a Call in the global registry by path or return nil if not
+// found
+func Find(path string) *Call {
+	return registry.get(path)
+}
@@ -0,0 +1,264 @@
+package sync
+
+import (
+	"container/heap"
+	"context"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/ncw/rclone/fs"
+	"github.com/pkg/errors"
+)
+
+// lessFn is the comparison function used to order the pipe
+type lessFn func(a, b fs.ObjectPair) bool
+
+// pipe provides an unbounded channel like experience for passing
+// ObjectPairs between the parts of the sync.
+//
+// If a less function is set then Get returns the pairs in that
+// order rather than the order they were Put.
+type pipe struct {
+	mu        sync.Mutex
+	c         chan struct{} // one token per item in the queue
+	queue     []fs.ObjectPair
+	closed    bool
+	totalSize int64
+	stats     func(items int, totalSize int64)
+	less      lessFn
+	fraction  int // percentage of Get callers which take from the max end or -1
+}
+
+// newPipe makes a new pipe ordered by orderBy (see --order-by).
+//
+// stats is called with the number of items and their total size
+// whenever the pipe changes and may be nil.
+//
+// maxBacklog is the maximum number of items which can be queued
+// before Put blocks, or < 0 for no limit.
+func newPipe(orderBy string, stats func(items int, totalSize int64), maxBacklog int) (*pipe, error) {
+	if maxBacklog < 0 {
+		// chan struct{} doesn't allocate space for its buffer
+		// so this is cheap
+		maxBacklog = math.MaxInt32
+	}
+	less, fraction, err := newLess(orderBy)
+	if err != nil {
+		return nil, err
+	}
+	p := &pipe{
+		c:        make(chan struct{}, maxBacklog),
+		stats:    stats,
+		less:     less,
+		fraction: fraction,
+	}
+	if p.less != nil {
+		heap.Init(p)
+	}
+	return p, nil
+}
+
+// Len satisfy heap.Interface - must be called with lock held
+func (p *pipe) Len() int {
+	return len(p.queue)
+}
+
+// Less satisfy heap.Interface - must be called with lock held
+func (p *pipe) Less(i, j int) bool {
+	return p.less(p.queue[i], p.queue[j])
+}
+
+// Swap satisfy heap.Interface - must be called with lock held
+func (p *pipe) Swap(i, j int) {
+	p.queue[i], p.queue[j] = p.queue[j], p.queue[i]
+}
+
+// Push satisfy heap.Interface - must be called with lock held
+func (p *pipe) Push(item interface{}) {
+	p.queue = append(p.queue, item.(fs.ObjectPair))
+}
+
+// Pop satisfy heap.Interface - must be called with lock held
+func (p *pipe) Pop() interface{} {
+	old := p.queue
+	n := len(old)
+	item := old[n-1]
+	old[n-1] = fs.ObjectPair{} // avoid memory leak
+	p.queue = old[0 : n-1]
+	return item
+}
+
+// updateStats reports the state of the pipe - must be called with
+// lock held
+func (p *pipe) updateStats(pair fs.ObjectPair, sign int64) {
+	size := pair.Src.Size()
+	if size > 0 {
+		p.totalSize += sign * size
+	}
+	if p.totalSize < 0 {
+		p.totalSize = 0
+	}
+	if p.stats != nil {
+		p.stats(len(p.queue), p.totalSize)
+	}
+}
+
+// Put a pair into the pipe
+//
+// It returns ok = false if the context was cancelled.
+//
+// It will panic if you call it after Close()
+func (p *pipe) Put(ctx context.Context, pair fs.ObjectPair) (ok bool) {
+	if ctx.Err() != nil {
+		return false
+	}
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		panic("sync: Put called on closed pipe")
+	}
+	if p.less == nil {
+		p.queue = append(p.queue, pair)
+	} else {
+		heap.Push(p, pair)
+	}
+	p.updateStats(pair, 1)
+	p.mu.Unlock()
+	select {
+	case <-ctx.Done():
+		return false
+	case p.c <- struct{}{}:
+	}
+	return true
+}
+
+// GetMax gets a pair from the pipe
+//
+// If fraction is >= the mixed fraction of the pipe then it takes the
+// pair from the max end of the ordering rather than the min end.
+//
+// It returns ok = false if the context was cancelled or Close() has
+// been called and the pipe is empty.
+func (p *pipe) GetMax(ctx context.Context, fraction int) (pair fs.ObjectPair, ok bool) {
+	if ctx.Err() != nil {
+		return pair, false
+	}
+	select {
+	case <-ctx.Done():
+		return pair, false
+	case _, ok = <-p.c:
+		if !ok {
+			return pair, false
+		}
+	}
+	p.mu.Lock()
+	if p.less == nil {
+		pair = p.queue[0]
+		p.queue[0] = fs.ObjectPair{} // avoid memory leak
+		p.queue = p.queue[1:]
+	} else if p.fraction < 0 || fraction < p.fraction {
+		pair = heap.Pop(p).(fs.ObjectPair)
+	} else {
+		pair = p.popMax()
+	}
+	p.updateStats(pair, -1)
+	p.mu.Unlock()
+	return pair, true
+}
+
+// Get a pair from the pipe
+//
+// It returns ok = false if the context was cancelled or Close() has
+// been called and the pipe is empty.
+func (p *pipe) Get(ctx context.Context) (pair fs.ObjectPair, ok bool) {
+	return p.GetMax(ctx, -1)
+}
+
+// popMax removes the maximum item from the heap - must be called
+// with the lock held and the queue non empty
+func (p *pipe) popMax() fs.ObjectPair {
+	// The maximum is one of the leaves, which are the second
+	// half of the queue
+	n := len(p.queue)
+	max := n / 2
+	for i := max + 1; i < n; i++ {
+		if p.Less(max, i) {
+			max = i
+		}
+	}
+	return heap.Remove(p, max).(fs.ObjectPair)
+}
+
+// Stats reads the number of items in the queue and their total size
+func (p *pipe) Stats() (items int, totalSize int64) {
+	p.mu.Lock()
+	items, totalSize = len(p.queue), p.totalSize
+	p.mu.Unlock()
+	return items, totalSize
+}
+
+// Close the pipe
+//
+// Writes to a closed pipe will panic as will double closing a pipe
+func (p *pipe) Close() {
+	p.mu.Lock()
+	close(p.c)
+	p.closed = true
+	p.mu.Unlock()
+}
+
+// newLess parses orderBy (see --order-by) into a less function and
+// the mixed fraction which is -1 if not mixed.
+func newLess(orderBy string) (less lessFn, fraction int, err error) {
+	fraction = -1
+	if orderBy == "" {
+		return nil, fraction, nil
+	}
+	parts := strings.Split(strings.ToLower(orderBy), ",")
+	switch parts[0] {
+	case "name":
+		less = func(a, b fs.ObjectPair) bool {
+			return a.Src.Remote() < b.Src.Remote()
+		}
+	case "size":
+		less = func(a, b fs.ObjectPair) bool {
+			return a.Src.Size() < b.Src.Size()
+		}
+	case "modtime":
+		less = func(a, b fs.ObjectPair) bool {
+			return a.Src.ModTime().Before(b.Src.ModTime())
+		}
+	default:
+		return nil, fraction, errors.Errorf("unknown --order-by comparison %q", parts[0])
+	}
+	descending := false
+	if len(parts) > 1 {
+		switch parts[1] {
+		case "ascending", "asc":
+		case "descending", "desc":
+			descending = true
+		case "mixed":
+			fraction = 50
+			if len(parts) > 2 {
+				fraction, err = strconv.Atoi(parts[2])
+				if err != nil || fraction < 0 || fraction > 100 {
+					return nil, fraction, errors.Errorf("bad mixed fraction --order-by %q", parts[2])
+				}
+			}
+		default:
+			return nil, fraction, errors.Errorf("unknown --order-by sort direction %q", parts[1])
+		}
+	}
+	if (fraction >= 0 && len(parts) > 3) || (fraction < 0 && len(parts) > 2) {
+		return nil, fraction, errors.Errorf("bad --order-by string %q", orderBy)
+	}
+	if descending {
+		oldLess := less
+		less = func(a, b fs.ObjectPair) bool {
+			return oldLess(b, a)
+		}
+	}
+	return less, fraction, nil
+}
@@ -0,0 +1,289 @@
+package sync
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ncw/rclone/fs"
+	"github.com/ncw/rclone/fstest/mockobject"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// sizedObject is a mock object with a size and modification time
+type sizedObject struct {
+	mockobject.Object
+	size    int64
+	modTime time.Time
+}
+
+func (o sizedObject) Size() int64        { return o.size }
+func (o sizedObject) ModTime() time.Time { return o.modTime }
+
+// newPair makes an ObjectPair with remote and size for testing
+func newPair(remote string, size int64) fs.ObjectPair {
+	o := sizedObject{
+		Object:  mockobject.Object(remote),
+		size:    size,
+		modTime: time.Unix(size, 0),
+	}
+	return fs.ObjectPair{Src: o, Dst: o}
+}
+
+func TestPipe(t *testing.T) {
+	var queueLength int
+	var queueSize int64
+	stats := func(n int, size int64) {
+		queueLength, queueSize = n, size
+	}
+
+	// Make a new pipe
+	p, err := newPipe("", stats, 10)
+	require.NoError(t, err)
+
+	checkStats := func(expectedN int, expectedSize int64) {
+		n, size := p.Stats()
+		assert.Equal(t, expectedN, n)
+		assert.Equal(t, expectedSize, size)
+		assert.Equal(t, expectedN, queueLength)
+		assert.Equal(t, expectedSize, queueSize)
+	}
+
+	checkStats(0, 0)
+
+	ctx := context.Background()
+
+	pair1 := newPair("pair1", 1)
+	pair2 := newPair("pair2", 2)
+
+	// Put an object
+	ok := p.Put(ctx, pair1)
+	assert.Equal(t, true, ok)
+	checkStats(1, 1)
+
+	// Put another object
+	ok = p.Put(ctx, pair2)
+	assert.Equal(t, true, ok)
+	checkStats(2, 3)
+
+	// Get them back in the order they were put
+	gotPair, ok := p.Get(ctx)
+	assert.Equal(t, true, ok)
+	assert.Equal(t, pair1, gotPair)
+	checkStats(1, 2)
+
+	gotPair, ok = p.Get(ctx)
+	assert.Equal(t, true, ok)
+	assert.Equal(t, pair2, gotPair)
+	checkStats(0, 0)
+
+	ctx2, cancel := context.WithCancel(ctx)
+
+	// Check put when context cancelled fails
+	cancel()
+	ok = p.Put(ctx2, pair1)
+	assert.Equal(t, false, ok)
+	checkStats(0, 0)
+
+	// Check get when context cancelled fails
+	gotPair, ok = p.Get(ctx2)
+	assert.Equal(t, false, ok)
+	checkStats(0, 0)
+
+	// Readers can read what is left after close
+	ok = p.Put(ctx, pair1)
+	assert.Equal(t, true, ok)
+	p.Close()
+	gotPair, ok = p.Get(ctx)
+	assert.Equal(t, true, ok)
+	assert.Equal(t, pair1, gotPair)
+
+	// Then reading from a closed pipe fails
+	_, ok = p.Get(ctx)
+	assert.Equal(t, false, ok)
+
+	// Check panic on write to closed pipe
+	assert.Panics(t, func() { p.Put(ctx, pair1) })
+}
+
+// TestPipeConcurrent runs concurrent Get and Put to flush out any
+// race conditions and concurrency problems.
+func TestPipeConcurrent(t *testing.T) {
+	const (
+		N           = 1000
+		readWriters = 10
+	)
+
+	stats := func(n int, size int64) {}
+
+	// Make a new pipe
+	p, err := newPipe("", stats, 10)
+	require.NoError(t, err)
+
+	var wg sync.WaitGroup
+	pair1 := newPair("potato", 5)
+	ctx := context.Background()
+	var mu sync.Mutex
+	count := 0
+
+	for j := 0; j < readWriters; j++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < N; i++ {
+				// Read an object
+				pair2, ok := p.Get(ctx)
+				assert.Equal(t, pair1, pair2)
+				assert.Equal(t, true, ok)
+				mu.Lock()
+				count--
+				mu.Unlock()
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for i := 0; i < N; i++ {
+				// Put an object
+				ok := p.Put(ctx, pair1)
+				assert.Equal(t, true, ok)
+				mu.Lock()
+				count++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, 0, count)
+}
+
+func TestPipeOrderBy(t *testing.T) {
+	ctx := context.Background()
+	for _, test := range []struct {
+		orderBy  string
+		swapped1 bool
+		swapped2 bool
+		fraction int
+	}{
+		{"", false, true, -1},
+		{"size", false, false, -1},
+		{"name", true, true, -1},
+		{"modtime", false, false, -1},
+		{"size,ascending", false, false, -1},
+		{"name,asc", true, true, -1},
+		{"modtime,ascending", false, false, -1},
+		{"size,descending", true, true, -1},
+		{"name,desc", false, false, -1},
+		{"modtime,descending", true, true, -1},
+		{"size,mixed,50", false, false, 25},
+		{"size,mixed,51", true, true, 75},
+	} {
+		t.Run(test.orderBy, func(t *testing.T) {
+			p, err := newPipe(test.orderBy, nil, 10)
+			require.NoError(t, err)
+
+			ok := p.Put(ctx, newPair("b", 1))
+			assert.True(t, ok)
+			ok = p.Put(ctx, newPair("a", 2))
+			assert.True(t, ok)
+
+			readAndCheck := func(swapped bool) {
+				var readFirst, readSecond fs.ObjectPair
+				var ok1, ok2 bool
+				if test.fraction < 0 {
+					readFirst, ok1 = p.Get(ctx)
+					readSecond, ok2 = p.Get(ctx)
+				} else {
+					readFirst, ok1 = p.GetMax(ctx, test.fraction)
+					readSecond, ok2 = p.GetMax(ctx, test.fraction)
+				}
+				assert.True(t, ok1)
+				assert.True(t, ok2)
+				got := []string{readFirst.Src.Remote(), readSecond.Src.Remote()}
+				want := []string{"b", "a"}
+				if swapped {
+					want = []string{"a", "b"}
+				}
+				assert.Equal(t, want, got)
+			}
+
+			readAndCheck(test.swapped1)
+
+			// Now do it again in the opposite order
+			ok = p.Put(ctx, newPair("a", 2))
+			assert.True(t, ok)
+			ok = p.Put(ctx, newPair("b", 1))
+			assert.True(t, ok)
+
+			readAndCheck(test.swapped2)
+		})
+	}
+}
+
+func TestPipeMixed(t *testing.T) {
+	ctx := context.Background()
+	p, err := newPipe("size,mixed,25", nil, -1)
+	require.NoError(t, err)
+	assert.Equal(t, 25, p.fraction)
+
+	for _, size := range []int64{5, 3, 9, 1, 7, 2, 8, 4, 6} {
+		ok := p.Put(ctx, newPair("file", size))
+		require.True(t, ok)
+	}
+
+	// Callers below the fraction get the smallest and the others
+	// the largest
+	for _, test := range []struct {
+		fraction int
+		want     int64
+	}{
+		{0, 1},
+		{24, 2},
+		{25, 9},
+		{100, 8},
+		{0, 3},
+		{50, 7},
+		{50, 6},
+		{10, 4},
+		{99, 5},
+	} {
+		pair, ok := p.GetMax(ctx, test.fraction)
+		require.True(t, ok)
+		assert.Equal(t, test.want, pair.Src.Size())
+	}
+}
+
+func TestNewLess(t *testing.T) {
+	for _, test := range []struct {
+		orderBy      string
+		wantFraction int
+		wantNil      bool
+		wantErr      bool
+	}{
+		{"", -1, true, false},
+		{"size", -1, false, false},
+		{"name,ascending", -1, false, false},
+		{"modtime,desc", -1, false, false},
+		{"SIZE,Mixed", 50, false, false},
+		{"size,mixed,0", 0, false, false},
+		{"size,mixed,100", 100, false, false},
+		{"potato", -1, true, true},
+		{"size,potato", -1, true, true},
+		{"size,mixed,101", -1, true, true},
+		{"size,mixed,-1", -1, true, true},
+		{"size,mixed,potato", -1, true, true},
+		{"size,mixed,25,1", -1, true, true},
+		{"size,ascending,1", -1, true, true},
+	} {
+		less, fraction, err := newLess(test.orderBy)
+		if test.wantErr {
+			assert.Error(t, err, test.orderBy)
+			continue
+		}
+		require.NoError(t, err, test.orderBy)
+		assert.Equal(t, test.wantNil, less == nil, test.orderBy)
+		assert.Equal(t, test.wantFraction, fraction, test.orderBy)
+	}
+}
@@ -0,0 +1,543 @@
+// Record the actions a sync would take so they can be reviewed and
+// applied later
+
+package sync
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"path"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ncw/rclone/fs"
+	"github.com/ncw/rclone/fs/accounting"
+	"github.com/ncw/rclone/fs/fserrors"
+	"github.com/ncw/rclone/fs/hash"
+	"github.com/ncw/rclone/fs/operations"
+	"github.com/pkg/errors"
+)
+
+// The actions which can be in a Plan
+const (
+	PlanCopy      = "copy"       // copy a new file from the source
+	PlanUpdate    = "update"     // overwrite a destination file from the source
+	PlanMove      = "move"       // move a file from the source
+	PlanRename    = "rename"     // rename a destination file from OldPath
+	PlanRenameDir = "rename-dir" // rename a destination directory from OldPath
+	PlanDelete    = "delete"     // delete a destination file
+	PlanDeleteSrc = "delete-src" // delete a source file
+	PlanMkdir     = "mkdir"      // make a destination directory
+	PlanRmdir     = "rmdir"      // remove a destination directory if empty
+	PlanRmdirSrc  = "rmdir-src"  // remove a source directory if empty
+)
+
+// planVersion is the version of the plan file format
+const planVersion = 1
+
+// Plan is a record of the actions a sync, copy or move would take,
+// made with --plan-out.  It can be executed later with ApplyPlan.
+type Plan struct {
+	Version  int       // version of the plan format
+	Mode     string    // "sync", "copy" or "move"
+	Src      string    // the source remote
+	Dst      string    // the destination remote
+	HashType string    // the type of the Hash in each PlanObject or "" for none
+	Created  time.Time // when the plan was made
+	Actions  []PlanAction
+
+	mu       sync.Mutex
+	hashType hash.Type
+	newDirs  map[string]bool // source only directories, true if a mkdir has been recorded
+}
+
+// PlanAction is a single action in a Plan
+type PlanAction struct {
+	Action  string      // one of the Plan* constants
+	Path    string      // path of the file or directory acted on
+	OldPath string      `json:",omitempty"` // path renamed from for rename and rename-dir
+	Src     *PlanObject `json:",omitempty"` // the source file when planned
+	Dst     *PlanObject `json:",omitempty"` // the destination file when planned, for rename the one at OldPath
+}
+
+// PlanObject records the state of a file when the plan was made
+type PlanObject struct {
+	Size    int64
+	ModTime time.Time
+	Hash    string `json:",omitempty"`
+}
+
+// planRemotesKey is the context key for the planRemotes
+type planRemotesKey struct{}
+
+// planRemotes are the source and destination as the user gave them
+type planRemotes struct {
+	src, dst string
+}
+
+// WithPlanRemotes returns a copy of ctx which records srcRemote and
+// dstRemote as the strings the source and destination were made from.
+//
+// Any plan made with --plan-out using the returned context records
+// these rather than the name and root of the Fs so the plan keeps
+// any connection string parameters.  If the source is a file then
+// srcRemote should be the directory it is in.
+func WithPlanRemotes(ctx context.Context, srcRemote, dstRemote string) context.Context {
+	return context.WithValue(ctx, planRemotesKey{}, planRemotes{src: srcRemote, dst: dstRemote})
+}
+
+// fsString returns a string which fs.NewFs can use to make f again
+//
+// This is remote if set so it keeps any connection string
+// parameters, except for local paths which are made absolute so the
+// plan can be applied from any directory.
+func fsString(f fs.Fs, remote string) string {
+	if f.Name() == "local" {
+		return f.Root()
+	}
+	if remote != "" {
+		return remote
+	}
+	return f.Name() + ":" + f.Root()
+}
+
+// newPlan makes a new empty Plan for a mode operation from fsrc to fdst
+func newPlan(ctx context.Context, fdst, fsrc fs.Fs, mode string, hashType hash.Type) *Plan {
+	remotes, _ := ctx.Value(planRemotesKey{}).(planRemotes)
+	p := &Plan{
+		Version:  planVersion,
+		Mode:     mode,
+		Src:      fsString(fsrc, remotes.src),
+		Dst:      fsString(fdst, remotes.dst),
+		Created:  time.Now(),
+		Actions:  []PlanAction{},
+		hashType: hashType,
+		newDirs:  make(map[string]bool),
+	}
+	if hashType != hash.None {
+		p.HashType = hashType.String()
+	}
+	return p
+}
+
+// LoadPlan reads a plan written with --plan-out from the file name
+func LoadPlan(name string) (*Plan, error) {
+	data, err := ioutil.ReadFile(name)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read plan")
+	}
+	p := &Plan{}
+	err = json.Unmarshal(data, p)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decode plan")
+	}
+	if p.Version != planVersion {
+		return nil, errors.Errorf("unsupported plan version %d - expecting %d", p.Version, planVersion)
+	}
+	if p.HashType != "" {
+		err = p.hashType.Set(p.HashType)
+		if err != nil {
+			return nil, errors.Wrap(err, "bad hash type in plan")
+		}
+	}
+	return p, nil
+}
+
+// Save writes the plan to the file name
+func (p *Plan) Save(name string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	data, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return errors.Wrap(err, "failed to encode plan")
+	}
+	err = ioutil.WriteFile(name, append(data, '\n'), 0666)
+	if err != nil {
+		return errors.Wrap(err, "failed to write plan")
+	}
+	return nil
+}
+
+// planObject records the state of o, or returns nil if o is nil
+func (p *Plan) planObject(o fs.Object) *PlanObject {
+	if o == nil {
+		return nil
+	}
+	po := &PlanObject{
+		Size:    o.Size(),
+		ModTime: o.ModTime(),
+	}
+	if p.hashType != hash.None {
+		hashValue, err := o.Hash(p.hashType)
+		if err != nil {
+			fs.Debugf(o, "Failed to read hash for plan: %v", err)
+		} else {
+			po.Hash = hashValue
+		}
+	}
+	return po
+}
+
+// add records an action in the plan
+func (p *Plan) add(action PlanAction) {
+	p.mu.Lock()
+	p.Actions = append(p.Actions, action)
+	p.mu.Unlock()
+}
+
+// addNewDir records that dir is only in the source so will be
+// created on the destination if anything is transferred into it
+func (p *Plan) addNewDir(dir string) {
+	p.mu.Lock()
+	p.newDirs[dir] = false
+	p.mu.Unlock()
+}
+
+// addTransfer records action (copy, update or move) of src
+// overwriting dst which may be nil.  It records a mkdir first for any
+// new directories the file is transferred into.
+func (p *Plan) addTransfer(action string, src, dst fs.Object) {
+	transfer := PlanAction{
+		Action: action,
+		Path:   src.Remote(),
+		Src:    p.planObject(src),
+		Dst:    p.planObject(dst),
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	var dirs []string
+	for dir := path.Dir(src.Remote()); dir != "." && dir != "/"; dir = path.Dir(dir) {
+		if made, found := p.newDirs[dir]; found && !made {
+			p.newDirs[dir] = true
+			dirs = append(dirs, dir)
+		}
+	}
+	// make the parents first
+	for i := len(dirs) - 1; i >= 0; i-- {
+		p.Actions = append(p.Actions, PlanAction{Action: PlanMkdir, Path: dirs[i]})
+	}
+	p.Actions = append(p.Actions, transfer)
+}
+
+// addDelete records action (delete or delete-src) of o
+func (p *Plan) addDelete(action string, o fs.Object) {
+	planned := PlanAction{
+		Action: action,
+		Path:   o.Remote(),
+	}
+	if action == PlanDeleteSrc {
+		planned.Src = p.planObject(o)
+	} else {
+		planned.Dst = p.planObject(o)
+	}
+	p.add(planned)
+}
+
+// addRmdirs records action (rmdir or rmdir-src) of the directories
+// in entries, deepest first
+func (p *Plan) addRmdirs(action string, entries fs.DirEntries) {
+	entries = append(fs.DirEntries(nil), entries...)
+	sort.Sort(entries)
+	for i := len(entries) - 1; i >= 0; i-- {
+		if dir, ok := entries[i].(fs.Directory); ok {
+			p.add(PlanAction{Action: action, Path: dir.Remote()})
+		}
+	}
+}
+
+// planStep is a PlanAction with the objects it acts on
+type planStep struct {
+	*PlanAction
+	src fs.Object
+	dst fs.Object
+}
+
+// The phases the actions are applied in
+const (
+	planPhaseMkdir = iota
+	planPhaseRename
+	planPhaseTransfer
+	planPhaseDelete
+	planPhaseRmdir
+)
+
+// phase returns which phase the step is applied in
+func (step *planStep) phase() int {
+	switch step.Action {
+	case PlanMkdir:
+		return planPhaseMkdir
+	case PlanRename, PlanRenameDir:
+		return planPhaseRename
+	case PlanCopy, PlanUpdate, PlanMove:
+		return planPhaseTransfer
+	case PlanDelete, PlanDeleteSrc:
+		return planPhaseDelete
+	}
+	return planPhaseRmdir
+}
+
+// planSteps sorts the steps into the order they are applied
+type planSteps []*planStep
+
+func (ps planSteps) Len() int           { return len(ps) }
+func (ps planSteps) Swap(i, j int)      { ps[i], ps[j] = ps[j], ps[i] }
+func (ps planSteps) Less(i, j int) bool { return ps[i].phase() < ps[j].phase() }
+
+// newObject finds remote on f returning nil if it doesn't exist
+func newObject(ctx context.Context, f fs.Fs, remote string) (fs.Object, error) {
+	o, err := f.NewObject(ctx, remote)
+	if err == fs.ErrorObjectNotFound {
+		return nil, nil
+	}
+	return o, err
+}
+
+// checkObject checks o is as recorded in want.  If want is nil then
+// o should be nil too.
+func (p *Plan) checkObject(o fs.Object, want *PlanObject) error {
+	if want == nil {
+		if o != nil {
+			return errors.New("file exists but didn't when planned")
+		}
+		return nil
+	}
+	if o == nil {
+		return errors.New("file not found")
+	}
+	if o.Size() != want.Size {
+		return errors.Errorf("size changed from %d to %d", want.Size, o.Size())
+	}
+	// Compare the modification times within --modify-window as
+	// operations.Equal does
+	if fs.Config.ModifyWindow != fs.ModTimeNotSupported {
+		modTime := o.ModTime()
+		dt := modTime.Sub(want.ModTime)
+		if dt >= fs.Config.ModifyWindow || dt <= -fs.Config.ModifyWindow {
+			return errors.Errorf("modification time changed from %v to %v", want.ModTime, modTime)
+		}
+	}
+	if want.Hash != "" && p.hashType != hash.None {
+		hashValue, err := o.Hash(p.hashType)
+		if err != nil {
+			return errors.Wrap(err, "failed to read hash")
+		}
+		if hashValue != "" && hashValue != want.Hash {
+			return errors.Errorf("%v changed from %s to %s", p.hashType, want.Hash, hashValue)
+		}
+	}
+	return nil
+}
+
+// dirExists returns whether dir on f exists and has anything in it
+func dirExists(ctx context.Context, f fs.Fs, dir string) (bool, error) {
+	entries, err := f.List(ctx, dir)
+	if err == fs.ErrorDirNotFound {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return len(entries) > 0, nil
+}
+
+// resolve finds the objects step acts on and checks they are as they
+// were when the plan was made
+func (p *Plan) resolve(ctx context.Context, fdst, fsrc fs.Fs, step *planStep) (err error) {
+	switch step.Action {
+	case PlanCopy, PlanUpdate, PlanMove, PlanDeleteSrc:
+		step.src, err = newObject(ctx, fsrc, step.Path)
+		if err == nil {
+			err = p.checkObject(step.src, step.Src)
+		}
+		if err != nil || step.Action == PlanDeleteSrc {
+			return errors.Wrap(err, "source")
+		}
+		fallthrough
+	case PlanDelete:
+		step.dst, err = newObject(ctx, fdst, step.Path)
+		if err == nil {
+			err = p.checkObject(step.dst, step.Dst)
+		}
+		return errors.Wrap(err, "destination")
+	case PlanRename:
+		step.dst, err = newObject(ctx, fdst, step.OldPath)
+		if err == nil {
+			err = p.checkObject(step.dst, step.Dst)
+		}
+		if err != nil {
+			return errors.Wrapf(err, "destination %q", step.OldPath)
+		}
+		var existing fs.Object
+		existing, err = newObject(ctx, fdst, step.Path)
+		if err == nil {
+			err = p.checkObject(existing, nil)
+		}
+		return errors.Wrap(err, "destination")
+	case PlanRenameDir:
+		found, err := dirExists(ctx, fdst, step.OldPath)
+		if err == nil && !found {
+			err = errors.Errorf("directory %q not found", step.OldPath)
+		}
+		if err != nil {
+			return err
+		}
+		found, err = dirExists(ctx, fdst, step.Path)
+		if err == nil && found {
+			err = errors.New("directory exists but didn't when planned")
+		}
+		return err
+	case PlanMkdir, PlanRmdir, PlanRmdirSrc:
+		return nil
+	}
+	return errors.Errorf("unknown action %q", step.Action)
+}
+
+// apply does the action in step
+func (p *Plan) apply(ctx context.Context, fdst, fsrc fs.Fs, step *planStep) (err error) {
+	switch step.Action {
+	case PlanMkdir:
+		return operations.Mkdir(ctx, fdst, step.Path)
+	case PlanRenameDir:
+		doDirMove := fdst.Features().DirMove
+		if doDirMove == nil {
+			return errors.Errorf("can't rename directory %q as %v doesn't support it", step.OldPath, fdst)
+		}
+		if fs.Config.DryRun {
+			fs.Logf(fs.LogDirName(fdst, step.OldPath), "Not renaming directory to %q as --dry-run", step.Path)
+			return nil
+		}
+		err = doDirMove(ctx, fdst, step.OldPath, step.Path)
+		if err != nil {
+			fs.CountError(err)
+			fs.Errorf(fs.LogDirName(fdst, step.OldPath), "Failed to rename directory to %q: %v", step.Path, err)
+			return err
+		}
+		fs.Infof(fs.LogDirName(fdst, step.Path), "Renamed directory from %q", step.OldPath)
+	case PlanRename:
+		_, err = operations.Move(ctx, fdst, nil, step.Path, step.dst)
+	case PlanCopy, PlanUpdate, PlanMove:
+		accounting.Stats.Transferring(step.Path)
+		if step.Action == PlanMove {
+			_, err = operations.Move(ctx, fdst, step.dst, step.Path, step.src)
+		} else {
+			_, err = operations.Copy(ctx, fdst, step.dst, step.Path, step.src)
+		}
+		accounting.Stats.DoneTransferringError(step.Path, err)
+	case PlanDelete:
+		err = operations.DeleteFile(ctx, step.dst)
+	case PlanDeleteSrc:
+		err = operations.DeleteFile(ctx, step.src)
+	case PlanRmdir, PlanRmdirSrc:
+		f := fdst
+		if step.Action == PlanRmdirSrc {
+			f = fsrc
+		}
+		// TryRmdir only deletes empty directories
+		rmdirErr := operations.TryRmdir(ctx, f, step.Path)
+		if rmdirErr != nil {
+			fs.Debugf(fs.LogDirName(f, step.Path), "Failed to Rmdir: %v", rmdirErr)
+		}
+	}
+	return err
+}
+
+// runSteps calls fn on each of steps using workers go routines
+func runSteps(ctx context.Context, steps []*planStep, workers int, fn func(step *planStep)) {
+	if workers < 1 {
+		workers = 1
+	}
+	in := make(chan *planStep, workers)
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go func() {
+			defer wg.Done()
+			for step := range in {
+				if ctx.Err() == nil {
+					fn(step)
+				}
+			}
+		}()
+	}
+	for _, step := range steps {
+		in <- step
+	}
+	close(in)
+	wg.Wait()
+}
+
+// ApplyPlan does the actions in plan, which was made with --plan-out
+// for fsrc and fdst.
+//
+// Before doing anything it checks all the files the plan acts on are
+// the same as when the plan was made and refuses to run if they
+// aren't.
+//
+// The actions are done in this order: mkdir, renames, transfers,
+// deletes then rmdir.  If there were any errors then the deletes and
+// rmdirs aren't done unless --ignore-errors is set.
+func ApplyPlan(ctx context.Context, fdst, fsrc fs.Fs, plan *Plan) error {
+	steps := make(planSteps, len(plan.Actions))
+	for i := range plan.Actions {
+		steps[i] = &planStep{PlanAction: &plan.Actions[i]}
+	}
+
+	// Check everything is as planned before doing anything
+	var mu sync.Mutex
+	stale := 0
+	runSteps(ctx, steps, fs.Config.Checkers, func(step *planStep) {
+		accounting.Stats.Checking(step.Path)
+		err := plan.resolve(ctx, fdst, fsrc, step)
+		accounting.Stats.DoneChecking(step.Path)
+		if err != nil {
+			fs.Errorf(step.Path, "Can't %s: %v", step.Action, err)
+			mu.Lock()
+			stale++
+			mu.Unlock()
+		}
+	})
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if stale > 0 {
+		return fserrors.FatalError(errors.Errorf("not applying plan as %d of its %d actions no longer match the source or destination - make a new plan", stale, len(steps)))
+	}
+
+	// Then do the actions a phase at a time
+	sort.Stable(steps)
+	var lastErr error
+	for start := 0; start < len(steps); {
+		phase := steps[start].phase()
+		end := start
+		for end < len(steps) && steps[end].phase() == phase {
+			end++
+		}
+		if phase >= planPhaseDelete && lastErr != nil && !fs.Config.IgnoreErrors {
+			fs.Errorf(fdst, "%v", fs.ErrorNotDeleting)
+			break
+		}
+		workers := fs.Config.Checkers
+		switch phase {
+		case planPhaseTransfer:
+			workers = fs.Config.Transfers
+		case planPhaseMkdir, planPhaseRmdir:
+			// these are in parent/child order
+			workers = 1
+		}
+		runSteps(ctx, steps[start:end], workers, func(step *planStep) {
+			err := plan.apply(ctx, fdst, fsrc, step)
+			if err != nil {
+				mu.Lock()
+				lastErr = err
+				mu.Unlock()
+			}
+		})
+		start = end
+	}
+	if lastErr == nil {
+		lastErr = ctx.Err()
+	}
+	return lastErr
+}
@@ -0,0 +1,266 @@
+package sync
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ncw/rclone/fs"
+	"github.com/ncw/rclone/fs/fserrors"
+	"github.com/ncw/rclone/fs/hash"
+	"github.com/ncw/rclone/fs/operations"
+	"github.com/ncw/rclone/fstest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setPlanOut sets --plan-out to a temporary file returning its name
+// and a function to undo it
+func setPlanOut(t *testing.T) (string, func()) {
+	dir, err := ioutil.TempDir("", "rclone-plan")
+	require.NoError(t, err)
+	fs.Config.PlanOut = filepath.Join(dir, "plan.json")
+	return fs.Config.PlanOut, func() {
+		fs.Config.PlanOut = ""
+		_ = os.RemoveAll(dir)
+	}
+}
+
+// planActions counts the actions in plan by type
+func planActions(plan *Plan) map[string]int {
+	actions := make(map[string]int)
+	for _, action := range plan.Actions {
+		actions[action.Action]++
+	}
+	return actions
+}
+
+// Test the plan records the remotes as they were given so connection
+// string parameters aren't lost
+func TestPlanFsString(t *testing.T) {
+	r := fstest.NewRun(t)
+	defer r.Finalise()
+	r.WriteFile("dir/file", "content", t1)
+	dir := filepath.ToSlash(filepath.Join(r.LocalName, "dir"))
+
+	remote := ":local,nounc=true:" + dir
+	f, err := fs.NewFs(remote)
+	require.NoError(t, err)
+	assert.Equal(t, remote, fsString(f, remote))
+
+	// Without the remote the name and root are used
+	assert.Equal(t, f.Name()+":"+f.Root(), fsString(f, ""))
+
+	// Local paths are absolute
+	assert.Equal(t, r.Flocal.Root(), fsString(r.Flocal, "."))
+
+	ctx := WithPlanRemotes(context.Background(), ".", remote)
+	plan := newPlan(ctx, f, r.Flocal, "sync", hash.None)
+	assert.Equal(t, remote, plan.Dst)
+	assert.Equal(t, r.Flocal.Root(), plan.Src)
+
+	plan = newPlan(context.Background(), f, r.Flocal, "sync", hash.None)
+	assert.Equal(t, f.Name()+":"+f.Root(), plan.Dst)
+}
+
+func TestPlanSyncAndApply(t *testing.T) {
+	r := fstest.NewRun(t)
+	defer r.Finalise()
+	ctx := context.Background()
+
+	file1 := r.WriteBoth("same", "same content", t1)
+	file2 := r.WriteFile("changed", "new content", t2)
+	file3 := r.WriteFile("sub dir/new", "brand new", t2)
+	oldFile2 := r.WriteObject("changed", "old content!", t1)
+	oldFile4 := r.WriteObject("gone/old", "delete me", t1)
+	fstest.CheckItems(t, r.Fremote, file1, oldFile2, oldFile4)
+
+	planOut, cleanup := setPlanOut(t)
+	defer cleanup()
+
+	require.NoError(t, Sync(ctx, r.Fremote, r.Flocal))
+
+	// Nothing should have changed
+	fstest.CheckItems(t, r.Fremote, file1, oldFile2, oldFile4)
+	assert.False(t, fs.Config.DryRun)
+
+	plan, err := LoadPlan(planOut)
+	require.NoError(t, err)
+	assert.Equal(t, "sync", plan.Mode)
+	assert.Equal(t, map[string]int{
+		PlanUpdate: 1,
+		PlanMkdir:  1,
+		PlanCopy:   1,
+		PlanDelete: 1,
+		PlanRmdir:  1,
+	}, planActions(plan))
+
+	fs.Config.PlanOut = ""
+	require.NoError(t, ApplyPlan(ctx, r.Fremote, r.Flocal, plan))
+
+	fstest.CheckItems(t, r.Flocal, file1, file2, file3)
+	fstest.CheckItems(t, r.Fremote, file1, file2, file3)
+	fstest.CheckListingWithPrecision(t, r.Fremote, []fstest.Item{file1, file2, file3}, []string{"sub dir"}, fs.Config.ModifyWindow)
+
+	// Applying the plan again should fail as it is out of date
+	err = ApplyPlan(ctx, r.Fremote, r.Flocal, plan)
+	require.Error(t, err)
+	assert.True(t, fserrors.IsFatalError(err))
+}
+
+// Test making a plan doesn't update the modification time of
+// identical files or change the global dry run flag
+func TestPlanDoesntUpdateModTime(t *testing.T) {
+	r := fstest.NewRun(t)
+	defer r.Finalise()
+	ctx := context.Background()
+
+	if r.Fremote.Hashes().Overlap(r.Flocal.Hashes()).Count() == 0 {
+		t.Skip("Skipping test as remote has no hashes in common with local")
+	}
+
+	file1 := r.WriteFile("file", "same content", t2)
+	file2 := r.WriteObject("file", "same content", t1)
+
+	planOut, cleanup := setPlanOut(t)
+	defer cleanup()
+	require.NoError(t, Sync(ctx, r.Fremote, r.Flocal))
+	assert.False(t, fs.Config.DryRun)
+
+	plan, err := LoadPlan(planOut)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{}, planActions(plan))
+
+	fstest.CheckItems(t, r.Flocal, file1)
+	fstest.CheckItems(t, r.Fremote, file2)
+}
+
+func TestApplyPlanStale(t *testing.T) {
+	r := fstest.NewRun(t)
+	defer r.Finalise()
+	ctx := context.Background()
+
+	r.WriteFile("file1", "content", t1)
+	file2 := r.WriteObject("file2", "delete me", t1)
+
+	planOut, cleanup := setPlanOut(t)
+	defer cleanup()
+	require.NoError(t, Sync(ctx, r.Fremote, r.Flocal))
+	fs.Config.PlanOut = ""
+
+	plan, err := LoadPlan(planOut)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{PlanCopy: 1, PlanDelete: 1}, planActions(plan))
+
+	// Change the source after the plan was made
+	file1 := r.WriteFile("file1", "changed content", t2)
+
+	err = ApplyPlan(ctx, r.Fremote, r.Flocal, plan)
+	require.Error(t, err)
+	assert.True(t, fserrors.IsFatalError(err))
+
+	// Nothing should have been done
+	fstest.CheckItems(t, r.Flocal, file1)
+	fstest.CheckItems(t, r.Fremote, file2)
+}
+
+// Test the modification times are checked within --modify-window
+func TestPlanCheckObjectModifyWindow(t *testing.T) {
+	r := fstest.NewRun(t)
+	defer r.Finalise()
+	ctx := context.Background()
+
+	file1 := r.WriteObject("file", "content", t1)
+	o, err := r.Fremote.NewObject(ctx, file1.Path)
+	require.NoError(t, err)
+
+	oldModifyWindow := fs.Config.ModifyWindow
+	fs.Config.ModifyWindow = time.Second
+	defer func() {
+		fs.Config.ModifyWindow = oldModifyWindow
+	}()
+
+	p := &Plan{}
+	want := &PlanObject{Size: file1.Size, ModTime: o.ModTime().Add(500 * time.Millisecond)}
+	assert.NoError(t, p.checkObject(o, want))
+
+	want.ModTime = o.ModTime().Add(2 * time.Second)
+	assert.Error(t, p.checkObject(o, want))
+
+	fs.Config.ModifyWindow = fs.ModTimeNotSupported
+	assert.NoError(t, p.checkObject(o, want))
+}
+
+func TestPlanMove(t *testing.T) {
+	r := fstest.NewRun(t)
+	defer r.Finalise()
+	ctx := context.Background()
+
+	file1 := r.WriteFile("file1", "move me", t1)
+	file2 := r.WriteBoth("dir/file2", "already there", t2)
+
+	planOut, cleanup := setPlanOut(t)
+	defer cleanup()
+	require.NoError(t, moveDir(ctx, r.Fremote, r.Flocal, true))
+	fs.Config.PlanOut = ""
+	fstest.CheckItems(t, r.Flocal, file1, file2)
+
+	plan, err := LoadPlan(planOut)
+	require.NoError(t, err)
+	assert.Equal(t, "move", plan.Mode)
+	assert.Equal(t, map[string]int{PlanMove: 1, PlanDeleteSrc: 1, PlanRmdirSrc: 1}, planActions(plan))
+
+	require.NoError(t, ApplyPlan(ctx, r.Fremote, r.Flocal, plan))
+	fstest.CheckItems(t, r.Flocal)
+	fstest.CheckItems(t, r.Fremote, file1, file2)
+	fstest.CheckListingWithPrecision(t, r.Flocal, []fstest.Item{}, []string{}, fs.Config.ModifyWindow)
+}
+
+func TestPlanTrackRenames(t *testing.T) {
+	r := fstest.NewRun(t)
+	defer r.Finalise()
+	ctx := context.Background()
+
+	haveHash := r.Fremote.Hashes().Overlap(r.Flocal.Hashes()).GetOne() != hash.None
+	if !haveHash || !operations.CanServerSideMove(r.Fremote) {
+		t.Skip("Can't track renames")
+	}
+
+	fs.Config.TrackRenames = true
+	defer func() { fs.Config.TrackRenames = false }()
+
+	file1 := r.WriteFile("potato", "Potato Content", t1)
+	r.WriteObject("yam", "Potato Content", t1)
+
+	planOut, cleanup := setPlanOut(t)
+	defer cleanup()
+	require.NoError(t, Sync(ctx, r.Fremote, r.Flocal))
+	fs.Config.PlanOut = ""
+
+	plan, err := LoadPlan(planOut)
+	require.NoError(t, err)
+	require.Equal(t, map[string]int{PlanRename: 1}, planActions(plan))
+	assert.Equal(t, "yam", plan.Actions[0].OldPath)
+	assert.Equal(t, "potato", plan.Actions[0].Path)
+
+	require.NoError(t, ApplyPlan(ctx, r.Fremote, r.Flocal, plan))
+	fstest.CheckItems(t, r.Fremote, file1)
+}
+
+func TestPlanBackupDir(t *testing.T) {
+	r := fstest.NewRun(t)
+	defer r.Finalise()
+	r.WriteFile("file1", "content", t1)
+
+	_, cleanup := setPlanOut(t)
+	defer cleanup()
+	fs.Config.BackupDir = r.FremoteName + "/backup"
+	defer func() { fs.Config.BackupDir = "" }()
+
+	err := Sync(context.Background(), r.Fremote, r.Flocal)
+	require.Error(t, err)
+	assert.True(t, fserrors.IsFatalError(err))
+}
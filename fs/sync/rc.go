@@ -0,0 +1,68 @@
+// Remote control for sync, copy and move
+
+package sync
+
+import (
+	"context"
+	"strings"
+
+	"github.com/ncw/rclone/fs/operations"
+	"github.com/ncw/rclone/fs/rc"
+)
+
+func init() {
+	for _, name := range []string{"sync", "copy", "move"} {
+		name := name
+		moveHelp := ""
+		if name == "move" {
+			moveHelp = "- deleteEmptySrcDirs - delete empty src directories if set\n"
+		}
+		rc.Add(rc.Call{
+			Path: "sync/" + name,
+			Fn: func(ctx context.Context, in rc.Params) (rc.Params, error) {
+				return rcSyncCopyMove(ctx, in, name)
+			},
+			Title: strings.ToUpper(name[:1]) + name[1:] + " a directory from source remote to destination remote",
+			Help: `This takes the following parameters
+
+- srcFs - a remote name string eg "drive:src" for the source
+- dstFs - a remote name string eg "drive:dst" for the destination
+` + moveHelp + `
+See the ` + name + ` command for more information on the above.`,
+			AuthRequired: true,
+		})
+	}
+}
+
+// Sync/Copy/Move a directory
+func rcSyncCopyMove(ctx context.Context, in rc.Params, name string) (out rc.Params, err error) {
+	srcFs, srcFileName, err := rc.GetFsNamedFile(in, "srcFs")
+	if err != nil {
+		return nil, err
+	}
+	dstFs, err := rc.GetFsNamed(in, "dstFs")
+	if err != nil {
+		return nil, err
+	}
+	deleteEmptySrcDirs, err := in.GetBool("deleteEmptySrcDirs")
+	if rc.NotErrParamNotFound(err) {
+		return nil, err
+	}
+	if srcFileName != "" {
+		// If the source is a file then transfer just that file
+		// as the commands do
+		if name == "move" {
+			return nil, operations.MoveFile(ctx, dstFs, srcFs, srcFileName, srcFileName)
+		}
+		return nil, operations.CopyFile(ctx, dstFs, srcFs, srcFileName, srcFileName)
+	}
+	switch name {
+	case "sync":
+		return nil, Sync(ctx, dstFs, srcFs)
+	case "copy":
+		return nil, CopyDir(ctx, dstFs, srcFs)
+	case "move":
+		return nil, MoveDir(ctx, dstFs, srcFs, deleteEmptySrcDirs)
+	}
+	panic("unknown rcSyncCopyMove type")
+}
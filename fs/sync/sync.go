@@ -4,8 +4,12 @@ package sync
 import (
 	"context"
 	"fmt"
+	"path"
 	"sort"
+	"strconv"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/ncw/rclone/fs"
 	"github.com/ncw/rclone/fs/accounting"
@@ -26,42 +30,53 @@ type syncCopyMove struct {
 	deleteEmptySrcDirs bool
 	dir                string
 	// internal state
-	ctx            context.Context        // internal context for controlling go-routines
-	cancel         func()                 // cancel the context
-	deletersWg     sync.WaitGroup         // for delete before go routine
-	deleteFilesCh  chan fs.Object         // channel to receive deletes if delete before
-	trackRenames   bool                   // set if we should do server side renames
-	dstFilesMu     sync.Mutex             // protect dstFiles
-	dstFiles       map[string]fs.Object   // dst files, always filled
-	srcFiles       map[string]fs.Object   // src files, only used if deleteBefore
-	srcFilesChan   chan fs.Object         // passes src objects
-	srcFilesResult chan error             // error result of src listing
-	dstFilesResult chan error             // error result of dst listing
-	dstEmptyDirsMu sync.Mutex             // protect dstEmptyDirs
-	dstEmptyDirs   []fs.DirEntry          // potentially empty directories
-	srcEmptyDirsMu sync.Mutex             // protect srcEmptyDirs
-	srcEmptyDirs   []fs.DirEntry          // potentially empty directories
-	checkerWg      sync.WaitGroup         // wait for checkers
-	toBeChecked    fs.ObjectPairChan      // checkers channel
-	transfersWg    sync.WaitGroup         // wait for transfers
-	toBeUploaded   fs.ObjectPairChan      // copiers channel
-	errorMu        sync.Mutex             // Mutex covering the errors variables
-	err            error                  // normal error from copy process
-	noRetryErr     error                  // error with NoRetry set
-	fatalErr       error                  // fatal error
-	commonHash     hash.Type              // common hash type between src and dst
-	renameMapMu    sync.Mutex             // mutex to protect the below
-	renameMap      map[string][]fs.Object // dst files by hash - only used by trackRenames
-	renamerWg      sync.WaitGroup         // wait for renamers
-	toBeRenamed    fs.ObjectPairChan      // renamers channel
-	trackRenamesWg sync.WaitGroup         // wg for background track renames
-	trackRenamesCh chan fs.Object         // objects are pumped in here
-	renameCheck    []fs.Object            // accumulate files to check for rename here
-	backupDir      fs.Fs                  // place to store overwrites/deletes
-	suffix         string                 // suffix to add to files placed in backupDir
-}
-
-func newSyncCopyMove(fdst, fsrc fs.Fs, deleteMode fs.DeleteMode, DoMove bool, deleteEmptySrcDirs bool) (*syncCopyMove, error) {
+	ctx            context.Context           // internal context for controlling go-routines
+	cancel         func()                    // cancel the context
+	deletersWg     sync.WaitGroup            // for delete before go routine
+	deleteFilesCh  chan fs.Object            // channel to receive deletes if delete before
+	trackRenames   bool                      // set if we should do server side renames
+	dstFilesMu     sync.Mutex                // protect dstFiles
+	dstFiles       map[string]fs.Object      // dst files, always filled
+	srcFiles       map[string]fs.Object      // src files, only used if deleteBefore
+	srcFilesChan   chan fs.Object            // passes src objects
+	srcFilesResult chan error                // error result of src listing
+	dstFilesResult chan error                // error result of dst listing
+	dstEmptyDirsMu sync.Mutex                // protect dstEmptyDirs
+	dstEmptyDirs   []fs.DirEntry             // potentially empty directories
+	srcEmptyDirsMu sync.Mutex                // protect srcEmptyDirs
+	srcEmptyDirs   []fs.DirEntry             // potentially empty directories
+	checkerWg      sync.WaitGroup            // wait for checkers
+	toBeChecked    *pipe                     // checkers channel
+	transfersWg    sync.WaitGroup            // wait for transfers
+	toBeUploaded   *pipe                     // copiers channel
+	errorMu        sync.Mutex                // Mutex covering the errors variables
+	err            error                     // normal error from copy process
+	noRetryErr     error                     // error with NoRetry set
+	fatalErr       error                     // fatal error
+	commonHash     hash.Type                 // common hash type between src and dst
+	renameMapMu    sync.Mutex                // mutex to protect the below
+	renameMap      map[string][]fs.Object    // dst files by rename ID - only used by trackRenames
+	renameStrategy trackRenamesStrategy      // how to match files for trackRenames
+	dstRenameIDs   map[string]string         // rename IDs of dst files by remote - only used by trackRenames
+	srcRenameIDs   map[string]string         // rename IDs of src files by remote if calculated already
+	dstOnlyDirs    map[string]struct{}       // directories only in the dst - only used by trackRenames
+	srcOnlyDirs    map[string]struct{}       // directories only in the src - only used by trackRenames
+	renamerWg      sync.WaitGroup            // wait for renamers
+	toBeRenamed    *pipe                     // renamers channel
+	trackRenamesWg sync.WaitGroup            // wg for background track renames
+	trackRenamesCh chan fs.Object            // objects are pumped in here
+	renameCheck    []fs.Object               // accumulate files to check for rename here
+	backupDir      fs.Fs                     // place to store overwrites/deletes
+	refs           []operations.ReferenceDir // reference directories from --compare-dest or --copy-dest
+	deadline       time.Time                 // time to stop transferring by if --max-duration is set
+	cutoffMu       sync.Mutex                // protects the below
+	cutoffErr      error                     // set if --max-transfer or --max-duration has been reached
+	reserved       int64                     // size of the transfers in progress for --cutoff-mode cautious
+	checkFirst     bool                      // if set run all the checkers before starting transfers
+	plan           *Plan                     // if set only record the actions here for --plan-out without doing them
+}
+
+func newSyncCopyMove(ctx context.Context, fdst, fsrc fs.Fs, deleteMode fs.DeleteMode, DoMove bool, deleteEmptySrcDirs bool) (*syncCopyMove, error) {
 	s := &syncCopyMove{
 		fdst:               fdst,
 		fsrc:               fsrc,
@@ -72,23 +87,58 @@ func newSyncCopyMove(fdst, fsrc fs.Fs, deleteMode fs.DeleteMode, DoMove bool, de
 		srcFilesChan:       make(chan fs.Object, fs.Config.Checkers+fs.Config.Transfers),
 		srcFilesResult:     make(chan error, 1),
 		dstFilesResult:     make(chan error, 1),
-		toBeChecked:        make(fs.ObjectPairChan, fs.Config.Transfers),
-		toBeUploaded:       make(fs.ObjectPairChan, fs.Config.Transfers),
 		deleteFilesCh:      make(chan fs.Object, fs.Config.Checkers),
 		trackRenames:       fs.Config.TrackRenames,
 		commonHash:         fsrc.Hashes().Overlap(fdst.Hashes()).GetOne(),
-		toBeRenamed:        make(fs.ObjectPairChan, fs.Config.Transfers),
 		trackRenamesCh:     make(chan fs.Object, fs.Config.Checkers),
+		dstOnlyDirs:        make(map[string]struct{}),
+		srcOnlyDirs:        make(map[string]struct{}),
+		checkFirst:         fs.Config.CheckFirst,
+	}
+	backlog := fs.Config.MaxBacklog
+	if s.checkFirst {
+		// All the transfers are queued until the checks finish
+		backlog = -1
+	}
+	var err error
+	s.toBeChecked, err = newPipe("", accounting.Stats.SetCheckQueue, fs.Config.MaxBacklog)
+	if err != nil {
+		return nil, fserrors.FatalError(err)
+	}
+	s.toBeUploaded, err = newPipe(fs.Config.OrderBy, accounting.Stats.SetTransferQueue, backlog)
+	if err != nil {
+		return nil, fserrors.FatalError(err)
+	}
+	s.toBeRenamed, err = newPipe(fs.Config.OrderBy, nil, fs.Config.MaxBacklog)
+	if err != nil {
+		return nil, fserrors.FatalError(err)
+	}
+	if fs.Config.MaxDuration > 0 {
+		s.deadline = time.Now().Add(fs.Config.MaxDuration)
+		fs.Infof(s.fdst, "Transfer session deadline: %s", s.deadline.Format("2006/01/02 15:04:05"))
+	}
+	if !s.deadline.IsZero() && fs.Config.CutoffMode == fs.CutoffModeHard {
+		s.ctx, s.cancel = context.WithDeadline(ctx, s.deadline)
+	} else {
+		s.ctx, s.cancel = context.WithCancel(ctx)
 	}
-	s.ctx, s.cancel = context.WithCancel(context.Background())
 	if s.trackRenames {
+		strategy, err := parseTrackRenamesStrategy(fs.Config.TrackRenamesStrategy)
+		if err != nil {
+			return nil, fserrors.FatalError(err)
+		}
+		s.renameStrategy = strategy
 		// Don't track renames for remotes without server-side move support.
 		if !operations.CanServerSideMove(fdst) {
 			fs.Errorf(fdst, "Ignoring --track-renames as the destination does not support server-side move or copy")
 			s.trackRenames = false
 		}
-		if s.commonHash == hash.None {
-			fs.Errorf(fdst, "Ignoring --track-renames as the source and destination do not have a common hash")
+		if s.renameStrategy.hash() && s.commonHash == hash.None {
+			fs.Errorf(fdst, "Ignoring --track-renames as the source and destination do not have a common hash - try --track-renames-strategy modtime or leaf")
+			s.trackRenames = false
+		}
+		if s.renameStrategy.modTime() && fs.Config.ModifyWindow == fs.ModTimeNotSupported {
+			fs.Errorf(fdst, "Ignoring --track-renames as the source and destination do not have a common modification time precision")
 			s.trackRenames = false
 		}
 		if s.deleteMode == fs.DeleteModeOff {
@@ -112,7 +162,7 @@ func newSyncCopyMove(fdst, fsrc fs.Fs, deleteMode fs.DeleteMode, DoMove bool, de
 		if !operations.CanServerSideMove(s.backupDir) {
 			return nil, fserrors.FatalError(errors.New("can't use --backup-dir on a remote which doesn't support server side move or copy"))
 		}
-		if !operations.SameConfig(fdst, s.backupDir) {
+		if !operations.ServerSideCompatible(s.backupDir, fdst) {
 			return nil, fserrors.FatalError(errors.New("parameter to --backup-dir has to be on the same remote as destination"))
 		}
 		if operations.Overlapping(fdst, s.backupDir) {
@@ -121,11 +171,49 @@ func newSyncCopyMove(fdst, fsrc fs.Fs, deleteMode fs.DeleteMode, DoMove bool, de
 		if operations.Overlapping(fsrc, s.backupDir) {
 			return nil, fserrors.FatalError(errors.New("source and parameter to --backup-dir mustn't overlap"))
 		}
-		s.suffix = fs.Config.Suffix
 	}
+	// Make Fses for --compare-dest and --copy-dest if required
+	if len(fs.Config.CompareDest) > 0 && len(fs.Config.CopyDest) > 0 {
+		return nil, fserrors.FatalError(errors.New("can't use --compare-dest with --copy-dest"))
+	}
+	s.refs, err = newReferenceDirs(fdst, "--compare-dest", fs.Config.CompareDest, false)
+	if err != nil {
+		return nil, err
+	}
+	copyDests, err := newReferenceDirs(fdst, "--copy-dest", fs.Config.CopyDest, true)
+	if err != nil {
+		return nil, err
+	}
+	s.refs = append(s.refs, copyDests...)
 	return s, nil
 }
 
+// newReferenceDirs makes Fses for the reference directories passed in
+// with flag, checking they are usable with fdst.  If serverSideCopy
+// is set they must support server side copy to fdst and identical
+// files are copied from them.
+func newReferenceDirs(fdst fs.Fs, flag string, dirs []string, serverSideCopy bool) (refs []operations.ReferenceDir, err error) {
+	for _, dir := range dirs {
+		ref, err := fs.NewFs(dir)
+		if err != nil {
+			return nil, fserrors.FatalError(errors.Errorf("Failed to make fs for %s %q: %v", flag, dir, err))
+		}
+		if serverSideCopy {
+			if fdst.Features().Copy == nil {
+				return nil, fserrors.FatalError(errors.Errorf("can't use %s on a remote which doesn't support server side copy", flag))
+			}
+			if !operations.ServerSideCompatible(fdst, ref) {
+				return nil, fserrors.FatalError(errors.Errorf("parameter to %s has to be on the same remote as destination", flag))
+			}
+		}
+		if operations.Overlapping(fdst, ref) {
+			return nil, fserrors.FatalError(errors.Errorf("destination and parameter to %s mustn't overlap", flag))
+		}
+		refs = append(refs, operations.ReferenceDir{Fs: ref, Copy: serverSideCopy})
+	}
+	return refs, nil
+}
+
 // Check to see if the context has been cancelled
 func (s *syncCopyMove) aborting() bool {
 	select {
@@ -193,107 +281,195 @@ func (s *syncCopyMove) currentError() error {
 // pairChecker reads Objects~s on in send to out if they need transferring.
 //
 // FIXME potentially doing lots of hashes at once
-func (s *syncCopyMove) pairChecker(in fs.ObjectPairChan, out fs.ObjectPairChan, wg *sync.WaitGroup) {
+func (s *syncCopyMove) pairChecker(in *pipe, out *pipe, wg *sync.WaitGroup) {
 	defer wg.Done()
 	for {
 		if s.aborting() {
 			return
 		}
-		select {
-		case pair, ok := <-in:
-			if !ok {
-				return
-			}
-			src := pair.Src
-			accounting.Stats.Checking(src.Remote())
-			// Check to see if can store this
-			if src.Storable() {
-				if operations.NeedTransfer(pair.Dst, pair.Src) {
-					// If files are treated as immutable, fail if destination exists and does not match
-					if fs.Config.Immutable && pair.Dst != nil {
-						fs.Errorf(pair.Dst, "Source and destination exist but do not match: immutable file modified")
-						s.processError(fs.ErrorImmutableModified)
-					} else {
+		pair, ok := in.Get(s.ctx)
+		if !ok {
+			return
+		}
+		src := pair.Src
+		accounting.Stats.Checking(src.Remote())
+		// Check to see if can store this
+		if src.Storable() {
+			if s.needTransfer(pair.Dst, pair.Src) {
+				// If files are treated as immutable, fail if destination exists and does not match
+				if fs.Config.Immutable && pair.Dst != nil {
+					fs.Errorf(pair.Dst, "Source and destination exist but do not match: immutable file modified")
+					s.processError(fs.ErrorImmutableModified)
+				} else {
+					noNeedTransfer, err := operations.CompareOrCopyDest(s.ctx, s.fdst, pair.Dst, pair.Src, s.refs, s.backupDir)
+					if err != nil {
+						s.processError(err)
+					} else if noNeedTransfer {
+						// If moving need to delete the source as it isn't being transferred
+						if s.DoMove {
+							s.deleteSrc(src)
+						}
+					} else if pair.Dst != nil && s.backupDir != nil {
 						// If destination already exists, then we must move it into --backup-dir if required
-						if pair.Dst != nil && s.backupDir != nil {
-							remoteWithSuffix := pair.Dst.Remote() + s.suffix
-							overwritten, _ := s.backupDir.NewObject(remoteWithSuffix)
-							_, err := operations.Move(s.backupDir, overwritten, remoteWithSuffix, pair.Dst)
-							if err != nil {
-								s.processError(err)
-							} else {
-								// If successful zero out the dst as it is no longer there and copy the file
-								pair.Dst = nil
-								out <- pair
-							}
+						err = operations.MoveBackupDir(s.ctx, s.backupDir, pair.Dst)
+						if err != nil {
+							s.processError(err)
 						} else {
-							out <- pair
+							// If successful zero out the dst as it is no longer there and copy the file
+							pair.Dst = nil
+							out.Put(s.ctx, pair)
 						}
-					}
-				} else {
-					// If moving need to delete the files we don't need to copy
-					if s.DoMove {
-						// Delete src if no error on copy
-						s.processError(operations.DeleteFile(src))
+					} else {
+						out.Put(s.ctx, pair)
 					}
 				}
+			} else {
+				// If moving need to delete the files we don't need to copy
+				if s.DoMove {
+					// Delete src if no error on copy
+					s.deleteSrc(src)
+				}
 			}
-			accounting.Stats.DoneChecking(src.Remote())
-		case <-s.ctx.Done():
-			return
 		}
+		accounting.Stats.DoneChecking(src.Remote())
+	}
+}
+
+// needTransfer checks to see if src needs to be transferred to dst.
+//
+// When planning the modification time of dst is never updated.
+func (s *syncCopyMove) needTransfer(dst, src fs.Object) bool {
+	if s.plan != nil {
+		return operations.NeedTransferNoUpdate(s.ctx, dst, src)
+	}
+	return operations.NeedTransfer(s.ctx, dst, src)
+}
+
+// deleteSrc deletes src when moving as it doesn't need transferring
+func (s *syncCopyMove) deleteSrc(src fs.Object) {
+	if s.plan != nil {
+		s.plan.addDelete(PlanDeleteSrc, src)
+		return
 	}
+	s.processError(operations.DeleteFile(s.ctx, src))
 }
 
 // pairRenamer reads Objects~s on in and attempts to rename them,
 // otherwise it sends them out if they need transferring.
-func (s *syncCopyMove) pairRenamer(in fs.ObjectPairChan, out fs.ObjectPairChan, wg *sync.WaitGroup) {
+func (s *syncCopyMove) pairRenamer(in *pipe, out *pipe, wg *sync.WaitGroup) {
 	defer wg.Done()
 	for {
 		if s.aborting() {
 			return
 		}
-		select {
-		case pair, ok := <-in:
-			if !ok {
-				return
+		pair, ok := in.Get(s.ctx)
+		if !ok {
+			return
+		}
+		src := pair.Src
+		if !s.tryRename(src) {
+			// pass on if not renamed
+			out.Put(s.ctx, pair)
+		}
+	}
+}
+
+// startTransfer checks --max-transfer and --max-duration to see if
+// the transfer of src may be started.  If not it returns the error to
+// stop with and no more transfers will be started.
+//
+// If it returns nil, doneTransfer must be called with src when the
+// transfer has finished.
+func (s *syncCopyMove) startTransfer(src fs.Object) error {
+	s.cutoffMu.Lock()
+	defer s.cutoffMu.Unlock()
+	if s.cutoffErr != nil {
+		return s.cutoffErr
+	}
+	hard := fs.Config.CutoffMode == fs.CutoffModeHard
+	if fs.Config.MaxTransfer >= 0 {
+		limit := int64(fs.Config.MaxTransfer)
+		used := accounting.Stats.GetBytes()
+		if fs.Config.CutoffMode == fs.CutoffModeCautious {
+			// Count the transfers in progress and this one
+			// as complete so the limit is never exceeded
+			used += s.reserved + src.Size()
+			if used > limit {
+				fs.Infof(src, "Not transferring as it would exceed --max-transfer %v", fs.Config.MaxTransfer)
+				s.cutoffErr = accounting.ErrorMaxTransferLimitReachedGraceful
 			}
-			src := pair.Src
-			if !s.tryRename(src) {
-				// pass on if not renamed
-				out <- pair
+		} else if used >= limit {
+			s.cutoffErr = accounting.ErrorMaxTransferLimitReachedGraceful
+			if hard {
+				s.cutoffErr = accounting.ErrorMaxTransferLimitReachedFatal
 			}
-		case <-s.ctx.Done():
-			return
 		}
 	}
+	if s.cutoffErr == nil && !s.deadline.IsZero() && !hard && time.Now().After(s.deadline) {
+		s.cutoffErr = accounting.ErrorMaxDurationReachedGraceful
+	}
+	if s.cutoffErr != nil {
+		fs.Errorf(s.fdst, "%v - not starting any more transfers", s.cutoffErr)
+		return s.cutoffErr
+	}
+	if src.Size() > 0 {
+		s.reserved += src.Size()
+	}
+	return nil
+}
+
+// doneTransfer must be called after each transfer allowed by
+// startTransfer has finished
+func (s *syncCopyMove) doneTransfer(src fs.Object) {
+	if src.Size() > 0 {
+		s.cutoffMu.Lock()
+		s.reserved -= src.Size()
+		s.cutoffMu.Unlock()
+	}
 }
 
 // pairCopyOrMove reads Objects on in and moves or copies them.
-func (s *syncCopyMove) pairCopyOrMove(in fs.ObjectPairChan, fdst fs.Fs, wg *sync.WaitGroup) {
+//
+// fraction is used to choose which end of the --order-by ordering
+// this transferer takes its files from.
+func (s *syncCopyMove) pairCopyOrMove(in *pipe, fdst fs.Fs, fraction int, wg *sync.WaitGroup) {
 	defer wg.Done()
 	var err error
 	for {
 		if s.aborting() {
 			return
 		}
-		select {
-		case pair, ok := <-in:
-			if !ok {
-				return
+		pair, ok := in.GetMax(s.ctx, fraction)
+		if !ok {
+			return
+		}
+		src := pair.Src
+		err = s.startTransfer(src)
+		if err != nil {
+			s.processError(err)
+			continue
+		}
+		if s.plan != nil {
+			action := PlanCopy
+			if s.DoMove {
+				action = PlanMove
+			} else if pair.Dst != nil {
+				action = PlanUpdate
 			}
-			src := pair.Src
-			accounting.Stats.Transferring(src.Remote())
+			s.plan.addTransfer(action, src, pair.Dst)
+		}
+		accounting.Stats.Transferring(src.Remote())
+		// Only transfer if not planning
+		if s.plan == nil {
 			if s.DoMove {
-				_, err = operations.Move(fdst, pair.Dst, src.Remote(), src)
+				_, err = operations.Move(s.ctx, fdst, pair.Dst, src.Remote(), src)
 			} else {
-				_, err = operations.Copy(fdst, pair.Dst, src.Remote(), src)
+				_, err = operations.Copy(s.ctx, fdst, pair.Dst, src.Remote(), src)
 			}
-			s.processError(err)
-			accounting.Stats.DoneTransferring(src.Remote(), err == nil)
-		case <-s.ctx.Done():
-			return
 		}
+		s.processError(err)
+		accounting.Stats.DoneTransferringError(src.Remote(), err)
+		s.doneTransfer(src)
 	}
 }
 
@@ -307,7 +483,7 @@ func (s *syncCopyMove) startCheckers() {
 
 // This stops the background checkers
 func (s *syncCopyMove) stopCheckers() {
-	close(s.toBeChecked)
+	s.toBeChecked.Close()
 	fs.Infof(s.fdst, "Waiting for checks to finish")
 	s.checkerWg.Wait()
 }
@@ -316,13 +492,14 @@ func (s *syncCopyMove) stopCheckers() {
 func (s *syncCopyMove) startTransfers() {
 	s.transfersWg.Add(fs.Config.Transfers)
 	for i := 0; i < fs.Config.Transfers; i++ {
-		go s.pairCopyOrMove(s.toBeUploaded, s.fdst, &s.transfersWg)
+		fraction := (100 * i) / fs.Config.Transfers
+		go s.pairCopyOrMove(s.toBeUploaded, s.fdst, fraction, &s.transfersWg)
 	}
 }
 
 // This stops the background transfers
 func (s *syncCopyMove) stopTransfers() {
-	close(s.toBeUploaded)
+	s.toBeUploaded.Close()
 	fs.Infof(s.fdst, "Waiting for transfers to finish")
 	s.transfersWg.Wait()
 }
@@ -343,7 +520,7 @@ func (s *syncCopyMove) stopRenamers() {
 	if !s.trackRenames {
 		return
 	}
-	close(s.toBeRenamed)
+	s.toBeRenamed.Close()
 	fs.Infof(s.fdst, "Waiting for renames to finish")
 	s.renamerWg.Wait()
 }
@@ -379,7 +556,7 @@ func (s *syncCopyMove) startDeleters() {
 	s.deletersWg.Add(1)
 	go func() {
 		defer s.deletersWg.Done()
-		err := operations.DeleteFilesWithBackupDir(s.deleteFilesCh, s.backupDir)
+		err := operations.DeleteFilesWithBackupDir(s.ctx, s.deleteFilesCh, s.backupDir)
 		s.processError(err)
 	}()
 }
@@ -417,16 +594,20 @@ func (s *syncCopyMove) deleteFiles(checkSrcMap bool) error {
 			if s.aborting() {
 				break
 			}
+			if s.plan != nil {
+				s.plan.addDelete(PlanDelete, o)
+				continue
+			}
 			toDelete <- o
 		}
 		close(toDelete)
 	}()
-	return operations.DeleteFilesWithBackupDir(toDelete, s.backupDir)
+	return operations.DeleteFilesWithBackupDir(s.ctx, toDelete, s.backupDir)
 }
 
 // This deletes the empty directories in the slice passed in.  It
 // ignores any errors deleting directories
-func deleteEmptyDirectories(f fs.Fs, entries fs.DirEntries) error {
+func deleteEmptyDirectories(ctx context.Context, f fs.Fs, entries fs.DirEntries) error {
 	if len(entries) == 0 {
 		return nil
 	}
@@ -444,7 +625,7 @@ func deleteEmptyDirectories(f fs.Fs, entries fs.DirEntries) error {
 		dir, ok := entry.(fs.Directory)
 		if ok {
 			// TryRmdir only deletes empty directories
-			err := operations.TryRmdir(f, dir.Remote())
+			err := operations.TryRmdir(ctx, f, dir.Remote())
 			if err != nil {
 				fs.Debugf(fs.LogDirName(f, dir.Remote()), "Failed to Rmdir: %v", err)
 				errorCount++
@@ -464,20 +645,68 @@ func deleteEmptyDirectories(f fs.Fs, entries fs.DirEntries) error {
 	return nil
 }
 
-// renameHash makes a string with the size and the hash for rename detection
+// trackRenamesStrategy is a bitmask of the things which are compared
+// to match files for --track-renames.  The size is always compared.
+type trackRenamesStrategy byte
+
+// Possible values of trackRenamesStrategy
+const (
+	trackRenamesStrategyHash trackRenamesStrategy = 1 << iota
+	trackRenamesStrategyModTime
+	trackRenamesStrategyLeaf
+)
+
+// parseTrackRenamesStrategy parses a comma separated list of
+// strategies as passed to --track-renames-strategy
+func parseTrackRenamesStrategy(strategies string) (strategy trackRenamesStrategy, err error) {
+	for _, name := range strings.Split(strategies, ",") {
+		switch strings.ToLower(strings.TrimSpace(name)) {
+		case "hash":
+			strategy |= trackRenamesStrategyHash
+		case "modtime":
+			strategy |= trackRenamesStrategyModTime
+		case "leaf":
+			strategy |= trackRenamesStrategyLeaf
+		case "size", "":
+			// size is always used
+		default:
+			return strategy, errors.Errorf("unknown --track-renames-strategy %q - use hash, modtime, leaf or size", name)
+		}
+	}
+	return strategy, nil
+}
+
+func (strategy trackRenamesStrategy) hash() bool    { return strategy&trackRenamesStrategyHash != 0 }
+func (strategy trackRenamesStrategy) modTime() bool { return strategy&trackRenamesStrategyModTime != 0 }
+func (strategy trackRenamesStrategy) leaf() bool    { return strategy&trackRenamesStrategyLeaf != 0 }
+
+// renameID makes a string with the size and whichever of the hash,
+// modification time and leaf name the rename strategy asks for
 //
-// it may return an empty string in which case no hash could be made
-func (s *syncCopyMove) renameHash(obj fs.Object) (hash string) {
-	var err error
-	hash, err = obj.Hash(s.commonHash)
-	if err != nil {
-		fs.Debugf(obj, "Hash failed: %v", err)
-		return ""
+// it may return an empty string in which case no ID could be made
+func (s *syncCopyMove) renameID(obj fs.Object) string {
+	parts := []string{strconv.FormatInt(obj.Size(), 10)}
+	if s.renameStrategy.hash() {
+		hash, err := obj.Hash(s.commonHash)
+		if err != nil {
+			fs.Debugf(obj, "Hash failed: %v", err)
+			return ""
+		}
+		if hash == "" {
+			return ""
+		}
+		parts = append(parts, hash)
 	}
-	if hash == "" {
-		return ""
+	if s.renameStrategy.modTime() {
+		// Truncate to the precision so times which are equal
+		// within the modify window compare the same
+		modTime := obj.ModTime().Truncate(fs.Config.ModifyWindow)
+		parts = append(parts, strconv.FormatInt(modTime.UnixNano(), 10))
 	}
-	return fmt.Sprintf("%d,%s", obj.Size(), hash)
+	if s.renameStrategy.leaf() {
+		parts = append(parts, path.Base(obj.Remote()))
+	}
+	return strings.Join(parts, ",")
 }
 
 // pushRenameMap adds the object with hash to the rename map
@@ -504,8 +733,26 @@ func (s *syncCopyMove) popRenameMap(hash string) (dst fs.Object) {
 	return dst
 }
 
-// makeRenameMap builds a map of the destination files by hash that
-// match sizes in the slice of objects in s.renameCheck
+// removeRenameMap removes obj with hash from the rename map if present
+func (s *syncCopyMove) removeRenameMap(hash string, obj fs.Object) {
+	s.renameMapMu.Lock()
+	dsts := s.renameMap[hash]
+	for i, dst := range dsts {
+		if dst == obj {
+			dsts = append(dsts[:i], dsts[i+1:]...)
+			break
+		}
+	}
+	if len(dsts) > 0 {
+		s.renameMap[hash] = dsts
+	} else {
+		delete(s.renameMap, hash)
+	}
+	s.renameMapMu.Unlock()
+}
+
+// makeRenameMap builds a map of the destination files by rename ID
+// that match sizes in the slice of objects in s.renameCheck
 func (s *syncCopyMove) makeRenameMap() {
 	fs.Infof(s.fdst, "Making map for --track-renames")
 
@@ -519,8 +766,9 @@ func (s *syncCopyMove) makeRenameMap() {
 	in := make(chan fs.Object, fs.Config.Checkers)
 	go s.pumpMapToChan(s.dstFiles, in)
 
-	// now make a map of size,hash for all dstFiles
+	// now make a map of rename IDs for all dstFiles
 	s.renameMap = make(map[string][]fs.Object)
+	s.dstRenameIDs = make(map[string]string)
 	var wg sync.WaitGroup
 	wg.Add(fs.Config.Transfers)
 	for i := 0; i < fs.Config.Transfers; i++ {
@@ -530,9 +778,12 @@ func (s *syncCopyMove) makeRenameMap() {
 				// only create hash for dst fs.Object if its size could match
 				if _, found := possibleSizes[obj.Size()]; found {
 					accounting.Stats.Checking(obj.Remote())
-					hash := s.renameHash(obj)
+					hash := s.renameID(obj)
 					if hash != "" {
 						s.pushRenameMap(hash, obj)
+						s.renameMapMu.Lock()
+						s.dstRenameIDs[obj.Remote()] = hash
+						s.renameMapMu.Unlock()
 					}
 					accounting.Stats.DoneChecking(obj.Remote())
 				}
@@ -549,8 +800,11 @@ func (s *syncCopyMove) tryRename(src fs.Object) bool {
 	accounting.Stats.Checking(src.Remote())
 	defer accounting.Stats.DoneChecking(src.Remote())
 
-	// Calculate the hash of the src object
-	hash := s.renameHash(src)
+	// Calculate the rename ID of the src object if not done already
+	hash, ok := s.srcRenameIDs[src.Remote()]
+	if !ok {
+		hash = s.renameID(src)
+	}
 	if hash == "" {
 		return false
 	}
@@ -562,13 +816,22 @@ func (s *syncCopyMove) tryRename(src fs.Object) bool {
 	}
 
 	// Find dst object we are about to overwrite if it exists
-	dstOverwritten, _ := s.fdst.NewObject(src.Remote())
+	dstOverwritten, _ := s.fdst.NewObject(s.ctx, src.Remote())
 
 	// Rename dst to have name src.Remote()
-	_, err := operations.Move(s.fdst, dstOverwritten, src.Remote(), dst)
-	if err != nil {
-		fs.Debugf(src, "Failed to rename to %q: %v", dst.Remote(), err)
-		return false
+	if s.plan != nil {
+		s.plan.add(PlanAction{
+			Action:  PlanRename,
+			Path:    src.Remote(),
+			OldPath: dst.Remote(),
+			Dst:     s.plan.planObject(dst),
+		})
+	} else {
+		_, err := operations.Move(s.ctx, s.fdst, dstOverwritten, src.Remote(), dst)
+		if err != nil {
+			fs.Debugf(src, "Failed to rename to %q: %v", dst.Remote(), err)
+			return false
+		}
 	}
 
 	// remove file from dstFiles if present
@@ -580,6 +843,207 @@ func (s *syncCopyMove) tryRename(src fs.Object) bool {
 	return true
 }
 
+// isUnder returns true if remote is dir or is inside dir
+func isUnder(remote, dir string) bool {
+	return remote == dir || strings.HasPrefix(remote, dir+"/")
+}
+
+// objectsByDir indexes objs under each of their parent directories
+// which are in dirs
+func objectsByDir(objs []fs.Object, dirs map[string]struct{}) map[string][]fs.Object {
+	byDir := make(map[string][]fs.Object)
+	for _, obj := range objs {
+		for dir := path.Dir(obj.Remote()); dir != "."; dir = path.Dir(dir) {
+			if _, found := dirs[dir]; found {
+				byDir[dir] = append(byDir[dir], obj)
+			}
+		}
+	}
+	return byDir
+}
+
+// dirPair is a possible rename of the dst directory to the src one
+type dirPair struct {
+	src, dst string
+}
+
+// dirPairs sorts dirPair with the shortest src paths first
+type dirPairs []dirPair
+
+func (ps dirPairs) Len() int      { return len(ps) }
+func (ps dirPairs) Swap(i, j int) { ps[i], ps[j] = ps[j], ps[i] }
+func (ps dirPairs) Less(i, j int) bool {
+	if len(ps[i].src) != len(ps[j].src) {
+		return len(ps[i].src) < len(ps[j].src)
+	}
+	return ps[i].src < ps[j].src
+}
+
+// makeSrcRenameIDs calculates the rename IDs of objs in parallel
+func (s *syncCopyMove) makeSrcRenameIDs(objs []fs.Object) {
+	s.srcRenameIDs = make(map[string]string, len(objs))
+	var mu sync.Mutex
+	in := make(chan fs.Object, fs.Config.Checkers)
+	var wg sync.WaitGroup
+	wg.Add(fs.Config.Checkers)
+	for i := 0; i < fs.Config.Checkers; i++ {
+		go func() {
+			defer wg.Done()
+			for obj := range in {
+				accounting.Stats.Checking(obj.Remote())
+				id := s.renameID(obj)
+				mu.Lock()
+				s.srcRenameIDs[obj.Remote()] = id
+				mu.Unlock()
+				accounting.Stats.DoneChecking(obj.Remote())
+			}
+		}()
+	}
+	for _, obj := range objs {
+		if s.aborting() {
+			break
+		}
+		in <- obj
+	}
+	close(in)
+	wg.Wait()
+}
+
+// tryDirRenames looks for directories which only exist in the source
+// whose files all match those of a directory which only exists in the
+// destination and renames each one with a single DirMove.
+//
+// The files renamed are removed from s.renameCheck, s.dstFiles and the
+// rename map.  This must be called after makeRenameMap.
+func (s *syncCopyMove) tryDirRenames() {
+	doDirMove := s.fdst.Features().DirMove
+	if doDirMove == nil || !filter.Active.InActive() || fs.Config.MaxDepth >= 0 {
+		return
+	}
+
+	// Find the files which are in a directory only in the source
+	srcByDir := objectsByDir(s.renameCheck, s.srcOnlyDirs)
+	if len(srcByDir) == 0 {
+		return
+	}
+	var srcObjs []fs.Object
+	for _, obj := range s.renameCheck {
+		if _, found := s.srcOnlyDirs[path.Dir(obj.Remote())]; found {
+			srcObjs = append(srcObjs, obj)
+		}
+	}
+	s.makeSrcRenameIDs(srcObjs)
+	dstObjs := make([]fs.Object, 0, len(s.dstFiles))
+	for _, obj := range s.dstFiles {
+		dstObjs = append(dstObjs, obj)
+	}
+	dstByDir := objectsByDir(dstObjs, s.dstOnlyDirs)
+
+	// Each src file which matches a dst file with the same leaf
+	// name in a different directory suggests the topmost
+	// directories which differ were renamed
+	candidates := make(map[dirPair]struct{})
+	for _, src := range srcObjs {
+		id := s.srcRenameIDs[src.Remote()]
+		if id == "" {
+			continue
+		}
+		for _, dst := range s.renameMap[id] {
+			srcDir, dstDir := path.Dir(src.Remote()), path.Dir(dst.Remote())
+			if path.Base(src.Remote()) != path.Base(dst.Remote()) || srcDir == dstDir || dstDir == "." {
+				continue
+			}
+			for path.Base(srcDir) == path.Base(dstDir) {
+				srcParent, dstParent := path.Dir(srcDir), path.Dir(dstDir)
+				if srcParent == "." || dstParent == "." {
+					break
+				}
+				srcDir, dstDir = srcParent, dstParent
+			}
+			candidates[dirPair{src: srcDir, dst: dstDir}] = struct{}{}
+		}
+	}
+	pairs := make(dirPairs, 0, len(candidates))
+	for pair := range candidates {
+		pairs = append(pairs, pair)
+	}
+	sort.Sort(pairs)
+
+	// The directories match if they contain the same relative
+	// paths with the same rename IDs
+	matches := func(pair dirPair) bool {
+		srcs, dsts := srcByDir[pair.src], dstByDir[pair.dst]
+		if len(srcs) == 0 || len(srcs) != len(dsts) {
+			return false
+		}
+		dstIDs := make(map[string]string, len(dsts))
+		for _, dst := range dsts {
+			dstIDs[dst.Remote()[len(pair.dst):]] = s.dstRenameIDs[dst.Remote()]
+		}
+		for _, src := range srcs {
+			id, found := dstIDs[src.Remote()[len(pair.src):]]
+			if !found || id == "" || id != s.srcRenameIDs[src.Remote()] {
+				return false
+			}
+		}
+		return true
+	}
+
+	var done []dirPair
+	renamed := make(map[string]struct{})
+outer:
+	for _, pair := range pairs {
+		if s.aborting() {
+			return
+		}
+		for _, d := range done {
+			if isUnder(pair.src, d.src) || isUnder(pair.dst, d.dst) || isUnder(d.src, pair.src) || isUnder(d.dst, pair.dst) {
+				continue outer
+			}
+		}
+		if !matches(pair) {
+			continue
+		}
+		if s.plan != nil {
+			s.plan.add(PlanAction{
+				Action:  PlanRenameDir,
+				Path:    pair.src,
+				OldPath: pair.dst,
+			})
+		} else if fs.Config.DryRun {
+			fs.Logf(fs.LogDirName(s.fdst, pair.dst), "Not renaming directory to %q as --dry-run", pair.src)
+		} else {
+			err := doDirMove(s.ctx, s.fdst, pair.dst, pair.src)
+			if err != nil {
+				fs.Debugf(fs.LogDirName(s.fdst, pair.dst), "Failed to rename directory to %q: %v", pair.src, err)
+				continue
+			}
+			fs.Infof(fs.LogDirName(s.fdst, pair.src), "Renamed directory from %q", pair.dst)
+		}
+		done = append(done, pair)
+		for _, src := range srcByDir[pair.src] {
+			renamed[src.Remote()] = struct{}{}
+		}
+		s.dstFilesMu.Lock()
+		for _, dst := range dstByDir[pair.dst] {
+			delete(s.dstFiles, dst.Remote())
+			s.removeRenameMap(s.dstRenameIDs[dst.Remote()], dst)
+		}
+		s.dstFilesMu.Unlock()
+	}
+
+	// Remove the renamed files from the files to check
+	if len(renamed) > 0 {
+		renameCheck := s.renameCheck[:0]
+		for _, obj := range s.renameCheck {
+			if _, found := renamed[obj.Remote()]; !found {
+				renameCheck = append(renameCheck, obj)
+			}
+		}
+		s.renameCheck = renameCheck
+	}
+}
+
 // Syncs fsrc into fdst
 //
 // If Delete is true then it deletes any files in fdst that aren't in fsrc
@@ -588,6 +1052,7 @@ func (s *syncCopyMove) tryRename(src fs.Object) bool {
 //
 // dir is the start directory, "" for root
 func (s *syncCopyMove) run() error {
+	defer s.cancel()
 	if operations.Same(s.fdst, s.fsrc) {
 		fs.Errorf(s.fdst, "Nothing to do as source and destination are the same")
 		return nil
@@ -596,7 +1061,9 @@ func (s *syncCopyMove) run() error {
 	// Start background checking and transferring pipeline
 	s.startCheckers()
 	s.startRenamers()
-	s.startTransfers()
+	if !s.checkFirst {
+		s.startTransfers()
+	}
 	s.startDeleters()
 	s.dstFiles = make(map[string]fs.Object)
 
@@ -608,17 +1075,23 @@ func (s *syncCopyMove) run() error {
 
 	s.stopTrackRenames()
 	if s.trackRenames {
-		// Build the map of the remaining dstFiles by hash
+		// Build the map of the remaining dstFiles by rename ID
 		s.makeRenameMap()
+		// Rename whole directories where possible
+		s.tryDirRenames()
 		// Attempt renames for all the files which don't have a matching dst
 		for _, src := range s.renameCheck {
-			s.toBeRenamed <- fs.ObjectPair{Src: src, Dst: nil}
+			s.toBeRenamed.Put(s.ctx, fs.ObjectPair{Src: src, Dst: nil})
 		}
 	}
 
 	// Stop background checking and transferring pipeline
 	s.stopCheckers()
 	s.stopRenamers()
+	if s.checkFirst {
+		fs.Infof(s.fdst, "Checks finished, now starting transfers")
+		s.startTransfers()
+	}
 	s.stopTransfers()
 	s.stopDeleters()
 
@@ -636,7 +1109,11 @@ func (s *syncCopyMove) run() error {
 		if s.currentError() != nil && !fs.Config.IgnoreErrors {
 			fs.Errorf(s.fdst, "%v", fs.ErrorNotDeletingDirs)
 		} else {
-			s.processError(deleteEmptyDirectories(s.fdst, s.dstEmptyDirs))
+			if s.plan != nil {
+				s.plan.addRmdirs(PlanRmdir, s.dstEmptyDirs)
+			} else {
+				s.processError(deleteEmptyDirectories(s.ctx, s.fdst, s.dstEmptyDirs))
+			}
 		}
 	}
 
@@ -644,9 +1121,30 @@ func (s *syncCopyMove) run() error {
 	// if DoMove and --delete-empty-src-dirs flag is set
 	if s.DoMove && s.deleteEmptySrcDirs {
 		//delete empty subdirectories that were part of the move
-		s.processError(deleteEmptyDirectories(s.fsrc, s.srcEmptyDirs))
+		if s.plan != nil {
+			s.plan.addRmdirs(PlanRmdirSrc, s.srcEmptyDirs)
+		} else {
+			s.processError(deleteEmptyDirectories(s.ctx, s.fsrc, s.srcEmptyDirs))
+		}
+	}
+
+	// Remove old versions from --backup-dir if required
+	if s.backupDir != nil && s.ctx.Err() == nil {
+		s.processError(operations.PruneBackupDir(s.ctx, s.backupDir))
+	}
+
+	// Report reaching --max-duration in preference to the errors
+	// the cancellation caused
+	if !s.deadline.IsZero() && s.ctx.Err() == context.DeadlineExceeded {
+		s.processError(accounting.ErrorMaxDurationReachedFatal)
 	}
-	return s.currentError()
+
+	// Report cancellation by the caller if nothing else went wrong
+	err := s.currentError()
+	if err == nil {
+		err = s.ctx.Err()
+	}
+	return err
 }
 
 // DstOnly have an object which is in the destination only
@@ -663,13 +1161,22 @@ func (s *syncCopyMove) DstOnly(dst fs.DirEntry) (recurse bool) {
 			s.dstFiles[x.Remote()] = x
 			s.dstFilesMu.Unlock()
 		case fs.DeleteModeDuring, fs.DeleteModeOnly:
-			s.deleteFilesCh <- x
+			if s.plan != nil {
+				s.plan.addDelete(PlanDelete, x)
+			} else {
+				s.deleteFilesCh <- x
+			}
 		default:
 			panic(fmt.Sprintf("unexpected delete mode %d", s.deleteMode))
 		}
 	case fs.Directory:
 		// Do the same thing to the entire contents of the directory
 		// Record directory as it is potentially empty and needs deleting
+		if s.trackRenames {
+			s.dstEmptyDirsMu.Lock()
+			s.dstOnlyDirs[dst.Remote()] = struct{}{}
+			s.dstEmptyDirsMu.Unlock()
+		}
 		if s.fdst.Features().CanHaveEmptyDirectories {
 			s.dstEmptyDirsMu.Lock()
 			s.dstEmptyDirs = append(s.dstEmptyDirs, dst)
@@ -693,15 +1200,24 @@ func (s *syncCopyMove) SrcOnly(src fs.DirEntry) (recurse bool) {
 		if s.trackRenames {
 			// Save object to check for a rename later
 			s.trackRenamesCh <- x
+		} else if len(s.refs) > 0 {
+			// Check the reference directories for the file
+			s.toBeChecked.Put(s.ctx, fs.ObjectPair{Src: x, Dst: nil})
 		} else {
 			// No need to check since doesn't exist
-			s.toBeUploaded <- fs.ObjectPair{Src: x, Dst: nil}
+			s.toBeUploaded.Put(s.ctx, fs.ObjectPair{Src: x, Dst: nil})
 		}
 	case fs.Directory:
 		// Do the same thing to the entire contents of the directory
+		if s.plan != nil {
+			s.plan.addNewDir(x.Remote())
+		}
 		// Record the directory for deletion
 		s.srcEmptyDirsMu.Lock()
 		s.srcEmptyDirs = append(s.srcEmptyDirs, src)
+		if s.trackRenames {
+			s.srcOnlyDirs[src.Remote()] = struct{}{}
+		}
 		s.srcEmptyDirsMu.Unlock()
 		return true
 	default:
@@ -719,7 +1235,7 @@ func (s *syncCopyMove) Match(dst, src fs.DirEntry) (recurse bool) {
 		}
 		dstX, ok := dst.(fs.Object)
 		if ok {
-			s.toBeChecked <- fs.ObjectPair{Src: srcX, Dst: dstX}
+			s.toBeChecked.Put(s.ctx, fs.ObjectPair{Src: srcX, Dst: dstX})
 		} else {
 			// FIXME src is file, dst is directory
 			err := errors.New("can't overwrite directory with file")
@@ -753,20 +1269,60 @@ func (s *syncCopyMove) Match(dst, src fs.DirEntry) (recurse bool) {
 // If DoMove is true then files will be moved instead of copied
 //
 // dir is the start directory, "" for root
-func runSyncCopyMove(fdst, fsrc fs.Fs, deleteMode fs.DeleteMode, DoMove bool, deleteEmptySrcDirs bool) error {
+//
+// If --plan-out is set then the actions are written there instead of
+// being done
+func runSyncCopyMove(ctx context.Context, fdst, fsrc fs.Fs, deleteMode fs.DeleteMode, DoMove bool, deleteEmptySrcDirs bool) error {
 	if deleteMode != fs.DeleteModeOff && DoMove {
 		return fserrors.FatalError(errors.New("can't delete and move at the same time"))
 	}
+	if fs.Config.PlanOut != "" {
+		return planSyncCopyMove(ctx, fdst, fsrc, deleteMode, DoMove, deleteEmptySrcDirs)
+	}
+	return doSyncCopyMove(ctx, fdst, fsrc, deleteMode, DoMove, deleteEmptySrcDirs, nil)
+}
+
+// planSyncCopyMove works out what runSyncCopyMove would do without
+// doing it and writes the actions to the --plan-out file
+func planSyncCopyMove(ctx context.Context, fdst, fsrc fs.Fs, deleteMode fs.DeleteMode, DoMove bool, deleteEmptySrcDirs bool) error {
+	if fs.Config.BackupDir != "" || len(fs.Config.CopyDest) > 0 {
+		return fserrors.FatalError(errors.New("can't use --plan-out with --backup-dir or --copy-dest"))
+	}
+	mode := "copy"
+	if DoMove {
+		mode = "move"
+	} else if deleteMode != fs.DeleteModeOff {
+		mode = "sync"
+	}
+	plan := newPlan(ctx, fdst, fsrc, mode, fsrc.Hashes().Overlap(fdst.Hashes()).GetOne())
+
+	// The plan being set means nothing is changed
+	err := doSyncCopyMove(ctx, fdst, fsrc, deleteMode, DoMove, deleteEmptySrcDirs, plan)
+	if err != nil {
+		return err
+	}
+	err = plan.Save(fs.Config.PlanOut)
+	if err != nil {
+		return err
+	}
+	fs.Logf(fdst, "Wrote plan of %d actions to %q", len(plan.Actions), fs.Config.PlanOut)
+	return nil
+}
+
+// doSyncCopyMove does the work for runSyncCopyMove recording the
+// actions in plan if it is set
+func doSyncCopyMove(ctx context.Context, fdst, fsrc fs.Fs, deleteMode fs.DeleteMode, DoMove bool, deleteEmptySrcDirs bool, plan *Plan) error {
 	// Run an extra pass to delete only
 	if deleteMode == fs.DeleteModeBefore {
 		if fs.Config.TrackRenames {
 			return fserrors.FatalError(errors.New("can't use --delete-before with --track-renames"))
 		}
 		// only delete stuff during in this pass
-		do, err := newSyncCopyMove(fdst, fsrc, fs.DeleteModeOnly, false, deleteEmptySrcDirs)
+		do, err := newSyncCopyMove(ctx, fdst, fsrc, fs.DeleteModeOnly, false, deleteEmptySrcDirs)
 		if err != nil {
 			return err
 		}
+		do.plan = plan
 		err = do.run()
 		if err != nil {
 			return err
@@ -774,43 +1330,44 @@ func runSyncCopyMove(fdst, fsrc fs.Fs, deleteMode fs.DeleteMode, DoMove bool, de
 		// Next pass does a copy only
 		deleteMode = fs.DeleteModeOff
 	}
-	do, err := newSyncCopyMove(fdst, fsrc, deleteMode, DoMove, deleteEmptySrcDirs)
+	do, err := newSyncCopyMove(ctx, fdst, fsrc, deleteMode, DoMove, deleteEmptySrcDirs)
 	if err != nil {
 		return err
 	}
+	do.plan = plan
 	return do.run()
 }
 
 // Sync fsrc into fdst
-func Sync(fdst, fsrc fs.Fs) error {
-	return runSyncCopyMove(fdst, fsrc, fs.Config.DeleteMode, false, false)
+func Sync(ctx context.Context, fdst, fsrc fs.Fs) error {
+	return runSyncCopyMove(ctx, fdst, fsrc, fs.Config.DeleteMode, false, false)
 }
 
 // CopyDir copies fsrc into fdst
-func CopyDir(fdst, fsrc fs.Fs) error {
-	return runSyncCopyMove(fdst, fsrc, fs.DeleteModeOff, false, false)
+func CopyDir(ctx context.Context, fdst, fsrc fs.Fs) error {
+	return runSyncCopyMove(ctx, fdst, fsrc, fs.DeleteModeOff, false, false)
 }
 
 // moveDir moves fsrc into fdst
-func moveDir(fdst, fsrc fs.Fs, deleteEmptySrcDirs bool) error {
-	return runSyncCopyMove(fdst, fsrc, fs.DeleteModeOff, true, deleteEmptySrcDirs)
+func moveDir(ctx context.Context, fdst, fsrc fs.Fs, deleteEmptySrcDirs bool) error {
+	return runSyncCopyMove(ctx, fdst, fsrc, fs.DeleteModeOff, true, deleteEmptySrcDirs)
 }
 
 // MoveDir moves fsrc into fdst
-func MoveDir(fdst, fsrc fs.Fs, deleteEmptySrcDirs bool) error {
+func MoveDir(ctx context.Context, fdst, fsrc fs.Fs, deleteEmptySrcDirs bool) error {
 	if operations.Same(fdst, fsrc) {
 		fs.Errorf(fdst, "Nothing to do as source and destination are the same")
 		return nil
 	}
 
-	// First attempt to use DirMover if exists, same Fs and no filters are active
-	if fdstDirMove := fdst.Features().DirMove; fdstDirMove != nil && operations.SameConfig(fsrc, fdst) && filter.Active.InActive() {
+	// First attempt to use DirMover if exists, compatible Fs and no filters are active
+	if fdstDirMove := fdst.Features().DirMove; fdstDirMove != nil && operations.ServerSideCompatible(fdst, fsrc) && filter.Active.InActive() && fs.Config.PlanOut == "" {
 		if fs.Config.DryRun {
 			fs.Logf(fdst, "Not doing server side directory move as --dry-run")
 			return nil
 		}
 		fs.Debugf(fdst, "Using server side directory move")
-		err := fdstDirMove(fsrc, "", "")
+		err := fdstDirMove(ctx, fsrc, "", "")
 		switch err {
 		case fs.ErrorCantDirMove, fs.ErrorDirExists:
 			fs.Infof(fdst, "Server side directory move failed - fallback to file moves: %v", err)
@@ -832,5 +1389,5 @@ func MoveDir(fdst, fsrc fs.Fs, deleteEmptySrcDirs bool) error {
 	}
 
 	// Otherwise move the files one by one
-	return moveDir(fdst, fsrc, deleteEmptySrcDirs)
+	return moveDir(ctx, fdst, fsrc, deleteEmptySrcDirs)
 }
@@ -3,7 +3,10 @@
 package sync
 
 import (
+	"context"
+	"os"
 	"runtime"
+	"strings"
 	"testing"
 	"time"
 
@@ -11,9 +14,11 @@ import (
 	"github.com/ncw/rclone/fs"
 	"github.com/ncw/rclone/fs/accounting"
 	"github.com/ncw/rclone/fs/filter"
+	"github.com/ncw/rclone/fs/fserrors"
 	"github.com/ncw/rclone/fs/hash"
 	"github.com/ncw/rclone/fs/operations"
 	"github.com/ncw/rclone/fstest"
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"golang.org/x/text/unicode/norm"
@@ -39,7 +44,7 @@ func TestCopyWithDryRun(t *testing.T) {
 	r.Mkdir(r.Fremote)
 
 	fs.Config.DryRun = true
-	err := CopyDir(r.Fremote, r.Flocal)
+	err := CopyDir(context.Background(), r.Fremote, r.Flocal)
 	fs.Config.DryRun = false
 	require.NoError(t, err)
 
@@ -54,7 +59,7 @@ func TestCopy(t *testing.T) {
 	file1 := r.WriteFile("sub dir/hello world", "hello world", t1)
 	r.Mkdir(r.Fremote)
 
-	err := CopyDir(r.Fremote, r.Flocal)
+	err := CopyDir(context.Background(), r.Fremote, r.Flocal)
 	require.NoError(t, err)
 
 	fstest.CheckItems(t, r.Flocal, file1)
@@ -72,7 +77,7 @@ func TestCopyWithDepth(t *testing.T) {
 	fs.Config.MaxDepth = 1
 	defer func() { fs.Config.MaxDepth = -1 }()
 
-	err := CopyDir(r.Fremote, r.Flocal)
+	err := CopyDir(context.Background(), r.Fremote, r.Flocal)
 	require.NoError(t, err)
 
 	fstest.CheckItems(t, r.Flocal, file1, file2)
@@ -91,7 +96,7 @@ func TestServerSideCopy(t *testing.T) {
 	defer finaliseCopy()
 	t.Logf("Server side copy (if possible) %v -> %v", r.Fremote, FremoteCopy)
 
-	err = CopyDir(FremoteCopy, r.Fremote)
+	err = CopyDir(context.Background(), FremoteCopy, r.Fremote)
 	require.NoError(t, err)
 
 	fstest.CheckItems(t, FremoteCopy, file1)
@@ -106,10 +111,10 @@ func TestCopyAfterDelete(t *testing.T) {
 	fstest.CheckItems(t, r.Flocal)
 	fstest.CheckItems(t, r.Fremote, file1)
 
-	err := operations.Mkdir(r.Flocal, "")
+	err := operations.Mkdir(context.Background(), r.Flocal, "")
 	require.NoError(t, err)
 
-	err = CopyDir(r.Fremote, r.Flocal)
+	err = CopyDir(context.Background(), r.Fremote, r.Flocal)
 	require.NoError(t, err)
 
 	fstest.CheckItems(t, r.Flocal)
@@ -123,7 +128,7 @@ func TestCopyRedownload(t *testing.T) {
 	file1 := r.WriteObject("sub dir/hello world", "hello world", t1)
 	fstest.CheckItems(t, r.Fremote, file1)
 
-	err := CopyDir(r.Flocal, r.Fremote)
+	err := CopyDir(context.Background(), r.Flocal, r.Fremote)
 	require.NoError(t, err)
 
 	fstest.CheckItems(t, r.Flocal, file1)
@@ -142,7 +147,7 @@ func TestSyncBasedOnCheckSum(t *testing.T) {
 	fstest.CheckItems(t, r.Flocal, file1)
 
 	accounting.Stats.ResetCounters()
-	err := Sync(r.Fremote, r.Flocal)
+	err := Sync(context.Background(), r.Fremote, r.Flocal)
 	require.NoError(t, err)
 
 	// We should have transferred exactly one file.
@@ -154,7 +159,7 @@ func TestSyncBasedOnCheckSum(t *testing.T) {
 	fstest.CheckItems(t, r.Flocal, file2)
 
 	accounting.Stats.ResetCounters()
-	err = Sync(r.Fremote, r.Flocal)
+	err = Sync(context.Background(), r.Fremote, r.Flocal)
 	require.NoError(t, err)
 
 	// We should have transferred no files
@@ -176,7 +181,7 @@ func TestSyncSizeOnly(t *testing.T) {
 	fstest.CheckItems(t, r.Flocal, file1)
 
 	accounting.Stats.ResetCounters()
-	err := Sync(r.Fremote, r.Flocal)
+	err := Sync(context.Background(), r.Fremote, r.Flocal)
 	require.NoError(t, err)
 
 	// We should have transferred exactly one file.
@@ -188,7 +193,7 @@ func TestSyncSizeOnly(t *testing.T) {
 	fstest.CheckItems(t, r.Flocal, file2)
 
 	accounting.Stats.ResetCounters()
-	err = Sync(r.Fremote, r.Flocal)
+	err = Sync(context.Background(), r.Fremote, r.Flocal)
 	require.NoError(t, err)
 
 	// We should have transferred no files
@@ -210,7 +215,7 @@ func TestSyncIgnoreSize(t *testing.T) {
 	fstest.CheckItems(t, r.Flocal, file1)
 
 	accounting.Stats.ResetCounters()
-	err := Sync(r.Fremote, r.Flocal)
+	err := Sync(context.Background(), r.Fremote, r.Flocal)
 	require.NoError(t, err)
 
 	// We should have transferred exactly one file.
@@ -222,7 +227,7 @@ func TestSyncIgnoreSize(t *testing.T) {
 	fstest.CheckItems(t, r.Flocal, file2)
 
 	accounting.Stats.ResetCounters()
-	err = Sync(r.Fremote, r.Flocal)
+	err = Sync(context.Background(), r.Fremote, r.Flocal)
 	require.NoError(t, err)
 
 	// We should have transferred no files
@@ -238,7 +243,7 @@ func TestSyncIgnoreTimes(t *testing.T) {
 	fstest.CheckItems(t, r.Fremote, file1)
 
 	accounting.Stats.ResetCounters()
-	err := Sync(r.Fremote, r.Flocal)
+	err := Sync(context.Background(), r.Fremote, r.Flocal)
 	require.NoError(t, err)
 
 	// We should have transferred exactly 0 files because the
@@ -249,7 +254,7 @@ func TestSyncIgnoreTimes(t *testing.T) {
 	defer func() { fs.Config.IgnoreTimes = false }()
 
 	accounting.Stats.ResetCounters()
-	err = Sync(r.Fremote, r.Flocal)
+	err = Sync(context.Background(), r.Fremote, r.Flocal)
 	require.NoError(t, err)
 
 	// We should have transferred exactly one file even though the
@@ -269,7 +274,7 @@ func TestSyncIgnoreExisting(t *testing.T) {
 	defer func() { fs.Config.IgnoreExisting = false }()
 
 	accounting.Stats.ResetCounters()
-	err := Sync(r.Fremote, r.Flocal)
+	err := Sync(context.Background(), r.Fremote, r.Flocal)
 	require.NoError(t, err)
 	fstest.CheckItems(t, r.Flocal, file1)
 	fstest.CheckItems(t, r.Fremote, file1)
@@ -277,7 +282,7 @@ func TestSyncIgnoreExisting(t *testing.T) {
 	// Change everything
 	r.WriteFile("existing", "newpotatoes", t2)
 	accounting.Stats.ResetCounters()
-	err = Sync(r.Fremote, r.Flocal)
+	err = Sync(context.Background(), r.Fremote, r.Flocal)
 	require.NoError(t, err)
 	// Items should not change
 	fstest.CheckItems(t, r.Fremote, file1)
@@ -293,7 +298,7 @@ func TestSyncIgnoreErrors(t *testing.T) {
 	file1 := r.WriteFile("a/potato2", "------------------------------------------------------------", t1)
 	file2 := r.WriteObject("b/potato", "SMALLER BUT SAME DATE", t2)
 	file3 := r.WriteBoth("c/non empty space", "AhHa!", t2)
-	require.NoError(t, operations.Mkdir(r.Fremote, "d"))
+	require.NoError(t, operations.Mkdir(context.Background(), r.Fremote, "d"))
 
 	fstest.CheckListingWithPrecision(
 		t,
@@ -325,7 +330,7 @@ func TestSyncIgnoreErrors(t *testing.T) {
 
 	accounting.Stats.ResetCounters()
 	fs.CountError(nil)
-	assert.NoError(t, Sync(r.Fremote, r.Flocal))
+	assert.NoError(t, Sync(context.Background(), r.Fremote, r.Flocal))
 
 	fstest.CheckListingWithPrecision(
 		t,
@@ -368,7 +373,7 @@ func TestSyncAfterChangingModtimeOnly(t *testing.T) {
 	defer func() { fs.Config.DryRun = false }()
 
 	accounting.Stats.ResetCounters()
-	err := Sync(r.Fremote, r.Flocal)
+	err := Sync(context.Background(), r.Fremote, r.Flocal)
 	require.NoError(t, err)
 
 	fstest.CheckItems(t, r.Flocal, file1)
@@ -377,7 +382,7 @@ func TestSyncAfterChangingModtimeOnly(t *testing.T) {
 	fs.Config.DryRun = false
 
 	accounting.Stats.ResetCounters()
-	err = Sync(r.Fremote, r.Flocal)
+	err = Sync(context.Background(), r.Fremote, r.Flocal)
 	require.NoError(t, err)
 
 	fstest.CheckItems(t, r.Flocal, file1)
@@ -405,7 +410,7 @@ func TestSyncAfterChangingModtimeOnlyWithNoUpdateModTime(t *testing.T) {
 	fstest.CheckItems(t, r.Fremote, file2)
 
 	accounting.Stats.ResetCounters()
-	err := Sync(r.Fremote, r.Flocal)
+	err := Sync(context.Background(), r.Fremote, r.Flocal)
 	require.NoError(t, err)
 
 	fstest.CheckItems(t, r.Flocal, file1)
@@ -426,7 +431,7 @@ func TestSyncDoesntUpdateModtime(t *testing.T) {
 	fstest.CheckItems(t, r.Fremote, file2)
 
 	accounting.Stats.ResetCounters()
-	err := Sync(r.Fremote, r.Flocal)
+	err := Sync(context.Background(), r.Fremote, r.Flocal)
 	require.NoError(t, err)
 
 	fstest.CheckItems(t, r.Flocal, file1)
@@ -446,7 +451,7 @@ func TestSyncAfterAddingAFile(t *testing.T) {
 	fstest.CheckItems(t, r.Fremote, file1)
 
 	accounting.Stats.ResetCounters()
-	err := Sync(r.Fremote, r.Flocal)
+	err := Sync(context.Background(), r.Fremote, r.Flocal)
 	require.NoError(t, err)
 	fstest.CheckItems(t, r.Flocal, file1, file2)
 	fstest.CheckItems(t, r.Fremote, file1, file2)
@@ -461,7 +466,7 @@ func TestSyncAfterChangingFilesSizeOnly(t *testing.T) {
 	fstest.CheckItems(t, r.Flocal, file2)
 
 	accounting.Stats.ResetCounters()
-	err := Sync(r.Fremote, r.Flocal)
+	err := Sync(context.Background(), r.Fremote, r.Flocal)
 	require.NoError(t, err)
 	fstest.CheckItems(t, r.Flocal, file2)
 	fstest.CheckItems(t, r.Fremote, file2)
@@ -484,7 +489,7 @@ func TestSyncAfterChangingContentsOnly(t *testing.T) {
 	fstest.CheckItems(t, r.Flocal, file2)
 
 	accounting.Stats.ResetCounters()
-	err := Sync(r.Fremote, r.Flocal)
+	err := Sync(context.Background(), r.Fremote, r.Flocal)
 	require.NoError(t, err)
 	fstest.CheckItems(t, r.Flocal, file2)
 	fstest.CheckItems(t, r.Fremote, file2)
@@ -500,7 +505,7 @@ func TestSyncAfterRemovingAFileAndAddingAFileDryRun(t *testing.T) {
 
 	fs.Config.DryRun = true
 	accounting.Stats.ResetCounters()
-	err := Sync(r.Fremote, r.Flocal)
+	err := Sync(context.Background(), r.Fremote, r.Flocal)
 	fs.Config.DryRun = false
 	require.NoError(t, err)
 
@@ -519,7 +524,7 @@ func TestSyncAfterRemovingAFileAndAddingAFile(t *testing.T) {
 	fstest.CheckItems(t, r.Flocal, file1, file3)
 
 	accounting.Stats.ResetCounters()
-	err := Sync(r.Fremote, r.Flocal)
+	err := Sync(context.Background(), r.Fremote, r.Flocal)
 	require.NoError(t, err)
 	fstest.CheckItems(t, r.Flocal, file1, file3)
 	fstest.CheckItems(t, r.Fremote, file1, file3)
@@ -532,8 +537,8 @@ func TestSyncAfterRemovingAFileAndAddingAFileSubDir(t *testing.T) {
 	file1 := r.WriteFile("a/potato2", "------------------------------------------------------------", t1)
 	file2 := r.WriteObject("b/potato", "SMALLER BUT SAME DATE", t2)
 	file3 := r.WriteBoth("c/non empty space", "AhHa!", t2)
-	require.NoError(t, operations.Mkdir(r.Fremote, "d"))
-	require.NoError(t, operations.Mkdir(r.Fremote, "d/e"))
+	require.NoError(t, operations.Mkdir(context.Background(), r.Fremote, "d"))
+	require.NoError(t, operations.Mkdir(context.Background(), r.Fremote, "d/e"))
 
 	fstest.CheckListingWithPrecision(
 		t,
@@ -565,7 +570,7 @@ func TestSyncAfterRemovingAFileAndAddingAFileSubDir(t *testing.T) {
 	)
 
 	accounting.Stats.ResetCounters()
-	err := Sync(r.Fremote, r.Flocal)
+	err := Sync(context.Background(), r.Fremote, r.Flocal)
 	require.NoError(t, err)
 
 	fstest.CheckListingWithPrecision(
@@ -603,7 +608,7 @@ func TestSyncAfterRemovingAFileAndAddingAFileSubDirWithErrors(t *testing.T) {
 	file1 := r.WriteFile("a/potato2", "------------------------------------------------------------", t1)
 	file2 := r.WriteObject("b/potato", "SMALLER BUT SAME DATE", t2)
 	file3 := r.WriteBoth("c/non empty space", "AhHa!", t2)
-	require.NoError(t, operations.Mkdir(r.Fremote, "d"))
+	require.NoError(t, operations.Mkdir(context.Background(), r.Fremote, "d"))
 
 	fstest.CheckListingWithPrecision(
 		t,
@@ -635,7 +640,7 @@ func TestSyncAfterRemovingAFileAndAddingAFileSubDirWithErrors(t *testing.T) {
 
 	accounting.Stats.ResetCounters()
 	fs.CountError(nil)
-	err := Sync(r.Fremote, r.Flocal)
+	err := Sync(context.Background(), r.Fremote, r.Flocal)
 	assert.Equal(t, fs.ErrorNotDeleting, err)
 
 	fstest.CheckListingWithPrecision(
@@ -712,7 +717,7 @@ func TestCopyDeleteBefore(t *testing.T) {
 	fstest.CheckItems(t, r.Flocal, file2)
 
 	accounting.Stats.ResetCounters()
-	err := CopyDir(r.Fremote, r.Flocal)
+	err := CopyDir(context.Background(), r.Fremote, r.Flocal)
 	require.NoError(t, err)
 
 	fstest.CheckItems(t, r.Fremote, file1, file2)
@@ -735,14 +740,14 @@ func TestSyncWithExclude(t *testing.T) {
 	}()
 
 	accounting.Stats.ResetCounters()
-	err := Sync(r.Fremote, r.Flocal)
+	err := Sync(context.Background(), r.Fremote, r.Flocal)
 	require.NoError(t, err)
 	fstest.CheckItems(t, r.Fremote, file2, file1)
 
 	// Now sync the other way round and check enormous doesn't get
 	// deleted as it is excluded from the sync
 	accounting.Stats.ResetCounters()
-	err = Sync(r.Flocal, r.Fremote)
+	err = Sync(context.Background(), r.Flocal, r.Fremote)
 	require.NoError(t, err)
 	fstest.CheckItems(t, r.Flocal, file2, file1, file3)
 }
@@ -765,18 +770,128 @@ func TestSyncWithExcludeAndDeleteExcluded(t *testing.T) {
 	}()
 
 	accounting.Stats.ResetCounters()
-	err := Sync(r.Fremote, r.Flocal)
+	err := Sync(context.Background(), r.Fremote, r.Flocal)
 	require.NoError(t, err)
 	fstest.CheckItems(t, r.Fremote, file2)
 
 	// Check sync the other way round to make sure enormous gets
 	// deleted even though it is excluded
 	accounting.Stats.ResetCounters()
-	err = Sync(r.Flocal, r.Fremote)
+	err = Sync(context.Background(), r.Flocal, r.Fremote)
 	require.NoError(t, err)
 	fstest.CheckItems(t, r.Flocal, file2)
 }
 
+// noListFs is an fs.Fs which returns an error if it is listed
+type noListFs struct {
+	fs.Fs
+}
+
+// List returns an error as noListFs shouldn't be listed
+func (f noListFs) List(ctx context.Context, dir string) (fs.DirEntries, error) {
+	return nil, errors.New("directory listed")
+}
+
+// Features returns no optional features so there is no ListR or ListP
+func (f noListFs) Features() *fs.Features {
+	return &fs.Features{}
+}
+
+// Test --files-from looks up the files rather than listing
+func TestSyncWithFilesFrom(t *testing.T) {
+	r := fstest.NewRun(t)
+	defer r.Finalise()
+	file1 := r.WriteFile("potato", "potato", t1)
+	file2 := r.WriteFile("sub dir/yam", "yam", t1)
+	r.WriteFile("carrot", "carrot", t1)
+	r.WriteFile("sub dir/parsnip", "parsnip", t1)
+	file3 := r.WriteObject("turnip", "not in the list", t1)
+	r.WriteObject("sub dir/yam", "old yam", t2)
+
+	oldActive := filter.Active
+	var err error
+	filter.Active, err = filter.NewFilter(nil)
+	require.NoError(t, err)
+	defer func() {
+		filter.Active = oldActive
+	}()
+	for _, remote := range []string{"potato", "sub dir/yam", "missing", "sub dir/missing"} {
+		require.NoError(t, filter.Active.AddFile(remote))
+	}
+
+	accounting.Stats.ResetCounters()
+	err = Sync(context.Background(), noListFs{r.Fremote}, noListFs{r.Flocal})
+	require.NoError(t, err)
+	fstest.CheckItems(t, r.Fremote, file1, file2, file3)
+	assert.Equal(t, int64(0), accounting.Stats.GetErrors())
+}
+
+// Test with per-directory gitignore style filter files
+func TestSyncWithIgnoreFile(t *testing.T) {
+	r := fstest.NewRun(t)
+	defer r.Finalise()
+	ignore1 := r.WriteFile(".rcloneignore", "*.o\n!keep.o\nbuild/\n/top.txt\n", t1)
+	ignore2 := r.WriteFile("sub/.rcloneignore", "!*.o\n*.txt\n", t1)
+	file1 := r.WriteFile("main.c", "main", t1)
+	r.WriteFile("main.o", "object", t1)
+	file2 := r.WriteFile("keep.o", "keep", t1)
+	r.WriteFile("top.txt", "top", t1)
+	r.WriteFile("build/out", "output", t1)
+	r.WriteFile("sub/build/out", "output", t1)
+	file3 := r.WriteFile("sub/top.txt.c", "top", t1)
+	file4 := r.WriteFile("sub/lib.o", "lib", t1)
+	r.WriteFile("sub/notes.txt", "notes", t1)
+	fstest.CheckItems(t, r.Fremote)
+
+	oldActive := filter.Active
+	opt := filter.DefaultOpt
+	opt.IgnoreFile = ".rcloneignore"
+	var err error
+	filter.Active, err = filter.NewFilter(&opt)
+	require.NoError(t, err)
+	defer func() {
+		filter.Active = oldActive
+	}()
+
+	accounting.Stats.ResetCounters()
+	err = Sync(context.Background(), r.Fremote, r.Flocal)
+	require.NoError(t, err)
+	fstest.CheckItems(t, r.Fremote, ignore1, ignore2, file1, file2, file3, file4)
+}
+
+// Test the filter files are read from the source only so files in
+// the destination they ignore aren't deleted
+func TestSyncWithIgnoreFileInSourceOnly(t *testing.T) {
+	r := fstest.NewRun(t)
+	defer r.Finalise()
+	ignore := r.WriteFile(".rcloneignore", "node_modules/\n", t1)
+	r.WriteFile("node_modules/m.js", "source", t1)
+	file1 := r.WriteObject("node_modules/m.js", "destination", t2)
+	fstest.CheckItems(t, r.Fremote, file1)
+
+	oldActive := filter.Active
+	opt := filter.DefaultOpt
+	opt.IgnoreFile = ".rcloneignore"
+	var err error
+	filter.Active, err = filter.NewFilter(&opt)
+	require.NoError(t, err)
+	defer func() {
+		filter.Active = oldActive
+	}()
+
+	accounting.Stats.ResetCounters()
+	err = Sync(context.Background(), r.Fremote, r.Flocal)
+	require.NoError(t, err)
+	fstest.CheckItems(t, r.Fremote, ignore, file1)
+
+	// Only deleted with --delete-excluded
+	filter.Active.Opt.DeleteExcluded = true
+	accounting.Stats.ResetCounters()
+	err = Sync(context.Background(), r.Fremote, r.Flocal)
+	require.NoError(t, err)
+	fstest.CheckItems(t, r.Fremote, ignore)
+}
+
 // Test with UpdateOlder set
 func TestSyncWithUpdateOlder(t *testing.T) {
 	if fs.Config.ModifyWindow == fs.ModTimeNotSupported {
@@ -807,7 +922,7 @@ func TestSyncWithUpdateOlder(t *testing.T) {
 	}()
 
 	accounting.Stats.ResetCounters()
-	err := Sync(r.Fremote, r.Flocal)
+	err := Sync(context.Background(), r.Fremote, r.Flocal)
 	require.NoError(t, err)
 	fstest.CheckItems(t, r.Fremote, oneO, twoF, threeO, fourF, fiveF)
 }
@@ -831,7 +946,7 @@ func TestSyncWithTrackRenames(t *testing.T) {
 	f2 := r.WriteFile("yam", "Yam Content", t2)
 
 	accounting.Stats.ResetCounters()
-	require.NoError(t, Sync(r.Fremote, r.Flocal))
+	require.NoError(t, Sync(context.Background(), r.Fremote, r.Flocal))
 
 	fstest.CheckItems(t, r.Fremote, f1, f2)
 	fstest.CheckItems(t, r.Flocal, f1, f2)
@@ -840,7 +955,7 @@ func TestSyncWithTrackRenames(t *testing.T) {
 	f2 = r.RenameFile(f2, "yaml")
 
 	accounting.Stats.ResetCounters()
-	require.NoError(t, Sync(r.Fremote, r.Flocal))
+	require.NoError(t, Sync(context.Background(), r.Fremote, r.Flocal))
 
 	fstest.CheckItems(t, r.Fremote, f1, f2)
 
@@ -851,6 +966,318 @@ func TestSyncWithTrackRenames(t *testing.T) {
 	}
 }
 
+// Test --max-transfer and --max-duration with the different cutoff modes
+func testSyncCutoff(t *testing.T, maxTransfer fs.SizeSuffix, maxDuration time.Duration, cutoffMode fs.CutoffMode, wantTransfers int64, wantFatal bool) {
+	r := fstest.NewRun(t)
+	defer r.Finalise()
+	r.Mkdir(r.Fremote)
+
+	oldTransfers := fs.Config.Transfers
+	fs.Config.Transfers = 1
+	fs.Config.MaxTransfer = maxTransfer
+	fs.Config.MaxDuration = maxDuration
+	fs.Config.CutoffMode = cutoffMode
+	defer func() {
+		fs.Config.Transfers = oldTransfers
+		fs.Config.MaxTransfer = -1
+		fs.Config.MaxDuration = 0
+		fs.Config.CutoffMode = fs.CutoffModeDefault
+	}()
+
+	content := strings.Repeat("x", 100)
+	r.WriteFile("file1", content, t1)
+	r.WriteFile("file2", content, t1)
+	r.WriteFile("file3", content, t1)
+
+	accounting.Stats.ResetCounters()
+	err := CopyDir(context.Background(), r.Fremote, r.Flocal)
+	require.Error(t, err)
+	assert.True(t, accounting.IsCutoffError(err), err.Error())
+	assert.Equal(t, wantFatal, fserrors.IsFatalError(err), err.Error())
+	if wantTransfers >= 0 {
+		assert.Equal(t, wantTransfers, accounting.Stats.GetTransfers())
+	}
+	objects, _, err := operations.Count(context.Background(), r.Fremote)
+	require.NoError(t, err)
+	assert.True(t, objects < 3, "expecting some files not to be transferred")
+}
+
+func TestSyncMaxTransferHard(t *testing.T) {
+	testSyncCutoff(t, 150, 0, fs.CutoffModeHard, -1, true)
+}
+
+func TestSyncMaxTransferSoft(t *testing.T) {
+	testSyncCutoff(t, 150, 0, fs.CutoffModeSoft, 2, false)
+}
+
+func TestSyncMaxTransferCautious(t *testing.T) {
+	testSyncCutoff(t, 150, 0, fs.CutoffModeCautious, 1, false)
+}
+
+func TestSyncMaxDurationHard(t *testing.T) {
+	testSyncCutoff(t, -1, time.Nanosecond, fs.CutoffModeHard, 0, true)
+}
+
+func TestSyncMaxDurationSoft(t *testing.T) {
+	testSyncCutoff(t, -1, time.Nanosecond, fs.CutoffModeSoft, 0, false)
+}
+
+// Test --check-first
+func TestSyncCheckFirst(t *testing.T) {
+	r := fstest.NewRun(t)
+	defer r.Finalise()
+	file1 := r.WriteFile("file1", "hello", t1)
+	file2 := r.WriteFile("sub dir/file2", "hello world", t2)
+	file3 := r.WriteObject("file1", "hello!", t2)
+	fstest.CheckItems(t, r.Fremote, file3)
+
+	fs.Config.CheckFirst = true
+	defer func() { fs.Config.CheckFirst = false }()
+
+	accounting.Stats.ResetCounters()
+	err := Sync(context.Background(), r.Fremote, r.Flocal)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), accounting.Stats.GetTransfers())
+
+	fstest.CheckItems(t, r.Flocal, file1, file2)
+	fstest.CheckItems(t, r.Fremote, file1, file2)
+}
+
+// Test a sync where the directory listings are sorted on disk
+func TestSyncListCutoff(t *testing.T) {
+	r := fstest.NewRun(t)
+	defer r.Finalise()
+	file1 := r.WriteFile("file1", "hello", t1)
+	file2 := r.WriteFile("file2", "changed", t2)
+	file3 := r.WriteFile("file3", "new", t1)
+	file4 := r.WriteFile("sub dir/file4", "sub", t2)
+	r.WriteObject("file1", "hello", t1)
+	r.WriteObject("file2", "old", t1)
+	r.WriteObject("file5", "delete me", t1)
+	r.WriteObject("file6", "delete me too", t1)
+
+	oldListCutoff := fs.Config.ListCutoff
+	fs.Config.ListCutoff = 2
+	defer func() { fs.Config.ListCutoff = oldListCutoff }()
+
+	accounting.Stats.ResetCounters()
+	err := Sync(context.Background(), r.Fremote, r.Flocal)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), accounting.Stats.GetTransfers())
+
+	fstest.CheckItems(t, r.Flocal, file1, file2, file3, file4)
+	fstest.CheckItems(t, r.Fremote, file1, file2, file3, file4)
+}
+
+// Test --order-by with --check-first so the order of all the
+// transfers is known, using --max-transfer to see which went first
+func testSyncOrderBy(t *testing.T, orderBy string, want ...string) {
+	r := fstest.NewRun(t)
+	defer r.Finalise()
+	r.Mkdir(r.Fremote)
+
+	oldTransfers := fs.Config.Transfers
+	fs.Config.Transfers = 1
+	fs.Config.CheckFirst = true
+	fs.Config.OrderBy = orderBy
+	fs.Config.MaxTransfer = 150
+	fs.Config.CutoffMode = fs.CutoffModeCautious
+	defer func() {
+		fs.Config.Transfers = oldTransfers
+		fs.Config.CheckFirst = false
+		fs.Config.OrderBy = ""
+		fs.Config.MaxTransfer = -1
+		fs.Config.CutoffMode = fs.CutoffModeDefault
+	}()
+
+	items := map[string]fstest.Item{
+		"small":  r.WriteFile("small", strings.Repeat("x", 10), t1),
+		"medium": r.WriteFile("medium", strings.Repeat("x", 100), t2),
+		"large":  r.WriteFile("large", strings.Repeat("x", 1000), t3),
+	}
+
+	accounting.Stats.ResetCounters()
+	err := CopyDir(context.Background(), r.Fremote, r.Flocal)
+	require.Error(t, err)
+	assert.True(t, accounting.IsCutoffError(err), err.Error())
+
+	var wantItems []fstest.Item
+	for _, name := range want {
+		wantItems = append(wantItems, items[name])
+	}
+	fstest.CheckItems(t, r.Fremote, wantItems...)
+}
+
+func TestSyncOrderBySizeAscending(t *testing.T) {
+	testSyncOrderBy(t, "size,ascending", "small", "medium")
+}
+
+func TestSyncOrderBySizeDescending(t *testing.T) {
+	testSyncOrderBy(t, "size,descending")
+}
+
+func TestSyncOrderByModTime(t *testing.T) {
+	testSyncOrderBy(t, "modtime", "small", "medium")
+}
+
+func TestSyncOrderByName(t *testing.T) {
+	testSyncOrderBy(t, "name")
+}
+
+func TestSyncOrderByBad(t *testing.T) {
+	r := fstest.NewRun(t)
+	defer r.Finalise()
+	r.WriteFile("file1", "hello", t1)
+
+	fs.Config.OrderBy = "potato"
+	defer func() { fs.Config.OrderBy = "" }()
+
+	err := CopyDir(context.Background(), r.Fremote, r.Flocal)
+	require.Error(t, err)
+	assert.True(t, fserrors.IsFatalError(err), err.Error())
+}
+
+// Test with TrackRenames set using strategies which don't need a hash
+func TestSyncWithTrackRenamesStrategy(t *testing.T) {
+	for _, strategy := range []string{"modtime", "leaf", "modtime,leaf", "hash,modtime,leaf"} {
+		t.Run(strategy, func(t *testing.T) {
+			r := fstest.NewRun(t)
+			defer r.Finalise()
+
+			fs.Config.TrackRenames = true
+			fs.Config.TrackRenamesStrategy = strategy
+			defer func() {
+				fs.Config.TrackRenames = false
+				fs.Config.TrackRenamesStrategy = "hash"
+			}()
+
+			haveHash := r.Fremote.Hashes().Overlap(r.Flocal.Hashes()).GetOne() != hash.None
+			canTrackRenames := operations.CanServerSideMove(r.Fremote) && (haveHash || !strings.Contains(strategy, "hash"))
+			t.Logf("Can track renames: %v", canTrackRenames)
+
+			f1 := r.WriteFile("potato", "Potato Content", t1)
+			f2 := r.WriteFile("yam", "Yam Content", t2)
+
+			accounting.Stats.ResetCounters()
+			require.NoError(t, Sync(context.Background(), r.Fremote, r.Flocal))
+			fstest.CheckItems(t, r.Fremote, f1, f2)
+
+			// Move the file into a directory so the leaf is unchanged
+			require.NoError(t, os.Mkdir(r.LocalName+"/sub dir", 0777))
+			f2 = r.RenameFile(f2, "sub dir/yam")
+
+			accounting.Stats.ResetCounters()
+			require.NoError(t, Sync(context.Background(), r.Fremote, r.Flocal))
+			fstest.CheckItems(t, r.Fremote, f1, f2)
+
+			if canTrackRenames {
+				assert.Equal(t, int64(0), accounting.Stats.GetTransfers())
+			} else {
+				assert.Equal(t, int64(1), accounting.Stats.GetTransfers())
+			}
+		})
+	}
+}
+
+// Test with TrackRenames set that a renamed directory is renamed
+// with a single DirMove
+func TestSyncWithTrackRenamesDirectory(t *testing.T) {
+	r := fstest.NewRun(t)
+	defer r.Finalise()
+
+	if r.Fremote.Features().DirMove == nil {
+		t.Skip("Skipping test as remote does not support DirMove")
+	}
+
+	fs.Config.TrackRenames = true
+	fs.Config.TrackRenamesStrategy = "modtime"
+	defer func() {
+		fs.Config.TrackRenames = false
+		fs.Config.TrackRenamesStrategy = "hash"
+	}()
+
+	f1 := r.WriteFile("photos/2018/one", "one", t1)
+	f2 := r.WriteFile("photos/2018/sub/two", "two", t2)
+	f3 := r.WriteFile("photos/2018/sub/three", "three", t3)
+	f4 := r.WriteFile("other", "other", t1)
+
+	accounting.Stats.ResetCounters()
+	require.NoError(t, Sync(context.Background(), r.Fremote, r.Flocal))
+	fstest.CheckItems(t, r.Fremote, f1, f2, f3, f4)
+
+	// Remember the remote directory if it is local to check it is renamed
+	oldDir, statErr := os.Stat(r.FremoteName + "/photos/2018")
+
+	// Rename the directory locally
+	require.NoError(t, os.Rename(r.LocalName+"/photos/2018", r.LocalName+"/photos/2018-holiday"))
+	for _, item := range []*fstest.Item{&f1, &f2, &f3} {
+		item.Path = strings.Replace(item.Path, "photos/2018/", "photos/2018-holiday/", 1)
+	}
+	fstest.CheckItems(t, r.Flocal, f1, f2, f3, f4)
+
+	accounting.Stats.ResetCounters()
+	require.NoError(t, Sync(context.Background(), r.Fremote, r.Flocal))
+	fstest.CheckItems(t, r.Fremote, f1, f2, f3, f4)
+	assert.Equal(t, int64(0), accounting.Stats.GetTransfers())
+
+	if statErr == nil {
+		newDir, err := os.Stat(r.FremoteName + "/photos/2018-holiday")
+		require.NoError(t, err)
+		assert.True(t, os.SameFile(oldDir, newDir), "directory wasn't renamed with DirMove")
+	}
+}
+
+// Test with TrackRenames set that a directory present on both sides
+// isn't treated as the target of a directory rename
+func TestSyncWithTrackRenamesDirectoryInBoth(t *testing.T) {
+	r := fstest.NewRun(t)
+	defer r.Finalise()
+
+	if r.Fremote.Features().DirMove == nil {
+		t.Skip("Skipping test as remote does not support DirMove")
+	}
+
+	fs.Config.TrackRenames = true
+	fs.Config.TrackRenamesStrategy = "modtime"
+	defer func() {
+		fs.Config.TrackRenames = false
+		fs.Config.TrackRenamesStrategy = "hash"
+	}()
+
+	r.WriteBoth("photos/keep", "keep", t1)
+	r.WriteFile("photos/new", "new", t2)
+	r.WriteObject("archive/new", "new", t2)
+
+	planOut, cleanup := setPlanOut(t)
+	defer cleanup()
+	require.NoError(t, Sync(context.Background(), r.Fremote, r.Flocal))
+
+	plan, err := LoadPlan(planOut)
+	require.NoError(t, err)
+	assert.Equal(t, 0, planActions(plan)[PlanRenameDir])
+}
+
+func TestParseTrackRenamesStrategy(t *testing.T) {
+	for _, test := range []struct {
+		in      string
+		want    trackRenamesStrategy
+		wantErr bool
+	}{
+		{"", 0, false},
+		{"size", 0, false},
+		{"hash", trackRenamesStrategyHash, false},
+		{"modtime,leaf", trackRenamesStrategyModTime | trackRenamesStrategyLeaf, false},
+		{"Hash, ModTime, Size", trackRenamesStrategyHash | trackRenamesStrategyModTime, false},
+		{"potato", 0, true},
+	} {
+		got, err := parseTrackRenamesStrategy(test.in)
+		assert.Equal(t, test.wantErr, err != nil, test.in)
+		if !test.wantErr {
+			assert.Equal(t, test.want, got, test.in)
+		}
+	}
+}
+
 // Test a server side move if possible, or the backup path if not
 func testServerSideMove(t *testing.T, r *fstest.Run, withFilter, testDeleteEmptyDirs bool) {
 	FremoteMove, _, finaliseMove, err := fstest.RandomRemote(*fstest.RemoteName, *fstest.SubDir)
@@ -862,7 +1289,7 @@ func testServerSideMove(t *testing.T, r *fstest.Run, withFilter, testDeleteEmpty
 	file3u := r.WriteBoth("potato3", "------------------------------------------------------------ UPDATED", t2)
 
 	if testDeleteEmptyDirs {
-		err := operations.Mkdir(r.Fremote, "tomatoDir")
+		err := operations.Mkdir(context.Background(), r.Fremote, "tomatoDir")
 		require.NoError(t, err)
 	}
 
@@ -877,7 +1304,7 @@ func testServerSideMove(t *testing.T, r *fstest.Run, withFilter, testDeleteEmpty
 
 	// Do server side move
 	accounting.Stats.ResetCounters()
-	err = MoveDir(FremoteMove, r.Fremote, testDeleteEmptyDirs)
+	err = MoveDir(context.Background(), FremoteMove, r.Fremote, testDeleteEmptyDirs)
 	require.NoError(t, err)
 
 	if withFilter {
@@ -898,13 +1325,13 @@ func testServerSideMove(t *testing.T, r *fstest.Run, withFilter, testDeleteEmpty
 	defer finaliseMove2()
 
 	if testDeleteEmptyDirs {
-		err := operations.Mkdir(FremoteMove, "tomatoDir")
+		err := operations.Mkdir(context.Background(), FremoteMove, "tomatoDir")
 		require.NoError(t, err)
 	}
 
 	// Move it back to a new empty remote, dst does not exist this time
 	accounting.Stats.ResetCounters()
-	err = MoveDir(FremoteMove2, FremoteMove, testDeleteEmptyDirs)
+	err = MoveDir(context.Background(), FremoteMove2, FremoteMove, testDeleteEmptyDirs)
 	require.NoError(t, err)
 
 	if withFilter {
@@ -964,7 +1391,7 @@ func TestServerSideMoveOverlap(t *testing.T) {
 	fstest.CheckItems(t, r.Fremote, file1)
 
 	// Subdir move with no filters should return ErrorCantMoveOverlapping
-	err = MoveDir(FremoteMove, r.Fremote, false)
+	err = MoveDir(context.Background(), FremoteMove, r.Fremote, false)
 	assert.EqualError(t, err, fs.ErrorCantMoveOverlapping.Error())
 
 	// Now try with a filter which should also fail with ErrorCantMoveOverlapping
@@ -972,7 +1399,7 @@ func TestServerSideMoveOverlap(t *testing.T) {
 	defer func() {
 		filter.Active.Opt.MinSize = -1
 	}()
-	err = MoveDir(FremoteMove, r.Fremote, false)
+	err = MoveDir(context.Background(), FremoteMove, r.Fremote, false)
 	assert.EqualError(t, err, fs.ErrorCantMoveOverlapping.Error())
 }
 
@@ -1008,7 +1435,7 @@ func testSyncBackupDir(t *testing.T, suffix string) {
 	require.NoError(t, err)
 
 	accounting.Stats.ResetCounters()
-	err = Sync(fdst, r.Flocal)
+	err = Sync(context.Background(), fdst, r.Flocal)
 	require.NoError(t, err)
 
 	// one should be moved to the backup dir and the new one installed
@@ -1029,7 +1456,7 @@ func testSyncBackupDir(t *testing.T, suffix string) {
 	// This should delete three and overwrite one again, checking
 	// the files got overwritten correctly in backup-dir
 	accounting.Stats.ResetCounters()
-	err = Sync(fdst, r.Flocal)
+	err = Sync(context.Background(), fdst, r.Flocal)
 	require.NoError(t, err)
 
 	// one should be moved to the backup dir and the new one installed
@@ -1044,6 +1471,187 @@ func testSyncBackupDir(t *testing.T, suffix string) {
 func TestSyncBackupDir(t *testing.T)           { testSyncBackupDir(t, "") }
 func TestSyncBackupDirWithSuffix(t *testing.T) { testSyncBackupDir(t, ".bak") }
 
+// Test with --backup-versions and --backup-keep-last
+func TestSyncBackupVersions(t *testing.T) {
+	r := fstest.NewRun(t)
+	defer r.Finalise()
+	ctx := context.Background()
+
+	if !operations.CanServerSideMove(r.Fremote) {
+		t.Skip("Skipping test as remote does not support server side move")
+	}
+	r.Mkdir(r.Fremote)
+
+	fs.Config.BackupDir = r.FremoteName + "/backup"
+	fs.Config.BackupVersions = true
+	fs.Config.BackupKeepLast = 2
+	defer func() {
+		fs.Config.BackupDir = ""
+		fs.Config.BackupVersions = false
+		fs.Config.BackupKeepLast = 0
+	}()
+
+	fdst, err := fs.NewFs(r.FremoteName + "/dst")
+	require.NoError(t, err)
+	backupDir, err := fs.NewFs(fs.Config.BackupDir)
+	require.NoError(t, err)
+
+	// Sync four different versions of the file
+	r.WriteObject("dst/one", "1", t1)
+	for i, content := range []string{"22", "333", "4444"} {
+		r.WriteFile("one", content, t2)
+		accounting.Stats.ResetCounters()
+		require.NoError(t, Sync(ctx, fdst, r.Flocal))
+		versions, err := operations.ListBackupVersions(ctx, backupDir, "one")
+		require.NoError(t, err)
+		assert.Equal(t, i+1 >= 2, len(versions) == 2, "number of versions")
+		// Make sure the versions get different timestamps
+		time.Sleep(2 * time.Millisecond)
+	}
+
+	// Only the last two versions should be kept, newest first
+	versions, err := operations.ListBackupVersions(ctx, backupDir, "")
+	require.NoError(t, err)
+	require.Equal(t, 2, len(versions))
+	for i, size := range []int64{3, 2} {
+		assert.Equal(t, "one", versions[i].Remote)
+		assert.Equal(t, size, versions[i].Object.Size())
+	}
+
+	// A version is stamped when it stopped being current so the
+	// version current at t is the oldest version stamped after t
+	for _, test := range []struct {
+		t    time.Time
+		want *operations.BackupVersion
+	}{
+		{versions[1].Version.Add(-time.Second), &versions[1]},
+		{versions[1].Version.Add(-time.Nanosecond), &versions[1]},
+		{versions[1].Version, &versions[0]},
+		{versions[0].Version.Add(-time.Nanosecond), &versions[0]},
+		{versions[0].Version, nil}, // the live file
+		{versions[0].Version.Add(time.Second), nil},
+		{time.Time{}, &versions[0]}, // the newest
+	} {
+		v, err := operations.FindBackupVersion(ctx, backupDir, "one", test.t)
+		require.NoError(t, err)
+		if test.want == nil {
+			assert.Nil(t, v, test.t)
+		} else if assert.NotNil(t, v, test.t) {
+			assert.Equal(t, test.want.Object.Remote(), v.Object.Remote(), test.t)
+		}
+	}
+	_, err = operations.FindBackupVersion(ctx, backupDir, "two", time.Time{})
+	assert.Equal(t, fs.ErrorObjectNotFound, err)
+}
+
+// Test with --compare-dest
+func TestSyncCompareDest(t *testing.T) {
+	r := fstest.NewRun(t)
+	defer r.Finalise()
+
+	fs.Config.CompareDest = []string{r.FremoteName + "/old", r.FremoteName + "/older"}
+	defer func() {
+		fs.Config.CompareDest = nil
+	}()
+
+	// one is identical in the first reference, two in the second,
+	// three differs and four is new
+	file1 := r.WriteObject("old/one", "one", t1)
+	file2 := r.WriteObject("older/two", "two", t1)
+	file3 := r.WriteObject("old/three", "three", t1)
+	r.WriteFile("one", "one", t1)
+	r.WriteFile("two", "two", t1)
+	file3a := r.WriteFile("three", "threeA", t2)
+	file4 := r.WriteFile("four", "four", t2)
+
+	fdst, err := fs.NewFs(r.FremoteName + "/dst")
+	require.NoError(t, err)
+
+	accounting.Stats.ResetCounters()
+	err = CopyDir(context.Background(), fdst, r.Flocal)
+	require.NoError(t, err)
+
+	file3a.Path = "dst/three"
+	file4.Path = "dst/four"
+	fstest.CheckItems(t, r.Fremote, file1, file2, file3, file3a, file4)
+}
+
+// Test with --copy-dest
+func TestSyncCopyDest(t *testing.T) {
+	r := fstest.NewRun(t)
+	defer r.Finalise()
+
+	if r.Fremote.Features().Copy == nil {
+		t.Skip("Skipping test as remote does not support server side copy")
+	}
+
+	fs.Config.CopyDest = []string{r.FremoteName + "/old"}
+	defer func() {
+		fs.Config.CopyDest = nil
+	}()
+
+	// one is identical in the reference, two differs and three is new
+	file1 := r.WriteObject("old/one", "one", t1)
+	file2 := r.WriteObject("old/two", "two", t1)
+	file1a := r.WriteFile("one", "one", t1)
+	file2a := r.WriteFile("two", "twoA", t2)
+	file3 := r.WriteFile("three", "three", t2)
+
+	fdst, err := fs.NewFs(r.FremoteName + "/dst")
+	require.NoError(t, err)
+
+	accounting.Stats.ResetCounters()
+	err = CopyDir(context.Background(), fdst, r.Flocal)
+	require.NoError(t, err)
+
+	file1a.Path = "dst/one"
+	file2a.Path = "dst/two"
+	file3.Path = "dst/three"
+	fstest.CheckItems(t, r.Fremote, file1, file2, file1a, file2a, file3)
+	// Only two and three should have been uploaded
+	assert.Equal(t, int64(2), accounting.Stats.GetTransfers())
+}
+
+// Test that --compare-dest and --copy-dest can't be used together
+func TestSyncCompareDestWithCopyDest(t *testing.T) {
+	r := fstest.NewRun(t)
+	defer r.Finalise()
+
+	fs.Config.CompareDest = []string{r.FremoteName + "/compare"}
+	fs.Config.CopyDest = []string{r.FremoteName + "/copy"}
+	defer func() {
+		fs.Config.CompareDest = nil
+		fs.Config.CopyDest = nil
+	}()
+
+	fdst, err := fs.NewFs(r.FremoteName + "/dst")
+	require.NoError(t, err)
+
+	err = CopyDir(context.Background(), fdst, r.Flocal)
+	require.Error(t, err)
+	assert.True(t, fserrors.IsFatalError(err))
+	assert.Contains(t, err.Error(), "can't use --compare-dest with --copy-dest")
+}
+
+// Test the reference directories mustn't overlap the destination
+func TestSyncCompareDestOverlap(t *testing.T) {
+	r := fstest.NewRun(t)
+	defer r.Finalise()
+	r.Mkdir(r.Fremote)
+
+	fs.Config.CompareDest = []string{r.FremoteName + "/dst/old"}
+	defer func() {
+		fs.Config.CompareDest = nil
+	}()
+
+	fdst, err := fs.NewFs(r.FremoteName + "/dst")
+	require.NoError(t, err)
+
+	err = CopyDir(context.Background(), fdst, r.Flocal)
+	require.Error(t, err)
+	assert.True(t, fserrors.IsFatalError(err))
+}
+
 // Check we can sync two files with differing UTF-8 representations
 func TestSyncUTFNorm(t *testing.T) {
 	if runtime.GOOS == "darwin" {
@@ -1066,7 +1674,7 @@ func TestSyncUTFNorm(t *testing.T) {
 	fstest.CheckItems(t, r.Fremote, file2)
 
 	accounting.Stats.ResetCounters()
-	err := Sync(r.Fremote, r.Flocal)
+	err := Sync(context.Background(), r.Fremote, r.Flocal)
 	require.NoError(t, err)
 
 	// We should have transferred exactly one file, but kept the
@@ -1092,7 +1700,7 @@ func TestSyncImmutable(t *testing.T) {
 
 	// Should succeed
 	accounting.Stats.ResetCounters()
-	err := Sync(r.Fremote, r.Flocal)
+	err := Sync(context.Background(), r.Fremote, r.Flocal)
 	require.NoError(t, err)
 	fstest.CheckItems(t, r.Flocal, file1)
 	fstest.CheckItems(t, r.Fremote, file1)
@@ -1104,7 +1712,7 @@ func TestSyncImmutable(t *testing.T) {
 
 	// Should fail with ErrorImmutableModified and not modify local or remote files
 	accounting.Stats.ResetCounters()
-	err = Sync(r.Fremote, r.Flocal)
+	err = Sync(context.Background(), r.Fremote, r.Flocal)
 	assert.EqualError(t, err, fs.ErrorImmutableModified.Error())
 	fstest.CheckItems(t, r.Flocal, file2)
 	fstest.CheckItems(t, r.Fremote, file1)
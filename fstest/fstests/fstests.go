@@ -4,6 +4,7 @@ package fstests
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"io"
 	"io/ioutil"
@@ -71,7 +72,7 @@ func findObject(t *testing.T, f fs.Fs, Name string) fs.Object {
 	var obj fs.Object
 	var err error
 	for i := 1; i <= *fstest.ListRetries; i++ {
-		obj, err = f.NewObject(Name)
+		obj, err = f.NewObject(context.Background(), Name)
 		if err == nil {
 			break
 		}
@@ -94,7 +95,7 @@ again:
 
 	file.Size = int64(buf.Len())
 	obji := object.NewStaticObjectInfo(file.Path, file.ModTime, file.Size, true, nil, nil)
-	obj, err := f.Put(in, obji)
+	obj, err := f.Put(context.Background(), in, obji)
 	if err != nil {
 		// Retry if err returned a retry error
 		if fserrors.IsRetryError(err) && tries < maxTries {
@@ -127,7 +128,7 @@ func (er errorReader) Read(p []byte) (n int, err error) {
 // read the contents of an object as a string
 func readObject(t *testing.T, obj fs.Object, limit int64, options ...fs.OpenOption) string {
 	what := fmt.Sprintf("readObject(%q) limit=%d, options=%+v", obj, limit, options)
-	in, err := obj.Open(options...)
+	in, err := obj.Open(context.Background(), options...)
 	require.NoError(t, err, what)
 	var r io.Reader = in
 	if limit >= 0 {
@@ -171,6 +172,7 @@ func Run(t *testing.T, opt *Opt) {
 			WinPath: `hello_ sausage/êé/Hello, 世界/ _ ' @ _ _ & _ + ≠/z.txt`,
 		}
 		isLocalRemote bool
+		ctx           = context.Background()
 	)
 
 	// Make the Fs we are testing with, initialising the global variables
@@ -240,7 +242,7 @@ func Run(t *testing.T, opt *Opt) {
 
 		skipIfNotOk(t)
 
-		err = remote.Mkdir("")
+		err = remote.Mkdir(ctx, "")
 		require.NoError(t, err)
 		fstest.CheckListing(t, remote, []fstest.Item{})
 	})
@@ -279,14 +281,14 @@ func Run(t *testing.T, opt *Opt) {
 	// TestFsRmdirEmpty tests deleting an empty directory
 	t.Run("TestFsRmdirEmpty", func(t *testing.T) {
 		skipIfNotOk(t)
-		err := remote.Rmdir("")
+		err := remote.Rmdir(ctx, "")
 		require.NoError(t, err)
 	})
 
 	// TestFsRmdirNotFound tests deleting a non existent directory
 	t.Run("TestFsRmdirNotFound", func(t *testing.T) {
 		skipIfNotOk(t)
-		err := remote.Rmdir("")
+		err := remote.Rmdir(ctx, "")
 		assert.Error(t, err, "Expecting error on Rmdir non existent")
 	})
 
@@ -299,11 +301,11 @@ func Run(t *testing.T, opt *Opt) {
 		// (eg azure blob)
 		newFs(t)
 
-		err := remote.Mkdir("")
+		err := remote.Mkdir(ctx, "")
 		require.NoError(t, err)
 		fstest.CheckListing(t, remote, []fstest.Item{})
 
-		err = remote.Mkdir("")
+		err = remote.Mkdir(ctx, "")
 		require.NoError(t, err)
 	})
 
@@ -311,15 +313,15 @@ func Run(t *testing.T, opt *Opt) {
 	t.Run("TestFsMkdirRmdirSubdir", func(t *testing.T) {
 		skipIfNotOk(t)
 		dir := "dir/subdir"
-		err := operations.Mkdir(remote, dir)
+		err := operations.Mkdir(ctx, remote, dir)
 		require.NoError(t, err)
 		fstest.CheckListingWithPrecision(t, remote, []fstest.Item{}, []string{"dir", "dir/subdir"}, fs.Config.ModifyWindow)
 
-		err = operations.Rmdir(remote, dir)
+		err = operations.Rmdir(ctx, remote, dir)
 		require.NoError(t, err)
 		fstest.CheckListingWithPrecision(t, remote, []fstest.Item{}, []string{"dir"}, fs.Config.ModifyWindow)
 
-		err = operations.Rmdir(remote, "dir")
+		err = operations.Rmdir(ctx, remote, "dir")
 		require.NoError(t, err)
 		fstest.CheckListingWithPrecision(t, remote, []fstest.Item{}, []string{}, fs.Config.ModifyWindow)
 	})
@@ -333,7 +335,7 @@ func Run(t *testing.T, opt *Opt) {
 	// TestFsListDirEmpty tests listing the directories from an empty directory
 	TestFsListDirEmpty := func(t *testing.T) {
 		skipIfNotOk(t)
-		objs, dirs, err := walk.GetAll(remote, "", true, 1)
+		objs, dirs, err := walk.GetAll(ctx, remote, "", true, 1)
 		require.NoError(t, err)
 		assert.Equal(t, []string{}, objsToNames(objs))
 		assert.Equal(t, []string{}, dirsToNames(dirs))
@@ -350,11 +352,11 @@ func Run(t *testing.T, opt *Opt) {
 	t.Run("TestFsNewObjectNotFound", func(t *testing.T) {
 		skipIfNotOk(t)
 		// Object in an existing directory
-		o, err := remote.NewObject("potato")
+		o, err := remote.NewObject(ctx, "potato")
 		assert.Nil(t, o)
 		assert.Equal(t, fs.ErrorObjectNotFound, err)
 		// Now try an object in a non existing directory
-		o, err = remote.NewObject("directory/not/found/potato")
+		o, err = remote.NewObject(ctx, "directory/not/found/potato")
 		assert.Nil(t, o)
 		assert.Equal(t, fs.ErrorObjectNotFound, err)
 	})
@@ -379,11 +381,11 @@ func Run(t *testing.T, opt *Opt) {
 		in := io.MultiReader(buf, er)
 
 		obji := object.NewStaticObjectInfo(file2.Path, file2.ModTime, 100, true, nil, nil)
-		_, err := remote.Put(in, obji)
+		_, err := remote.Put(ctx, in, obji)
 		// assert.Nil(t, obj) - FIXME some remotes return the object even on nil
 		assert.NotNil(t, err)
 
-		obj, err := remote.NewObject(file2.Path)
+		obj, err := remote.NewObject(ctx, file2.Path)
 		assert.Nil(t, obj)
 		assert.Equal(t, fs.ErrorObjectNotFound, err)
 	})
@@ -408,9 +410,9 @@ func Run(t *testing.T, opt *Opt) {
 		list := func(dir string, expectedDirNames, expectedObjNames []string) {
 			var objNames, dirNames []string
 			for i := 1; i <= *fstest.ListRetries; i++ {
-				objs, dirs, err := walk.GetAll(remote, dir, true, 1)
+				objs, dirs, err := walk.GetAll(ctx, remote, dir, true, 1)
 				if errors.Cause(err) == fs.ErrorDirNotFound {
-					objs, dirs, err = walk.GetAll(remote, winPath(dir), true, 1)
+					objs, dirs, err = walk.GetAll(ctx, remote, winPath(dir), true, 1)
 				}
 				require.NoError(t, err)
 				objNames = objsToNames(objs)
@@ -458,7 +460,7 @@ func Run(t *testing.T, opt *Opt) {
 		skipIfNotOk(t)
 		rootRemote, err := fs.NewFs(remoteName)
 		require.NoError(t, err)
-		_, dirs, err := walk.GetAll(rootRemote, "", true, 1)
+		_, dirs, err := walk.GetAll(ctx, rootRemote, "", true, 1)
 		require.NoError(t, err)
 		assert.Contains(t, dirsToNames(dirs), subRemoteLeaf, "Remote leaf not found")
 	}
@@ -480,7 +482,7 @@ func Run(t *testing.T, opt *Opt) {
 		for i := 0; i < 2; i++ {
 			dir, _ := path.Split(fileName)
 			dir = dir[:len(dir)-1]
-			objs, dirs, err = walk.GetAll(remote, dir, true, -1)
+			objs, dirs, err = walk.GetAll(ctx, remote, dir, true, -1)
 			if err != fs.ErrorDirNotFound {
 				break
 			}
@@ -502,7 +504,7 @@ func Run(t *testing.T, opt *Opt) {
 	// TestFsListLevel2 tests List works for 2 levels
 	TestFsListLevel2 := func(t *testing.T) {
 		skipIfNotOk(t)
-		objs, dirs, err := walk.GetAll(remote, "", true, 2)
+		objs, dirs, err := walk.GetAll(ctx, remote, "", true, 2)
 		if err == fs.ErrorLevelNotSupported {
 			return
 		}
@@ -541,7 +543,7 @@ func Run(t *testing.T, opt *Opt) {
 	t.Run("TestFsNewObjectDir", func(t *testing.T) {
 		skipIfNotOk(t)
 		dir := path.Dir(file2.Path)
-		obj, err := remote.NewObject(dir)
+		obj, err := remote.NewObject(ctx, dir)
 		assert.Nil(t, obj)
 		assert.NotNil(t, err)
 	})
@@ -562,7 +564,7 @@ func Run(t *testing.T, opt *Opt) {
 
 		// do the copy
 		src := findObject(t, remote, file2.Path)
-		dst, err := doCopy(src, file2Copy.Path)
+		dst, err := doCopy(ctx, src, file2Copy.Path)
 		if err == fs.ErrorCantCopy {
 			t.Skip("FS can't copy")
 		}
@@ -575,7 +577,7 @@ func Run(t *testing.T, opt *Opt) {
 		assert.Equal(t, file2Copy.Path, dst.Remote())
 
 		// Delete copy
-		err = dst.Remove()
+		err = dst.Remove(ctx)
 		require.NoError(t, err)
 
 	})
@@ -602,7 +604,7 @@ func Run(t *testing.T, opt *Opt) {
 		file2Move.Path = "other.txt"
 		file2Move.WinPath = ""
 		src := findObject(t, remote, file2.Path)
-		dst, err := doMove(src, file2Move.Path)
+		dst, err := doMove(ctx, src, file2Move.Path)
 		if err == fs.ErrorCantMove {
 			t.Skip("FS can't move")
 		}
@@ -617,7 +619,7 @@ func Run(t *testing.T, opt *Opt) {
 		// Check conflict on "rename, then move"
 		file1Move.Path = "moveTest/other.txt"
 		src = findObject(t, remote, file1.Path)
-		_, err = doMove(src, file1Move.Path)
+		_, err = doMove(ctx, src, file1Move.Path)
 		require.NoError(t, err)
 		fstest.CheckListing(t, remote, []fstest.Item{file1Move, file2Move})
 		// 1: moveTest/other.txt
@@ -625,14 +627,14 @@ func Run(t *testing.T, opt *Opt) {
 
 		// Check conflict on "move, then rename"
 		src = findObject(t, remote, file1Move.Path)
-		_, err = doMove(src, file1.Path)
+		_, err = doMove(ctx, src, file1.Path)
 		require.NoError(t, err)
 		fstest.CheckListing(t, remote, []fstest.Item{file1, file2Move})
 		// 1: file name.txt
 		// 2: other.txt
 
 		src = findObject(t, remote, file2Move.Path)
-		_, err = doMove(src, file2.Path)
+		_, err = doMove(ctx, src, file2.Path)
 		require.NoError(t, err)
 		fstest.CheckListing(t, remote, []fstest.Item{file1, file2})
 		// 1: file name.txt
@@ -660,7 +662,7 @@ func Run(t *testing.T, opt *Opt) {
 		}
 
 		// Check it can't move onto itself
-		err := doDirMove(remote, "", "")
+		err := doDirMove(ctx, remote, "", "")
 		require.Equal(t, fs.ErrorDirExists, err)
 
 		// new remote
@@ -670,7 +672,7 @@ func Run(t *testing.T, opt *Opt) {
 
 		const newName = "new_name/sub_new_name"
 		// try the move
-		err = newRemote.Features().DirMove(remote, "", newName)
+		err = newRemote.Features().DirMove(ctx, remote, "", newName)
 		require.NoError(t, err)
 
 		// check remotes
@@ -684,7 +686,7 @@ func Run(t *testing.T, opt *Opt) {
 		fstest.CheckListing(t, newRemote, []fstest.Item{file2Copy, file1Copy})
 
 		// move it back
-		err = doDirMove(newRemote, newName, "")
+		err = doDirMove(ctx, newRemote, newName, "")
 		require.NoError(t, err)
 
 		// check remotes
@@ -695,7 +697,7 @@ func Run(t *testing.T, opt *Opt) {
 	// TestFsRmdirFull tests removing a non empty directory
 	t.Run("TestFsRmdirFull", func(t *testing.T) {
 		skipIfNotOk(t)
-		err := remote.Rmdir("")
+		err := remote.Rmdir(ctx, "")
 		require.Error(t, err, "Expecting error on RMdir on non empty remote")
 	})
 
@@ -725,12 +727,12 @@ func Run(t *testing.T, opt *Opt) {
 			t.Skip("FS has no ChangeNotify interface")
 		}
 
-		err := operations.Mkdir(remote, "dir")
+		err := operations.Mkdir(ctx, remote, "dir")
 		require.NoError(t, err)
 
 		dirChanges := []string{}
 		objChanges := []string{}
-		quitChannel := doChangeNotify(func(x string, e fs.EntryType) {
+		quitChannel := doChangeNotify(ctx, func(x string, e fs.EntryType) {
 			fs.Debugf(nil, "doChangeNotify(%q, %+v)", x, e)
 			if strings.HasPrefix(x, file1.Path[:5]) || strings.HasPrefix(x, file2.Path[:5]) {
 				fs.Debugf(nil, "Ignoring notify for file1 or file2: %q, %v", x, e)
@@ -747,7 +749,7 @@ func Run(t *testing.T, opt *Opt) {
 		var dirs []string
 		for _, idx := range []int{1, 3, 2} {
 			dir := fmt.Sprintf("dir/subdir%d", idx)
-			err = operations.Mkdir(remote, dir)
+			err = operations.Mkdir(ctx, remote, dir)
 			require.NoError(t, err)
 			dirs = append(dirs, dir)
 		}
@@ -758,7 +760,7 @@ func Run(t *testing.T, opt *Opt) {
 		var objs []fs.Object
 		for _, idx := range []int{2, 4, 3} {
 			obji := object.NewStaticObjectInfo(fmt.Sprintf("dir/file%d", idx), time.Now(), int64(buf.Len()), true, nil, nil)
-			o, err := remote.Put(buf, obji)
+			o, err := remote.Put(ctx, buf, obji)
 			require.NoError(t, err)
 			objs = append(objs, o)
 		}
@@ -770,11 +772,11 @@ func Run(t *testing.T, opt *Opt) {
 
 		// tidy up afterwards
 		for _, o := range objs {
-			assert.NoError(t, o.Remove())
+			assert.NoError(t, o.Remove(ctx))
 		}
 		dirs = append(dirs, "dir")
 		for _, dir := range dirs {
-			assert.NoError(t, remote.Rmdir(dir))
+			assert.NoError(t, remote.Rmdir(ctx, dir))
 		}
 	})
 
@@ -843,7 +845,7 @@ func Run(t *testing.T, opt *Opt) {
 		skipIfNotOk(t)
 		newModTime := fstest.Time("2011-12-13T14:15:16.999999999Z")
 		obj := findObject(t, remote, file1.Path)
-		err := obj.SetModTime(newModTime)
+		err := obj.SetModTime(ctx, newModTime)
 		if err == fs.ErrorCantSetModTime || err == fs.ErrorCantSetModTimeWithoutDelete {
 			t.Log(err)
 			return
@@ -915,7 +917,7 @@ func Run(t *testing.T, opt *Opt) {
 		file1.Size = int64(buf.Len())
 		obj := findObject(t, remote, file1.Path)
 		obji := object.NewStaticObjectInfo(file1.Path, file1.ModTime, int64(len(contents)), true, nil, obj.Fs())
-		err := obj.Update(in, obji)
+		err := obj.Update(ctx, in, obji)
 		require.NoError(t, err)
 		file1.Hashes = hash.Sums()
 
@@ -970,34 +972,34 @@ func Run(t *testing.T, opt *Opt) {
 		}
 
 		// if object not found
-		link, err := doPublicLink(file1.Path + "_does_not_exist")
+		link, err := doPublicLink(ctx, file1.Path+"_does_not_exist")
 		require.Error(t, err, "Expected to get error when file doesn't exist")
 		require.Equal(t, "", link, "Expected link to be empty on error")
 
 		// sharing file for the first time
-		link1, err := doPublicLink(file1.Path)
+		link1, err := doPublicLink(ctx, file1.Path)
 		require.NoError(t, err)
 		require.NotEqual(t, "", link1, "Link should not be empty")
 
-		link2, err := doPublicLink(file2.Path)
+		link2, err := doPublicLink(ctx, file2.Path)
 		require.NoError(t, err)
 		require.NotEqual(t, "", link2, "Link should not be empty")
 
 		require.NotEqual(t, link1, link2, "Links to different files should differ")
 
 		// sharing file for the 2nd time
-		link1, err = doPublicLink(file1.Path)
+		link1, err = doPublicLink(ctx, file1.Path)
 		require.NoError(t, err)
 		require.NotEqual(t, "", link1, "Link should not be empty")
 
 		// sharing directory for the first time
 		path := path.Dir(file2.Path)
-		link3, err := doPublicLink(path)
+		link3, err := doPublicLink(ctx, path)
 		require.NoError(t, err)
 		require.NotEqual(t, "", link3, "Link should not be empty")
 
 		// sharing directory for the second time
-		link3, err = doPublicLink(path)
+		link3, err = doPublicLink(ctx, path)
 		require.NoError(t, err)
 		require.NotEqual(t, "", link3, "Link should not be empty")
 
@@ -1008,10 +1010,10 @@ func Run(t *testing.T, opt *Opt) {
 		// ensure sub remote isn't empty
 		buf := bytes.NewBufferString("somecontent")
 		obji := object.NewStaticObjectInfo("somefile", time.Now(), int64(buf.Len()), true, nil, nil)
-		_, err = subRemote.Put(buf, obji)
+		_, err = subRemote.Put(ctx, buf, obji)
 		require.NoError(t, err)
 
-		link4, err := subRemote.Features().PublicLink("")
+		link4, err := subRemote.Features().PublicLink(ctx, "")
 		require.NoError(t, err, "Sharing root in a sub-remote should work")
 		require.NotEqual(t, "", link4, "Link should not be empty")
 	})
@@ -1020,7 +1022,7 @@ func Run(t *testing.T, opt *Opt) {
 	t.Run("TestObjectRemove", func(t *testing.T) {
 		skipIfNotOk(t)
 		obj := findObject(t, remote, file1.Path)
-		err := obj.Remove()
+		err := obj.Remove(ctx)
 		require.NoError(t, err)
 		// check listing without modtime as TestPublicLink may change the modtime
 		fstest.CheckListingWithPrecision(t, remote, []fstest.Item{file2}, nil, fs.ModTimeNotSupported)
@@ -1050,7 +1052,7 @@ func Run(t *testing.T, opt *Opt) {
 
 		file.Size = -1
 		obji := object.NewStaticObjectInfo(file.Path, file.ModTime, file.Size, true, nil, nil)
-		obj, err := remote.Features().PutStream(in, obji)
+		obj, err := remote.Features().PutStream(ctx, in, obji)
 		if err != nil {
 			// Retry if err returned a retry error
 			if fserrors.IsRetryError(err) && tries < maxTries {
@@ -1074,11 +1076,11 @@ func Run(t *testing.T, opt *Opt) {
 	t.Run("TestObjectPurge", func(t *testing.T) {
 		skipIfNotOk(t)
 
-		err := operations.Purge(remote, "")
+		err := operations.Purge(ctx, remote, "")
 		require.NoError(t, err)
 		fstest.CheckListing(t, remote, []fstest.Item{})
 
-		err = operations.Purge(remote, "")
+		err = operations.Purge(ctx, remote, "")
 		assert.Error(t, err, "Expecting error after on second purge")
 	})
 
@@ -5,6 +5,7 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"go/build"
 	"log"
@@ -264,7 +265,7 @@ func (t *test) cleanFs() error {
 	if err != nil {
 		return err
 	}
-	entries, err := list.DirSorted(f, true, "")
+	entries, err := list.DirSorted(context.Background(), f, true, "")
 	if err != nil {
 		return err
 	}
@@ -277,7 +278,7 @@ func (t *test) cleanFs() error {
 			if err != nil {
 				return err
 			}
-			return operations.Purge(dir, "")
+			return operations.Purge(context.Background(), dir, "")
 		}
 		return nil
 	})
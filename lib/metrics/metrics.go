@@ -0,0 +1,165 @@
+// Package metrics exports counters and gauges in the Prometheus text
+// exposition format.
+//
+// Packages register a Collector which is called each time the
+// metrics are read and adds the current values to a Writer.
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Type is the type of a metric
+type Type string
+
+// Types of metric
+const (
+	Counter Type = "counter" // a value which only goes up
+	Gauge   Type = "gauge"   // a value which can go up and down
+)
+
+// Collector is called to add the current values of metrics to w
+type Collector func(w *Writer)
+
+var (
+	collectorsMu sync.Mutex
+	collectors   []Collector
+)
+
+// Register adds a Collector which will be called whenever the metrics
+// are read
+func Register(c Collector) {
+	collectorsMu.Lock()
+	collectors = append(collectors, c)
+	collectorsMu.Unlock()
+}
+
+// sample is a single value of a metric
+type sample struct {
+	labels string // formatted labels, eg {remote="drive"}
+	value  float64
+}
+
+// family is all the samples of a metric with the same name
+type family struct {
+	name    string
+	help    string
+	kind    Type
+	samples []sample
+}
+
+// Writer collects the metrics from the Collectors
+type Writer struct {
+	families map[string]*family
+}
+
+// newWriter makes a new empty Writer
+func newWriter() *Writer {
+	return &Writer{
+		families: make(map[string]*family),
+	}
+}
+
+// Add adds a value for the metric called name
+//
+// labels should be pairs of label names and values. If the same name
+// is added more than once the help and type from the first are used.
+func (w *Writer) Add(name string, kind Type, help string, value float64, labels ...string) {
+	f := w.families[name]
+	if f == nil {
+		f = &family{name: name, help: help, kind: kind}
+		w.families[name] = f
+	}
+	f.samples = append(f.samples, sample{
+		labels: formatLabels(labels),
+		value:  value,
+	})
+}
+
+// formatLabels formats pairs of label names and values
+func formatLabels(labels []string) string {
+	if len(labels) == 0 {
+		return ""
+	}
+	var out []string
+	for i := 0; i+1 < len(labels); i += 2 {
+		out = append(out, labels[i]+`="`+escapeLabel(labels[i+1])+`"`)
+	}
+	return "{" + strings.Join(out, ",") + "}"
+}
+
+// labelEscaper escapes label values
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// escapeLabel escapes a label value
+func escapeLabel(s string) string {
+	return labelEscaper.Replace(s)
+}
+
+// helpEscaper escapes help text
+var helpEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+
+// bySample sorts samples by their labels
+type bySample []sample
+
+func (s bySample) Len() int           { return len(s) }
+func (s bySample) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+func (s bySample) Less(i, j int) bool { return s[i].labels < s[j].labels }
+
+// writeTo writes the metrics to out sorted by name and labels
+func (w *Writer) writeTo(out io.Writer) error {
+	names := make([]string, 0, len(w.families))
+	for name := range w.families {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	buf := bufio.NewWriter(out)
+	for _, name := range names {
+		f := w.families[name]
+		sort.Stable(bySample(f.samples))
+		_, _ = fmt.Fprintf(buf, "# HELP %s %s\n", f.name, helpEscaper.Replace(f.help))
+		_, _ = fmt.Fprintf(buf, "# TYPE %s %s\n", f.name, f.kind)
+		for _, s := range f.samples {
+			_, _ = fmt.Fprintf(buf, "%s%s %s\n", f.name, s.labels, strconv.FormatFloat(s.value, 'g', -1, 64))
+		}
+	}
+	return buf.Flush()
+}
+
+// Write calls all the registered Collectors and writes their metrics
+// to out
+func Write(out io.Writer) error {
+	collectorsMu.Lock()
+	cs := append([]Collector(nil), collectors...)
+	collectorsMu.Unlock()
+	w := newWriter()
+	for _, c := range cs {
+		c(w)
+	}
+	return w.writeTo(out)
+}
+
+// ContentType is the content type of the metrics served by Handler
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// Handler returns an http.Handler which serves the metrics
+func Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "GET" && r.Method != "HEAD" {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		w.Header().Set("Content-Type", ContentType)
+		if r.Method == "HEAD" {
+			return
+		}
+		_ = Write(w)
+	})
+}
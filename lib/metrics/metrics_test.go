@@ -0,0 +1,52 @@
+package metrics
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriter(t *testing.T) {
+	w := newWriter()
+	w.Add("test_gauge", Gauge, "A gauge", 1.5, "remote", `b"\`+"\n")
+	w.Add("test_counter_total", Counter, "A counter\nwith two lines", 3)
+	w.Add("test_gauge", Gauge, "Ignored help", 2, "remote", "a")
+	var out bytes.Buffer
+	require.NoError(t, w.writeTo(&out))
+	assert.Equal(t, `# HELP test_counter_total A counter\nwith two lines
+# TYPE test_counter_total counter
+test_counter_total 3
+# HELP test_gauge A gauge
+# TYPE test_gauge gauge
+test_gauge{remote="a"} 2
+test_gauge{remote="b\"\\\n"} 1.5
+`, out.String())
+}
+
+func TestHandler(t *testing.T) {
+	Register(func(w *Writer) {
+		w.Add("test_registered_total", Counter, "Registered", 42, "remote", "potato")
+	})
+
+	srv := httptest.NewServer(Handler())
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL)
+	require.NoError(t, err)
+	body, err := ioutil.ReadAll(resp.Body)
+	require.NoError(t, resp.Body.Close())
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, ContentType, resp.Header.Get("Content-Type"))
+	assert.Contains(t, string(body), "# TYPE test_registered_total counter\ntest_registered_total{remote=\"potato\"} 42\n")
+
+	resp, err = http.Post(srv.URL, "text/plain", nil)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	assert.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+}
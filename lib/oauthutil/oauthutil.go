@@ -14,6 +14,7 @@ import (
 
 	"github.com/ncw/rclone/fs"
 	"github.com/ncw/rclone/fs/config"
+	"github.com/ncw/rclone/fs/config/configmap"
 	"github.com/ncw/rclone/fs/fshttp"
 	"github.com/pkg/errors"
 	"github.com/skratchdot/open-golang/open"
@@ -53,11 +54,11 @@ type oldToken struct {
 	Expiry       time.Time
 }
 
-// GetToken returns the token saved in the config file under
-// section name.
-func GetToken(name string) (*oauth2.Token, error) {
-	tokenString := config.FileGet(name, config.ConfigToken)
-	if tokenString == "" {
+// GetToken returns the token saved in the config for the remote
+// called name.
+func GetToken(name string, m configmap.Mapper) (*oauth2.Token, error) {
+	tokenString, ok := m.Get(config.ConfigToken)
+	if !ok || tokenString == "" {
 		return nil, errors.New("empty token found - please run rclone config again")
 	}
 	token := new(oauth2.Token)
@@ -80,32 +81,26 @@ func GetToken(name string) (*oauth2.Token, error) {
 	token.RefreshToken = oldtoken.RefreshToken
 	token.Expiry = oldtoken.Expiry
 	// Save new format in config file
-	err = PutToken(name, token, false)
+	err = PutToken(name, m, token)
 	if err != nil {
 		return nil, err
 	}
 	return token, nil
 }
 
-// PutToken stores the token in the config file
+// PutToken stores the token in the config for the remote called name
 //
 // This saves the config file if it changes
-func PutToken(name string, token *oauth2.Token, newSection bool) error {
+func PutToken(name string, m configmap.Mapper, token *oauth2.Token) error {
 	tokenBytes, err := json.Marshal(token)
 	if err != nil {
 		return err
 	}
 	tokenString := string(tokenBytes)
-	old := config.FileGet(name, config.ConfigToken)
-	if tokenString != old {
-		err = config.SetValueAndSave(name, config.ConfigToken, tokenString)
-		if newSection && err != nil {
-			fs.Debugf(name, "Added new token to config, still needs to be saved")
-		} else if err != nil {
-			fs.Errorf(nil, "Failed to save new token in config file: %v", err)
-		} else {
-			fs.Debugf(name, "Saved new token in config file")
-		}
+	old, ok := m.Get(config.ConfigToken)
+	if !ok || tokenString != old {
+		m.Set(config.ConfigToken, tokenString)
+		fs.Debugf(name, "Saved new token in config")
 	}
 	return nil
 }
@@ -114,6 +109,7 @@ func PutToken(name string, token *oauth2.Token, newSection bool) error {
 type TokenSource struct {
 	mu          sync.Mutex
 	name        string
+	m           configmap.Mapper
 	tokenSource oauth2.TokenSource
 	token       *oauth2.Token
 	config      *oauth2.Config
@@ -146,7 +142,7 @@ func (ts *TokenSource) Token() (*oauth2.Token, error) {
 		if ts.expiryTimer != nil {
 			ts.expiryTimer.Reset(ts.timeToExpiry())
 		}
-		err = PutToken(ts.name, token, false)
+		err = PutToken(ts.name, ts.m, token)
 		if err != nil {
 			return nil, err
 		}
@@ -196,29 +192,29 @@ func Context(client *http.Client) context.Context {
 }
 
 // overrideCredentials sets the ClientID and ClientSecret from the
-// config file if they are not blank.
+// config if they are not blank.
 // If any value is overridden, true is returned.
 // the origConfig is copied
-func overrideCredentials(name string, origConfig *oauth2.Config) (newConfig *oauth2.Config, changed bool) {
+func overrideCredentials(name string, m configmap.Mapper, origConfig *oauth2.Config) (newConfig *oauth2.Config, changed bool) {
 	newConfig = new(oauth2.Config)
 	*newConfig = *origConfig
 	changed = false
-	ClientID := config.FileGet(name, config.ConfigClientID)
+	ClientID, _ := m.Get(config.ConfigClientID)
 	if ClientID != "" {
 		newConfig.ClientID = ClientID
 		changed = true
 	}
-	ClientSecret := config.FileGet(name, config.ConfigClientSecret)
+	ClientSecret, _ := m.Get(config.ConfigClientSecret)
 	if ClientSecret != "" {
 		newConfig.ClientSecret = ClientSecret
 		changed = true
 	}
-	AuthURL := config.FileGet(name, config.ConfigAuthURL)
+	AuthURL, _ := m.Get(config.ConfigAuthURL)
 	if AuthURL != "" {
 		newConfig.Endpoint.AuthURL = AuthURL
 		changed = true
 	}
-	TokenURL := config.FileGet(name, config.ConfigTokenURL)
+	TokenURL, _ := m.Get(config.ConfigTokenURL)
 	if TokenURL != "" {
 		newConfig.Endpoint.TokenURL = TokenURL
 		changed = true
@@ -226,13 +222,13 @@ func overrideCredentials(name string, origConfig *oauth2.Config) (newConfig *oau
 	return newConfig, changed
 }
 
-// NewClientWithBaseClient gets a token from the config file and
+// NewClientWithBaseClient gets a token from the config and
 // configures a Client with it.  It returns the client and a
 // TokenSource which Invalidate may need to be called on.  It uses the
 // httpClient passed in as the base client.
-func NewClientWithBaseClient(name string, config *oauth2.Config, baseClient *http.Client) (*http.Client, *TokenSource, error) {
-	config, _ = overrideCredentials(name, config)
-	token, err := GetToken(name)
+func NewClientWithBaseClient(name string, m configmap.Mapper, config *oauth2.Config, baseClient *http.Client) (*http.Client, *TokenSource, error) {
+	config, _ = overrideCredentials(name, m, config)
+	token, err := GetToken(name, m)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -244,6 +240,7 @@ func NewClientWithBaseClient(name string, config *oauth2.Config, baseClient *htt
 	// tokens in the config file
 	ts := &TokenSource{
 		name:   name,
+		m:      m,
 		token:  token,
 		config: config,
 		ctx:    ctx,
@@ -252,36 +249,37 @@ func NewClientWithBaseClient(name string, config *oauth2.Config, baseClient *htt
 
 }
 
-// NewClient gets a token from the config file and configures a Client
+// NewClient gets a token from the config and configures a Client
 // with it.  It returns the client and a TokenSource which Invalidate may need to be called on
-func NewClient(name string, oauthConfig *oauth2.Config) (*http.Client, *TokenSource, error) {
-	return NewClientWithBaseClient(name, oauthConfig, fshttp.NewClient(fs.Config))
+func NewClient(name string, m configmap.Mapper, oauthConfig *oauth2.Config) (*http.Client, *TokenSource, error) {
+	return NewClientWithBaseClient(name, m, oauthConfig, fshttp.NewClient(fs.Config))
 }
 
 // Config does the initial creation of the token
 //
 // It may run an internal webserver to receive the results
-func Config(id, name string, config *oauth2.Config, opts ...oauth2.AuthCodeOption) error {
-	return doConfig(id, name, config, true, opts)
+func Config(id, name string, m configmap.Mapper, config *oauth2.Config, opts ...oauth2.AuthCodeOption) error {
+	return doConfig(id, name, m, config, true, opts)
 }
 
 // ConfigNoOffline does the same as Config but does not pass the
 // "access_type=offline" parameter.
-func ConfigNoOffline(id, name string, config *oauth2.Config, opts ...oauth2.AuthCodeOption) error {
-	return doConfig(id, name, config, false, opts)
+func ConfigNoOffline(id, name string, m configmap.Mapper, config *oauth2.Config, opts ...oauth2.AuthCodeOption) error {
+	return doConfig(id, name, m, config, false, opts)
 }
 
-func doConfig(id, name string, oauthConfig *oauth2.Config, offline bool, opts []oauth2.AuthCodeOption) error {
-	oauthConfig, changed := overrideCredentials(name, oauthConfig)
-	automatic := config.FileGet(name, config.ConfigAutomatic) != ""
+func doConfig(id, name string, m configmap.Mapper, oauthConfig *oauth2.Config, offline bool, opts []oauth2.AuthCodeOption) error {
+	oauthConfig, changed := overrideCredentials(name, m, oauthConfig)
+	auto, ok := m.Get(config.ConfigAutomatic)
+	automatic := ok && auto != ""
 
 	if changed {
 		fmt.Printf("Make sure your Redirect URL is set to %q in your custom config.\n", RedirectURL)
 	}
 
 	// See if already have a token
-	tokenString := config.FileGet(name, "token")
-	if tokenString != "" {
+	tokenString, ok := m.Get(config.ConfigToken)
+	if ok && tokenString != "" {
 		fmt.Printf("Already have a token - refresh?\n")
 		if !config.Confirm() {
 			return nil
@@ -319,7 +317,7 @@ func doConfig(id, name string, oauthConfig *oauth2.Config, offline bool, opts []
 			if err != nil {
 				return err
 			}
-			return PutToken(name, token, false)
+			return PutToken(name, m, token)
 		}
 	case TitleBarRedirectURL:
 		useWebServer = automatic
@@ -395,7 +393,7 @@ func doConfig(id, name string, oauthConfig *oauth2.Config, offline bool, opts []
 		}
 		fmt.Printf("Paste the following into your remote machine --->\n%s\n<---End paste", result)
 	}
-	return PutToken(name, token, true)
+	return PutToken(name, m, token)
 }
 
 // Local web server for collecting auth
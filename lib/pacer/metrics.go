@@ -0,0 +1,106 @@
+// Prometheus metrics for the pacers
+
+package pacer
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ncw/rclone/lib/metrics"
+)
+
+// pacerStats are the metrics shared by the pacers with the same name
+//
+// The methods may be called on a nil *pacerStats and do nothing.
+type pacerStats struct {
+	mu          sync.Mutex
+	calls       int64         // number of calls made
+	retries     int64         // number of low level retries
+	rateLimited int64         // number of calls which asked to be retried
+	sleepTime   time.Duration // current sleep time between calls
+	waited      time.Duration // total time spent waiting for the pacer
+}
+
+var (
+	statsMu sync.Mutex
+	stats   = make(map[string]*pacerStats)
+)
+
+// getStats returns the pacerStats for name, making it if necessary
+func getStats(name string) *pacerStats {
+	statsMu.Lock()
+	defer statsMu.Unlock()
+	s := stats[name]
+	if s == nil {
+		s = new(pacerStats)
+		stats[name] = s
+	}
+	return s
+}
+
+// addCall records a call which waited for the pacer for waited
+func (s *pacerStats) addCall(waited time.Duration) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	s.calls++
+	s.waited += waited
+	s.mu.Unlock()
+}
+
+// endCall records the end of a call and the new sleep time
+func (s *pacerStats) endCall(retry bool, sleepTime time.Duration) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	if retry {
+		s.rateLimited++
+	}
+	s.sleepTime = sleepTime
+	s.mu.Unlock()
+}
+
+// addRetry records a low level retry
+func (s *pacerStats) addRetry() {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	s.retries++
+	s.mu.Unlock()
+}
+
+// setSleep sets the current sleep time
+func (s *pacerStats) setSleep(sleepTime time.Duration) {
+	s.mu.Lock()
+	s.sleepTime = sleepTime
+	s.mu.Unlock()
+}
+
+// collect adds the metrics for all the named pacers to w
+func collect(w *metrics.Writer) {
+	statsMu.Lock()
+	names := make([]string, 0, len(stats))
+	for name := range stats {
+		names = append(names, name)
+	}
+	statsMu.Unlock()
+	sort.Strings(names)
+	for _, name := range names {
+		s := getStats(name)
+		s.mu.Lock()
+		w.Add("rclone_pacer_calls_total", metrics.Counter, "Total number of calls made through the pacer.", float64(s.calls), "remote", name)
+		w.Add("rclone_pacer_retries_total", metrics.Counter, "Total number of low level retries.", float64(s.retries), "remote", name)
+		w.Add("rclone_pacer_rate_limited_total", metrics.Counter, "Total number of calls which were rate limited or asked to be retried.", float64(s.rateLimited), "remote", name)
+		w.Add("rclone_pacer_sleep_seconds", metrics.Gauge, "Current time the pacer sleeps between calls in seconds.", s.sleepTime.Seconds(), "remote", name)
+		w.Add("rclone_pacer_wait_seconds_total", metrics.Counter, "Total time spent waiting for the pacer in seconds.", s.waited.Seconds(), "remote", name)
+		s.mu.Unlock()
+	}
+}
+
+func init() {
+	metrics.Register(collect)
+}
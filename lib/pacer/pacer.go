@@ -2,6 +2,7 @@
 package pacer
 
 import (
+	"context"
 	"math/rand"
 	"sync"
 	"time"
@@ -24,6 +25,7 @@ type Pacer struct {
 	connTokens         chan struct{} // Connection tokens
 	calculatePace      func(bool)    // switchable pacing algorithm - call with mu held
 	consecutiveRetries int           // number of consecutive retries
+	stats              *pacerStats   // metrics for the pacer or nil if not named
 }
 
 // Type is for selecting different pacing algorithms
@@ -102,6 +104,18 @@ func (p *Pacer) GetSleep() time.Duration {
 	return p.sleepTime
 }
 
+// SetName sets the name of the pacer, usually the name of the remote
+//
+// Pacers with the same name share their metrics.  Metrics are only
+// collected for named pacers.
+func (p *Pacer) SetName(name string) *Pacer {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.stats = getStats(name)
+	p.stats.setSleep(p.sleepTime)
+	return p
+}
+
 // SetMinSleep sets the minimum sleep time for the pacer
 func (p *Pacer) SetMinSleep(t time.Duration) *Pacer {
 	p.mu.Lock()
@@ -193,20 +207,34 @@ func (p *Pacer) SetPacer(t Type) *Pacer {
 
 // Start a call to the API
 //
-// This must be called as a pair with endCall
+// This must be called as a pair with endCall if it returns nil
 //
-// This waits for the pacer token
-func (p *Pacer) beginCall() {
+// This waits for the pacer token.  If the context is cancelled while
+// waiting then it returns the context's error without taking a
+// token.
+func (p *Pacer) beginCall(ctx context.Context) error {
 	// pacer starts with a token in and whenever we take one out
 	// XXX ms later we put another in.  We could do this with a
 	// Ticker more accurately, but then we'd have to work out how
 	// not to run it when it wasn't needed
-	<-p.pacer
+	start := time.Now()
+	select {
+	case <-p.pacer:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
 	if p.maxConnections > 0 {
-		<-p.connTokens
+		select {
+		case <-p.connTokens:
+		case <-ctx.Done():
+			// give the pacer token back
+			p.pacer <- struct{}{}
+			return ctx.Err()
+		}
 	}
 
 	p.mu.Lock()
+	p.stats.addCall(time.Since(start))
 	// Restart the timer
 	go func(t time.Duration) {
 		// fs.Debugf(f, "New sleep for %v at %v", t, time.Now())
@@ -214,6 +242,7 @@ func (p *Pacer) beginCall() {
 		p.pacer <- struct{}{}
 	}(p.sleepTime)
 	p.mu.Unlock()
+	return nil
 }
 
 // exponentialImplementation implements a exponentialImplementation up
@@ -324,20 +353,38 @@ func (p *Pacer) endCall(retry bool) {
 		p.consecutiveRetries = 0
 	}
 	p.calculatePace(retry)
+	p.stats.endCall(retry, p.sleepTime)
 	p.mu.Unlock()
 }
 
 // call implements Call but with settable retries
-func (p *Pacer) call(fn Paced, retries int) (err error) {
+//
+// It stops retrying as soon as ctx is cancelled, returning the error
+// from the last attempt, or the context's error if there wasn't one.
+func (p *Pacer) call(ctx context.Context, fn Paced, retries int) (err error) {
 	var retry bool
 	for i := 1; i <= retries; i++ {
-		p.beginCall()
+		err = p.beginCall(ctx)
+		if err != nil {
+			return err
+		}
 		retry, err = fn()
 		p.endCall(retry)
 		if !retry {
 			break
 		}
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			if err == nil {
+				err = ctxErr
+			}
+			return err
+		}
 		fs.Debugf("pacer", "low level retry %d/%d (error %v)", i, retries, err)
+		if i < retries {
+			p.mu.Lock()
+			p.stats.addRetry()
+			p.mu.Unlock()
+		}
 	}
 	if retry {
 		err = fserrors.RetryError(err)
@@ -352,10 +399,16 @@ func (p *Pacer) call(fn Paced, retries int) (err error) {
 // error. This error may be returned wrapped in a RetryError if the
 // number of retries is exceeded.
 func (p *Pacer) Call(fn Paced) (err error) {
+	return p.CallContext(context.Background(), fn)
+}
+
+// CallContext is like Call but stops waiting for the pacer and stops
+// retrying if ctx is cancelled.
+func (p *Pacer) CallContext(ctx context.Context, fn Paced) (err error) {
 	p.mu.Lock()
 	retries := p.retries
 	p.mu.Unlock()
-	return p.call(fn, retries)
+	return p.call(ctx, fn, retries)
 }
 
 // CallNoRetry paces the remote operations to not exceed the limits
@@ -364,5 +417,11 @@ func (p *Pacer) Call(fn Paced) (err error) {
 // This calls fn and wraps the output in a RetryError if it would like
 // it to be retried
 func (p *Pacer) CallNoRetry(fn Paced) error {
-	return p.call(fn, 1)
+	return p.CallNoRetryContext(context.Background(), fn)
+}
+
+// CallNoRetryContext is like CallNoRetry but stops waiting for the
+// pacer if ctx is cancelled.
+func (p *Pacer) CallNoRetryContext(ctx context.Context, fn Paced) error {
+	return p.call(ctx, fn, 1)
 }
@@ -1,12 +1,16 @@
 package pacer
 
 import (
+	"bytes"
+	"context"
 	"fmt"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/ncw/rclone/fs"
 	"github.com/ncw/rclone/fs/fserrors"
+	"github.com/ncw/rclone/lib/metrics"
 	"github.com/pkg/errors"
 )
 
@@ -202,7 +206,7 @@ func waitForPace(p *Pacer, duration time.Duration) (when time.Time) {
 func TestBeginCall(t *testing.T) {
 	p := New().SetMaxConnections(10).SetMinSleep(1 * time.Millisecond)
 	emptyTokens(p)
-	go p.beginCall()
+	go func() { _ = p.beginCall(context.Background()) }()
 	if !waitForPace(p, 10*time.Millisecond).IsZero() {
 		t.Errorf("beginSleep fired too early #1")
 	}
@@ -225,7 +229,7 @@ func TestBeginCall(t *testing.T) {
 func TestBeginCallZeroConnections(t *testing.T) {
 	p := New().SetMaxConnections(0).SetMinSleep(1 * time.Millisecond)
 	emptyTokens(p)
-	go p.beginCall()
+	go func() { _ = p.beginCall(context.Background()) }()
 	if !waitForPace(p, 10*time.Millisecond).IsZero() {
 		t.Errorf("beginSleep fired too early #1")
 	}
@@ -382,7 +386,7 @@ func Test_callNoRetry(t *testing.T) {
 	p := New().SetMinSleep(time.Millisecond).SetMaxSleep(2 * time.Millisecond)
 
 	dp := &dummyPaced{retry: false}
-	err := p.call(dp.fn, 10)
+	err := p.call(context.Background(), dp.fn, 10)
 	if dp.called != 1 {
 		t.Errorf("called want %d got %d", 1, dp.called)
 	}
@@ -395,7 +399,7 @@ func Test_callRetry(t *testing.T) {
 	p := New().SetMinSleep(time.Millisecond).SetMaxSleep(2 * time.Millisecond)
 
 	dp := &dummyPaced{retry: true}
-	err := p.call(dp.fn, 10)
+	err := p.call(context.Background(), dp.fn, 10)
 	if dp.called != 10 {
 		t.Errorf("called want %d got %d", 10, dp.called)
 	}
@@ -435,3 +439,70 @@ func TestCallNoRetry(t *testing.T) {
 		t.Errorf("didn't return a retry error")
 	}
 }
+
+func TestCallContextCancelled(t *testing.T) {
+	p := New().SetMinSleep(time.Millisecond).SetMaxSleep(2 * time.Millisecond).SetRetries(20)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	dp := &dummyPaced{retry: true}
+	fn := func() (bool, error) {
+		if dp.called == 2 {
+			cancel()
+		}
+		return dp.fn()
+	}
+	err := p.CallContext(ctx, fn)
+	if dp.called != 3 {
+		t.Errorf("called want %d got %d", 3, dp.called)
+	}
+	if err != errFoo {
+		t.Errorf("err want %v got %v", errFoo, err)
+	}
+
+	// Check we don't call fn at all with a cancelled context
+	dp = &dummyPaced{retry: true}
+	err = p.CallContext(ctx, dp.fn)
+	if dp.called != 0 {
+		t.Errorf("called want %d got %d", 0, dp.called)
+	}
+	if err != context.Canceled {
+		t.Errorf("err want %v got %v", context.Canceled, err)
+	}
+}
+
+func TestSetNameMetrics(t *testing.T) {
+	p := New().SetMinSleep(time.Millisecond).SetMaxSleep(2 * time.Millisecond).SetName("metrics-test")
+	q := New().SetMinSleep(time.Millisecond).SetMaxSleep(2 * time.Millisecond).SetName("metrics-test")
+	if p.stats == nil || p.stats != q.stats {
+		t.Fatalf("pacers with the same name should share stats")
+	}
+
+	dp := &dummyPaced{retry: true}
+	_ = p.call(context.Background(), dp.fn, 3)
+	dp = &dummyPaced{retry: false}
+	_ = q.call(context.Background(), dp.fn, 3)
+
+	s := p.stats
+	if s.calls != 4 {
+		t.Errorf("calls want %d got %d", 4, s.calls)
+	}
+	if s.retries != 2 {
+		t.Errorf("retries want %d got %d", 2, s.retries)
+	}
+	if s.rateLimited != 3 {
+		t.Errorf("rateLimited want %d got %d", 3, s.rateLimited)
+	}
+	if s.sleepTime != time.Millisecond {
+		t.Errorf("sleepTime want %v got %v", time.Millisecond, s.sleepTime)
+	}
+
+	var out bytes.Buffer
+	err := metrics.Write(&out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `rclone_pacer_calls_total{remote="metrics-test"} 4`
+	if !strings.Contains(out.String(), want) {
+		t.Errorf("metrics didn't contain %q: %s", want, out.String())
+	}
+}
@@ -0,0 +1,73 @@
+// Package version provides machinery for versioning file names
+// with a timestamp-based version string
+package version
+
+import (
+	"path"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const versionFormat = "-v2006-01-02-150405.000"
+
+var versionRegexp = regexp.MustCompile(`-v\d{4}-\d{2}-\d{2}-\d{6}-\d{3}`)
+
+// Add a time-based version to fileName
+//
+// The version is added before the extension, so "potato.txt"
+// becomes "potato-v2001-02-03-040506-123.txt".
+func Add(fileName string, t time.Time) string {
+	ext := path.Ext(fileName)
+	base := fileName[:len(fileName)-len(ext)]
+	s := t.Format(versionFormat)
+	// Replace the '.' with a '-'
+	s = strings.Replace(s, ".", "-", -1)
+	return base + s + ext
+}
+
+// Remove the time-based version from fileName
+//
+// It returns the time of the version and the fileName without the
+// version, or a zero time and fileName unchanged if there wasn't a
+// version.
+func Remove(fileName string) (t time.Time, fileNameWithoutVersion string) {
+	fileNameWithoutVersion = fileName
+	ext := path.Ext(fileName)
+	base := fileName[:len(fileName)-len(ext)]
+	if len(base) < len(versionFormat) {
+		return
+	}
+	versionStart := len(base) - len(versionFormat)
+	// Check it ends in -xxx
+	if base[len(base)-4] != '-' {
+		return
+	}
+	// Replace with .xxx for parsing
+	base = base[:len(base)-4] + "." + base[len(base)-3:]
+	newT, err := time.Parse(versionFormat, base[versionStart:])
+	if err != nil {
+		return
+	}
+	return newT, base[:versionStart] + ext
+}
+
+// Match returns true if fileName has a time-based version in it
+func Match(fileName string) bool {
+	return versionRegexp.MatchString(fileName)
+}
+
+// Format formats t as it would appear in a versioned file name
+// without the leading "-v", eg "2001-02-03-040506-123"
+func Format(t time.Time) string {
+	return Add("", t)[2:]
+}
+
+// Parse parses a version as produced by Format
+func Parse(s string) (time.Time, error) {
+	s = "-v" + s
+	if len(s) > 4 && s[len(s)-4] == '-' {
+		s = s[:len(s)-4] + "." + s[len(s)-3:]
+	}
+	return time.Parse(versionFormat, s)
+}
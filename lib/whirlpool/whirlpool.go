@@ -0,0 +1,210 @@
+// Package whirlpool implements the Whirlpool hash function as
+// standardised in ISO/IEC 10118-3
+//
+// https://web.archive.org/web/20171129084214/http://www.larc.usp.br/~pbarreto/WhirlpoolPage.html
+//
+// The lookup tables are calculated at startup from the mini boxes
+// which define the S-box rather than being written out in full.
+package whirlpool
+
+import (
+	"encoding/binary"
+	"hash"
+)
+
+const (
+	// BlockSize of the checksum in bytes.
+	BlockSize = 64
+	// Size of the checksum in bytes.
+	Size = 64
+
+	rounds     = 10
+	lengthSize = 32 // bytes used to store the length in the padding
+)
+
+var (
+	// The S-box and the circulant tables C[0]..C[7] derived from it
+	sBox [256]byte
+	c    [8][256]uint64
+	// Round constants
+	rc [rounds + 1]uint64
+)
+
+// Multiply x by y in GF(2^8) with the reduction polynomial
+// x^8 + x^4 + x^3 + x^2 + 1
+func gfMul(x, y byte) byte {
+	var p byte
+	for ; y != 0; y >>= 1 {
+		if y&1 != 0 {
+			p ^= x
+		}
+		carry := x & 0x80
+		x <<= 1
+		if carry != 0 {
+			x ^= 0x1d
+		}
+	}
+	return p
+}
+
+func init() {
+	// Mini boxes from which the S-box is built
+	e := [16]byte{0x1, 0xB, 0x9, 0xC, 0xD, 0x6, 0xF, 0x3, 0xE, 0x8, 0x7, 0x4, 0xA, 0x2, 0x5, 0x0}
+	r := [16]byte{0x7, 0xC, 0xB, 0xD, 0xE, 0x4, 0x9, 0xF, 0x6, 0x3, 0x8, 0xA, 0x2, 0x5, 0x1, 0x0}
+	var eInv [16]byte
+	for i, x := range e {
+		eInv[x] = byte(i)
+	}
+	for x := 0; x < 256; x++ {
+		a, b := e[x>>4], eInv[x&0xF]
+		t := r[a^b]
+		sBox[x] = e[a^t]<<4 | eInv[b^t]
+	}
+
+	// First row of the circulant matrix cir(1, 1, 4, 1, 8, 5, 2, 9)
+	row := [8]byte{1, 1, 4, 1, 8, 5, 2, 9}
+	for x := 0; x < 256; x++ {
+		var v uint64
+		for _, m := range row {
+			v = v<<8 | uint64(gfMul(sBox[x], m))
+		}
+		for t := 0; t < 8; t++ {
+			c[t][x] = v>>(8*uint(t)) | v<<(64-8*uint(t))
+		}
+	}
+
+	for i := 1; i <= rounds; i++ {
+		rc[i] = binary.BigEndian.Uint64(sBox[8*(i-1) : 8*i])
+	}
+}
+
+type digest struct {
+	hash   [8]uint64       // the chaining state
+	buf    [BlockSize]byte // buffered data not yet processed
+	n      int             // bytes in buf
+	length uint64          // total bytes written
+}
+
+// New returns a new hash.Hash computing the Whirlpool checksum.
+func New() hash.Hash {
+	return &digest{}
+}
+
+// apply the round function to in, xoring with key to produce out
+func transform(out, in *[8]uint64, key *[8]uint64) {
+	for i := 0; i < 8; i++ {
+		var v uint64
+		for t := 0; t < 8; t++ {
+			v ^= c[t][byte(in[(i-t)&7]>>(56-8*uint(t)))]
+		}
+		out[i] = v ^ key[i]
+	}
+}
+
+// processBlock compresses a single block into the state
+func (d *digest) processBlock(b []byte) {
+	var block, state, k, l [8]uint64
+	for i := range block {
+		block[i] = binary.BigEndian.Uint64(b[8*i:])
+		k[i] = d.hash[i]
+		state[i] = block[i] ^ k[i]
+	}
+	var zero [8]uint64
+	for r := 1; r <= rounds; r++ {
+		// Compute the round key
+		transform(&l, &k, &zero)
+		l[0] ^= rc[r]
+		k = l
+		// Apply the round to the state
+		transform(&l, &state, &k)
+		state = l
+	}
+	for i := range d.hash {
+		d.hash[i] ^= state[i] ^ block[i]
+	}
+}
+
+// Write writes len(p) bytes from p to the underlying data stream. It returns
+// the number of bytes written from p (0 <= n <= len(p)) and any error
+// encountered that caused the write to stop early. Write must return a non-nil
+// error if it returns n < len(p). Write must not modify the slice data, even
+// temporarily.
+//
+// Implementations must not retain p.
+func (d *digest) Write(p []byte) (n int, err error) {
+	n = len(p)
+	d.length += uint64(n)
+	if d.n > 0 {
+		copied := copy(d.buf[d.n:], p)
+		d.n += copied
+		p = p[copied:]
+		if d.n < BlockSize {
+			return n, nil
+		}
+		d.processBlock(d.buf[:])
+		d.n = 0
+	}
+	for len(p) >= BlockSize {
+		d.processBlock(p[:BlockSize])
+		p = p[BlockSize:]
+	}
+	d.n = copy(d.buf[:], p)
+	return n, nil
+}
+
+// Sum appends the current hash to b and returns the resulting slice.
+// It does not change the underlying hash state.
+func (d *digest) Sum(b []byte) []byte {
+	// Work on a copy so the caller can keep writing
+	d0 := *d
+
+	// Pad with a 1 bit then 0 bits until the length fits at the
+	// end of a block
+	var padding [2 * BlockSize]byte
+	padding[0] = 0x80
+	padLen := BlockSize - lengthSize - d0.n
+	if padLen <= 0 {
+		padLen += BlockSize
+	}
+	// The length in bits is stored as a 256 bit big endian number
+	length := padding[padLen : padLen+lengthSize]
+	binary.BigEndian.PutUint64(length[lengthSize-8:], d0.length<<3)
+	binary.BigEndian.PutUint64(length[lengthSize-16:], d0.length>>61)
+	_, _ = d0.Write(padding[:padLen+lengthSize])
+
+	var out [Size]byte
+	for i, v := range d0.hash {
+		binary.BigEndian.PutUint64(out[8*i:], v)
+	}
+	return append(b, out[:]...)
+}
+
+// Reset resets the Hash to its initial state.
+func (d *digest) Reset() {
+	*d = digest{}
+}
+
+// Size returns the number of bytes Sum will return.
+func (d *digest) Size() int {
+	return Size
+}
+
+// BlockSize returns the hash's underlying block size.
+// The Write method must be able to accept any amount
+// of data, but it may operate more efficiently if all writes
+// are a multiple of the block size.
+func (d *digest) BlockSize() int {
+	return BlockSize
+}
+
+// Sum returns the Whirlpool checksum of the data.
+func Sum(data []byte) [Size]byte {
+	var d digest
+	_, _ = d.Write(data)
+	var out [Size]byte
+	d.Sum(out[:0])
+	return out
+}
+
+// must implement this interface
+var _ hash.Hash = (*digest)(nil)
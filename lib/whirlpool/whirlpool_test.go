@@ -0,0 +1,59 @@
+package whirlpool_test
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/ncw/rclone/lib/whirlpool"
+	"github.com/stretchr/testify/assert"
+)
+
+// Test vectors from the ISO/IEC 10118-3 test suite
+var testVectors = []struct {
+	in   string
+	want string
+}{
+	{"", "19fa61d75522a4669b44e39c1d2e1726c530232130d407f89afee0964997f7a73e83be698b288febcf88e3e03c4f0757ea8964e59b63d93708b138cc42a66eb3"},
+	{"a", "8aca2602792aec6f11a67206531fb7d7f0dff59413145e6973c45001d0087b42d11bc645413aeff63a42391a39145a591a92200d560195e53b478584fdae231a"},
+	{"abc", "4e2448a4c6f486bb16b6562c73b4020bf3043e3a731bce721ae1b303d97e6d4c7181eebdb6c57e277d0e34957114cbd6c797fc9d95d8b582d225292076d4eef5"},
+	{"message digest", "378c84a4126e2dc6e56dcc7458377aac838d00032230f53ce1f5700c0ffb4d3b8421557659ef55c106b4b52ac5a4aaa692ed920052838f3362e86dbd37a8903e"},
+	{"The quick brown fox jumps over the lazy dog", "b97de512e91e3828b40d2b0fdce9ceb3c4a71f9bea8d88e75c4fa854df36725fd2b52eb6544edcacd6f8beddfea403cb55ae31f03ad62a5ef54e42ee82c3fb35"},
+}
+
+func TestSum(t *testing.T) {
+	for _, test := range testVectors {
+		got := whirlpool.Sum([]byte(test.in))
+		assert.Equal(t, test.want, hex.EncodeToString(got[:]), fmt.Sprintf("input %q", test.in))
+	}
+}
+
+// Check that splitting the input into chunks doesn't change the result
+func TestChunks(t *testing.T) {
+	data := []byte(strings.Repeat("Hello, world! ", 1000))
+	want := whirlpool.Sum(data)
+	for _, chunk := range []int{1, 3, 31, 32, 33, 63, 64, 65, 4096} {
+		d := whirlpool.New()
+		for p := data; len(p) > 0; {
+			n := chunk
+			if n > len(p) {
+				n = len(p)
+			}
+			written, err := d.Write(p[:n])
+			assert.NoError(t, err)
+			assert.Equal(t, n, written)
+			p = p[n:]
+		}
+		assert.Equal(t, want[:], d.Sum(nil), fmt.Sprintf("chunk size %d", chunk))
+	}
+}
+
+// Check that Sum doesn't change the state
+func TestSumTwice(t *testing.T) {
+	d := whirlpool.New()
+	_, _ = d.Write([]byte("a"))
+	assert.Equal(t, d.Sum(nil), d.Sum(nil))
+	_, _ = d.Write([]byte("bc"))
+	assert.Equal(t, testVectors[2].want, hex.EncodeToString(d.Sum(nil)))
+}
@@ -0,0 +1,177 @@
+// Package xxhash64 implements the 64 bit xxHash algorithm (XXH64)
+// with a seed of 0 as described in
+//
+// https://github.com/Cyan4973/xxHash/blob/dev/doc/xxhash_spec.md
+//
+// The checksum is returned big endian which is the canonical form
+// used by the xxhsum tool.
+package xxhash64
+
+import (
+	"encoding/binary"
+	"hash"
+)
+
+const (
+	// BlockSize of the checksum in bytes.
+	BlockSize = 32
+	// Size of the checksum in bytes.
+	Size = 8
+
+	prime1 uint64 = 11400714785074694791
+	prime2 uint64 = 14029467366897019727
+	prime3 uint64 = 1609587929392839161
+	prime4 uint64 = 9650029242287828579
+	prime5 uint64 = 2870177450012600261
+)
+
+type digest struct {
+	v1, v2, v3, v4 uint64          // accumulators
+	total          uint64          // total bytes written
+	mem            [BlockSize]byte // buffered data not yet processed
+	n              int             // bytes in mem
+}
+
+// New returns a new hash.Hash64 computing the XXH64 checksum.
+func New() hash.Hash64 {
+	d := &digest{}
+	d.Reset()
+	return d
+}
+
+// rol rotates x left by r bits
+func rol(x uint64, r uint) uint64 {
+	return (x << r) | (x >> (64 - r))
+}
+
+func round(acc, input uint64) uint64 {
+	acc += input * prime2
+	acc = rol(acc, 31)
+	return acc * prime1
+}
+
+func mergeRound(acc, val uint64) uint64 {
+	val = round(0, val)
+	acc ^= val
+	return acc*prime1 + prime4
+}
+
+// processBlock updates the accumulators with a BlockSize block
+func (d *digest) processBlock(b []byte) {
+	d.v1 = round(d.v1, binary.LittleEndian.Uint64(b[0:8]))
+	d.v2 = round(d.v2, binary.LittleEndian.Uint64(b[8:16]))
+	d.v3 = round(d.v3, binary.LittleEndian.Uint64(b[16:24]))
+	d.v4 = round(d.v4, binary.LittleEndian.Uint64(b[24:32]))
+}
+
+// Write writes len(p) bytes from p to the underlying data stream. It returns
+// the number of bytes written from p (0 <= n <= len(p)) and any error
+// encountered that caused the write to stop early. Write must return a non-nil
+// error if it returns n < len(p). Write must not modify the slice data, even
+// temporarily.
+//
+// Implementations must not retain p.
+func (d *digest) Write(p []byte) (n int, err error) {
+	n = len(p)
+	d.total += uint64(n)
+	// Fill up the buffer first
+	if d.n > 0 {
+		copied := copy(d.mem[d.n:], p)
+		d.n += copied
+		p = p[copied:]
+		if d.n < BlockSize {
+			return n, nil
+		}
+		d.processBlock(d.mem[:])
+		d.n = 0
+	}
+	for len(p) >= BlockSize {
+		d.processBlock(p[:BlockSize])
+		p = p[BlockSize:]
+	}
+	d.n = copy(d.mem[:], p)
+	return n, nil
+}
+
+// Sum64 returns the current hash as a uint64.
+func (d *digest) Sum64() uint64 {
+	var h uint64
+	if d.total >= BlockSize {
+		h = rol(d.v1, 1) + rol(d.v2, 7) +
+			rol(d.v3, 12) + rol(d.v4, 18)
+		h = mergeRound(h, d.v1)
+		h = mergeRound(h, d.v2)
+		h = mergeRound(h, d.v3)
+		h = mergeRound(h, d.v4)
+	} else {
+		h = d.v3 + prime5
+	}
+	h += d.total
+
+	p := d.mem[:d.n]
+	for ; len(p) >= 8; p = p[8:] {
+		h ^= round(0, binary.LittleEndian.Uint64(p))
+		h = rol(h, 27)*prime1 + prime4
+	}
+	if len(p) >= 4 {
+		h ^= uint64(binary.LittleEndian.Uint32(p)) * prime1
+		h = rol(h, 23)*prime2 + prime3
+		p = p[4:]
+	}
+	for _, b := range p {
+		h ^= uint64(b) * prime5
+		h = rol(h, 11) * prime1
+	}
+
+	h ^= h >> 33
+	h *= prime2
+	h ^= h >> 29
+	h *= prime3
+	h ^= h >> 32
+	return h
+}
+
+// Sum appends the current hash to b and returns the resulting slice.
+// It does not change the underlying hash state.
+func (d *digest) Sum(b []byte) []byte {
+	var out [Size]byte
+	binary.BigEndian.PutUint64(out[:], d.Sum64())
+	return append(b, out[:]...)
+}
+
+// Reset resets the Hash to its initial state.
+func (d *digest) Reset() {
+	// use variables as the initial values overflow
+	p1, p2 := prime1, prime2
+	d.v1 = p1 + p2
+	d.v2 = p2
+	d.v3 = 0
+	d.v4 = -p1
+	d.total = 0
+	d.n = 0
+}
+
+// Size returns the number of bytes Sum will return.
+func (d *digest) Size() int {
+	return Size
+}
+
+// BlockSize returns the hash's underlying block size.
+// The Write method must be able to accept any amount
+// of data, but it may operate more efficiently if all writes
+// are a multiple of the block size.
+func (d *digest) BlockSize() int {
+	return BlockSize
+}
+
+// Sum returns the XXH64 checksum of the data.
+func Sum(data []byte) [Size]byte {
+	d := New()
+	_, _ = d.Write(data)
+	var out [Size]byte
+	d.Sum(out[:0])
+	return out
+}
+
+// must implement this interface
+var _ hash.Hash64 = (*digest)(nil)
@@ -0,0 +1,55 @@
+package xxhash64_test
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/ncw/rclone/lib/xxhash64"
+	"github.com/stretchr/testify/assert"
+)
+
+var testVectors = []struct {
+	in   string
+	want string
+}{
+	{"", "ef46db3751d8e999"},
+	{"a", "d24ec4f1a98c6e5b"},
+	{"abc", "44bc2cf5ad770999"},
+	{"The quick brown fox jumps over the lazy dog", "0b242d361fda71bc"},
+}
+
+func TestSum(t *testing.T) {
+	for _, test := range testVectors {
+		got := xxhash64.Sum([]byte(test.in))
+		assert.Equal(t, test.want, hex.EncodeToString(got[:]), fmt.Sprintf("input %q", test.in))
+	}
+}
+
+// Check that splitting the input into chunks doesn't change the result
+func TestChunks(t *testing.T) {
+	data := []byte(strings.Repeat("Hello, world! ", 1000))
+	want := xxhash64.Sum(data)
+	for _, chunk := range []int{1, 3, 7, 8, 31, 32, 33, 4096} {
+		d := xxhash64.New()
+		for p := data; len(p) > 0; {
+			n := chunk
+			if n > len(p) {
+				n = len(p)
+			}
+			written, err := d.Write(p[:n])
+			assert.NoError(t, err)
+			assert.Equal(t, n, written)
+			p = p[n:]
+		}
+		assert.Equal(t, want[:], d.Sum(nil), fmt.Sprintf("chunk size %d", chunk))
+	}
+}
+
+func TestReset(t *testing.T) {
+	d := xxhash64.New()
+	_, _ = d.Write([]byte("potato"))
+	d.Reset()
+	assert.Equal(t, testVectors[0].want, hex.EncodeToString(d.Sum(nil)))
+}
@@ -64,11 +64,15 @@ func (l *CacheMode) Type() string {
 
 // cache opened files
 type cache struct {
-	f      fs.Fs                 // fs for the cache directory
-	opt    *Options              // vfs Options
-	root   string                // root of the cache directory
-	itemMu sync.Mutex            // protects the next two maps
-	item   map[string]*cacheItem // files/directories in the cache
+	f       fs.Fs                 // fs for the cache directory
+	opt     *Options              // vfs Options
+	root    string                // root of the cache directory
+	fremote string                // name of the remote being cached
+	itemMu  sync.Mutex            // protects the next two maps
+	item    map[string]*cacheItem // files/directories in the cache
+	statsMu sync.Mutex            // protects the counters below
+	hits    int64                 // number of opens which found the file in the cache
+	misses  int64                 // number of opens which fetched the file from the remote
 }
 
 // cacheItem is stored in the item map
@@ -95,7 +99,8 @@ func newCache(ctx context.Context, f fs.Fs, opt *Options) (*cache, error) {
 		}
 		fRoot = strings.Replace(fRoot, ":", "", -1)
 	}
-	root := filepath.Join(config.CacheDir, "vfs", f.Name(), fRoot)
+	fremote := f.Name()
+	root := filepath.Join(config.CacheDir, "vfs", fremote, fRoot)
 	fs.Debugf(nil, "vfs cache root is %q", root)
 
 	f, err := fs.NewFs(root)
@@ -104,14 +109,22 @@ func newCache(ctx context.Context, f fs.Fs, opt *Options) (*cache, error) {
 	}
 
 	c := &cache{
-		f:    f,
-		opt:  opt,
-		root: root,
-		item: make(map[string]*cacheItem),
+		f:       f,
+		opt:     opt,
+		root:    root,
+		fremote: fremote,
+		item:    make(map[string]*cacheItem),
 	}
 
 	go c.cleaner(ctx)
 
+	// Export metrics for the cache until the context is cancelled
+	addCacheMetrics(c)
+	go func() {
+		<-ctx.Done()
+		removeCacheMetrics(c)
+	}()
+
 	return c, nil
 }
 
@@ -284,6 +297,38 @@ func (c *cache) close(name string) {
 	c.itemMu.Unlock()
 }
 
+// found records whether an open found the file in the cache (hit)
+// or had to fetch it from the remote
+func (c *cache) found(hit bool) {
+	c.statsMu.Lock()
+	if hit {
+		c.hits++
+	} else {
+		c.misses++
+	}
+	c.statsMu.Unlock()
+}
+
+// usage returns the number of files in the cache and their total size
+func (c *cache) usage() (items int64, size int64) {
+	c.itemMu.Lock()
+	var names []string
+	for name, item := range c.item {
+		if item.isFile {
+			names = append(names, name)
+		}
+	}
+	c.itemMu.Unlock()
+	for _, name := range names {
+		fi, err := os.Stat(c.toOSPath(name))
+		if err == nil && !fi.IsDir() {
+			items++
+			size += fi.Size()
+		}
+	}
+	return items, size
+}
+
 // remove should be called if name is deleted
 func (c *cache) remove(name string) {
 	osPath := c.toOSPath(name)
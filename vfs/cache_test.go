@@ -1,6 +1,7 @@
 package vfs
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"io/ioutil"
@@ -12,6 +13,7 @@ import (
 
 	"github.com/djherbis/times"
 	"github.com/ncw/rclone/fstest"
+	"github.com/ncw/rclone/lib/metrics"
 	"github.com/spf13/pflag"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -460,3 +462,51 @@ func TestCachePurgeOld(t *testing.T) {
 
 	assert.Equal(t, []string(nil), itemAsString(c))
 }
+
+func TestCacheUsageAndMetrics(t *testing.T) {
+	r := fstest.NewRun(t)
+	defer r.Finalise()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Disable the cache cleaner as it interferes with these tests
+	opt := DefaultOpt
+	opt.CachePollInterval = 0
+	c, err := newCache(ctx, r.Fremote, &opt)
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, c.cleanUp())
+	}()
+
+	// an item which isn't on disk isn't counted
+	c.open("potato")
+	items, size := c.usage()
+	assert.Equal(t, int64(0), items)
+	assert.Equal(t, int64(0), size)
+
+	p, err := c.mkdir("potato")
+	require.NoError(t, err)
+	require.NoError(t, ioutil.WriteFile(p, []byte("hello"), 0600))
+	c.cacheDir("sub")
+	items, size = c.usage()
+	assert.Equal(t, int64(1), items)
+	assert.Equal(t, int64(5), size)
+
+	c.found(true)
+	c.found(false)
+	c.found(false)
+	var out bytes.Buffer
+	require.NoError(t, metrics.Write(&out))
+	remote := fmt.Sprintf("{remote=%q}", r.Fremote.Name())
+	assert.Contains(t, out.String(), "rclone_vfs_cache_misses_total"+remote+" ")
+	assert.Contains(t, out.String(), "rclone_vfs_cache_size_bytes"+remote+" ")
+
+	// the metrics stop when the context is cancelled
+	cancel()
+	time.Sleep(10 * time.Millisecond)
+	cachesMu.Lock()
+	_, found := caches[c]
+	cachesMu.Unlock()
+	assert.False(t, found)
+}
@@ -0,0 +1,72 @@
+// Prometheus metrics for the VFS cache
+
+package vfs
+
+import (
+	"sync"
+
+	"github.com/ncw/rclone/lib/metrics"
+)
+
+var (
+	cachesMu sync.Mutex
+	caches   = make(map[*cache]struct{}) // caches to export metrics for
+)
+
+// addCacheMetrics starts exporting metrics for c
+func addCacheMetrics(c *cache) {
+	cachesMu.Lock()
+	caches[c] = struct{}{}
+	cachesMu.Unlock()
+}
+
+// removeCacheMetrics stops exporting metrics for c
+func removeCacheMetrics(c *cache) {
+	cachesMu.Lock()
+	delete(caches, c)
+	cachesMu.Unlock()
+}
+
+// cacheMetrics are the metrics for all the caches of a remote
+type cacheMetrics struct {
+	items  int64
+	size   int64
+	hits   int64
+	misses int64
+}
+
+// collectCaches adds the metrics for the caches to w, adding together
+// those for the same remote
+func collectCaches(w *metrics.Writer) {
+	cachesMu.Lock()
+	cs := make([]*cache, 0, len(caches))
+	for c := range caches {
+		cs = append(cs, c)
+	}
+	cachesMu.Unlock()
+	byRemote := make(map[string]*cacheMetrics)
+	for _, c := range cs {
+		m := byRemote[c.fremote]
+		if m == nil {
+			m = new(cacheMetrics)
+			byRemote[c.fremote] = m
+		}
+		items, size := c.usage()
+		m.items += items
+		m.size += size
+		c.statsMu.Lock()
+		m.hits += c.hits
+		m.misses += c.misses
+		c.statsMu.Unlock()
+	}
+	for remote, m := range byRemote {
+		w.Add("rclone_vfs_cache_items", metrics.Gauge, "Number of files in the VFS cache.", float64(m.items), "remote", remote)
+		w.Add("rclone_vfs_cache_size_bytes", metrics.Gauge, "Total size of the files in the VFS cache in bytes.", float64(m.size), "remote", remote)
+		w.Add("rclone_vfs_cache_hits_total", metrics.Counter, "Total number of opens which found the file in the VFS cache.", float64(m.hits), "remote", remote)
+		w.Add("rclone_vfs_cache_misses_total", metrics.Counter, "Total number of opens which fetched the file from the remote into the VFS cache.", float64(m.misses), "remote", remote)
+	}
+}
+
+func init() {
+	metrics.Register(collectCaches)
+}
@@ -1,6 +1,7 @@
 package vfs
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"io/ioutil"
@@ -84,10 +85,10 @@ func newRWFileHandle(d *Dir, f *File, remote string, flags int) (fh *RWFileHandl
 
 // copy an object to or from the remote while accounting for it
 func copyObj(f fs.Fs, dst fs.Object, remote string, src fs.Object) (newDst fs.Object, err error) {
-	if operations.NeedTransfer(dst, src) {
+	if operations.NeedTransfer(context.TODO(), dst, src) {
 		accounting.Stats.Transferring(src.Remote())
-		newDst, err = operations.Copy(f, dst, remote, src)
-		accounting.Stats.DoneTransferring(src.Remote(), err == nil)
+		newDst, err = operations.Copy(context.TODO(), f, dst, remote, src)
+		accounting.Stats.DoneTransferringError(src.Remote(), err)
 	} else {
 		newDst = dst
 	}
@@ -114,12 +115,14 @@ func (fh *RWFileHandle) openPending(truncate bool) (err error) {
 		// If the remote object exists AND its cached file exists locally AND there are no
 		// other RW handles with it open, then attempt to update it.
 		if o != nil && fh.file.rwOpens() == 0 {
-			cacheObj, err := fh.d.vfs.cache.f.NewObject(fh.remote)
+			cacheObj, err := fh.d.vfs.cache.f.NewObject(context.TODO(), fh.remote)
 			if err == nil && cacheObj != nil {
-				cacheObj, err = copyObj(fh.d.vfs.cache.f, cacheObj, fh.remote, o)
+				newCacheObj, err := copyObj(fh.d.vfs.cache.f, cacheObj, fh.remote, o)
 				if err != nil {
 					return errors.Wrap(err, "open RW handle failed to update cached file")
 				}
+				// it is a hit if the cached copy was up to date
+				fh.d.vfs.cache.found(newCacheObj == cacheObj)
 			}
 		}
 
@@ -129,6 +132,7 @@ func (fh *RWFileHandle) openPending(truncate bool) (err error) {
 			// cache file does not exist, so need to fetch it if we have an object to fetch
 			// it from
 			if o != nil {
+				fh.d.vfs.cache.found(false)
 				_, err = copyObj(fh.d.vfs.cache.f, nil, fh.remote, o)
 				if err != nil {
 					cause := errors.Cause(err)
@@ -295,7 +299,7 @@ func (fh *RWFileHandle) close() (err error) {
 
 	if copy {
 		// Transfer the temp file to the remote
-		cacheObj, err := fh.d.vfs.cache.f.NewObject(fh.remote)
+		cacheObj, err := fh.d.vfs.cache.f.NewObject(context.TODO(), fh.remote)
 		if err != nil {
 			err = errors.Wrap(err, "failed to find cache file")
 			fs.Errorf(fh.logPrefix(), "%v", err)
@@ -587,3 +587,24 @@ func TestRWFileModTimeWithOpenWriters(t *testing.T) {
 	// avoid errors because of timezone differences
 	assert.Equal(t, info.ModTime().Unix(), mtime.Unix())
 }
+
+func TestRWFileHandleCacheHitsAndMisses(t *testing.T) {
+	r := fstest.NewRun(t)
+	vfs, fh := rwHandleCreateReadOnly(t, r)
+	defer cleanup(t, r, vfs)
+
+	// The first read fetches the file into the cache
+	assert.Equal(t, "0123", rwReadString(t, fh, 4))
+	require.NoError(t, fh.Close())
+	assert.Equal(t, int64(0), vfs.cache.hits)
+	assert.Equal(t, int64(1), vfs.cache.misses)
+
+	// The second read finds it there
+	h, err := vfs.OpenFile("dir/file1", os.O_RDONLY, 0777)
+	require.NoError(t, err)
+	fh = h.(*RWFileHandle)
+	assert.Equal(t, "0123", rwReadString(t, fh, 4))
+	require.NoError(t, fh.Close())
+	assert.Equal(t, int64(1), vfs.cache.hits)
+	assert.Equal(t, int64(1), vfs.cache.misses)
+}